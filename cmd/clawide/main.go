@@ -11,6 +11,7 @@ import (
 
 	"github.com/davydany/ClawIDE/internal/banner"
 	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/ctl"
 	"github.com/davydany/ClawIDE/internal/mcpserve"
 	"github.com/davydany/ClawIDE/internal/pidfile"
 	"github.com/davydany/ClawIDE/internal/server"
@@ -26,6 +27,9 @@ func main() {
 		mcpserve.Run()
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		os.Exit(ctl.Run(os.Args[2:], os.Stdout, os.Stderr))
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -72,6 +76,12 @@ func main() {
 
 	srv := server.New(cfg, st, renderer)
 
+	// Record where this instance listens (server.New settled http vs https)
+	// for `clawide ctl`.
+	if err := pidfile.SetURL(pidPath, cfg.LocalURL()); err != nil {
+		log.Printf("Warning: recording URL in PID file: %v", err)
+	}
+
 	// Graceful shutdown
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
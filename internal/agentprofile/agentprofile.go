@@ -0,0 +1,93 @@
+// Package agentprofile turns agent profiles into the command lines typed
+// into agent panes. Built-in profiles come from the aicli provider catalog,
+// so the CLIs it knows about (and whether they are installed) show up
+// without configuration.
+package agentprofile
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/aicli"
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// interactive holds how each provider's CLI is started for an interactive
+// session, which differs from the one-shot invocation aicli uses.
+var interactive = map[string]struct {
+	args      string
+	modelFlag string
+	needModel bool
+}{
+	"claude": {modelFlag: "--model"},
+	"codex":  {modelFlag: "--model"},
+	"gemini": {modelFlag: "--model"},
+	"ollama": {args: "run", needModel: true},
+}
+
+// Builtins returns one profile per registered aicli provider, using the
+// provider ID as the profile ID. The CLI's own default model is used unless
+// it requires one.
+func Builtins(reg *aicli.Registry) []model.AgentProfile {
+	if reg == nil {
+		return nil
+	}
+	var out []model.AgentProfile
+	for _, p := range reg.List() {
+		cfg, ok := interactive[p.ID()]
+		if !ok {
+			continue
+		}
+		prof := model.AgentProfile{
+			ID:        p.ID(),
+			Name:      p.DisplayName(),
+			Provider:  p.ID(),
+			Command:   p.Binary(),
+			Args:      cfg.args,
+			ModelFlag: cfg.modelFlag,
+			Builtin:   true,
+			Installed: reg.IsInstalled(p.ID()),
+		}
+		if models := p.AvailableModels(); cfg.needModel && len(models) > 0 {
+			prof.Model = models[0].ID
+		}
+		out = append(out, prof)
+	}
+	return out
+}
+
+// Installed reports whether the profile's command is on PATH.
+func Installed(p model.AgentProfile) bool {
+	fields := strings.Fields(p.Command)
+	if len(fields) == 0 {
+		return false
+	}
+	_, err := exec.LookPath(fields[0])
+	return err == nil
+}
+
+// CommandLine is the shell command that starts the profile's agent.
+// Command and Args are used as written; the model is quoted.
+func CommandLine(p model.AgentProfile) string {
+	parts := []string{p.Command}
+	if p.Args != "" {
+		parts = append(parts, p.Args)
+	}
+	if p.Model != "" {
+		if p.ModelFlag != "" {
+			parts = append(parts, p.ModelFlag)
+		}
+		parts = append(parts, shellQuote(p.Model))
+	}
+	return strings.Join(parts, " ")
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9._:/@%+=,-]+$`)
+
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,44 @@
+package agentprofile
+
+import (
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/aicli"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltins(t *testing.T) {
+	reg := aicli.NewRegistry(nil)
+	aicli.RegisterDefaults(reg)
+
+	byID := map[string]model.AgentProfile{}
+	for _, p := range Builtins(reg) {
+		byID[p.ID] = p
+		assert.True(t, p.Builtin)
+		assert.Equal(t, reg.IsInstalled(p.ID), p.Installed)
+	}
+	require.Contains(t, byID, "claude")
+	assert.Equal(t, "claude", CommandLine(byID["claude"]))
+	if ollama, ok := byID["ollama"]; ok {
+		assert.NotEmpty(t, ollama.Model, "ollama run needs a model")
+		assert.Contains(t, CommandLine(ollama), "ollama run ")
+	}
+	assert.Nil(t, Builtins(nil))
+}
+
+func TestCommandLine(t *testing.T) {
+	assert.Equal(t, "codex --full-auto --model o3",
+		CommandLine(model.AgentProfile{Command: "codex", Args: "--full-auto", Model: "o3", ModelFlag: "--model"}))
+	assert.Equal(t, "llm chat 'my model'",
+		CommandLine(model.AgentProfile{Command: "llm chat", Model: "my model"}))
+	assert.Equal(t, `aider --model 'it'\''s'`,
+		CommandLine(model.AgentProfile{Command: "aider", Model: "it's", ModelFlag: "--model"}))
+}
+
+func TestInstalled(t *testing.T) {
+	assert.True(t, Installed(model.AgentProfile{Command: "sh -c true"}))
+	assert.False(t, Installed(model.AgentProfile{Command: "definitely-not-a-real-agent-cli"}))
+	assert.False(t, Installed(model.AgentProfile{}))
+}
@@ -0,0 +1,284 @@
+// Package agentstate classifies what the agent in each agent pane is doing
+// (working, idle, or waiting on a prompt) from its terminal output, and
+// publishes changes over the SSE hub.
+package agentstate
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/pty"
+	"github.com/davydany/ClawIDE/internal/sse"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/davydany/ClawIDE/internal/termsearch"
+	"github.com/davydany/ClawIDE/internal/tmux"
+)
+
+// State is the detected activity of an agent pane.
+type State string
+
+const (
+	StateWorking State = "working" // producing output or showing a busy indicator
+	StateIdle    State = "idle"    // quiet, waiting for the next instruction
+	StateWaiting State = "waiting" // blocked on a permission or confirmation prompt
+	StateStopped State = "stopped" // no terminal is running for the pane
+)
+
+// SSEEventType is the event name used on the notification stream.
+const SSEEventType = "agent-state"
+
+const (
+	pollInterval = 2 * time.Second
+	// quietPeriod is how long a pane must be silent before it counts as idle.
+	quietPeriod = 3 * time.Second
+	// screenTail is how many trailing screen lines are checked for prompts.
+	screenTail = 15
+)
+
+// promptPatterns match the confirmation prompts agent CLIs show while they
+// wait for the user: Claude Code's numbered "Do you want to ...?" menus,
+// y/n prompts, and generic approval requests.
+var promptPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bdo you want to\b.*\?`),
+	regexp.MustCompile(`(?i)\bwould you like to\b.*\?`),
+	regexp.MustCompile(`(?i)^\s*[❯>]\s*1\.\s*yes\b`),
+	regexp.MustCompile(`(?i)[\[(]y/n[\])]`),
+	regexp.MustCompile(`(?i)^\s*allow\b.*\?\s*$`),
+	regexp.MustCompile(`(?i)\b(approve|confirm)\b.*\?\s*$`),
+	regexp.MustCompile(`(?i)\bpress enter to continue\b`),
+}
+
+// busyPatterns match the status lines agent CLIs show while a turn is in
+// progress, even when the spinner is between redraws.
+var busyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\besc to interrupt\b`),
+	regexp.MustCompile(`(?i)\bctrl\+c to interrupt\b`),
+}
+
+// Snapshot is what the classifier looks at for one pane.
+type Snapshot struct {
+	Screen     []string  // visible screen lines, trailing blanks removed
+	LastOutput time.Time // zero if unknown
+}
+
+// Classify returns the pane's state and, when waiting, the prompt line that
+// triggered it.
+func Classify(s Snapshot, now time.Time) (State, string) {
+	tail := s.Screen
+	if len(tail) > screenTail {
+		tail = tail[len(tail)-screenTail:]
+	}
+	for _, line := range tail {
+		for _, re := range busyPatterns {
+			if re.MatchString(line) {
+				return StateWorking, ""
+			}
+		}
+	}
+	// The question is usually printed above the options, so report the
+	// first matching line.
+	for _, line := range tail {
+		for _, re := range promptPatterns {
+			if re.MatchString(line) {
+				return StateWaiting, strings.TrimSpace(line)
+			}
+		}
+	}
+	if !s.LastOutput.IsZero() && now.Sub(s.LastOutput) < quietPeriod {
+		return StateWorking, ""
+	}
+	return StateIdle, ""
+}
+
+// PaneState is the published state of one agent pane.
+type PaneState struct {
+	ProjectID   string    `json:"project_id"`
+	SessionID   string    `json:"session_id"`
+	SessionName string    `json:"session_name,omitempty"`
+	FeatureID   string    `json:"feature_id,omitempty"`
+	PaneID      string    `json:"pane_id"`
+	PaneName    string    `json:"pane_name,omitempty"`
+	State       State     `json:"state"`
+	Prompt      string    `json:"prompt,omitempty"`
+	Since       time.Time `json:"since"`
+	LastOutput  time.Time `json:"last_output,omitempty"`
+}
+
+// ChangeFunc is called after a pane's state changes. prev.State is empty the
+// first time a pane is seen.
+type ChangeFunc func(prev, cur PaneState)
+
+// Monitor polls every agent pane and keeps the latest state of each.
+type Monitor struct {
+	store  *store.Store
+	ptyMgr *pty.Manager
+	hub    *sse.Hub
+
+	mu       sync.RWMutex
+	states   map[string]PaneState // keyed by pane ID
+	handlers []ChangeFunc
+
+	stopCh chan struct{}
+	done   chan struct{}
+}
+
+func NewMonitor(st *store.Store, ptyMgr *pty.Manager, hub *sse.Hub) *Monitor {
+	return &Monitor{
+		store:  st,
+		ptyMgr: ptyMgr,
+		hub:    hub,
+		states: make(map[string]PaneState),
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// OnChange registers fn to be called on every state change. Register
+// handlers before Start.
+func (m *Monitor) OnChange(fn ChangeFunc) {
+	m.mu.Lock()
+	m.handlers = append(m.handlers, fn)
+	m.mu.Unlock()
+}
+
+func (m *Monitor) Start() {
+	go m.loop()
+}
+
+func (m *Monitor) Stop() {
+	close(m.stopCh)
+	<-m.done
+}
+
+// States returns the current state of every agent pane, ordered by project,
+// session and pane.
+func (m *Monitor) States() []PaneState {
+	m.mu.RLock()
+	out := make([]PaneState, 0, len(m.states))
+	for _, s := range m.states {
+		out = append(out, s)
+	}
+	m.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.ProjectID != b.ProjectID {
+			return a.ProjectID < b.ProjectID
+		}
+		if a.SessionID != b.SessionID {
+			return a.SessionID < b.SessionID
+		}
+		return a.PaneID < b.PaneID
+	})
+	return out
+}
+
+// Get returns the current state of one pane.
+func (m *Monitor) Get(paneID string) (PaneState, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.states[paneID]
+	return s, ok
+}
+
+func (m *Monitor) loop() {
+	defer close(m.done)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		m.poll(time.Now())
+		select {
+		case <-ticker.C:
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *Monitor) poll(now time.Time) {
+	seen := make(map[string]bool)
+	for _, sess := range m.store.GetAllSessions() {
+		if sess.Layout == nil {
+			continue
+		}
+		for _, paneID := range sess.Layout.CollectLeaves() {
+			node, _ := sess.Layout.FindPane(paneID)
+			if node == nil || node.EffectivePaneType() != model.PaneTypeAgent {
+				continue
+			}
+			seen[paneID] = true
+			state, prompt, lastOutput := m.classifyPane(paneID, now)
+			m.update(PaneState{
+				ProjectID:   sess.ProjectID,
+				SessionID:   sess.ID,
+				SessionName: sess.Name,
+				FeatureID:   sess.FeatureID,
+				PaneID:      paneID,
+				PaneName:    node.Name,
+				State:       state,
+				Prompt:      prompt,
+				Since:       now,
+				LastOutput:  lastOutput,
+			})
+		}
+	}
+
+	// Forget panes that were closed.
+	m.mu.Lock()
+	for id := range m.states {
+		if !seen[id] {
+			delete(m.states, id)
+		}
+	}
+	m.mu.Unlock()
+}
+
+func (m *Monitor) classifyPane(paneID string, now time.Time) (State, string, time.Time) {
+	tmuxName := tmux.TmuxName(paneID)
+	if !tmux.HasSession(tmuxName) {
+		return StateStopped, "", time.Time{}
+	}
+	screen, err := tmux.CapturePane(tmuxName, 0)
+	if err != nil {
+		log.Printf("[agentstate] capture %s: %v", tmuxName, err)
+		return StateStopped, "", time.Time{}
+	}
+
+	snap := Snapshot{Screen: termsearch.Lines([]byte(screen))}
+	// The attached PTY sees output as it happens; fall back to tmux's
+	// one-second activity clock for detached panes.
+	if ptySess, ok := m.ptyMgr.GetSession(paneID); ok {
+		snap.LastOutput = ptySess.LastOutput()
+	} else if t, err := tmux.LastActivity(tmuxName); err == nil {
+		snap.LastOutput = t
+	}
+	state, prompt := Classify(snap, now)
+	return state, prompt, snap.LastOutput
+}
+
+// update stores cur, keeping Since from the previous state when nothing
+// changed, and notifies handlers and SSE clients about changes.
+func (m *Monitor) update(cur PaneState) {
+	m.mu.Lock()
+	prev, existed := m.states[cur.PaneID]
+	if existed && prev.State == cur.State && prev.Prompt == cur.Prompt {
+		cur.Since = prev.Since
+		m.states[cur.PaneID] = cur
+		m.mu.Unlock()
+		return
+	}
+	m.states[cur.PaneID] = cur
+	handlers := append([]ChangeFunc(nil), m.handlers...)
+	m.mu.Unlock()
+
+	if m.hub != nil {
+		m.hub.Publish(sse.Event{Type: SSEEventType, ProjectID: cur.ProjectID, Data: cur})
+	}
+	for _, fn := range handlers {
+		fn(prev, cur)
+	}
+}
@@ -0,0 +1,139 @@
+package agentstate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/notify"
+	"github.com/davydany/ClawIDE/internal/sse"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		snap   Snapshot
+		state  State
+		prompt string
+	}{
+		{
+			name: "claude permission menu",
+			snap: Snapshot{Screen: []string{
+				"Bash command",
+				"  rm -rf build",
+				"Do you want to proceed?",
+				"❯ 1. Yes",
+				"  2. No, and tell Claude what to do differently (esc)",
+			}, LastOutput: now},
+			state:  StateWaiting,
+			prompt: "Do you want to proceed?",
+		},
+		{
+			name:   "y/n prompt",
+			snap:   Snapshot{Screen: []string{"Overwrite config.json? [y/N]"}},
+			state:  StateWaiting,
+			prompt: "Overwrite config.json? [y/N]",
+		},
+		{
+			name:  "busy indicator wins over old output",
+			snap:  Snapshot{Screen: []string{"✻ Thinking… (12s · esc to interrupt)"}, LastOutput: now.Add(-time.Minute)},
+			state: StateWorking,
+		},
+		{
+			name:  "recent output",
+			snap:  Snapshot{Screen: []string{"compiling..."}, LastOutput: now.Add(-time.Second)},
+			state: StateWorking,
+		},
+		{
+			name:  "quiet prompt",
+			snap:  Snapshot{Screen: []string{"> "}, LastOutput: now.Add(-time.Minute)},
+			state: StateIdle,
+		},
+		{
+			name:  "nothing known",
+			snap:  Snapshot{},
+			state: StateIdle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, prompt := Classify(tt.snap, now)
+			assert.Equal(t, tt.state, state)
+			assert.Equal(t, tt.prompt, prompt)
+		})
+	}
+}
+
+func TestMonitorUpdate(t *testing.T) {
+	hub := sse.NewHub()
+	events := hub.SubscribeEvents("test")
+	m := NewMonitor(nil, nil, hub)
+
+	var changes []PaneState
+	m.OnChange(func(prev, cur PaneState) { changes = append(changes, cur) })
+
+	t0 := time.Now()
+	m.update(PaneState{ProjectID: "p", PaneID: "a", State: StateWorking, Since: t0})
+	m.update(PaneState{ProjectID: "p", PaneID: "a", State: StateWorking, Since: t0.Add(time.Second)})
+	m.update(PaneState{ProjectID: "p", PaneID: "a", State: StateWaiting, Prompt: "Do you want to proceed?", Since: t0.Add(2 * time.Second)})
+
+	require.Len(t, changes, 2, "unchanged states are not re-announced")
+	assert.Equal(t, StateWaiting, changes[1].State)
+	assert.Len(t, events, 2)
+
+	got, ok := m.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, StateWaiting, got.State)
+	assert.Equal(t, t0.Add(2*time.Second), got.Since)
+	assert.Len(t, m.States(), 1)
+}
+
+func TestApprovalNotifier(t *testing.T) {
+	notifs, err := store.NewNotificationStore(filepath.Join(t.TempDir(), "notifications.json"), 50)
+	require.NoError(t, err)
+	hub := sse.NewHub()
+	broadcasts := hub.Subscribe("test")
+
+	cfg := &config.Config{ApprovalNotifyDelay: 1}
+	m := NewMonitor(nil, nil, nil)
+	n := NewApprovalNotifier(cfg, m, notify.New(notifs, nil, hub, nil))
+
+	since := time.Now().Add(-time.Minute)
+	waiting := PaneState{ProjectID: "p", SessionID: "s", SessionName: "Main", PaneID: "a", State: StateWaiting, Prompt: "Do you want to proceed?", Since: since, LastOutput: since}
+	m.update(waiting)
+
+	// Checking directly instead of waiting for the timer.
+	n.check(waiting)
+	n.check(waiting)
+
+	all := notifs.GetAll()
+	require.Len(t, all, 1, "one prompt notifies once")
+	assert.Equal(t, "a", all[0].PaneID)
+	assert.Equal(t, "s", all[0].SessionID)
+	assert.Contains(t, all[0].Body, "Do you want to proceed?")
+	assert.NotEmpty(t, all[0].IdempotencyKey)
+	assert.Len(t, broadcasts, 1)
+
+	t.Run("no notification once the prompt is answered", func(t *testing.T) {
+		m.update(PaneState{ProjectID: "p", PaneID: "a", State: StateWorking, Since: time.Now()})
+		n.check(waiting)
+		assert.Len(t, notifs.GetAll(), 1)
+	})
+
+	t.Run("recent output postpones the notification", func(t *testing.T) {
+		fresh := PaneState{ProjectID: "p", PaneID: "b", State: StateWaiting, Since: since, LastOutput: time.Now()}
+		m.update(fresh)
+		n.check(fresh)
+		assert.Len(t, notifs.GetAll(), 1)
+		n.mu.Lock()
+		_, pending := n.timers["b"]
+		n.mu.Unlock()
+		assert.True(t, pending)
+	})
+}
@@ -0,0 +1,111 @@
+package agentstate
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/notify"
+	"github.com/google/uuid"
+)
+
+// ApprovalNotifier creates a notification when an agent pane has been
+// sitting at a prompt, with no new output, for cfg.ApprovalNotifyDelay
+// seconds. It needs no cooperation from the agent (unlike clawide_notify).
+type ApprovalNotifier struct {
+	cfg      *config.Config
+	monitor  *Monitor
+	notifier *notify.Center
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer // keyed by pane ID
+}
+
+// NewApprovalNotifier registers the notifier with monitor.
+func NewApprovalNotifier(cfg *config.Config, monitor *Monitor, notifier *notify.Center) *ApprovalNotifier {
+	n := &ApprovalNotifier{
+		cfg:      cfg,
+		monitor:  monitor,
+		notifier: notifier,
+		timers:   make(map[string]*time.Timer),
+	}
+	monitor.OnChange(n.handle)
+	return n
+}
+
+func (n *ApprovalNotifier) delay() time.Duration {
+	return time.Duration(n.cfg.ApprovalNotifyDelay) * time.Second
+}
+
+func (n *ApprovalNotifier) handle(prev, cur PaneState) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if t, ok := n.timers[cur.PaneID]; ok {
+		t.Stop()
+		delete(n.timers, cur.PaneID)
+	}
+	if cur.State != StateWaiting || n.delay() <= 0 {
+		return
+	}
+	n.schedule(cur, n.delay())
+}
+
+// schedule re-checks the pane after d. Callers hold n.mu.
+func (n *ApprovalNotifier) schedule(waiting PaneState, d time.Duration) {
+	n.timers[waiting.PaneID] = time.AfterFunc(d, func() { n.check(waiting) })
+}
+
+// check fires the notification if the pane is still at the same prompt and
+// has been quiet for the whole delay; otherwise it waits out the remainder.
+func (n *ApprovalNotifier) check(waiting PaneState) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.timers, waiting.PaneID)
+
+	cur, ok := n.monitor.Get(waiting.PaneID)
+	if !ok || cur.State != StateWaiting || !cur.Since.Equal(waiting.Since) {
+		return
+	}
+	delay := n.delay()
+	if delay <= 0 {
+		return
+	}
+	if quiet := time.Since(cur.LastOutput); !cur.LastOutput.IsZero() && quiet < delay {
+		n.schedule(waiting, delay-quiet)
+		return
+	}
+	n.notify(cur)
+}
+
+func (n *ApprovalNotifier) notify(s PaneState) {
+	where := s.SessionName
+	if s.PaneName != "" {
+		where += " / " + s.PaneName
+	}
+	body := s.Prompt
+	if where != "" {
+		body = fmt.Sprintf("%s\n%s", where, s.Prompt)
+	}
+
+	notif := model.Notification{
+		ID:        uuid.New().String(),
+		Title:     "Agent is waiting for approval",
+		Body:      body,
+		Source:    "clawide",
+		Level:     "warning",
+		ProjectID: s.ProjectID,
+		SessionID: s.SessionID,
+		FeatureID: s.FeatureID,
+		PaneID:    s.PaneID,
+		// One notification per prompt: the key changes only when the pane
+		// leaves the waiting state and comes back.
+		IdempotencyKey: fmt.Sprintf("agent-waiting-%s-%d", s.PaneID, s.Since.UnixNano()),
+		CreatedAt:      time.Now(),
+	}
+	if _, _, err := n.notifier.Post(notif); err != nil {
+		log.Printf("[agentstate] notification add: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+// Package asciicast reads and writes terminal recordings in the asciicast v2
+// format (https://docs.asciinema.org/manual/asciicast/v2/): a JSON header
+// line followed by one JSON array per event.
+package asciicast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event types.
+const (
+	EventOutput = "o"
+	EventResize = "r"
+)
+
+// Header is the first line of a recording.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is one recorded frame: seconds since the start of the recording, the
+// event type and its data (output text, or "COLSxROWS" for resizes).
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// MarshalJSON encodes the event as asciicast's [time, type, data] triple.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Type, e.Data})
+}
+
+// UnmarshalJSON decodes an asciicast [time, type, data] triple.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("asciicast event has %d fields, want 3", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &e.Data)
+}
+
+// Writer appends events to a recording file. It is safe for concurrent use.
+type Writer struct {
+	mu      sync.Mutex
+	f       *os.File
+	start   time.Time
+	pending []byte // trailing bytes of an incomplete UTF-8 sequence
+}
+
+// Create starts a new recording at path and writes its header. A zero width
+// or height defaults to 80x24.
+func Create(path string, h Header) (*Writer, error) {
+	h.Version = 2
+	if h.Width <= 0 {
+		h.Width = 80
+	}
+	if h.Height <= 0 {
+		h.Height = 24
+	}
+	start := time.Now()
+	if h.Timestamp == 0 {
+		h.Timestamp = start.Unix()
+	}
+
+	line, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return &Writer{f: f, start: start}, nil
+}
+
+// Path returns the file being written.
+func (w *Writer) Path() string {
+	return w.f.Name()
+}
+
+// Output records terminal output. Multi-byte characters split across calls
+// are held back until complete, since event data must be valid UTF-8.
+func (w *Writer) Output(p []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := append(w.pending, p...)
+	cut := completeUTF8(data)
+	w.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return nil
+	}
+	return w.write(EventOutput, string(data[:cut]))
+}
+
+// Resize records a terminal size change.
+func (w *Writer) Resize(cols, rows int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.write(EventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// Close flushes any held-back bytes and closes the file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return nil
+	}
+	if len(w.pending) > 0 {
+		w.write(EventOutput, string(w.pending))
+		w.pending = nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+func (w *Writer) write(typ, data string) error {
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	line, err := json.Marshal(Event{Time: time.Since(w.start).Seconds(), Type: typ, Data: data})
+	if err != nil {
+		return err
+	}
+	_, err = w.f.Write(append(line, '\n'))
+	return err
+}
+
+// completeUTF8 returns the length of the longest prefix of p that does not
+// end in a truncated (but otherwise valid) UTF-8 sequence.
+func completeUTF8(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			return i
+		}
+		break
+	}
+	return len(p)
+}
+
+// ReadHeader reads only the header line of a recording.
+func ReadHeader(r io.Reader) (Header, error) {
+	var h Header
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return h, err
+	}
+	if err := json.Unmarshal(line, &h); err != nil {
+		return h, fmt.Errorf("invalid asciicast header: %w", err)
+	}
+	if h.Version != 2 {
+		return h, fmt.Errorf("unsupported asciicast version %d", h.Version)
+	}
+	return h, nil
+}
+
+// Read parses a whole recording. A truncated final line (a recording that
+// is still being written, or was cut off by a crash) is ignored.
+func Read(r io.Reader) (Header, []Event, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return Header{}, nil, err
+		}
+		return Header{}, nil, fmt.Errorf("empty asciicast file")
+	}
+	var h Header
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return h, nil, fmt.Errorf("invalid asciicast header: %w", err)
+	}
+	if h.Version != 2 {
+		return h, nil, fmt.Errorf("unsupported asciicast version %d", h.Version)
+	}
+
+	events := []Event{}
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			break
+		}
+		events = append(events, e)
+	}
+	return h, events, sc.Err()
+}
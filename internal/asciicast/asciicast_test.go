@@ -0,0 +1,79 @@
+package asciicast
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane.cast")
+	w, err := Create(path, Header{Width: 120, Height: 40, Title: "build"})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Output([]byte("hello ")))
+	// "é" split across two reads must come out as one character.
+	require.NoError(t, w.Output([]byte{0xc3}))
+	require.NoError(t, w.Output([]byte{0xa9, '\n'}))
+	require.NoError(t, w.Resize(100, 30))
+	require.NoError(t, w.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	h, events, err := Read(f)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, h.Version)
+	assert.Equal(t, 120, h.Width)
+	assert.Equal(t, "build", h.Title)
+	assert.NotZero(t, h.Timestamp)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, Event{Time: events[0].Time, Type: EventOutput, Data: "hello "}, events[0])
+	assert.Equal(t, "é\n", events[1].Data)
+	assert.Equal(t, EventResize, events[2].Type)
+	assert.Equal(t, "100x30", events[2].Data)
+	assert.LessOrEqual(t, events[0].Time, events[2].Time)
+}
+
+func TestCreateRefusesToOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane.cast")
+	require.NoError(t, os.WriteFile(path, []byte("x"), 0600))
+	_, err := Create(path, Header{})
+	assert.Error(t, err)
+}
+
+func TestReadIgnoresTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane.cast")
+	data := `{"version": 2, "width": 80, "height": 24}` + "\n" +
+		`[0.5, "o", "ok"]` + "\n" +
+		`[1.0, "o", "cut of`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	h, err := ReadHeader(f)
+	require.NoError(t, err)
+	assert.Equal(t, 80, h.Width)
+
+	f.Seek(0, 0)
+	_, events, err := Read(f)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "ok", events[0].Data)
+}
+
+func TestReadRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v1.cast")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 1}`+"\n"), 0600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, _, err = Read(f)
+	assert.Error(t, err)
+}
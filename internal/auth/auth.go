@@ -0,0 +1,599 @@
+// Package auth implements ClawIDE's built-in login: an admin password stored
+// as a hash in config.json, optional additional users (see model.User) whose
+// access is limited to the projects they are members of, HMAC-signed session
+// cookies for browsers, and per-device bearer tokens for API, WebSocket and
+// CLI clients.
+//
+// Authentication is enabled as soon as an admin password hash exists. Until
+// then every request is treated as the admin, which keeps the default
+// localhost-only install friction-free.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/google/uuid"
+)
+
+const (
+	// SessionCookieName is the browser session cookie.
+	SessionCookieName = "clawide_session"
+	// TokenPrefix marks ClawIDE bearer tokens so they are easy to spot in
+	// shell history and secret scanners.
+	TokenPrefix = "cide_"
+
+	sessionTTL     = 30 * 24 * time.Hour
+	maxFailures    = 5
+	lockoutPeriod  = time.Minute
+	agentTokenSalt = "clawide-agent-token"
+	paneTokenSalt  = "clawide-pane-token"
+	// paneTokenPrefix starts the tokens of panes opened by non-admin users.
+	paneTokenPrefix = TokenPrefix + "pane."
+
+	// AdminUsername is the login name of the instance admin. An empty
+	// username on the login form also means the admin.
+	AdminUsername = "admin"
+	// adminSessionUID stands in for the admin's (empty) user ID in cookies.
+	adminSessionUID = "-"
+)
+
+// Principal identifies who made a request. The zero value is the instance
+// admin, which is what every request resolves to while auth is disabled.
+type Principal struct {
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username"`
+	// Project, when set, confines the principal to that one project. Pane
+	// tokens of non-admin users carry it; see PaneToken for what that is
+	// worth.
+	Project string `json:"project,omitempty"`
+}
+
+// Admin is the principal for the instance admin.
+var Admin = Principal{Username: AdminUsername}
+
+// IsAdmin reports whether p is the instance admin.
+func (p Principal) IsAdmin() bool {
+	return p.UserID == ""
+}
+
+// Manager owns the password hash, the cookie signing key and the bearer
+// token store. It is safe for concurrent use.
+type Manager struct {
+	cfg    *config.Config
+	tokens *store.TokenStore
+	users  *store.UserStore
+	key    []byte
+
+	mu           sync.RWMutex
+	passwordHash string
+
+	failMu   sync.Mutex
+	failures map[string]*loginFailures
+}
+
+type loginFailures struct {
+	count       int
+	lockedUntil time.Time
+}
+
+// NewManager loads (or creates) the signing key and, if CLAWIDE_PASSWORD was
+// provided, hashes it into config.json.
+func NewManager(cfg *config.Config, tokens *store.TokenStore, users *store.UserStore) (*Manager, error) {
+	key, err := loadOrCreateKey(cfg.AuthKeyPath())
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		cfg:          cfg,
+		tokens:       tokens,
+		users:        users,
+		key:          key,
+		passwordHash: cfg.PasswordHash,
+		failures:     make(map[string]*loginFailures),
+	}
+
+	if cfg.Password != "" {
+		if !VerifyPassword(cfg.PasswordHash, cfg.Password) {
+			if err := m.SetPassword(cfg.Password); err != nil {
+				return nil, fmt.Errorf("applying CLAWIDE_PASSWORD: %w", err)
+			}
+			log.Println("[auth] password set from CLAWIDE_PASSWORD")
+		}
+		cfg.Password = ""
+	}
+
+	return m, nil
+}
+
+// loadOrCreateKey reads the 32-byte signing key, generating it on first run.
+func loadOrCreateKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil && len(data) >= 32 {
+		return data, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading auth key: %w", err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating auth key: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, fmt.Errorf("writing auth key: %w", err)
+	}
+	return key, nil
+}
+
+// Enabled reports whether a password has been configured.
+func (m *Manager) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.passwordHash != ""
+}
+
+// SetPassword hashes and persists a new password. An empty password removes
+// the hash and disables authentication.
+func (m *Manager) SetPassword(password string) error {
+	hash := ""
+	if password != "" {
+		var err error
+		hash, err = HashPassword(password)
+		if err != nil {
+			return err
+		}
+	}
+	if err := m.cfg.SavePasswordHash(hash); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.passwordHash = hash
+	m.mu.Unlock()
+	return nil
+}
+
+// CheckPassword verifies password. Callers should go through Login instead
+// when the attempt comes from a remote client so lockouts apply.
+func (m *Manager) CheckPassword(password string) bool {
+	m.mu.RLock()
+	hash := m.passwordHash
+	m.mu.RUnlock()
+	return hash != "" && VerifyPassword(hash, password)
+}
+
+// Login checks the credentials for the client at r.RemoteAddr, applying a
+// short lockout after repeated failures. An empty username or AdminUsername
+// signs in as the admin. On success it sets the session cookie.
+func (m *Manager) Login(w http.ResponseWriter, r *http.Request, username, password string) error {
+	ip := clientIP(r)
+
+	m.failMu.Lock()
+	f := m.failures[ip]
+	if f != nil && time.Now().Before(f.lockedUntil) {
+		m.failMu.Unlock()
+		return fmt.Errorf("too many failed attempts, try again later")
+	}
+	m.failMu.Unlock()
+
+	p, ok := m.checkCredentials(username, password)
+	if !ok {
+		m.failMu.Lock()
+		if f == nil {
+			f = &loginFailures{}
+			m.failures[ip] = f
+		}
+		f.count++
+		if f.count >= maxFailures {
+			f.count = 0
+			f.lockedUntil = time.Now().Add(lockoutPeriod)
+			log.Printf("[auth] locking out %s for %s after %d failed logins", ip, lockoutPeriod, maxFailures)
+		}
+		m.failMu.Unlock()
+		return fmt.Errorf("invalid username or password")
+	}
+
+	m.failMu.Lock()
+	delete(m.failures, ip)
+	m.failMu.Unlock()
+
+	m.issueSession(w, r, p)
+	return nil
+}
+
+func (m *Manager) checkCredentials(username, password string) (Principal, bool) {
+	username = strings.TrimSpace(username)
+	if username == "" || strings.EqualFold(username, AdminUsername) {
+		return Admin, m.CheckPassword(password)
+	}
+	u, ok := m.users.GetByUsername(username)
+	if !ok || !VerifyPassword(u.PasswordHash, password) {
+		return Principal{}, false
+	}
+	return Principal{UserID: u.ID, Username: u.Username}, true
+}
+
+// Logout clears the session cookie.
+func (m *Manager) Logout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (m *Manager) issueSession(w http.ResponseWriter, r *http.Request, p Principal) {
+	expires := time.Now().Add(sessionTTL)
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	uid := p.UserID
+	if p.IsAdmin() {
+		uid = adminSessionUID
+	}
+	payload := strconv.FormatInt(expires.Unix(), 10) + "." + uid + "." + base64.RawURLEncoding.EncodeToString(nonce)
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    payload + "." + m.sign(m.passwordHashFor(p), payload),
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// passwordHashFor returns the current password hash of p, or "" if p no
+// longer exists.
+func (m *Manager) passwordHashFor(p Principal) string {
+	if p.IsAdmin() {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return m.passwordHash
+	}
+	u, ok := m.users.Get(p.UserID)
+	if !ok {
+		return ""
+	}
+	return u.PasswordHash
+}
+
+// sign returns the hex HMAC of payload. The signature also covers the
+// principal's password hash, so changing a password logs that principal out
+// of every browser.
+func (m *Manager) sign(hash, payload string) string {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(hash))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (m *Manager) validSession(value string) (Principal, bool) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 4 {
+		return Principal{}, false
+	}
+	exp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Now().Unix() >= exp {
+		return Principal{}, false
+	}
+
+	p := Admin
+	if parts[1] != adminSessionUID {
+		u, ok := m.users.Get(parts[1])
+		if !ok {
+			return Principal{}, false
+		}
+		p = Principal{UserID: u.ID, Username: u.Username}
+	}
+	hash := m.passwordHashFor(p)
+	if hash == "" {
+		return Principal{}, false
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(m.sign(hash, payload))) {
+		return Principal{}, false
+	}
+	return p, true
+}
+
+// Authenticate reports whether r carries a valid session cookie, bearer token
+// or agent token. It always succeeds while authentication is disabled.
+func (m *Manager) Authenticate(r *http.Request) bool {
+	_, ok := m.Identify(r)
+	return ok
+}
+
+// Identify resolves the principal behind r. While authentication is disabled
+// every request is the admin. The agent token also maps to the admin; only
+// the admin's panes and local clients such as `clawide ctl` get it. Pane
+// tokens map to the user who opened the pane, confined to its project.
+func (m *Manager) Identify(r *http.Request) (Principal, bool) {
+	if !m.Enabled() {
+		return Admin, true
+	}
+	if c, err := r.Cookie(SessionCookieName); err == nil {
+		if p, ok := m.validSession(c.Value); ok {
+			return p, true
+		}
+	}
+	token := bearerToken(r)
+	if token == "" {
+		return Principal{}, false
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(m.AgentToken())) == 1 {
+		return Admin, true
+	}
+	if strings.HasPrefix(token, paneTokenPrefix) {
+		return m.identifyPaneToken(token)
+	}
+	t, ok := m.tokens.FindByHash(HashToken(token))
+	if !ok {
+		return Principal{}, false
+	}
+	p := Admin
+	if t.UserID != "" {
+		u, ok := m.users.Get(t.UserID)
+		if !ok {
+			return Principal{}, false
+		}
+		p = Principal{UserID: u.ID, Username: u.Username}
+	}
+	if err := m.tokens.Touch(t.ID, time.Now()); err != nil {
+		log.Printf("[auth] recording token use: %v", err)
+	}
+	return p, true
+}
+
+// AgentToken is the bearer token injected into terminal panes as
+// CLAWIDE_API_TOKEN so `clawide mcp-serve` and scripts running inside a pane
+// can reach the API. It is derived from the signing key, so it stays stable
+// across restarts and tmux sessions that outlive the server keep working.
+func (m *Manager) AgentToken() string {
+	return agentToken(m.key)
+}
+
+// ReadAgentToken returns the agent token for the signing key at keyPath, so
+// a local client running as the same user (clawide ctl) can authenticate
+// without a password. Anything running as that user, terminal panes
+// included, can do the same.
+func ReadAgentToken(keyPath string) (string, error) {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", err
+	}
+	if len(key) < 32 {
+		return "", fmt.Errorf("%s: key too short", keyPath)
+	}
+	return agentToken(key), nil
+}
+
+func agentToken(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(agentTokenSalt))
+	return TokenPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// PaneToken is the CLAWIDE_API_TOKEN of a pane p opens in a project. The
+// admin's panes get the agent token. Other users' panes get a token that
+// acts as that user and only in that project, so an agent doesn't act on
+// other projects by mistake. It is a guard rail, not a boundary: the pane
+// runs as the same OS user as the server and can read the signing key, and
+// with it derive the agent token (see ReadAgentToken).
+func (m *Manager) PaneToken(p Principal, projectID string) string {
+	if p.IsAdmin() {
+		return m.AgentToken()
+	}
+	payload := p.UserID + "." + projectID
+	return paneTokenPrefix + payload + "." + m.paneTokenMAC(payload)
+}
+
+func (m *Manager) paneTokenMAC(payload string) string {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(paneTokenSalt))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (m *Manager) identifyPaneToken(token string) (Principal, bool) {
+	parts := strings.Split(strings.TrimPrefix(token, paneTokenPrefix), ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return Principal{}, false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(m.paneTokenMAC(payload))) {
+		return Principal{}, false
+	}
+	u, ok := m.users.Get(parts[0])
+	if !ok {
+		return Principal{}, false
+	}
+	return Principal{UserID: u.ID, Username: u.Username, Project: parts[1]}, true
+}
+
+// CreateToken mints a new bearer token acting as owner. The plaintext is
+// returned once and never stored.
+func (m *Manager) CreateToken(name string, owner Principal) (model.APIToken, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return model.APIToken{}, "", fmt.Errorf("generating token: %w", err)
+	}
+	secret := TokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	t := model.APIToken{
+		ID:        uuid.New().String(),
+		Name:      name,
+		UserID:    owner.UserID,
+		Prefix:    secret[:len(TokenPrefix)+6],
+		TokenHash: HashToken(secret),
+		CreatedAt: time.Now(),
+	}
+	if err := m.tokens.Add(t); err != nil {
+		return model.APIToken{}, "", err
+	}
+	return t, secret, nil
+}
+
+// Tokens returns the bearer tokens visible to p: all of them for the admin,
+// otherwise only p's own. Hashes are included; callers must not expose them.
+func (m *Manager) Tokens(p Principal) []model.APIToken {
+	all := m.tokens.GetAll()
+	if p.IsAdmin() {
+		return all
+	}
+	out := make([]model.APIToken, 0, len(all))
+	for _, t := range all {
+		if t.UserID == p.UserID {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// RevokeToken deletes a bearer token visible to p.
+func (m *Manager) RevokeToken(id string, p Principal) error {
+	for _, t := range m.Tokens(p) {
+		if t.ID == id {
+			return m.tokens.Delete(id)
+		}
+	}
+	return fmt.Errorf("token %s not found", id)
+}
+
+// User looks up a non-admin user by ID.
+func (m *Manager) User(id string) (model.User, bool) {
+	return m.users.Get(id)
+}
+
+// Users returns all non-admin users.
+func (m *Manager) Users() []model.User {
+	return m.users.GetAll()
+}
+
+// CreateUser adds a non-admin user. Users can only sign in once the admin
+// password is set, since authentication is off until then.
+func (m *Manager) CreateUser(username, password string) (model.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" || strings.EqualFold(username, AdminUsername) || strings.ContainsAny(username, " .") {
+		return model.User{}, fmt.Errorf("invalid username %q", username)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return model.User{}, err
+	}
+	u := model.User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}
+	if err := m.users.Add(u); err != nil {
+		return model.User{}, err
+	}
+	return u, nil
+}
+
+// SetUserPassword replaces a user's password, which also signs them out everywhere.
+func (m *Manager) SetUserPassword(userID, password string) error {
+	u, ok := m.users.Get(userID)
+	if !ok {
+		return fmt.Errorf("user %s not found", userID)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = hash
+	return m.users.Update(u)
+}
+
+// CheckUserPassword verifies a non-admin user's current password.
+func (m *Manager) CheckUserPassword(userID, password string) bool {
+	u, ok := m.users.Get(userID)
+	return ok && VerifyPassword(u.PasswordHash, password)
+}
+
+// DeleteUser removes a user and revokes their bearer tokens. Project
+// memberships are left for the caller to clean up.
+func (m *Manager) DeleteUser(userID string) error {
+	if err := m.users.Delete(userID); err != nil {
+		return err
+	}
+	for _, t := range m.tokens.GetAll() {
+		if t.UserID == userID {
+			if err := m.tokens.Delete(t.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RefreshSession re-issues the session cookie for p, e.g. after p changed
+// their password and the old cookie's signature stopped matching.
+func (m *Manager) RefreshSession(w http.ResponseWriter, r *http.Request, p Principal) {
+	m.issueSession(w, r, p)
+}
+
+// NewSecret returns a random URL-safe token with the given prefix, along with
+// the hash to store for it. Used for share links and other capability URLs.
+func NewSecret(prefix string) (secret, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("generating token: %w", err)
+	}
+	secret = prefix + base64.RawURLEncoding.EncodeToString(raw)
+	return secret, HashToken(secret), nil
+}
+
+// HashToken returns the hex SHA-256 under which a token is stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// IsLoopbackHost reports whether host (as passed to --host) only accepts
+// connections from the local machine.
+func IsLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T, password string) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.Config{DataDir: dir, Password: password}
+	tokens, err := store.NewTokenStore(filepath.Join(dir, "tokens.json"))
+	require.NoError(t, err)
+	users, err := store.NewUserStore(filepath.Join(dir, "users.json"))
+	require.NoError(t, err)
+	m, err := NewManager(cfg, tokens, users)
+	require.NoError(t, err)
+	return m
+}
+
+func TestHashAndVerifyPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.True(t, VerifyPassword(hash, "correct horse"))
+	assert.False(t, VerifyPassword(hash, "wrong horse"))
+	assert.False(t, VerifyPassword("garbage", "correct horse"))
+
+	_, err = HashPassword("short")
+	assert.Error(t, err)
+}
+
+func TestManager_DisabledAllowsEverything(t *testing.T) {
+	m := newTestManager(t, "")
+	assert.False(t, m.Enabled())
+	assert.True(t, m.Authenticate(httptest.NewRequest("GET", "/", nil)))
+}
+
+func TestManager_InitialPasswordFromConfig(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+	assert.True(t, m.Enabled())
+	assert.Empty(t, m.cfg.Password, "plaintext password must be cleared after hashing")
+	assert.True(t, m.CheckPassword("s3cret-password"))
+
+	// The hash is persisted so a restart without the env var stays protected.
+	data, err := os.ReadFile(filepath.Join(m.cfg.DataDir, "config.json"))
+	require.NoError(t, err)
+	var onDisk config.Config
+	require.NoError(t, json.Unmarshal(data, &onDisk))
+	assert.Equal(t, m.cfg.PasswordHash, onDisk.PasswordHash)
+}
+
+func TestManager_LoginIssuesSessionCookie(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.False(t, m.Authenticate(req))
+
+	w := httptest.NewRecorder()
+	require.NoError(t, m.Login(w, httptest.NewRequest("POST", "/api/auth/login", nil), "", "s3cret-password"))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, SessionCookieName, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+
+	req.AddCookie(cookies[0])
+	assert.True(t, m.Authenticate(req))
+
+	t.Run("tampered cookie is rejected", func(t *testing.T) {
+		bad := httptest.NewRequest("GET", "/", nil)
+		bad.AddCookie(&http.Cookie{Name: SessionCookieName, Value: cookies[0].Value + "0"})
+		assert.False(t, m.Authenticate(bad))
+	})
+
+	t.Run("password change invalidates sessions", func(t *testing.T) {
+		require.NoError(t, m.SetPassword("another-password"))
+		assert.False(t, m.Authenticate(req))
+	})
+}
+
+func TestManager_LoginLockout(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+	req := httptest.NewRequest("POST", "/api/auth/login", nil)
+
+	for i := 0; i < maxFailures; i++ {
+		assert.Error(t, m.Login(httptest.NewRecorder(), req, "", "nope"))
+	}
+	// Even the right password is refused while locked out.
+	assert.Error(t, m.Login(httptest.NewRecorder(), req, "", "s3cret-password"))
+}
+
+func TestManager_BearerTokens(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+
+	tok, secret, err := m.CreateToken("laptop", Admin)
+	require.NoError(t, err)
+	assert.Contains(t, secret, TokenPrefix)
+	assert.NotContains(t, tok.TokenHash, secret)
+
+	req := httptest.NewRequest("GET", "/api/version", nil)
+	req.Header.Set("Authorization", "Bearer "+secret)
+	assert.True(t, m.Authenticate(req))
+	require.NotNil(t, m.Tokens(Admin)[0].LastUsedAt)
+
+	require.NoError(t, m.RevokeToken(tok.ID, Admin))
+	assert.False(t, m.Authenticate(req))
+}
+
+func TestManager_AgentTokenIsStable(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+
+	req := httptest.NewRequest("POST", "/api/notifications", nil)
+	req.Header.Set("Authorization", "Bearer "+m.AgentToken())
+	assert.True(t, m.Authenticate(req))
+
+	// A second manager over the same data dir reuses the signing key.
+	tokens, err := store.NewTokenStore(filepath.Join(m.cfg.DataDir, "tokens.json"))
+	require.NoError(t, err)
+	m2, err := NewManager(&config.Config{DataDir: m.cfg.DataDir}, tokens, m.users)
+	require.NoError(t, err)
+	assert.Equal(t, m.AgentToken(), m2.AgentToken())
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	assert.True(t, IsLoopbackHost("localhost"))
+	assert.True(t, IsLoopbackHost("127.0.0.1"))
+	assert.True(t, IsLoopbackHost("::1"))
+	assert.False(t, IsLoopbackHost("0.0.0.0"))
+	assert.False(t, IsLoopbackHost("192.168.1.10"))
+}
+
+func TestManager_UserLogin(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+	u, err := m.CreateUser("alice", "alice-password")
+	require.NoError(t, err)
+
+	_, err = m.CreateUser("admin", "whatever-password")
+	assert.Error(t, err, "admin is reserved")
+
+	assert.Error(t, m.Login(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), "alice", "s3cret-password"))
+
+	w := httptest.NewRecorder()
+	require.NoError(t, m.Login(w, httptest.NewRequest("POST", "/", nil), "alice", "alice-password"))
+	cookie := w.Result().Cookies()[0]
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookie)
+	p, ok := m.Identify(req)
+	require.True(t, ok)
+	assert.Equal(t, u.ID, p.UserID)
+	assert.False(t, p.IsAdmin())
+
+	t.Run("password change invalidates only that user's sessions", func(t *testing.T) {
+		aw := httptest.NewRecorder()
+		require.NoError(t, m.Login(aw, httptest.NewRequest("POST", "/", nil), "", "s3cret-password"))
+		adminReq := httptest.NewRequest("GET", "/", nil)
+		adminReq.AddCookie(aw.Result().Cookies()[0])
+
+		require.NoError(t, m.SetUserPassword(u.ID, "alice-password-2"))
+		_, ok := m.Identify(req)
+		assert.False(t, ok)
+		p, ok := m.Identify(adminReq)
+		assert.True(t, ok)
+		assert.True(t, p.IsAdmin())
+	})
+}
+
+func TestManager_UserTokens(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+	u, err := m.CreateUser("bob", "bob-password")
+	require.NoError(t, err)
+	bob := Principal{UserID: u.ID, Username: u.Username}
+
+	tok, secret, err := m.CreateToken("bob laptop", bob)
+	require.NoError(t, err)
+	_, _, err = m.CreateToken("admin laptop", Admin)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+secret)
+	p, ok := m.Identify(req)
+	require.True(t, ok)
+	assert.Equal(t, u.ID, p.UserID)
+
+	assert.Len(t, m.Tokens(bob), 1)
+	assert.Len(t, m.Tokens(Admin), 2)
+
+	// Deleting the user revokes their tokens.
+	require.NoError(t, m.DeleteUser(u.ID))
+	_, ok = m.Identify(req)
+	assert.False(t, ok)
+	_, ok = m.tokens.FindByHash(tok.TokenHash)
+	assert.False(t, ok)
+}
+
+func TestManager_PaneTokens(t *testing.T) {
+	m := newTestManager(t, "s3cret-password")
+	u, err := m.CreateUser("carol", "carol-password")
+	require.NoError(t, err)
+	carol := Principal{UserID: u.ID, Username: u.Username}
+
+	assert.Equal(t, m.AgentToken(), m.PaneToken(Admin, "proj-1"), "the admin's panes keep the agent token")
+
+	identify := func(token string) (Principal, bool) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return m.Identify(req)
+	}
+
+	token := m.PaneToken(carol, "proj-1")
+	assert.NotEqual(t, m.AgentToken(), token)
+	p, ok := identify(token)
+	require.True(t, ok)
+	assert.Equal(t, Principal{UserID: u.ID, Username: "carol", Project: "proj-1"}, p)
+	assert.False(t, p.IsAdmin())
+
+	// Changing the project (or user) in the token breaks its signature.
+	forged := paneTokenPrefix + u.ID + ".proj-2." + token[strings.LastIndex(token, ".")+1:]
+	_, ok = identify(forged)
+	assert.False(t, ok)
+	_, ok = identify(paneTokenPrefix + u.ID + ".proj-1")
+	assert.False(t, ok)
+
+	require.NoError(t, m.DeleteUser(u.ID))
+	_, ok = identify(token)
+	assert.False(t, ok, "a deleted user's panes lose access")
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	hashScheme     = "pbkdf2-sha256"
+	hashIterations = 600000
+	saltLen        = 16
+	keyLen         = 32
+)
+
+// MinPasswordLength is the shortest password HashPassword accepts.
+const MinPasswordLength = 8
+
+// HashPassword derives a PBKDF2-SHA256 hash for password. The result is a
+// self-describing string ("pbkdf2-sha256$<iterations>$<salt>$<key>") so the
+// iteration count can be raised later without invalidating stored hashes.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generating salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, keyLen)
+	if err != nil {
+		return "", fmt.Errorf("deriving key: %w", err)
+	}
+	return fmt.Sprintf("%s$%d$%s$%s",
+		hashScheme,
+		hashIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword reports whether password matches a hash produced by
+// HashPassword. Malformed hashes never match.
+func VerifyPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
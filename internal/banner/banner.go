@@ -2,9 +2,9 @@ package banner
 
 import (
 	"fmt"
-	"net"
 	"strings"
 
+	"github.com/davydany/ClawIDE/internal/portdetect"
 	qrcode "github.com/skip2/go-qrcode"
 )
 
@@ -60,20 +60,32 @@ var asciiArt = []string{
 	`  ╚═════╝╚══════╝╚═╝  ╚═╝ ╚══╝╚══╝ ╚═╝╚═════╝ ╚══════╝`,
 }
 
+// TLSInfo describes the certificate when the server speaks HTTPS.
+type TLSInfo struct {
+	Fingerprint string // SHA-256, colon-separated
+	CAFile      string // local CA to install on other devices; empty for user-supplied certificates
+}
+
 // Print renders the startup banner with ASCII art, server URL, and QR code.
-func Print(host string, port int, versionStr string) {
+// A nil tlsInfo means plain HTTP.
+func Print(host string, port int, versionStr string, tlsInfo *TLSInfo) {
+	scheme := "http"
+	if tlsInfo != nil {
+		scheme = "https"
+	}
+
 	// Determine the accessible URL
 	displayHost := host
 	if host == "0.0.0.0" || host == "::" || host == "" {
 		displayHost = "localhost"
 	}
-	localURL := fmt.Sprintf("http://%s:%d", displayHost, port)
+	localURL := fmt.Sprintf("%s://%s:%d", scheme, displayHost, port)
 
 	// Find LAN IP for QR code (phones/tablets need the real IP, not localhost)
 	lanIP := detectLANIP()
 	lanURL := ""
 	if lanIP != "" && (host == "0.0.0.0" || host == "::" || host == "") {
-		lanURL = fmt.Sprintf("http://%s:%d", lanIP, port)
+		lanURL = fmt.Sprintf("%s://%s:%d", scheme, lanIP, port)
 	}
 
 	fmt.Println()
@@ -100,6 +112,13 @@ func Print(host string, port int, versionStr string) {
 	if lanURL != "" {
 		fmt.Printf("  %s%sNetwork:%s %s%s\n", bold, white, reset, lanURL, reset)
 	}
+	if tlsInfo != nil {
+		fmt.Printf("  %s%sSHA-256:%s %s%s%s\n", bold, white, reset, gray, tlsInfo.Fingerprint, reset)
+		if tlsInfo.CAFile != "" {
+			fmt.Printf("  %s%sCA:%s      %s%s%s\n", bold, white, reset, gray, tlsInfo.CAFile, reset)
+			fmt.Printf("  %sInstall the CA on other devices to avoid certificate warnings.%s\n", gray, reset)
+		}
+	}
 
 	fmt.Println()
 
@@ -189,20 +208,5 @@ func renderTerminalQR(url string) string {
 // detectLANIP returns the first non-loopback IPv4 address found on the machine,
 // which is typically the address other devices on the same LAN can reach.
 func detectLANIP() string {
-	addrs, err := net.InterfaceAddrs()
-	if err != nil {
-		return ""
-	}
-	for _, addr := range addrs {
-		ipNet, ok := addr.(*net.IPNet)
-		if !ok {
-			continue
-		}
-		ip := ipNet.IP
-		if ip.IsLoopback() || ip.To4() == nil {
-			continue
-		}
-		return ip.String()
-	}
-	return ""
+	return portdetect.LANIP()
 }
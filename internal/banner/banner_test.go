@@ -6,11 +6,15 @@ import (
 
 func TestPrint_DoesNotPanic(t *testing.T) {
 	// Smoke test: calling Print should not panic regardless of host config.
-	Print("0.0.0.0", 9800, "ClawIDE dev (commit: none, built: unknown)")
+	Print("0.0.0.0", 9800, "ClawIDE dev (commit: none, built: unknown)", nil)
 }
 
 func TestPrint_SpecificHost(t *testing.T) {
-	Print("192.168.1.42", 3000, "ClawIDE v1.2.3 (commit: abc, built: 2025-01-01)")
+	Print("192.168.1.42", 3000, "ClawIDE v1.2.3 (commit: abc, built: 2025-01-01)", nil)
+}
+
+func TestPrint_TLS(t *testing.T) {
+	Print("0.0.0.0", 9800, "ClawIDE dev", &TLSInfo{Fingerprint: "AB:CD", CAFile: "/tmp/ca.pem"})
 }
 
 func TestDetectLANIP(t *testing.T) {
@@ -18,6 +18,7 @@ type Config struct {
 	ProjectsDir    string `json:"projects_dir"`
 	MaxSessions    int    `json:"max_sessions"`
 	ScrollbackSize int    `json:"scrollback_size"`
+	PersistScrollback bool `json:"persist_scrollback"` // keep pane scrollback on disk across restarts
 	AgentCommand   string `json:"agent_command"`
 	AgentArgs      string `json:"agent_args"`
 	LogLevel       string `json:"log_level"`
@@ -25,6 +26,7 @@ type Config struct {
 	OnboardingCompleted    bool   `json:"onboarding_completed"`
 	WorkspaceTourCompleted bool   `json:"workspace_tour_completed"`
 	MaxNotifications       int    `json:"max_notifications"`
+	ApprovalNotifyDelay    int    `json:"approval_notify_delay"` // seconds an agent must sit at a prompt before ClawIDE notifies; 0 disables
 	SidebarPosition        string `json:"sidebar_position"`
 	SidebarWidth           int    `json:"sidebar_width"`
 	AutoUpdateCheck        bool   `json:"auto_update_check"`
@@ -34,11 +36,25 @@ type Config struct {
 	Theme                  string `json:"theme"`
 	Mode                   string `json:"mode"`
 	Multiplexer            string `json:"multiplexer"`
+	PasswordHash           string `json:"password_hash,omitempty"`
+	TLS                    string `json:"tls"`
+	TLSCert                string `json:"tls_cert"`
+	TLSKey                 string `json:"tls_key"`
+	TLSEnabled             bool   `json:"-"`
+	Password               string `json:"-"`
 	Restart                bool   `json:"-"`
 	ShowVersion            bool   `json:"-"`
 	Mobile                 bool   `json:"-"`
 }
 
+// TLS modes. In auto mode ClawIDE serves HTTPS whenever a certificate is
+// configured or it listens on a non-loopback address.
+const (
+	TLSAuto = "auto"
+	TLSOn   = "on"
+	TLSOff  = "off"
+)
+
 func DefaultConfig() *Config {
 	home, _ := os.UserHomeDir()
 
@@ -57,11 +73,24 @@ func DefaultConfig() *Config {
 		LogLevel:         "info",
 		DataDir:          filepath.Join(home, ".clawide"),
 		MaxNotifications: 200,
+		ApprovalNotifyDelay: 20,
 		SidebarPosition:  "left",
 		SidebarWidth:     288,
 		AutoUpdateCheck:  true,
 		Multiplexer:     mux,
+		TLS:             TLSAuto,
+	}
+}
+
+// LoadClient loads the config file and environment but not command-line
+// flags, for subcommands that talk to a running instance (clawide ctl).
+func LoadClient() (*Config, error) {
+	cfg := DefaultConfig()
+	if err := cfg.loadFile(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("loading config file: %w", err)
 	}
+	cfg.loadEnv()
+	return cfg, nil
 }
 
 func Load() (*Config, error) {
@@ -94,6 +123,19 @@ func Load() (*Config, error) {
 	// Expand ~ in paths
 	cfg.ProjectsDir = expandHome(cfg.ProjectsDir)
 	cfg.DataDir = expandHome(cfg.DataDir)
+	cfg.TLSCert = expandHome(cfg.TLSCert)
+	cfg.TLSKey = expandHome(cfg.TLSKey)
+
+	switch cfg.TLS {
+	case TLSAuto, TLSOn, TLSOff:
+	case "":
+		cfg.TLS = TLSAuto
+	default:
+		return nil, fmt.Errorf("invalid --tls mode %q (want auto, on or off)", cfg.TLS)
+	}
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be set together")
+	}
 
 	// Ensure data directory exists
 	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
@@ -150,6 +192,11 @@ func (c *Config) loadEnv() {
 			c.ScrollbackSize = n
 		}
 	}
+	if v := os.Getenv("CLAWIDE_PERSIST_SCROLLBACK"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.PersistScrollback = b
+		}
+	}
 	if v := os.Getenv("CLAWIDE_AGENT_COMMAND"); v != "" {
 		c.AgentCommand = v
 	} else if v := os.Getenv("CLAWIDE_CLAUDE_COMMAND"); v != "" {
@@ -158,6 +205,11 @@ func (c *Config) loadEnv() {
 	if v := os.Getenv("CLAWIDE_AGENT_ARGS"); v != "" {
 		c.AgentArgs = v
 	}
+	if v := os.Getenv("CLAWIDE_APPROVAL_NOTIFY_DELAY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.ApprovalNotifyDelay = n
+		}
+	}
 	if v := os.Getenv("CLAWIDE_LOG_LEVEL"); v != "" {
 		c.LogLevel = v
 	}
@@ -167,6 +219,20 @@ func (c *Config) loadEnv() {
 	if v := os.Getenv("CLAWIDE_MULTIPLEXER"); v != "" {
 		c.Multiplexer = v
 	}
+	if v := os.Getenv("CLAWIDE_TLS"); v != "" {
+		c.TLS = v
+	}
+	if v := os.Getenv("CLAWIDE_TLS_CERT"); v != "" {
+		c.TLSCert = v
+	}
+	if v := os.Getenv("CLAWIDE_TLS_KEY"); v != "" {
+		c.TLSKey = v
+	}
+	// The initial password is only accepted from the environment (not a flag)
+	// so it never shows up in `ps` output. It is hashed on startup.
+	if v := os.Getenv("CLAWIDE_PASSWORD"); v != "" {
+		c.Password = v
+	}
 }
 
 func (c *Config) loadFlags() {
@@ -174,11 +240,15 @@ func (c *Config) loadFlags() {
 	flag.IntVar(&c.Port, "port", c.Port, "Listen port")
 	flag.StringVar(&c.ProjectsDir, "projects-dir", c.ProjectsDir, "Projects root directory")
 	flag.IntVar(&c.MaxSessions, "max-sessions", c.MaxSessions, "Maximum concurrent sessions")
+	flag.BoolVar(&c.PersistScrollback, "persist-scrollback", c.PersistScrollback, "Save pane scrollback to disk so it survives restarts")
 	flag.StringVar(&c.AgentCommand, "agent-command", c.AgentCommand, "AI agent command to auto-launch in new panes")
 	flag.StringVar(&c.AgentArgs, "agent-args", c.AgentArgs, "Additional CLI arguments for the AI agent command")
 	flag.StringVar(&c.LogLevel, "log-level", c.LogLevel, "Log level (debug, info, warn, error)")
 	flag.StringVar(&c.DataDir, "data-dir", c.DataDir, "Data directory for state/config")
 	flag.StringVar(&c.Multiplexer, "multiplexer", c.Multiplexer, "Terminal multiplexer binary (tmux or psmux)")
+	flag.StringVar(&c.TLS, "tls", c.TLS, "Serve HTTPS: auto (when a certificate is set or the host is not loopback), on, or off")
+	flag.StringVar(&c.TLSCert, "tls-cert", c.TLSCert, "TLS certificate file (PEM); a self-signed one is generated when unset")
+	flag.StringVar(&c.TLSKey, "tls-key", c.TLSKey, "TLS private key file (PEM)")
 	flag.BoolVar(&c.Mobile, "mobile", false, "Bind to 0.0.0.0 for mobile/LAN access")
 	flag.BoolVar(&c.Restart, "restart", false, "Kill existing instance and restart")
 	flag.BoolVar(&c.ShowVersion, "version", false, "Print version information and exit")
@@ -227,6 +297,78 @@ func (c *Config) PromptForgeDir() string {
 	return filepath.Join(c.DataDir, "promptforge")
 }
 
+func (c *Config) ShareLinksFilePath() string {
+	return filepath.Join(c.DataDir, "share_links.json")
+}
+
+func (c *Config) LayoutTemplatesFilePath() string {
+	return filepath.Join(c.DataDir, "layout_templates.json")
+}
+
+func (c *Config) AgentProfilesFilePath() string {
+	return filepath.Join(c.DataDir, "agent_profiles.json")
+}
+
+func (c *Config) UsersFilePath() string {
+	return filepath.Join(c.DataDir, "users.json")
+}
+
+func (c *Config) TokensFilePath() string {
+	return filepath.Join(c.DataDir, "tokens.json")
+}
+
+func (c *Config) WebhooksFilePath() string {
+	return filepath.Join(c.DataDir, "webhooks.json")
+}
+
+func (c *Config) WebhookDeliveriesFilePath() string {
+	return filepath.Join(c.DataDir, "webhook_deliveries.json")
+}
+
+func (c *Config) PushSubscriptionsFilePath() string {
+	return filepath.Join(c.DataDir, "push_subscriptions.json")
+}
+
+func (c *Config) NotificationRulesFilePath() string {
+	return filepath.Join(c.DataDir, "notification_rules.json")
+}
+
+// VAPIDKeysPath returns the file holding the key pair that signs Web Push
+// requests.
+func (c *Config) VAPIDKeysPath() string {
+	return filepath.Join(c.DataDir, "vapid.json")
+}
+
+// AuthKeyPath returns the file holding the HMAC key used to sign session cookies.
+func (c *Config) AuthKeyPath() string {
+	return filepath.Join(c.DataDir, "auth.key")
+}
+
+// ScrollbackDir holds the compressed scrollback of detached panes when
+// PersistScrollback is on.
+func (c *Config) ScrollbackDir() string {
+	return filepath.Join(c.DataDir, "scrollback")
+}
+
+// TLSDir holds the auto-generated local CA and server certificate.
+func (c *Config) TLSDir() string {
+	return filepath.Join(c.DataDir, "tls")
+}
+
+// Scheme returns "https" once the server has decided to serve TLS.
+func (c *Config) Scheme() string {
+	if c.TLSEnabled {
+		return "https"
+	}
+	return "http"
+}
+
+// LocalURL is how processes on this machine (terminal panes, mcp-serve)
+// reach the server.
+func (c *Config) LocalURL() string {
+	return fmt.Sprintf("%s://localhost:%d", c.Scheme(), c.Port)
+}
+
 func (c *Config) UpdateStatePath() string {
 	return filepath.Join(c.DataDir, "update-state.json")
 }
@@ -271,6 +413,34 @@ func (c *Config) SaveFile() error {
 	return nil
 }
 
+// SavePasswordHash persists the login password hash. Unlike SaveFile it only
+// touches the password_hash key, so values that came from flags or the
+// environment (e.g. --mobile's 0.0.0.0 host) are not written back to disk.
+// An empty hash removes the key, which disables authentication.
+func (c *Config) SavePasswordHash(hash string) error {
+	path := filepath.Join(c.DataDir, "config.json")
+	existing := make(map[string]any)
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("parsing config file: %w", err)
+		}
+	}
+	if hash == "" {
+		delete(existing, "password_hash")
+	} else {
+		existing["password_hash"] = hash
+	}
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	c.PasswordHash = hash
+	return nil
+}
+
 func expandHome(path string) string {
 	if len(path) > 0 && path[0] == '~' {
 		home, err := os.UserHomeDir()
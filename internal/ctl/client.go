@@ -0,0 +1,178 @@
+package ctl
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/auth"
+	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/pidfile"
+	"github.com/davydany/ClawIDE/internal/tlscert"
+	"github.com/gorilla/websocket"
+)
+
+// client talks to a running ClawIDE instance.
+type client struct {
+	baseURL    string
+	token      string
+	tlsConfig  *tls.Config
+	httpClient *http.Client
+}
+
+// apiError is a non-2xx response.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("server returned %d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+}
+
+// connect finds the instance to talk to. The URL comes from --url, then
+// CLAWIDE_API_URL (set inside panes), then the PID file of a running
+// instance. The token comes from --token, then CLAWIDE_API_TOKEN, then the
+// agent token derived from the instance's signing key.
+func connect(rawURL, token string) (*client, error) {
+	cfg, err := config.LoadClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if rawURL == "" {
+		rawURL = os.Getenv("CLAWIDE_API_URL")
+	}
+	if rawURL == "" {
+		path := cfg.PidFilePath()
+		pid, err := pidfile.Read(path)
+		if err != nil || !pidfile.IsRunning(pid) {
+			return nil, fmt.Errorf("no running ClawIDE instance found (%s); start clawide or pass --url", path)
+		}
+		// Instances from before the URL was recorded: assume the config.
+		if rawURL, _ = pidfile.ReadURL(path); rawURL == "" {
+			rawURL = cfg.LocalURL()
+		}
+	}
+	if _, err := url.Parse(rawURL); err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	if token == "" {
+		token = os.Getenv("CLAWIDE_API_TOKEN")
+	}
+	if token == "" {
+		// Missing when authentication was never set up, which is fine.
+		token, _ = auth.ReadAgentToken(cfg.AuthKeyPath())
+	}
+
+	c := &client{
+		baseURL:    strings.TrimRight(rawURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	caFile := os.Getenv("CLAWIDE_CA_CERT")
+	if caFile == "" {
+		if f := tlscert.FilesIn(cfg.TLSDir()).CAFile; fileExists(f) {
+			caFile = f
+		}
+	}
+	if caFile != "" {
+		pool, err := tlscert.CertPoolWith(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading CA certificate: %w", err)
+		}
+		c.tlsConfig = &tls.Config{RootCAs: pool}
+		c.httpClient.Transport = &http.Transport{TLSClientConfig: c.tlsConfig}
+	}
+	return c, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// do sends a request and decodes a JSON response into out (if non-nil).
+// body is sent as JSON, or as a form when it is url.Values.
+func (c *client) do(method, path string, body, out any) error {
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case url.Values:
+		reader = strings.NewReader(b.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	c.authorize(req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &apiError{Status: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func (c *client) authorize(h http.Header) {
+	if c.token != "" {
+		h.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// dial opens a WebSocket to path on the instance.
+func (c *client) dial(path string) (*websocket.Conn, error) {
+	wsURL := "ws" + strings.TrimPrefix(c.baseURL, "http") + path
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:  c.tlsConfig,
+	}
+	h := http.Header{}
+	c.authorize(h)
+	conn, resp, err := dialer.Dial(wsURL, h)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= 400 {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			return nil, &apiError{Status: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+		}
+		return nil, err
+	}
+	return conn, nil
+}
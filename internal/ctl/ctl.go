@@ -0,0 +1,497 @@
+// Package ctl implements `clawide ctl`, a command-line client for a running
+// ClawIDE instance.
+package ctl
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/gorilla/websocket"
+)
+
+const usage = `Usage: clawide ctl [flags] <command> [command flags] [args]
+
+Commands:
+  projects                              list projects
+  sessions [--feature ID]               list a project's sessions and panes
+  features                              list a project's features
+  feature create [--base B] [--type T] <name>
+                                        create a feature (branch + worktree)
+  feature delete <feature-id>           delete a feature and its worktree
+  send [--no-enter] <pane-id> <text>    type text into a pane
+  tail [-n N] [-f] <pane-id>            print a pane's output; -f follows it
+  task add [--column C] [--group G] [--description D] <title>
+                                        add a task to a project's board
+  notify [--body B] [--level L] <title> post a notification
+
+Flags (accepted before or after the command):
+  --url URL       ClawIDE URL (default: $CLAWIDE_API_URL, then the running instance)
+  --token TOKEN   API token (default: $CLAWIDE_API_TOKEN, then the local agent token)
+  -p, --project   project ID or name (default: $CLAWIDE_PROJECT_ID, then the current directory)
+  --json          print JSON instead of tables
+`
+
+// errUsage makes Run print the usage and exit 2.
+var errUsage = errors.New("usage")
+
+type cli struct {
+	stdout, stderr io.Writer
+
+	url, token, project string
+	json                bool
+
+	client *client
+}
+
+// Run runs `clawide ctl` with the arguments after "ctl" and returns the exit
+// code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	fs := c.flags("ctl")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch cmd, rest := args[0], args[1:]; cmd {
+	case "projects":
+		err = c.projects(rest)
+	case "sessions":
+		err = c.sessions(rest)
+	case "features":
+		err = c.features(rest)
+	case "feature":
+		err = c.feature(rest)
+	case "send":
+		err = c.send(rest)
+	case "tail":
+		err = c.tail(rest)
+	case "task":
+		err = c.task(rest)
+	case "notify":
+		err = c.notify(rest)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "clawide ctl: unknown command %q\n\n%s", cmd, usage)
+		return 2
+	}
+	switch {
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		fmt.Fprint(stderr, usage)
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "clawide ctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// flags returns a flag set with the flags every command accepts.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {}
+	fs.StringVar(&c.url, "url", c.url, "")
+	fs.StringVar(&c.token, "token", c.token, "")
+	fs.StringVar(&c.project, "project", c.project, "")
+	fs.StringVar(&c.project, "p", c.project, "")
+	fs.BoolVar(&c.json, "json", c.json, "")
+	return fs
+}
+
+// parse parses a command's flags and checks it got n positional arguments.
+func parse(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != n {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+func (c *cli) api() (*client, error) {
+	if c.client == nil {
+		cl, err := connect(c.url, c.token)
+		if err != nil {
+			return nil, err
+		}
+		c.client = cl
+	}
+	return c.client, nil
+}
+
+// resolveProject returns the project named by -p, $CLAWIDE_PROJECT_ID, or
+// the one containing the current directory.
+func (c *cli) resolveProject() (model.Project, error) {
+	cl, err := c.api()
+	if err != nil {
+		return model.Project{}, err
+	}
+	var projects []model.Project
+	if err := cl.do(http.MethodGet, "/projects/", nil, &projects); err != nil {
+		return model.Project{}, err
+	}
+
+	want := c.project
+	if want == "" {
+		want = os.Getenv("CLAWIDE_PROJECT_ID")
+	}
+	if want != "" {
+		for _, p := range projects {
+			if p.ID == want {
+				return p, nil
+			}
+		}
+		var match []model.Project
+		for _, p := range projects {
+			if strings.EqualFold(p.Name, want) {
+				match = append(match, p)
+			}
+		}
+		switch len(match) {
+		case 1:
+			return match[0], nil
+		case 0:
+			return model.Project{}, fmt.Errorf("no project %q", want)
+		default:
+			return model.Project{}, fmt.Errorf("several projects are named %q; use the ID", want)
+		}
+	}
+
+	// The deepest project containing the working directory.
+	cwd, err := os.Getwd()
+	if err == nil {
+		var best model.Project
+		for _, p := range projects {
+			if within(cwd, p.Path) && len(p.Path) > len(best.Path) {
+				best = p
+			}
+		}
+		if best.ID != "" {
+			return best, nil
+		}
+	}
+	return model.Project{}, errors.New("no project given and the current directory is in none; pass -p")
+}
+
+func within(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// print writes v as JSON with --json, otherwise calls table.
+func (c *cli) print(v any, table func(w io.Writer)) error {
+	if c.json {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+func (c *cli) projects(args []string) error {
+	if _, err := parse(c.flags("projects"), args, 0); err != nil {
+		return err
+	}
+	cl, err := c.api()
+	if err != nil {
+		return err
+	}
+	var projects []model.Project
+	if err := cl.do(http.MethodGet, "/projects/", nil, &projects); err != nil {
+		return err
+	}
+	return c.print(projects, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tPATH")
+		for _, p := range projects {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", p.ID, p.Name, p.Path)
+		}
+	})
+}
+
+func (c *cli) sessions(args []string) error {
+	fs := c.flags("sessions")
+	featureID := fs.String("feature", "", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	project, err := c.resolveProject()
+	if err != nil {
+		return err
+	}
+	path := "/projects/" + project.ID + "/sessions/"
+	if *featureID != "" {
+		path += "?feature_id=" + url.QueryEscape(*featureID)
+	}
+	var sessions []model.Session
+	if err := c.client.do(http.MethodGet, path, nil, &sessions); err != nil {
+		return err
+	}
+	return c.print(sessions, func(w io.Writer) {
+		fmt.Fprintln(w, "SESSION\tNAME\tFEATURE\tPANE\tPANE NAME\tTYPE")
+		for _, s := range sessions {
+			if s.Layout == nil {
+				fmt.Fprintf(w, "%s\t%s\t%s\t\t\t\n", s.ID, s.Name, s.FeatureID)
+				continue
+			}
+			for _, paneID := range s.Layout.CollectLeaves() {
+				pane, _ := s.Layout.FindPane(paneID)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.Name, s.FeatureID, paneID, pane.Name, pane.EffectivePaneType())
+			}
+		}
+	})
+}
+
+func (c *cli) features(args []string) error {
+	if _, err := parse(c.flags("features"), args, 0); err != nil {
+		return err
+	}
+	project, err := c.resolveProject()
+	if err != nil {
+		return err
+	}
+	var features []model.Feature
+	if err := c.client.do(http.MethodGet, "/projects/"+project.ID+"/features/", nil, &features); err != nil {
+		return err
+	}
+	return c.print(features, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tTYPE\tBRANCH\tWORKTREE")
+		for _, f := range features {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.ID, f.Name, f.Type, f.BranchName, f.WorktreePath)
+		}
+	})
+}
+
+func (c *cli) feature(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "create":
+		fs := c.flags("feature create")
+		base := fs.String("base", "", "")
+		typ := fs.String("type", "", "")
+		rest, err := parse(fs, args[1:], 1)
+		if err != nil {
+			return err
+		}
+		project, err := c.resolveProject()
+		if err != nil {
+			return err
+		}
+		form := url.Values{"name": {rest[0]}, "base_branch": {*base}, "type": {*typ}}
+		var feature model.Feature
+		if err := c.client.do(http.MethodPost, "/projects/"+project.ID+"/features/", form, &feature); err != nil {
+			return err
+		}
+		return c.print(feature, func(w io.Writer) {
+			fmt.Fprintf(w, "Created feature %s (%s) on branch %s\n", feature.Name, feature.ID, feature.BranchName)
+		})
+	case "delete":
+		rest, err := parse(c.flags("feature delete"), args[1:], 1)
+		if err != nil {
+			return err
+		}
+		project, err := c.resolveProject()
+		if err != nil {
+			return err
+		}
+		if err := c.client.do(http.MethodDelete, "/projects/"+project.ID+"/features/"+url.PathEscape(rest[0])+"/", nil, nil); err != nil {
+			return err
+		}
+		result := map[string]string{"deleted": rest[0]}
+		return c.print(result, func(w io.Writer) { fmt.Fprintf(w, "Deleted feature %s\n", rest[0]) })
+	}
+	return errUsage
+}
+
+// broadcastResponse mirrors the response of POST /projects/{id}/broadcast.
+type broadcastResponse struct {
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Results []struct {
+		PaneID string `json:"pane_id"`
+		OK     bool   `json:"ok"`
+		Error  string `json:"error,omitempty"`
+	} `json:"results"`
+}
+
+func (c *cli) send(args []string) error {
+	fs := c.flags("send")
+	noEnter := fs.Bool("no-enter", false, "")
+	rest, err := parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	project, err := c.resolveProject()
+	if err != nil {
+		return err
+	}
+	enter := !*noEnter
+	req := map[string]any{"input": rest[1], "enter": enter, "pane_ids": []string{rest[0]}}
+	var resp broadcastResponse
+	if err := c.client.do(http.MethodPost, "/projects/"+project.ID+"/broadcast", req, &resp); err != nil {
+		return err
+	}
+	if c.json {
+		return c.print(resp, nil)
+	}
+	for _, r := range resp.Results {
+		if !r.OK {
+			return fmt.Errorf("pane %s: %s", r.PaneID, r.Error)
+		}
+	}
+	return nil
+}
+
+// findPane returns the session holding paneID in the project.
+func (c *cli) findPane(project model.Project, paneID string) (model.Session, error) {
+	var sessions []model.Session
+	if err := c.client.do(http.MethodGet, "/projects/"+project.ID+"/sessions/", nil, &sessions); err != nil {
+		return model.Session{}, err
+	}
+	for _, s := range sessions {
+		if s.Layout != nil && s.Layout.HasPane(paneID) {
+			return s, nil
+		}
+	}
+	return model.Session{}, fmt.Errorf("no pane %s in project %s", paneID, project.Name)
+}
+
+func (c *cli) tail(args []string) error {
+	fs := c.flags("tail")
+	lines := fs.Int("n", 100, "")
+	follow := fs.Bool("f", false, "")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	paneID := rest[0]
+	project, err := c.resolveProject()
+	if err != nil {
+		return err
+	}
+	sess, err := c.findPane(project, paneID)
+	if err != nil {
+		return err
+	}
+
+	if !*follow {
+		var out struct {
+			SessionID string `json:"session_id"`
+			PaneID    string `json:"pane_id"`
+			Output    string `json:"output"`
+		}
+		path := fmt.Sprintf("/projects/%s/sessions/%s/panes/%s/output?lines=%s", project.ID, sess.ID, paneID, strconv.Itoa(*lines))
+		if err := c.client.do(http.MethodGet, path, nil, &out); err != nil {
+			return err
+		}
+		if c.json {
+			return c.print(out, nil)
+		}
+		_, err := io.WriteString(c.stdout, out.Output)
+		return err
+	}
+
+	// Following streams the raw terminal, starting with the scrollback the
+	// server keeps for the pane, until the pane or the server goes away.
+	conn, err := c.client.dial("/ws/terminal/" + sess.ID + "/" + paneID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+		if _, err := c.stdout.Write(data); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *cli) task(args []string) error {
+	if len(args) == 0 || args[0] != "add" {
+		return errUsage
+	}
+	fs := c.flags("task add")
+	column := fs.String("column", "backlog", "")
+	group := fs.String("group", "", "")
+	description := fs.String("description", "", "")
+	rest, err := parse(fs, args[1:], 1)
+	if err != nil {
+		return err
+	}
+	project, err := c.resolveProject()
+	if err != nil {
+		return err
+	}
+	req := map[string]string{"column": *column, "group": *group, "title": rest[0], "description": *description}
+	var task model.Task
+	if err := c.client.do(http.MethodPost, "/api/tasks/?project_id="+url.QueryEscape(project.ID), req, &task); err != nil {
+		return err
+	}
+	return c.print(task, func(w io.Writer) { fmt.Fprintf(w, "Added task %s: %s\n", task.ID, task.Title) })
+}
+
+func (c *cli) notify(args []string) error {
+	fs := c.flags("notify")
+	body := fs.String("body", "", "")
+	level := fs.String("level", "info", "")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	cl, err := c.api()
+	if err != nil {
+		return err
+	}
+	req := map[string]string{
+		"title":      rest[0],
+		"body":       *body,
+		"level":      *level,
+		"source":     "clawide-ctl",
+		"project_id": os.Getenv("CLAWIDE_PROJECT_ID"),
+		"session_id": os.Getenv("CLAWIDE_SESSION_ID"),
+		"feature_id": os.Getenv("CLAWIDE_FEATURE_ID"),
+		"pane_id":    os.Getenv("CLAWIDE_PANE_ID"),
+	}
+	// Notifications need no project; -p attaches one.
+	if c.project != "" {
+		project, err := c.resolveProject()
+		if err != nil {
+			return err
+		}
+		req["project_id"] = project.ID
+	}
+	var notif model.Notification
+	if err := cl.do(http.MethodPost, "/api/notifications", req, &notif); err != nil {
+		return err
+	}
+	return c.print(notif, func(w io.Writer) { fmt.Fprintf(w, "Posted notification %s\n", notif.ID) })
+}
@@ -0,0 +1,228 @@
+package ctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/pidfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI serves the routes ctl uses and records what it was sent.
+type fakeAPI struct {
+	*httptest.Server
+	auth     []string
+	requests map[string]map[string]any // "METHOD path" -> decoded body
+	forms    map[string]string         // feature create form
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	f := &fakeAPI{requests: map[string]map[string]any{}, forms: map[string]string{}}
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	record := func(r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		f.requests[r.Method+" "+r.URL.Path] = body
+	}
+	mux.HandleFunc("GET /projects/", func(w http.ResponseWriter, r *http.Request) {
+		f.auth = append(f.auth, r.Header.Get("Authorization"))
+		reply(w, 200, []model.Project{
+			{ID: "proj-1", Name: "Alpha", Path: "/srv/alpha"},
+			{ID: "proj-2", Name: "Beta", Path: "/srv/beta"},
+		})
+	})
+	mux.HandleFunc("GET /projects/proj-1/sessions/", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, 200, []model.Session{{ID: "s1", ProjectID: "proj-1", Name: "main", Layout: model.NewLeafPane("p1")}})
+	})
+	mux.HandleFunc("GET /projects/proj-1/features/", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, 200, []model.Feature{{ID: "f1", Name: "login", Type: "feature", BranchName: "feature/login"}})
+	})
+	mux.HandleFunc("POST /projects/proj-1/features/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		f.forms["name"], f.forms["base_branch"] = r.FormValue("name"), r.FormValue("base_branch")
+		if !strings.Contains(r.Header.Get("Accept"), "application/json") {
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return
+		}
+		reply(w, 201, model.Feature{ID: "f2", Name: r.FormValue("name"), BranchName: "feature/" + r.FormValue("name")})
+	})
+	mux.HandleFunc("DELETE /projects/proj-1/features/f1/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /projects/proj-1/broadcast", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		reply(w, 200, map[string]any{"sent": 1, "results": []map[string]any{{"pane_id": "p1", "ok": true}}})
+	})
+	mux.HandleFunc("GET /projects/proj-1/sessions/s1/panes/p1/output", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, 200, map[string]string{"session_id": "s1", "pane_id": "p1", "output": "lines=" + r.URL.Query().Get("lines") + "\n$ make\n"})
+	})
+	mux.HandleFunc("POST /api/tasks/", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		f.requests["POST /api/tasks/"]["project_id"] = r.URL.Query().Get("project_id")
+		reply(w, 201, model.Task{ID: "t1", Title: "Write docs"})
+	})
+	mux.HandleFunc("POST /api/notifications", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		reply(w, 201, model.Notification{ID: "n1"})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// isolate keeps the user's config, PID file and pane environment out of
+// the test.
+func isolate(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, v := range []string{"CLAWIDE_API_URL", "CLAWIDE_API_TOKEN", "CLAWIDE_CA_CERT", "CLAWIDE_PROJECT_ID",
+		"CLAWIDE_SESSION_ID", "CLAWIDE_FEATURE_ID", "CLAWIDE_PANE_ID", "CLAWIDE_DATA_DIR"} {
+		t.Setenv(v, "")
+	}
+	return home
+}
+
+func run(t *testing.T, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestCommands(t *testing.T) {
+	isolate(t)
+	api := newFakeAPI(t)
+	base := []string{"--url", api.URL, "--token", "cl_test"}
+	ctl := func(args ...string) (string, string, int) {
+		return run(t, append(append([]string{}, base...), args...)...)
+	}
+
+	out, errOut, code := ctl("projects")
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "proj-2  Beta")
+	assert.Equal(t, "Bearer cl_test", api.auth[0])
+
+	out, _, code = ctl("--json", "projects")
+	require.Equal(t, 0, code)
+	var projects []model.Project
+	require.NoError(t, json.Unmarshal([]byte(out), &projects))
+	assert.Len(t, projects, 2)
+
+	out, errOut, code = ctl("-p", "alpha", "sessions")
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "p1")
+	assert.Contains(t, out, "shell")
+
+	out, _, code = ctl("features", "-p", "proj-1")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "feature/login")
+
+	out, errOut, code = ctl("-p", "proj-1", "feature", "create", "--base", "main", "search")
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "feature/search")
+	assert.Equal(t, map[string]string{"name": "search", "base_branch": "main"}, api.forms)
+
+	_, errOut, code = ctl("-p", "proj-1", "feature", "delete", "f1")
+	require.Equal(t, 0, code, errOut)
+
+	_, errOut, code = ctl("-p", "proj-1", "send", "--no-enter", "p1", "ls -la")
+	require.Equal(t, 0, code, errOut)
+	sent := api.requests["POST /projects/proj-1/broadcast"]
+	assert.Equal(t, "ls -la", sent["input"])
+	assert.Equal(t, false, sent["enter"])
+	assert.Equal(t, []any{"p1"}, sent["pane_ids"])
+
+	out, errOut, code = ctl("-p", "proj-1", "tail", "-n", "20", "p1")
+	require.Equal(t, 0, code, errOut)
+	assert.Equal(t, "lines=20\n$ make\n", out)
+
+	_, errOut, code = ctl("-p", "proj-1", "tail", "p9")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "no pane p9")
+
+	out, errOut, code = ctl("-p", "Alpha", "task", "add", "--description", "all of them", "Write docs")
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "Added task t1")
+	task := api.requests["POST /api/tasks/"]
+	assert.Equal(t, "backlog", task["column"])
+	assert.Equal(t, "all of them", task["description"])
+	assert.Equal(t, "proj-1", task["project_id"])
+
+	t.Setenv("CLAWIDE_PANE_ID", "p1")
+	_, errOut, code = ctl("notify", "--level", "warning", "Build broke")
+	require.Equal(t, 0, code, errOut)
+	notif := api.requests["POST /api/notifications"]
+	assert.Equal(t, "Build broke", notif["title"])
+	assert.Equal(t, "warning", notif["level"])
+	assert.Equal(t, "p1", notif["pane_id"])
+}
+
+func TestUsageAndErrors(t *testing.T) {
+	isolate(t)
+	api := newFakeAPI(t)
+
+	_, errOut, code := run(t)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "Usage: clawide ctl")
+
+	_, _, code = run(t, "frobnicate")
+	assert.Equal(t, 2, code)
+
+	_, _, code = run(t, "--url", api.URL, "send", "p1")
+	assert.Equal(t, 2, code, "send needs a pane and text")
+
+	_, errOut, code = run(t, "--url", api.URL, "-p", "gamma", "features")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, `no project "gamma"`)
+
+	_, errOut, code = run(t, "--url", api.URL, "-p", "proj-1", "feature", "delete", "f9")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "server returned")
+}
+
+func TestDiscovery(t *testing.T) {
+	home := isolate(t)
+	api := newFakeAPI(t)
+
+	_, errOut, code := run(t, "projects")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "no running ClawIDE instance")
+
+	// This process stands in for the server.
+	dataDir := filepath.Join(home, ".clawide")
+	require.NoError(t, os.MkdirAll(dataDir, 0755))
+	require.NoError(t, pidfile.SetURL(pidfile.Path(dataDir), api.URL))
+	_, errOut, code = run(t, "projects")
+	require.Equal(t, 0, code, errOut)
+
+	t.Run("project from environment and working directory", func(t *testing.T) {
+		t.Setenv("CLAWIDE_PROJECT_ID", "proj-1")
+		out, _, code := run(t, "features")
+		require.Equal(t, 0, code)
+		assert.Contains(t, out, "login")
+
+		t.Setenv("CLAWIDE_PROJECT_ID", "")
+		_, errOut, code := run(t, "features")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, errOut, "pass -p")
+	})
+}
+
+func TestWithin(t *testing.T) {
+	assert.True(t, within("/srv/alpha", "/srv/alpha"))
+	assert.True(t, within("/srv/alpha/web", "/srv/alpha"))
+	assert.False(t, within("/srv/alphabet", "/srv/alpha"))
+	assert.False(t, within("/srv", "/srv/alpha"))
+}
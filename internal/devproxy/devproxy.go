@@ -0,0 +1,198 @@
+// Package devproxy serves a dev server running on a local port under a path
+// prefix of ClawIDE's own origin, so a feature's preview works wherever
+// ClawIDE is reachable. WebSocket upgrades (HMR) are streamed through.
+package devproxy
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxHTMLBody caps the HTML pages buffered to inject a base href; larger
+// pages are passed through untouched.
+const maxHTMLBody = 8 << 20
+
+// Options tunes how requests and responses are rewritten.
+type Options struct {
+	// KeepPrefix forwards the path prefix instead of stripping it, for dev
+	// servers configured to serve under it (e.g. vite --base).
+	KeepPrefix bool
+	// BaseHref injects <base href="prefix/"> into HTML pages so relative
+	// links resolve under the prefix.
+	BaseHref bool
+	// DropCookies are removed from requests before they reach the dev
+	// server, so ClawIDE's own session never leaves the process.
+	DropCookies []string
+}
+
+// New returns a handler proxying requests under prefix (e.g.
+// "/preview/abc") to http://127.0.0.1:port.
+func New(prefix string, port int, opts Options) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	target := &url.URL{Scheme: "http", Host: "127.0.0.1:" + strconv.Itoa(port)}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Header.Set("X-Forwarded-Prefix", prefix)
+			if !opts.KeepPrefix {
+				pr.Out.URL.Path = stripPrefix(pr.In.URL.Path, prefix)
+				pr.Out.URL.RawPath = ""
+			}
+			if opts.BaseHref {
+				// Injection needs the page uncompressed.
+				pr.Out.Header.Del("Accept-Encoding")
+			}
+			dropCookies(pr.Out, opts.DropCookies)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if loc := resp.Header.Get("Location"); loc != "" {
+				resp.Header.Set("Location", rewriteLocation(loc, target, prefix, opts.KeepPrefix))
+			}
+			if !opts.KeepPrefix {
+				rewriteCookiePaths(resp.Header, prefix)
+			}
+			if opts.BaseHref {
+				return injectBaseHref(resp, prefix+"/")
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("devproxy %s -> %s: %v", r.URL.Path, target.Host, err)
+			http.Error(w, fmt.Sprintf("Nothing is answering on port %d. Is the dev server running?", port), http.StatusBadGateway)
+		},
+	}
+}
+
+func stripPrefix(path, prefix string) string {
+	p := strings.TrimPrefix(path, prefix)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+func dropCookies(r *http.Request, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, c := range cookies {
+		drop := false
+		for _, name := range names {
+			if c.Name == name {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			r.AddCookie(c)
+		}
+	}
+}
+
+// rewriteLocation maps a redirect from the dev server back under prefix.
+// Absolute URLs pointing at the dev server itself become paths; other
+// hosts are left alone.
+func rewriteLocation(loc string, target *url.URL, prefix string, keepPrefix bool) string {
+	u, err := url.Parse(loc)
+	if err != nil {
+		return loc
+	}
+	if u.IsAbs() {
+		if !isDevServer(u, target) {
+			return loc
+		}
+		u.Scheme, u.Host, u.User = "", "", nil
+	} else if u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		// Protocol-relative or relative: the browser resolves these itself.
+		return loc
+	}
+	if !keepPrefix || !strings.HasPrefix(u.Path, prefix+"/") {
+		u.Path = prefix + u.Path
+		u.RawPath = ""
+	}
+	return u.String()
+}
+
+func isDevServer(u, target *url.URL) bool {
+	if u.Port() != target.Port() {
+		return false
+	}
+	switch u.Hostname() {
+	case "127.0.0.1", "localhost", "::1", "0.0.0.0":
+		return true
+	}
+	return false
+}
+
+// rewriteCookiePaths scopes the dev server's cookies to prefix, so they
+// don't leak to the rest of ClawIDE.
+func rewriteCookiePaths(h http.Header, prefix string) {
+	lines := h.Values("Set-Cookie")
+	if len(lines) == 0 {
+		return
+	}
+	h.Del("Set-Cookie")
+	for _, line := range lines {
+		c, err := http.ParseSetCookie(line)
+		if err != nil {
+			h.Add("Set-Cookie", line)
+			continue
+		}
+		// An empty path defaults to the request's, already under prefix.
+		if strings.HasPrefix(c.Path, "/") {
+			c.Path = prefix + c.Path
+		}
+		h.Add("Set-Cookie", c.String())
+	}
+}
+
+var (
+	headTag = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
+	baseTag = regexp.MustCompile(`(?i)<base\s`)
+)
+
+// injectBaseHref adds <base href> right after <head> of uncompressed HTML
+// pages that don't have one.
+func injectBaseHref(resp *http.Response, href string) error {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") ||
+		resp.Header.Get("Content-Encoding") != "" || resp.ContentLength > maxHTMLBody {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTMLBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+	if len(body) > maxHTMLBody {
+		// Too big to rewrite: send what was read, then the rest.
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil
+	}
+	resp.Body.Close()
+	if !baseTag.Match(body) {
+		if loc := headTag.FindIndex(body); loc != nil {
+			tag := `<base href="` + html.EscapeString(href) + `">`
+			body = append(body[:loc[1]:loc[1]], append([]byte(tag), body[loc[1]:]...)...)
+		}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
@@ -0,0 +1,136 @@
+package devproxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// devServer starts an upstream standing in for vite and the like, and
+// returns its port.
+func devServer(t *testing.T) int {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, `<html><head><title>app</title></head><body>path=`+r.URL.Path+` cookie=`+r.Header.Get("Cookie")+` prefix=`+r.Header.Get("X-Forwarded-Prefix")+`</body></html>`)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "app", Value: "1", Path: "/"})
+		http.Redirect(w, r, "/dashboard", http.StatusFound)
+	})
+	mux.HandleFunc("/away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:"+r.Host[strings.LastIndex(r.Host, ":")+1:]+"/home", http.StatusFound)
+	})
+	mux.HandleFunc("/hmr", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(mt, append([]byte("echo:"), msg...))
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return port
+}
+
+func noRedirect(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+func TestProxy_StripsPrefixAndRewrites(t *testing.T) {
+	port := devServer(t)
+	proxy := httptest.NewServer(New("/preview/f1", port, Options{DropCookies: []string{"clawide_session"}}))
+	defer proxy.Close()
+	client := &http.Client{CheckRedirect: noRedirect}
+
+	req, _ := http.NewRequest(http.MethodGet, proxy.URL+"/preview/f1/app/page", nil)
+	req.AddCookie(&http.Cookie{Name: "clawide_session", Value: "secret"})
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), "path=/app/page")
+	assert.Contains(t, string(body), "cookie=theme=dark ")
+	assert.Contains(t, string(body), "prefix=/preview/f1")
+	assert.NotContains(t, string(body), "<base")
+
+	resp, err = client.Get(proxy.URL + "/preview/f1/login")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "/preview/f1/dashboard", resp.Header.Get("Location"))
+	assert.Contains(t, resp.Header.Get("Set-Cookie"), "Path=/preview/f1/")
+
+	resp, err = client.Get(proxy.URL + "/preview/f1/away")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "/preview/f1/home", resp.Header.Get("Location"))
+}
+
+func TestProxy_BaseHrefAndKeepPrefix(t *testing.T) {
+	port := devServer(t)
+	proxy := httptest.NewServer(New("/preview/f1", port, Options{KeepPrefix: true, BaseHref: true}))
+	defer proxy.Close()
+
+	resp, err := http.Get(proxy.URL + "/preview/f1/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `<head><base href="/preview/f1/"><title>`)
+	assert.Contains(t, string(body), "path=/preview/f1/")
+	assert.Equal(t, strconv.Itoa(len(body)), resp.Header.Get("Content-Length"))
+}
+
+func TestProxy_WebSocket(t *testing.T) {
+	port := devServer(t)
+	proxy := httptest.NewServer(New("/preview/f1", port, Options{}))
+	defer proxy.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(proxy.URL, "http")+"/preview/f1/hmr", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("update")))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "echo:update", string(msg))
+}
+
+func TestProxy_DevServerDown(t *testing.T) {
+	ln := httptest.NewServer(http.NotFoundHandler())
+	u, _ := url.Parse(ln.URL)
+	port, _ := strconv.Atoi(u.Port())
+	ln.Close()
+
+	proxy := httptest.NewServer(New("/preview/f1", port, Options{}))
+	defer proxy.Close()
+	resp, err := http.Get(proxy.URL + "/preview/f1/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestRewriteLocation(t *testing.T) {
+	target := &url.URL{Scheme: "http", Host: "127.0.0.1:5173"}
+	assert.Equal(t, "/p/x?a=1", rewriteLocation("/x?a=1", target, "/p", false))
+	assert.Equal(t, "/p/x", rewriteLocation("/p/x", target, "/p", true))
+	assert.Equal(t, "/p/x", rewriteLocation("/x", target, "/p", true))
+	assert.Equal(t, "https://example.com/x", rewriteLocation("https://example.com/x", target, "/p", false))
+	assert.Equal(t, "next", rewriteLocation("next", target, "/p", false))
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// visibleProjects filters projects down to those the caller holds any role in.
+func visibleProjects(r *http.Request, projects []model.Project) []model.Project {
+	if middleware.GetPrincipal(r).IsAdmin() {
+		return projects
+	}
+	out := make([]model.Project, 0, len(projects))
+	for _, p := range projects {
+		if middleware.ProjectRoleFor(r, p) != model.RoleNone {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// projectRole returns the caller's role in the project with the given ID, for
+// routes that carry the project outside ProjectLoader (WebSockets, SSE).
+// Unknown projects resolve to RoleNone.
+func (h *Handlers) projectRole(r *http.Request, projectID string) model.ProjectRole {
+	project, ok := h.store.GetProject(projectID)
+	if !ok {
+		return model.RoleNone
+	}
+	return middleware.ProjectRoleFor(r, project)
+}
+
+// requireProjectRole writes a 404 (no access) or 403 (insufficient role) and
+// returns false unless the caller holds at least min in projectID.
+func (h *Handlers) requireProjectRole(w http.ResponseWriter, r *http.Request, projectID string, min model.ProjectRole) bool {
+	role := h.projectRole(r, projectID)
+	if role == model.RoleNone {
+		http.Error(w, "project not found", http.StatusNotFound)
+		return false
+	}
+	if !role.Allows(min) {
+		http.Error(w, "requires the "+string(min)+" role", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// canSeeNotification reports whether a notification belongs to a project the
+// caller can view. Notifications without a project are visible to everyone.
+func (h *Handlers) canSeeNotification(r *http.Request, n model.Notification) bool {
+	return n.ProjectID == "" || h.projectRole(r, n.ProjectID) != model.RoleNone
+}
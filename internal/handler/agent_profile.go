@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/agentprofile"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type agentProfileRequest struct {
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider"`
+	Command   string            `json:"command"`
+	Args      string            `json:"args"`
+	Model     string            `json:"model"`
+	ModelFlag string            `json:"model_flag"`
+	Env       map[string]string `json:"env"`
+}
+
+// listAgentProfiles returns the built-in profiles followed by the custom
+// ones, with their installed status filled in.
+func (h *Handlers) listAgentProfiles() []model.AgentProfile {
+	out := agentprofile.Builtins(h.aiRegistry)
+	for _, p := range h.agentProfiles.GetAll() {
+		p.Installed = agentprofile.Installed(p)
+		out = append(out, p)
+	}
+	if out == nil {
+		out = []model.AgentProfile{}
+	}
+	return out
+}
+
+// getAgentProfile looks up a built-in or custom profile by ID.
+func (h *Handlers) getAgentProfile(id string) (model.AgentProfile, bool) {
+	for _, p := range agentprofile.Builtins(h.aiRegistry) {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	if p, ok := h.agentProfiles.Get(id); ok {
+		p.Installed = agentprofile.Installed(p)
+		return p, true
+	}
+	return model.AgentProfile{}, false
+}
+
+// paneAgentProfile returns the profile that applies to an agent pane: the
+// pane's own, else its feature's, else its project's. ok is false when none
+// is selected or the selected profile no longer exists, in which case the
+// global agent command is used.
+func (h *Handlers) paneAgentProfile(sess model.Session, pane *model.PaneNode) (model.AgentProfile, bool) {
+	if pane == nil || pane.EffectivePaneType() != model.PaneTypeAgent {
+		return model.AgentProfile{}, false
+	}
+	id := pane.AgentProfile
+	if id == "" && sess.FeatureID != "" {
+		if f, ok := h.store.GetFeature(sess.FeatureID); ok {
+			id = f.AgentProfile
+		}
+	}
+	if id == "" {
+		if p, ok := h.store.GetProject(sess.ProjectID); ok {
+			id = p.AgentProfile
+		}
+	}
+	if id == "" {
+		return model.AgentProfile{}, false
+	}
+	prof, ok := h.getAgentProfile(id)
+	if !ok {
+		log.Printf("Agent profile %q for pane %s no longer exists, using the default agent command", id, pane.PaneID)
+	}
+	return prof, ok
+}
+
+// ListAgentProfiles returns every profile agent panes can use.
+// GET /api/agent-profiles
+func (h *Handlers) ListAgentProfiles(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.listAgentProfiles())
+}
+
+// CreateAgentProfile adds a custom profile.
+// POST /api/agent-profiles
+func (h *Handlers) CreateAgentProfile(w http.ResponseWriter, r *http.Request) {
+	var body agentProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	prof := model.AgentProfile{ID: uuid.New().String(), CreatedAt: now}
+	if msg := h.applyAgentProfileRequest(&prof, body); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	prof.UpdatedAt = now
+
+	if err := h.agentProfiles.Add(prof); err != nil {
+		log.Printf("agent profile create error: %v", err)
+		http.Error(w, "failed to create agent profile", http.StatusInternalServerError)
+		return
+	}
+	prof.Installed = agentprofile.Installed(prof)
+	writeJSON(w, http.StatusCreated, prof)
+}
+
+// UpdateAgentProfile replaces a custom profile. Built-in profiles are
+// read-only.
+// PUT /api/agent-profiles/{profileID}
+func (h *Handlers) UpdateAgentProfile(w http.ResponseWriter, r *http.Request) {
+	prof, ok := h.agentProfiles.Get(chi.URLParam(r, "profileID"))
+	if !ok {
+		http.Error(w, "agent profile not found", http.StatusNotFound)
+		return
+	}
+	var body agentProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if msg := h.applyAgentProfileRequest(&prof, body); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	prof.UpdatedAt = time.Now()
+
+	if err := h.agentProfiles.Update(prof); err != nil {
+		log.Printf("agent profile update error: %v", err)
+		http.Error(w, "failed to update agent profile", http.StatusInternalServerError)
+		return
+	}
+	prof.Installed = agentprofile.Installed(prof)
+	writeJSON(w, http.StatusOK, prof)
+}
+
+// DeleteAgentProfile removes a custom profile. Projects, features and panes
+// still pointing at it fall back to the default agent command.
+// DELETE /api/agent-profiles/{profileID}
+func (h *Handlers) DeleteAgentProfile(w http.ResponseWriter, r *http.Request) {
+	if err := h.agentProfiles.Delete(chi.URLParam(r, "profileID")); err != nil {
+		http.Error(w, "agent profile not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyAgentProfileRequest validates body and copies it into prof. It
+// returns a client error message, or "" on success.
+func (h *Handlers) applyAgentProfileRequest(prof *model.AgentProfile, body agentProfileRequest) string {
+	body.Name = strings.TrimSpace(body.Name)
+	body.Command = strings.TrimSpace(body.Command)
+	if body.Name == "" {
+		return "name is required"
+	}
+	if body.Provider != "" {
+		p, ok := h.aiRegistry.Get(body.Provider)
+		if !ok {
+			return "unknown provider: " + body.Provider
+		}
+		if body.Command == "" {
+			body.Command = p.Binary()
+		}
+	}
+	if body.Command == "" {
+		return "command is required"
+	}
+	for k := range body.Env {
+		if !paneEnvKeyRe.MatchString(k) {
+			return "invalid environment variable name: " + k
+		}
+		if strings.HasPrefix(k, "CLAWIDE_") {
+			return "CLAWIDE_* variables are set by ClawIDE"
+		}
+	}
+
+	prof.Name = body.Name
+	prof.Provider = body.Provider
+	prof.Command = body.Command
+	prof.Args = strings.TrimSpace(body.Args)
+	prof.Model = strings.TrimSpace(body.Model)
+	prof.ModelFlag = strings.TrimSpace(body.ModelFlag)
+	prof.Env = body.Env
+	if len(prof.Env) == 0 {
+		prof.Env = nil
+	}
+	return ""
+}
+
+// decodeAgentProfileSelection reads {"agent_profile": "<id>"} and checks the
+// profile exists. An empty ID clears the selection.
+func (h *Handlers) decodeAgentProfileSelection(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var body struct {
+		AgentProfile string `json:"agent_profile"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return "", false
+	}
+	if body.AgentProfile != "" {
+		if _, ok := h.getAgentProfile(body.AgentProfile); !ok {
+			http.Error(w, "agent profile not found", http.StatusBadRequest)
+			return "", false
+		}
+	}
+	return body.AgentProfile, true
+}
+
+// SetProjectAgentProfile selects the default profile for a project's agent
+// panes.
+// PUT /projects/{id}/agent-profile
+func (h *Handlers) SetProjectAgentProfile(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	id, ok := h.decodeAgentProfileSelection(w, r)
+	if !ok {
+		return
+	}
+	project.AgentProfile = id
+	project.UpdatedAt = time.Now()
+	if err := h.store.UpdateProject(project); err != nil {
+		log.Printf("Error saving project agent profile: %v", err)
+		http.Error(w, "failed to update project", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"agent_profile": id})
+}
+
+// SetFeatureAgentProfile selects the profile for a feature's agent panes,
+// overriding the project's.
+// PUT /projects/{id}/features/{fid}/agent-profile
+func (h *Handlers) SetFeatureAgentProfile(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	feature, ok := h.store.GetFeature(chi.URLParam(r, "fid"))
+	if !ok || feature.ProjectID != project.ID {
+		http.Error(w, "feature not found", http.StatusNotFound)
+		return
+	}
+	id, ok := h.decodeAgentProfileSelection(w, r)
+	if !ok {
+		return
+	}
+	feature.AgentProfile = id
+	feature.UpdatedAt = time.Now()
+	if err := h.store.UpdateFeature(feature); err != nil {
+		log.Printf("Error saving feature agent profile: %v", err)
+		http.Error(w, "failed to update feature", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"agent_profile": id})
+}
+
+// SetPaneAgentProfile selects the profile for a single agent pane. It takes
+// effect the next time the pane's shell starts.
+// PUT /projects/{id}/sessions/{sid}/panes/{pid}/agent-profile
+func (h *Handlers) SetPaneAgentProfile(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	sess, ok := h.store.GetSession(chi.URLParam(r, "sid"))
+	if !ok || sess.ProjectID != project.ID {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	target, _ := sess.Layout.FindPane(chi.URLParam(r, "pid"))
+	if target == nil {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	id, ok := h.decodeAgentProfileSelection(w, r)
+	if !ok {
+		return
+	}
+	target.AgentProfile = id
+	sess.UpdatedAt = time.Now()
+	if err := h.store.UpdateSession(sess); err != nil {
+		log.Printf("Error saving pane agent profile: %v", err)
+		http.Error(w, "failed to save layout", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"agent_profile": id})
+}
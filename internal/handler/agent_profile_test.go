@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/aicli"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentProfiles(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	aicli.RegisterDefaults(h.aiRegistry)
+	h.cfg.AgentCommand = "claude"
+	h.cfg.AgentArgs = ""
+
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddFeature(model.Feature{ID: "f1", ProjectID: "proj-1", Name: "feat"}))
+	require.NoError(t, st.AddSession(model.Session{
+		ID:        "s1",
+		ProjectID: "proj-1",
+		Layout: &model.PaneNode{Type: "split", Direction: "horizontal", Ratio: 0.5,
+			First: model.NewAgentPane("ap-1"), Second: model.NewAgentPane("ap-2")},
+	}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s2", ProjectID: "proj-1", FeatureID: "f1", Layout: model.NewAgentPane("ap-3")}))
+
+	router := chi.NewRouter()
+	router.Route("/api/agent-profiles", func(r chi.Router) {
+		r.Get("/", h.ListAgentProfiles)
+		r.Post("/", h.CreateAgentProfile)
+		r.Put("/{profileID}", h.UpdateAgentProfile)
+		r.Delete("/{profileID}", h.DeleteAgentProfile)
+	})
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Put("/agent-profile", h.SetProjectAgentProfile)
+		r.Put("/features/{fid}/agent-profile", h.SetFeatureAgentProfile)
+		r.Put("/sessions/{sid}/panes/{pid}/agent-profile", h.SetPaneAgentProfile)
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+	commandFor := func(sessionID, paneID string) string {
+		sess, _ := st.GetSession(sessionID)
+		pane, _ := sess.Layout.FindPane(paneID)
+		return h.startupCommand(sess, pane)
+	}
+
+	w := do("POST", "/api/agent-profiles/", `{"name":"Codex o3","provider":"codex","model":"o3","model_flag":"--model","env":{"CODEX_HOME":"/tmp/codex"}}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var codex model.AgentProfile
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &codex))
+	assert.Equal(t, "codex", codex.Command, "command defaults to the provider's binary")
+
+	var list []model.AgentProfile
+	require.NoError(t, json.Unmarshal(do("GET", "/api/agent-profiles/", "").Body.Bytes(), &list))
+	ids := map[string]bool{}
+	for _, p := range list {
+		ids[p.ID] = true
+	}
+	assert.True(t, ids["claude"], "built-in profiles come from the aicli catalog")
+	assert.True(t, ids[codex.ID])
+
+	t.Run("validation", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/agent-profiles/", `{"command":"x"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/agent-profiles/", `{"name":"x"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/agent-profiles/", `{"name":"x","provider":"nope"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/agent-profiles/", `{"name":"x","command":"x","env":{"CLAWIDE_PANE_ID":"y"}}`).Code)
+		assert.Equal(t, http.StatusNotFound, do("PUT", "/api/agent-profiles/claude", `{"name":"x","command":"x"}`).Code, "built-ins are read-only")
+		assert.Equal(t, http.StatusBadRequest, do("PUT", "/projects/proj-1/agent-profile", `{"agent_profile":"missing"}`).Code)
+	})
+
+	t.Run("pane beats feature beats project", func(t *testing.T) {
+		assert.Equal(t, "claude", commandFor("s1", "ap-1"), "no profile: global agent command")
+
+		require.Equal(t, http.StatusOK, do("PUT", "/projects/proj-1/agent-profile", `{"agent_profile":"gemini"}`).Code)
+		assert.Equal(t, "gemini", commandFor("s1", "ap-1"))
+		assert.Equal(t, "gemini", commandFor("s2", "ap-3"))
+
+		require.Equal(t, http.StatusOK, do("PUT", "/projects/proj-1/features/f1/agent-profile", `{"agent_profile":"claude"}`).Code)
+		assert.Equal(t, "claude", commandFor("s2", "ap-3"))
+
+		require.Equal(t, http.StatusOK, do("PUT", "/projects/proj-1/sessions/s1/panes/ap-2/agent-profile", `{"agent_profile":"`+codex.ID+`"}`).Code)
+		assert.Equal(t, "codex --model o3", commandFor("s1", "ap-2"))
+		assert.Equal(t, "gemini", commandFor("s1", "ap-1"), "side by side with a different CLI")
+
+		sess, _ := st.GetSession("s1")
+		env := h.paneEnv(sess, "ap-2", "")
+		assert.Equal(t, "/tmp/codex", env["CODEX_HOME"])
+		assert.Equal(t, "ap-2", env["CLAWIDE_PANE_ID"])
+	})
+
+	t.Run("deleted profile falls back to the default command", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, do("DELETE", "/api/agent-profiles/"+codex.ID, "").Code)
+		assert.Equal(t, "claude", commandFor("s1", "ap-2"))
+	})
+}
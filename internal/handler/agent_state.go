@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davydany/ClawIDE/internal/agentstate"
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// ListAgentStates returns the detected state of every agent pane the caller
+// can see. Changes are also pushed as "agent-state" events on
+// /api/notifications/stream.
+// GET /api/agent-state?project_id=...
+func (h *Handlers) ListAgentStates(w http.ResponseWriter, r *http.Request) {
+	only := r.URL.Query().Get("project_id")
+	out := []agentstate.PaneState{}
+	if h.agentStates != nil {
+		for _, s := range h.agentStates.States() {
+			if only != "" && s.ProjectID != only {
+				continue
+			}
+			if h.projectRole(r, s.ProjectID) == model.RoleNone {
+				continue
+			}
+			out = append(out, s)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/sse"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/go-chi/chi/v5"
+)
+
+// Endpoints that exist only under /api/v1. Everything else there is served
+// by the handlers the web UI uses, adapted by middleware.APIv1.
+
+// PaneInfo is one pane of a session as listed by /api/v1.
+type PaneInfo struct {
+	PaneID       string `json:"pane_id"`
+	SessionID    string `json:"session_id"`
+	Name         string `json:"name,omitempty"`
+	PaneType     string `json:"pane_type"`
+	AgentProfile string `json:"agent_profile,omitempty"`
+	Running      bool   `json:"running"` // its tmux session exists
+}
+
+// TaskItem is a task with its place on the board.
+type TaskItem struct {
+	model.Task
+	Column string `json:"column"` // column ID (slug)
+	Group  string `json:"group"`
+}
+
+// OpenURLEvent is the notification stream event asking the browser to open
+// a bookmark's URL.
+const OpenURLEvent = "open-url"
+
+// PaneInput is the body of POST /api/v1/.../panes/{pid}/input.
+type PaneInput struct {
+	Input string `json:"input"`
+	Enter *bool  `json:"enter"` // press Enter after the input; default true
+}
+
+// GetProjectAPI returns the project.
+// GET /api/v1/projects/{id}
+func (h *Handlers) GetProjectAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, middleware.GetProject(r))
+}
+
+// GetSessionAPI returns a session with its layout.
+// GET /api/v1/projects/{id}/sessions/{sid}
+func (h *Handlers) GetSessionAPI(w http.ResponseWriter, r *http.Request) {
+	sess, _ := h.store.GetSession(chi.URLParam(r, "sid"))
+	writeJSON(w, http.StatusOK, sess)
+}
+
+// ListPanesAPI lists a session's panes in layout order.
+// GET /api/v1/projects/{id}/sessions/{sid}/panes
+func (h *Handlers) ListPanesAPI(w http.ResponseWriter, r *http.Request) {
+	sess, _ := h.store.GetSession(chi.URLParam(r, "sid"))
+	panes := []PaneInfo{}
+	if sess.Layout != nil {
+		for _, paneID := range sess.Layout.CollectLeaves() {
+			pane, _ := sess.Layout.FindPane(paneID)
+			panes = append(panes, PaneInfo{
+				PaneID:       paneID,
+				SessionID:    sess.ID,
+				Name:         pane.Name,
+				PaneType:     pane.EffectivePaneType(),
+				AgentProfile: pane.AgentProfile,
+				Running:      tmux.HasSession(tmux.TmuxName(paneID)),
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, panes)
+}
+
+// SendPaneInput types input into one pane, as broadcast does for many.
+// POST /api/v1/projects/{id}/sessions/{sid}/panes/{pid}/input
+func (h *Handlers) SendPaneInput(w http.ResponseWriter, r *http.Request) {
+	sess, _ := h.store.GetSession(chi.URLParam(r, "sid"))
+	paneID := chi.URLParam(r, "pid")
+	if sess.Layout == nil || !sess.Layout.HasPane(paneID) {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	var req PaneInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	enter := req.Enter == nil || *req.Enter
+	if req.Input == "" && !enter {
+		http.Error(w, "input is required", http.StatusBadRequest)
+		return
+	}
+	tmuxName := tmux.TmuxName(paneID)
+	if !tmux.HasSession(tmuxName) {
+		http.Error(w, "terminal is not running", http.StatusConflict)
+		return
+	}
+	if err := tmux.SendText(tmuxName, req.Input, enter); err != nil {
+		log.Printf("pane input %s: %v", paneID, err)
+		http.Error(w, strings.TrimSpace(err.Error()), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFeatureAPI returns a feature.
+// GET /api/v1/projects/{id}/features/{fid}
+func (h *Handlers) GetFeatureAPI(w http.ResponseWriter, r *http.Request) {
+	feature, _ := h.store.GetFeature(chi.URLParam(r, "fid"))
+	writeJSON(w, http.StatusOK, feature)
+}
+
+// ListTasksAPI lists the project's tasks in board order, optionally only
+// those in one column.
+// GET /api/v1/projects/{id}/tasks?column=
+func (h *Handlers) ListTasksAPI(w http.ResponseWriter, r *http.Request) {
+	s, _, err := h.resolveTaskStore(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	board, err := s.Board()
+	if err != nil {
+		log.Printf("ListTasksAPI: %v", err)
+		http.Error(w, "failed to load board", http.StatusInternalServerError)
+		return
+	}
+	column := r.URL.Query().Get("column")
+	tasks := []TaskItem{}
+	for _, col := range board.Columns {
+		if column != "" && col.ID != column {
+			continue
+		}
+		for _, g := range col.Groups {
+			for _, t := range g.Tasks {
+				tasks = append(tasks, TaskItem{Task: t, Column: col.ID, Group: g.Title})
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// OpenBookmarkAPI asks the ClawIDE tabs open in a browser to open a bookmark,
+// for clients such as agents that have no browser of their own.
+// POST /api/v1/projects/{id}/bookmarks/{bookmarkID}/open
+func (h *Handlers) OpenBookmarkAPI(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	ps, err := h.getProjectBookmarkStore(project.ID)
+	if err != nil {
+		log.Printf("OpenBookmarkAPI: %v", err)
+		http.Error(w, "failed to access project store", http.StatusInternalServerError)
+		return
+	}
+	b, ok := ps.Get(chi.URLParam(r, "bookmarkID"))
+	if !ok {
+		http.Error(w, "bookmark not found", http.StatusNotFound)
+		return
+	}
+	if h.sseHub != nil {
+		h.sseHub.Publish(sse.Event{Type: OpenURLEvent, ProjectID: project.ID, Data: b})
+	}
+	writeJSON(w, http.StatusOK, b)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIv1Handlers(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "One", Path: t.TempDir()}))
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-2", Name: "Two", Path: t.TempDir()}))
+	layout := &model.PaneNode{Type: "split", Direction: "horizontal", Ratio: 0.5,
+		First: model.NewLeafPane("p1"), Second: model.NewAgentPane("p2")}
+	require.NoError(t, st.AddSession(model.Session{ID: "s1", ProjectID: "proj-1", Name: "main", Layout: layout}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s2", ProjectID: "proj-2", Layout: model.NewLeafPane("p3")}))
+	require.NoError(t, st.AddFeature(model.Feature{ID: "f2", ProjectID: "proj-2", Name: "other"}))
+
+	router := chi.NewRouter()
+	router.Route("/api/v1", func(r chi.Router) {
+		r.Use(middleware.APIv1)
+		r.Post("/projects", middleware.JSONForm(h.CreateProject))
+		r.Route("/projects/{id}", func(r chi.Router) {
+			r.Use(middleware.ProjectLoader(h.store))
+			// As in the server, ProjectOwned runs once the route (and its
+			// URL parameters) is known.
+			r = r.With(middleware.ProjectOwned(h.store))
+			r.Get("/", h.GetProjectAPI)
+			r.Get("/sessions", middleware.Paginate(h.ListSessions))
+			r.Post("/sessions", middleware.JSONForm(h.CreateSession))
+			r.Get("/sessions/{sid}", h.GetSessionAPI)
+			r.Get("/sessions/{sid}/panes", middleware.Paginate(h.ListPanesAPI))
+			r.Post("/sessions/{sid}/panes/{pid}/input", h.SendPaneInput)
+			r.Get("/features/{fid}", h.GetFeatureAPI)
+			r.With(middleware.ProjectQuery).Get("/tasks", middleware.Paginate(h.ListTasksAPI))
+			r.With(middleware.ProjectQuery).Post("/tasks", h.CreateTask)
+			r.With(middleware.ProjectQuery).Post("/tasks/{taskID}/comments", h.AddTaskComment)
+			r.Post("/bookmarks/{bookmarkID}/open", h.OpenBookmarkAPI)
+		})
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	errorOf := func(w *httptest.ResponseRecorder) middleware.APIError {
+		var body struct {
+			Error middleware.APIError `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+		return body.Error
+	}
+
+	w := do(http.MethodGet, "/api/v1/projects/proj-1/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"One"`)
+
+	t.Run("other projects' sessions and features are not found", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/v1/projects/proj-1/sessions/s2", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, middleware.APIError{Status: 404, Code: "not_found", Message: "session not found"}, errorOf(w))
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/projects/proj-1/features/f2", "").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/projects/proj-2/features/f2", "").Code)
+	})
+
+	t.Run("panes", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/v1/projects/proj-1/sessions/s1/panes", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var page struct {
+			Data       []PaneInfo            `json:"data"`
+			Pagination middleware.Pagination `json:"pagination"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		require.Len(t, page.Data, 2)
+		assert.Equal(t, PaneInfo{PaneID: "p1", SessionID: "s1", PaneType: model.PaneTypeShell}, page.Data[0])
+		assert.Equal(t, model.PaneTypeAgent, page.Data[1].PaneType)
+		assert.Equal(t, 2, page.Pagination.Total)
+
+		assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/v1/projects/proj-1/sessions/s1/panes/p3/input", `{"input":"ls"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/projects/proj-1/sessions/s1/panes/p1/input", `{"enter":false}`).Code)
+	})
+
+	t.Run("create from JSON", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/projects/proj-1/sessions", `{"name":"api"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var sess model.Session
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sess))
+		assert.Equal(t, "api", sess.Name)
+		assert.Equal(t, "proj-1", sess.ProjectID)
+
+		w = do(http.MethodPost, "/api/v1/projects", `{"name":"Three","path":"`+t.TempDir()+`"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"name":"Three"`)
+
+		w = do(http.MethodPost, "/api/v1/projects", `{"name":"Four"}`)
+		assert.Equal(t, "bad_request", errorOf(w).Code)
+	})
+
+	t.Run("tasks use the URL's project", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/projects/proj-1/tasks", `{"column":"backlog","title":"Ship v1","project_id":"proj-2"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = do(http.MethodGet, "/api/v1/projects/proj-1/tasks?column=backlog", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var page struct {
+			Data []TaskItem `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, "Ship v1", page.Data[0].Title)
+		assert.Equal(t, "backlog", page.Data[0].Column)
+
+		w = do(http.MethodGet, "/api/v1/projects/proj-2/tasks", "")
+		assert.Contains(t, w.Body.String(), `"total":0`)
+
+		comments := "/api/v1/projects/proj-1/tasks/" + page.Data[0].ID + "/comments"
+		w = do(http.MethodPost, comments, `{"body":"On it","author":"claude"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"author":"claude"`)
+		w = do(http.MethodPost, comments, `{"body":"Done"}`)
+		assert.Contains(t, w.Body.String(), `"author":"user"`)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, comments, `{"body":"x","author":"a]b"}`).Code)
+	})
+
+	t.Run("open a bookmark", func(t *testing.T) {
+		ps, err := h.getProjectBookmarkStore("proj-1")
+		require.NoError(t, err)
+		require.NoError(t, ps.Add(model.Bookmark{ID: "b1", ProjectID: "proj-1", Name: "Docs", URL: "https://docs.example.com"}))
+		events := h.sseHub.SubscribeEvents("test")
+		defer h.sseHub.UnsubscribeEvents("test")
+
+		w := do(http.MethodPost, "/api/v1/projects/proj-1/bookmarks/b1/open", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		e := <-events
+		assert.Equal(t, OpenURLEvent, e.Type)
+		assert.Equal(t, "proj-1", e.ProjectID)
+		assert.Equal(t, "https://docs.example.com", e.Data.(model.Bookmark).URL)
+
+		assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/v1/projects/proj-2/bookmarks/b1/open", "").Code)
+	})
+}
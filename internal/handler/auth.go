@@ -0,0 +1,412 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/auth"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+)
+
+// apiTokenView is the JSON shape of a bearer token. It omits the hash.
+type apiTokenView struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id,omitempty"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Token      string     `json:"token,omitempty"` // plaintext, only on create
+}
+
+// userView is the JSON shape of a non-admin user. It omits the password hash.
+type userView struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// safeNext only allows local redirect targets after login so /login can't be
+// used as an open redirect.
+func safeNext(next string) string {
+	if next == "" || !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// LoginPage renders the username/password form. With auth disabled it just redirects home.
+func (h *Handlers) LoginPage(w http.ResponseWriter, r *http.Request) {
+	next := safeNext(r.URL.Query().Get("next"))
+	if !h.auth.Enabled() || h.auth.Authenticate(r) {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]any{
+		"Title": "Sign in - ClawIDE",
+		"Theme": h.cfg.Theme,
+		"Mode":  h.cfg.Mode,
+		"Next":  next,
+		"Error": r.URL.Query().Get("error"),
+	}
+	if err := h.renderer.Render(w, "login", data); err != nil {
+		log.Printf("Error rendering login: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Login accepts either a form post from the login page or a JSON body
+// ({"username": "...", "password": "..."}) from scripts. An empty username
+// signs in as the admin.
+func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
+	isJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+
+	var username, password, next string
+	if isJSON {
+		var req struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		username, password = req.Username, req.Password
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		username = r.FormValue("username")
+		password = r.FormValue("password")
+		next = safeNext(r.FormValue("next"))
+	}
+
+	if !h.auth.Enabled() {
+		http.Error(w, "authentication is not enabled", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.auth.Login(w, r, username, password); err != nil {
+		if isJSON {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, "/login?error="+url.QueryEscape(err.Error())+"&next="+url.QueryEscape(next), http.StatusSeeOther)
+		return
+	}
+
+	if isJSON {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
+	h.auth.Logout(w, r)
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/login")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// AuthStatus reports whether a password is configured and who the caller
+// is. Reaching this handler at all means the caller is authenticated.
+func (h *Handlers) AuthStatus(w http.ResponseWriter, r *http.Request) {
+	p := middleware.GetPrincipal(r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled":  h.auth.Enabled(),
+		"username": p.Username,
+		"admin":    p.IsAdmin(),
+	})
+}
+
+// SetPassword changes the caller's own password. For the admin it can also
+// set the first password or (with an empty new_password) remove it, which
+// disables authentication. Changing an existing password requires the
+// current one.
+func (h *Handlers) SetPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	p := middleware.GetPrincipal(r)
+	if !p.IsAdmin() {
+		if !h.auth.CheckUserPassword(p.UserID, req.CurrentPassword) {
+			http.Error(w, "current password is incorrect", http.StatusForbidden)
+			return
+		}
+		if err := h.auth.SetUserPassword(p.UserID, req.NewPassword); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.auth.RefreshSession(w, r, p)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"enabled": true})
+		return
+	}
+
+	if h.auth.Enabled() && !h.auth.CheckPassword(req.CurrentPassword) {
+		http.Error(w, "current password is incorrect", http.StatusForbidden)
+		return
+	}
+	if req.NewPassword == "" && !auth.IsLoopbackHost(h.cfg.Host) {
+		http.Error(w, "the password can't be removed while ClawIDE listens on "+h.cfg.Host, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.auth.SetPassword(req.NewPassword); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Signatures cover the password hash, so the caller's old cookie is now
+	// invalid. Re-issue one so the browser that changed it stays signed in.
+	if req.NewPassword != "" {
+		h.auth.RefreshSession(w, r, auth.Admin)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"enabled": h.auth.Enabled()})
+}
+
+func (h *Handlers) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens := h.auth.Tokens(middleware.GetPrincipal(r))
+	out := make([]apiTokenView, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, apiTokenView{
+			ID:         t.ID,
+			Name:       t.Name,
+			UserID:     t.UserID,
+			Prefix:     t.Prefix,
+			CreatedAt:  t.CreatedAt,
+			LastUsedAt: t.LastUsedAt,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func (h *Handlers) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	owner := middleware.GetPrincipal(r)
+	if owner.Project != "" {
+		// A pane token must not mint a token that outlives its project scope.
+		http.Error(w, "pane tokens cannot create API tokens", http.StatusForbidden)
+		return
+	}
+	t, secret, err := h.auth.CreateToken(req.Name, owner)
+	if err != nil {
+		log.Printf("Failed to create API token: %v", err)
+		http.Error(w, "failed to create token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(apiTokenView{
+		ID:        t.ID,
+		Name:      t.Name,
+		UserID:    t.UserID,
+		Prefix:    t.Prefix,
+		CreatedAt: t.CreatedAt,
+		Token:     secret,
+	})
+}
+
+func (h *Handlers) DeleteAPIToken(w http.ResponseWriter, r *http.Request) {
+	tokenID := chi.URLParam(r, "tokenID")
+	if err := h.auth.RevokeToken(tokenID, middleware.GetPrincipal(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handlers) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users := h.auth.Users()
+	out := make([]userView, 0, len(users))
+	for _, u := range users {
+		out = append(out, userView{ID: u.ID, Username: u.Username, CreatedAt: u.CreatedAt})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func (h *Handlers) CreateUser(w http.ResponseWriter, r *http.Request) {
+	if !h.auth.Enabled() {
+		http.Error(w, "set an admin password before adding users", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	u, err := h.auth.CreateUser(req.Username, req.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(userView{ID: u.ID, Username: u.Username, CreatedAt: u.CreatedAt})
+}
+
+// ResetUserPassword lets the admin set a new password for a user.
+func (h *Handlers) ResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if err := h.auth.SetUserPassword(chi.URLParam(r, "userID"), req.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteUser removes a user, their tokens and their project memberships.
+func (h *Handlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	if err := h.auth.DeleteUser(userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	for _, p := range h.store.GetProjects() {
+		if p.RoleFor(userID) == model.RoleNone {
+			continue
+		}
+		members := make([]model.ProjectMember, 0, len(p.Members))
+		for _, m := range p.Members {
+			if m.UserID != userID {
+				members = append(members, m)
+			}
+		}
+		p.Members = members
+		if err := h.store.UpdateProject(p); err != nil {
+			log.Printf("Failed to remove user %s from project %s: %v", userID, p.ID, err)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// projectMemberView is a ProjectMember with the username resolved.
+type projectMemberView struct {
+	UserID   string            `json:"user_id"`
+	Username string            `json:"username"`
+	Role     model.ProjectRole `json:"role"`
+}
+
+// ListProjectMembers returns the project's members and the caller's own role.
+func (h *Handlers) ListProjectMembers(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	names := make(map[string]string)
+	for _, u := range h.auth.Users() {
+		names[u.ID] = u.Username
+	}
+	members := make([]projectMemberView, 0, len(project.Members))
+	for _, m := range project.Members {
+		members = append(members, projectMemberView{UserID: m.UserID, Username: names[m.UserID], Role: m.Role})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"members": members,
+		"role":    middleware.GetProjectRole(r),
+	})
+}
+
+// SetProjectMembers replaces the project's member list. Owner only.
+func (h *Handlers) SetProjectMembers(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+
+	var req []model.ProjectMember
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	seen := make(map[string]bool)
+	members := make([]model.ProjectMember, 0, len(req))
+	for _, m := range req {
+		if !m.Role.Valid() {
+			http.Error(w, "invalid role "+string(m.Role), http.StatusBadRequest)
+			return
+		}
+		if _, ok := h.auth.User(m.UserID); !ok {
+			http.Error(w, "unknown user "+m.UserID, http.StatusBadRequest)
+			return
+		}
+		if seen[m.UserID] {
+			continue
+		}
+		seen[m.UserID] = true
+		members = append(members, m)
+	}
+
+	// A non-admin owner must not be able to lock themselves out.
+	if p := middleware.GetPrincipal(r); !p.IsAdmin() {
+		updated := project
+		updated.Members = members
+		if updated.RoleFor(p.UserID) != model.RoleOwner {
+			http.Error(w, "you cannot remove your own owner role", http.StatusBadRequest)
+			return
+		}
+	}
+
+	project.Members = members
+	project.UpdatedAt = time.Now()
+	if err := h.store.UpdateProject(project); err != nil {
+		log.Printf("Failed to update project members: %v", err)
+		http.Error(w, "failed to update members", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// paneAPIToken returns the token injected into the environment of a pane
+// owner opens in projectID: the agent token for the admin, otherwise a token
+// limited to owner's role in that project. It is injected even while auth is
+// disabled so panes created before a password is set keep working afterwards
+// (tmux preserves their environment).
+func (h *Handlers) paneAPIToken(owner auth.Principal, projectID string) string {
+	if h.auth == nil {
+		return ""
+	}
+	return h.auth.PaneToken(owner, projectID)
+}
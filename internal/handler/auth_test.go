@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIToken_PaneTokens(t *testing.T) {
+	h, _ := setupHandlerWithRenderer(t)
+	alice := addUser(t, h, "alice")
+
+	create := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		h.CreateAPIToken(w, r)
+		return w.Code
+	}
+	newReq := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/api/auth/tokens", strings.NewReader(`{"name":"laptop"}`))
+	}
+
+	assert.Equal(t, http.StatusCreated, create(as(t, h, newReq(), alice)))
+
+	alice.Project = "proj-1"
+	assert.Equal(t, http.StatusForbidden, create(as(t, h, newReq(), alice)),
+		"a pane token can't mint a token without its project scope")
+}
+
+func TestSetPassword_KeptOnNonLoopbackHost(t *testing.T) {
+	h, _ := setupHandlerWithRenderer(t)
+	require.NoError(t, h.auth.SetPassword("admin-password"))
+	remove := func() int {
+		w := httptest.NewRecorder()
+		h.SetPassword(w, httptest.NewRequest(http.MethodPut, "/api/auth/password",
+			strings.NewReader(`{"current_password":"admin-password","new_password":""}`)))
+		return w.Code
+	}
+
+	h.cfg.Host = "0.0.0.0"
+	assert.Equal(t, http.StatusBadRequest, remove())
+	assert.True(t, h.auth.Enabled())
+
+	h.cfg.Host = "127.0.0.1"
+	assert.Equal(t, http.StatusOK, remove())
+	assert.False(t, h.auth.Enabled())
+}
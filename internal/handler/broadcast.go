@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/tmux"
+)
+
+// Broadcast scopes select every matching pane in a session, a feature, or the
+// whole project (main workspace and all features).
+const (
+	broadcastScopeSession = "session"
+	broadcastScopeFeature = "feature"
+	broadcastScopeProject = "project"
+)
+
+type broadcastRequest struct {
+	Input string `json:"input"`
+	// Enter presses Enter after the input. Defaults to true.
+	Enter *bool `json:"enter"`
+	// PaneIDs selects panes explicitly. When empty, Scope (with SessionID or
+	// FeatureID) and PaneType select them instead.
+	PaneIDs   []string `json:"pane_ids"`
+	Scope     string   `json:"scope"`
+	SessionID string   `json:"session_id"`
+	FeatureID string   `json:"feature_id"`
+	// PaneType filters scoped selections: "agent" (default), "shell" or "all".
+	PaneType string `json:"pane_type"`
+}
+
+type broadcastResult struct {
+	PaneID    string `json:"pane_id"`
+	SessionID string `json:"session_id,omitempty"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+type broadcastResponse struct {
+	Sent    int               `json:"sent"`
+	Failed  int               `json:"failed"`
+	Results []broadcastResult `json:"results"`
+}
+
+// BroadcastInput types the same input into several panes of a project.
+// POST /projects/{id}/broadcast
+func (h *Handlers) BroadcastInput(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+
+	var req broadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	enter := req.Enter == nil || *req.Enter
+	if req.Input == "" && !enter {
+		http.Error(w, "input is required", http.StatusBadRequest)
+		return
+	}
+
+	// Every session of the project, including its features' sessions.
+	var sessions []model.Session
+	for _, sess := range h.store.GetAllSessions() {
+		if sess.ProjectID == project.ID && sess.Layout != nil {
+			sessions = append(sessions, sess)
+		}
+	}
+
+	type target struct {
+		paneID    string
+		sessionID string
+	}
+	var targets []target
+
+	if len(req.PaneIDs) > 0 {
+		owner := make(map[string]string)
+		for _, sess := range sessions {
+			for _, id := range sess.Layout.CollectLeaves() {
+				owner[id] = sess.ID
+			}
+		}
+		for _, id := range req.PaneIDs {
+			targets = append(targets, target{paneID: id, sessionID: owner[id]})
+		}
+	} else {
+		paneType := req.PaneType
+		if paneType == "" {
+			paneType = model.PaneTypeAgent
+		}
+		if paneType != model.PaneTypeAgent && paneType != model.PaneTypeShell && paneType != "all" {
+			http.Error(w, "pane_type must be agent, shell or all", http.StatusBadRequest)
+			return
+		}
+
+		var match func(model.Session) bool
+		switch req.Scope {
+		case broadcastScopeSession:
+			if req.SessionID == "" {
+				http.Error(w, "session_id is required for the session scope", http.StatusBadRequest)
+				return
+			}
+			match = func(s model.Session) bool { return s.ID == req.SessionID }
+		case broadcastScopeFeature:
+			if req.FeatureID == "" {
+				http.Error(w, "feature_id is required for the feature scope", http.StatusBadRequest)
+				return
+			}
+			match = func(s model.Session) bool { return s.FeatureID == req.FeatureID }
+		case broadcastScopeProject:
+			match = func(model.Session) bool { return true }
+		default:
+			http.Error(w, "pane_ids or a scope (session, feature, project) is required", http.StatusBadRequest)
+			return
+		}
+
+		for _, sess := range sessions {
+			if !match(sess) {
+				continue
+			}
+			for _, id := range sess.Layout.CollectLeaves() {
+				node, _ := sess.Layout.FindPane(id)
+				if paneType != "all" && (node == nil || node.EffectivePaneType() != paneType) {
+					continue
+				}
+				targets = append(targets, target{paneID: id, sessionID: sess.ID})
+			}
+		}
+	}
+
+	resp := broadcastResponse{Results: make([]broadcastResult, 0, len(targets))}
+	for _, t := range targets {
+		res := broadcastResult{PaneID: t.paneID, SessionID: t.sessionID}
+		switch {
+		case t.sessionID == "":
+			res.Error = "pane not found in this project"
+		case !tmux.HasSession(tmux.TmuxName(t.paneID)):
+			res.Error = "terminal is not running"
+		default:
+			if err := tmux.SendText(tmux.TmuxName(t.paneID), req.Input, enter); err != nil {
+				res.Error = strings.TrimSpace(err.Error())
+			} else {
+				res.OK = true
+			}
+		}
+		if res.OK {
+			resp.Sent++
+		} else {
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, res)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
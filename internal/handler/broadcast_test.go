@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastInput(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddSession(model.Session{
+		ID:        "s1",
+		ProjectID: "proj-1",
+		Layout: &model.PaneNode{
+			Type:      "split",
+			Direction: "horizontal",
+			Ratio:     0.5,
+			First:     model.NewAgentPane("bcast-agent-1"),
+			Second:    model.NewLeafPane("bcast-shell-1"),
+		},
+	}))
+	require.NoError(t, st.AddSession(model.Session{
+		ID:        "s2",
+		ProjectID: "proj-1",
+		FeatureID: "f1",
+		Layout:    model.NewAgentPane("bcast-agent-2"),
+	}))
+
+	router := chi.NewRouter()
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Post("/broadcast", h.BroadcastInput)
+	})
+	send := func(body string) (*httptest.ResponseRecorder, broadcastResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/projects/proj-1/broadcast", strings.NewReader(body)))
+		var resp broadcastResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp
+	}
+	paneIDs := func(resp broadcastResponse) []string {
+		var ids []string
+		for _, r := range resp.Results {
+			ids = append(ids, r.PaneID)
+		}
+		return ids
+	}
+
+	t.Run("requires a target", func(t *testing.T) {
+		w, _ := send(`{"input": "git pull"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, _ = send(`{"input": "git pull", "scope": "session"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("session scope defaults to agent panes", func(t *testing.T) {
+		w, resp := send(`{"input": "/clear", "scope": "session", "session_id": "s1"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"bcast-agent-1"}, paneIDs(resp))
+	})
+
+	t.Run("project scope includes features", func(t *testing.T) {
+		_, resp := send(`{"input": "/clear", "scope": "project"}`)
+		assert.ElementsMatch(t, []string{"bcast-agent-1", "bcast-agent-2"}, paneIDs(resp))
+
+		_, resp = send(`{"input": "ls", "scope": "feature", "feature_id": "f1", "pane_type": "all"}`)
+		assert.Equal(t, []string{"bcast-agent-2"}, paneIDs(resp))
+	})
+
+	t.Run("reports per-pane failures", func(t *testing.T) {
+		_, resp := send(`{"input": "ls", "pane_ids": ["bcast-shell-1", "elsewhere"]}`)
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, 2, resp.Failed)
+		assert.Equal(t, "terminal is not running", resp.Results[0].Error)
+		assert.Equal(t, "s1", resp.Results[0].SessionID)
+		assert.Equal(t, "pane not found in this project", resp.Results[1].Error)
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The JSON views clawide ctl relies on.
+func TestJSONListings(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddFeature(model.Feature{ID: "f1", ProjectID: "proj-1", Name: "login"}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s1", ProjectID: "proj-1", Layout: model.NewLeafPane("p1")}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s2", ProjectID: "proj-1", FeatureID: "f1", Layout: model.NewLeafPane("p2")}))
+
+	router := chi.NewRouter()
+	router.Get("/projects/", h.ListProjects)
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Get("/sessions/", h.ListSessions)
+		r.Get("/features/", h.ListFeatures)
+		r.Get("/sessions/{sid}/panes/{pid}/output", h.PaneOutput)
+	})
+	get := func(target string, out any) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if out != nil && w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), out), w.Body.String())
+		}
+		return w.Code
+	}
+
+	var projects []model.Project
+	require.Equal(t, http.StatusOK, get("/projects/", &projects))
+	require.Len(t, projects, 1)
+	assert.Equal(t, "proj-1", projects[0].ID)
+
+	var sessions []model.Session
+	require.Equal(t, http.StatusOK, get("/projects/proj-1/sessions/", &sessions))
+	assert.Len(t, sessions, 2, "feature sessions are listed too")
+	require.Equal(t, http.StatusOK, get("/projects/proj-1/sessions/?feature_id=f1", &sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "s2", sessions[0].ID)
+
+	var features []model.Feature
+	require.Equal(t, http.StatusOK, get("/projects/proj-1/features/", &features))
+	require.Len(t, features, 1)
+	assert.Equal(t, "login", features[0].Name)
+
+	t.Run("pane output", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/projects/proj-1/sessions/s1/panes/p2/output", nil))
+		assert.Equal(t, http.StatusNotFound, get("/projects/proj-1/sessions/nope/panes/p1/output", nil))
+		assert.Equal(t, http.StatusBadRequest, get("/projects/proj-1/sessions/s1/panes/p1/output?lines=x", nil))
+	})
+}
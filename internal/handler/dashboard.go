@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/wizard"
 )
 
@@ -25,11 +26,12 @@ func (h *Handlers) Dashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	projects := h.store.GetProjects()
+	projects := visibleProjects(r, h.store.GetProjects())
+	isAdmin := middleware.GetPrincipal(r).IsAdmin()
 
-	// Scan projects_dir for discoverable folders
+	// Scan projects_dir for discoverable folders; only the admin can add them
 	var discovered []DirEntry
-	if h.cfg.ProjectsDir != "" {
+	if isAdmin && h.cfg.ProjectsDir != "" {
 		entries, err := os.ReadDir(h.cfg.ProjectsDir)
 		if err == nil {
 			// Build set of registered paths for fast lookup
@@ -69,6 +71,7 @@ func (h *Handlers) Dashboard(w http.ResponseWriter, r *http.Request) {
 		"StartTour":       r.URL.Query().Get("tour") == "dashboard",
 		"Languages":       wizard.SupportedLanguages(),
 		"ProjectsDir":     h.cfg.ProjectsDir,
+		"IsAdmin":         isAdmin,
 	}
 
 	if err := h.renderer.RenderHTMX(w, r, "project-list", "project-list", data); err != nil {
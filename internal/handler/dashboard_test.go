@@ -56,6 +56,14 @@ func TestDashboard(t *testing.T) {
 
 		h.Dashboard(w, req)
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "discovered:1")
+
+		// Only the admin can import them, so other users aren't shown any.
+		alice := addUser(t, h, "alice")
+		w = httptest.NewRecorder()
+		h.Dashboard(w, as(t, h, httptest.NewRequest(http.MethodGet, "/", nil), alice))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "discovered")
 	})
 
 	t.Run("empty projects dir handled gracefully", func(t *testing.T) {
@@ -19,8 +19,8 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-// dockerStatusResponse is the JSON envelope for the combined Docker status endpoint.
-type dockerStatusResponse struct {
+// DockerStatusResponse is the JSON envelope for the combined Docker status endpoint.
+type DockerStatusResponse struct {
 	DaemonRunning   bool                         `json:"daemon_running"`
 	ComposeFile     bool                         `json:"compose_file"`
 	Services        []model.DockerService        `json:"services"`
@@ -31,8 +31,8 @@ type dockerStatusResponse struct {
 }
 
 // dockerStatusForDir returns the combined Docker status for a given directory.
-func dockerStatusForDir(dir, label string) dockerStatusResponse {
-	resp := dockerStatusResponse{}
+func dockerStatusForDir(dir, label string) DockerStatusResponse {
+	resp := DockerStatusResponse{}
 	resp.DaemonRunning = docker.IsDockerRunning()
 	resp.ComposeFile = docker.HasComposeFile(dir)
 
@@ -306,11 +306,10 @@ func (h *Handlers) DockerLogsWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	project, ok := h.store.GetProject(projectID)
-	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+	if !h.requireProjectRole(w, r, projectID, model.RoleViewer) {
 		return
 	}
+	project, _ := h.store.GetProject(projectID)
 
 	dockerLogsWSForDir(w, r, project.Path, projectID, svc)
 }
@@ -325,11 +324,10 @@ func (h *Handlers) DockerBuildWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	project, ok := h.store.GetProject(projectID)
-	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+	if !h.requireProjectRole(w, r, projectID, model.RoleCollaborator) {
 		return
 	}
+	project, _ := h.store.GetProject(projectID)
 
 	dockerBuildWSForDir(w, r, project.Path, projectID, svc)
 }
@@ -482,6 +480,9 @@ func (h *Handlers) FeatureDockerLogsWS(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "feature not found", http.StatusNotFound)
 		return
 	}
+	if !h.requireProjectRole(w, r, feature.ProjectID, model.RoleViewer) {
+		return
+	}
 
 	dockerLogsWSForDir(w, r, feature.WorktreePath, "feature:"+fid, svc)
 }
@@ -501,6 +502,9 @@ func (h *Handlers) FeatureDockerBuildWS(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "feature not found", http.StatusNotFound)
 		return
 	}
+	if !h.requireProjectRole(w, r, feature.ProjectID, model.RoleCollaborator) {
+		return
+	}
 
 	dockerBuildWSForDir(w, r, feature.WorktreePath, "feature:"+fid, svc)
 }
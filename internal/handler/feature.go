@@ -11,6 +11,7 @@ import (
 	"github.com/davydany/ClawIDE/internal/git"
 	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -153,10 +154,26 @@ func (h *Handlers) CreateFeature(w http.ResponseWriter, r *http.Request) {
 	if err := h.store.AddSession(sess); err != nil {
 		log.Printf("Error creating initial session: %v", err)
 	}
+	h.webhooks.Emit(webhook.FeatureEvent(model.WebhookEventFeatureCreated, project, feature))
 
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusCreated, feature)
+		return
+	}
 	http.Redirect(w, r, "/projects/"+project.ID+"/features/"+featureID+"/", http.StatusSeeOther)
 }
 
+// ListFeatures returns a project's features as JSON.
+// GET /projects/{id}/features/
+func (h *Handlers) ListFeatures(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	features := h.store.GetFeatures(project.ID)
+	if features == nil {
+		features = []model.Feature{}
+	}
+	writeJSON(w, http.StatusOK, features)
+}
+
 // UpdateFeatureColor updates the color of a feature.
 // PATCH /projects/{id}/features/{fid}/color
 func (h *Handlers) UpdateFeatureColor(w http.ResponseWriter, r *http.Request) {
@@ -218,7 +235,7 @@ func (h *Handlers) FeatureWorkspace(w http.ResponseWriter, r *http.Request) {
 	sessions := h.store.GetFeatureSessions(featureID)
 
 	// Collect starred and non-starred projects for quick-switch panel
-	starredProjects, nonStarredProjects := splitAndSortProjects(h.store.GetProjects())
+	starredProjects, nonStarredProjects := splitAndSortProjects(visibleProjects(r, h.store.GetProjects()))
 
 	features := h.store.GetFeatures(project.ID)
 
@@ -326,7 +343,13 @@ func (h *Handlers) DeleteFeature(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to delete feature", http.StatusInternalServerError)
 		return
 	}
+	h.removePreviewBookmark(feature)
+	h.webhooks.Emit(webhook.FeatureEvent(model.WebhookEventFeatureDeleted, project, feature))
 
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/projects/"+project.ID+"/")
 		w.WriteHeader(http.StatusOK)
@@ -358,7 +381,11 @@ func (h *Handlers) CreateFeatureSession(w http.ResponseWriter, r *http.Request)
 		name = "Session " + time.Now().Format("15:04")
 	}
 
-	paneID := uuid.New().String()
+	layout, err := h.newSessionLayout(r, project.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	now := time.Now()
 
 	sess := model.Session{
@@ -367,7 +394,7 @@ func (h *Handlers) CreateFeatureSession(w http.ResponseWriter, r *http.Request)
 		FeatureID: featureID,
 		Name:      name,
 		WorkDir:   feature.WorktreePath,
-		Layout:    model.NewAgentPane(paneID),
+		Layout:    layout,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/davydany/ClawIDE/internal/git"
 	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/webhook"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -234,6 +235,8 @@ func (h *Handlers) featureMerge(w http.ResponseWriter, r *http.Request, project
 		return
 	}
 
+	h.webhooks.Emit(webhook.FeatureEvent(model.WebhookEventFeatureMerged, project, feature))
+
 	// 10. Redirect to project workspace.
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -285,6 +288,7 @@ func (h *Handlers) branchMerge(w http.ResponseWriter, r *http.Request, project m
 
 	// 5. Switch back to the workspace branch.
 	git.CheckoutBranch(clonePath, feature.BranchName)
+	h.webhooks.Emit(webhook.FeatureEvent(model.WebhookEventFeatureMerged, project, feature))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "merged"})
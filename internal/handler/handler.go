@@ -5,15 +5,21 @@ import (
 	"log"
 	"sync"
 
+	"github.com/davydany/ClawIDE/internal/agentstate"
 	"github.com/davydany/ClawIDE/internal/aicli"
+	"github.com/davydany/ClawIDE/internal/auth"
 	"github.com/davydany/ClawIDE/internal/config"
 	"github.com/davydany/ClawIDE/internal/mcpserver"
 	"github.com/davydany/ClawIDE/internal/migration"
+	"github.com/davydany/ClawIDE/internal/notify"
+	"github.com/davydany/ClawIDE/internal/procstats"
 	ptyPkg "github.com/davydany/ClawIDE/internal/pty"
 	"github.com/davydany/ClawIDE/internal/sse"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/davydany/ClawIDE/internal/tmpl"
 	"github.com/davydany/ClawIDE/internal/updater"
+	"github.com/davydany/ClawIDE/internal/webhook"
+	"github.com/davydany/ClawIDE/internal/webpush"
 	"github.com/davydany/ClawIDE/internal/wizard"
 )
 
@@ -34,6 +40,18 @@ type Handlers struct {
 	wizardJobs        *wizard.JobTracker
 	wizardGenerator   *wizard.Generator
 	mcpProcessManager *mcpserver.ProcessManager
+	auth              *auth.Manager
+	shareLinkStore    *store.ShareLinkStore
+	shareViewers      *shareViewers
+	agentStates       *agentstate.Monitor
+	layoutTemplates   *store.LayoutTemplateStore
+	agentProfiles     *store.AgentProfileStore
+	usageSampler      *procstats.Sampler
+	webhookStore      *store.WebhookStore
+	webhooks          *webhook.Dispatcher
+	pushSubscriptions *store.PushSubscriptionStore
+	pusher            *webpush.Pusher
+	notifier          *notify.Center
 
 	// Project-scoped stores, keyed by projectID. Lazily initialized.
 	projectNoteStores     map[string]*store.ProjectNoteStore
@@ -49,7 +67,7 @@ type Handlers struct {
 	aiRegistry *aicli.Registry
 }
 
-func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer, ptyMgr *ptyPkg.Manager, snippetSt *store.SnippetStore, notifSt *store.NotificationStore, noteSt *store.NoteStore, bookmarkSt *store.BookmarkStore, voiceBoxSt *store.VoiceBoxStore, scratchpadSt *store.ScratchpadStore, promptForgeSt *store.PromptForgeStore, globalTaskSt *store.TaskStore, aiReg *aicli.Registry, hub *sse.Hub, upd *updater.Updater, wizJobs *wizard.JobTracker, wizGen *wizard.Generator) *Handlers {
+func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer, ptyMgr *ptyPkg.Manager, snippetSt *store.SnippetStore, notifSt *store.NotificationStore, noteSt *store.NoteStore, bookmarkSt *store.BookmarkStore, voiceBoxSt *store.VoiceBoxStore, scratchpadSt *store.ScratchpadStore, promptForgeSt *store.PromptForgeStore, globalTaskSt *store.TaskStore, aiReg *aicli.Registry, hub *sse.Hub, upd *updater.Updater, wizJobs *wizard.JobTracker, wizGen *wizard.Generator, authMgr *auth.Manager, shareSt *store.ShareLinkStore, agentStates *agentstate.Monitor, layoutSt *store.LayoutTemplateStore, profileSt *store.AgentProfileStore, usageSampler *procstats.Sampler, webhookSt *store.WebhookStore, webhooks *webhook.Dispatcher, pushSubSt *store.PushSubscriptionStore, pusher *webpush.Pusher, notifier *notify.Center) *Handlers {
 	return &Handlers{
 		cfg:                   cfg,
 		store:                 st,
@@ -69,6 +87,18 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer, ptyMgr *p
 		wizardJobs:            wizJobs,
 		wizardGenerator:       wizGen,
 		mcpProcessManager:     mcpserver.NewProcessManager(),
+		auth:                  authMgr,
+		shareLinkStore:        shareSt,
+		shareViewers:          newShareViewers(),
+		agentStates:           agentStates,
+		layoutTemplates:       layoutSt,
+		agentProfiles:         profileSt,
+		usageSampler:          usageSampler,
+		webhookStore:          webhookSt,
+		webhooks:              webhooks,
+		pushSubscriptions:     pushSubSt,
+		pusher:                pusher,
+		notifier:              notifier,
 		projectNoteStores:     make(map[string]*store.ProjectNoteStore),
 		projectBookmarkStores: make(map[string]*store.ProjectBookmarkStore),
 		projectTaskStores:     make(map[string]*store.TaskStore),
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var errLayoutTemplateNotFound = errors.New("layout template not found")
+
+type layoutTemplateRequest struct {
+	ProjectID   string          `json:"project_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	SessionID   string          `json:"session_id"` // save this session's current layout
+	Layout      *model.PaneNode `json:"layout"`     // or give the tree directly
+}
+
+// ListLayoutTemplates returns the global templates plus, when project_id is
+// given, that project's templates.
+// GET /api/layout-templates?project_id=
+func (h *Handlers) ListLayoutTemplates(w http.ResponseWriter, r *http.Request) {
+	templates := h.layoutTemplates.ForProject(r.URL.Query().Get("project_id"))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(templates); err != nil {
+		log.Printf("layout template list JSON encode error: %v", err)
+	}
+}
+
+// CreateLayoutTemplate saves a template, either from an existing session's
+// layout (session_id) or from an explicit layout tree. Without project_id the
+// template is global.
+// POST /api/layout-templates
+func (h *Handlers) CreateLayoutTemplate(w http.ResponseWriter, r *http.Request) {
+	var body layoutTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if body.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	layout, status, err := h.templateLayoutFor(r, body)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	now := time.Now()
+	tpl := model.LayoutTemplate{
+		ID:          uuid.New().String(),
+		ProjectID:   body.ProjectID,
+		Name:        body.Name,
+		Description: body.Description,
+		Layout:      layout,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.layoutTemplates.Add(tpl); err != nil {
+		log.Printf("layout template create error: %v", err)
+		http.Error(w, "failed to create layout template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tpl)
+}
+
+// UpdateLayoutTemplate renames a template or replaces its layout. The
+// template's scope cannot change.
+// PUT /api/layout-templates/{templateID}?project_id=
+func (h *Handlers) UpdateLayoutTemplate(w http.ResponseWriter, r *http.Request) {
+	tpl, ok := h.scopedLayoutTemplate(r)
+	if !ok {
+		http.Error(w, errLayoutTemplateNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	var body layoutTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	body.ProjectID = tpl.ProjectID
+
+	if body.Name != "" {
+		tpl.Name = body.Name
+	}
+	tpl.Description = body.Description
+	if body.SessionID != "" || body.Layout != nil {
+		layout, status, err := h.templateLayoutFor(r, body)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		tpl.Layout = layout
+	}
+	tpl.UpdatedAt = time.Now()
+
+	if err := h.layoutTemplates.Update(tpl); err != nil {
+		log.Printf("layout template update error: %v", err)
+		http.Error(w, "failed to update layout template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tpl)
+}
+
+// DeleteLayoutTemplate removes a template.
+// DELETE /api/layout-templates/{templateID}?project_id=
+func (h *Handlers) DeleteLayoutTemplate(w http.ResponseWriter, r *http.Request) {
+	tpl, ok := h.scopedLayoutTemplate(r)
+	if !ok {
+		http.Error(w, errLayoutTemplateNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.layoutTemplates.Delete(tpl.ID); err != nil {
+		log.Printf("layout template delete error: %v", err)
+		http.Error(w, "failed to delete layout template", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scopedLayoutTemplate loads the {templateID} template if it belongs to the
+// project_id scope of the request. ProjectScope has already checked the
+// caller's access to that scope, so a project collaborator cannot change
+// global templates or those of other projects.
+func (h *Handlers) scopedLayoutTemplate(r *http.Request) (model.LayoutTemplate, bool) {
+	tpl, ok := h.layoutTemplates.Get(chi.URLParam(r, "templateID"))
+	if !ok || tpl.ProjectID != r.URL.Query().Get("project_id") {
+		return model.LayoutTemplate{}, false
+	}
+	return tpl, true
+}
+
+// templateLayoutFor builds a validated, ID-free template tree from the
+// request's session_id or layout.
+func (h *Handlers) templateLayoutFor(r *http.Request, body layoutTemplateRequest) (*model.PaneNode, int, error) {
+	var layout *model.PaneNode
+	if body.SessionID != "" {
+		sess, ok := h.store.GetSession(body.SessionID)
+		if !ok || (body.ProjectID != "" && sess.ProjectID != body.ProjectID) || h.projectRole(r, sess.ProjectID) == model.RoleNone {
+			return nil, http.StatusNotFound, errors.New("session not found")
+		}
+		layout = sess.Layout
+	} else {
+		layout = body.Layout
+	}
+	if layout == nil {
+		return nil, http.StatusBadRequest, errors.New("session_id or layout is required")
+	}
+
+	layout = model.TemplateFrom(layout)
+	if err := model.ValidateTemplateLayout(layout); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return layout, 0, nil
+}
+
+// newSessionLayout returns the starting layout for a session created in
+// projectID: fresh panes from the template named by the template_id form
+// value, or a single agent pane.
+func (h *Handlers) newSessionLayout(r *http.Request, projectID string) (*model.PaneNode, error) {
+	id := r.FormValue("template_id")
+	if id == "" {
+		return model.NewAgentPaneWithID(), nil
+	}
+	tpl, ok := h.layoutTemplates.Get(id)
+	if !ok || (tpl.ProjectID != "" && tpl.ProjectID != projectID) {
+		return nil, errLayoutTemplateNotFound
+	}
+	return tpl.Instantiate(), nil
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutTemplates(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-2", Name: "Other", Path: t.TempDir()}))
+
+	agent := model.NewAgentPane("tpl-agent")
+	agent.Name = "claude"
+	shell := model.NewLeafPane("tpl-shell")
+	shell.Command = "npm run dev"
+	require.NoError(t, st.AddSession(model.Session{
+		ID:        "s1",
+		ProjectID: "proj-1",
+		Layout:    &model.PaneNode{Type: "split", Direction: "horizontal", Ratio: 0.6, First: agent, Second: shell},
+	}))
+
+	router := chi.NewRouter()
+	router.Route("/api/layout-templates", func(r chi.Router) {
+		r.Use(middleware.ProjectScope(h.store))
+		r.Get("/", h.ListLayoutTemplates)
+		r.Post("/", h.CreateLayoutTemplate)
+		r.Put("/{templateID}", h.UpdateLayoutTemplate)
+		r.Delete("/{templateID}", h.DeleteLayoutTemplate)
+	})
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Post("/sessions/", h.CreateSession)
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("POST", "/api/layout-templates/", `{"project_id":"proj-1","name":"Agent + shell","session_id":"s1"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var tpl model.LayoutTemplate
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tpl))
+	assert.Equal(t, "proj-1", tpl.ProjectID)
+	assert.Empty(t, tpl.Layout.First.PaneID, "pane IDs are not saved")
+	assert.Equal(t, "claude", tpl.Layout.First.Name)
+	assert.Equal(t, "npm run dev", tpl.Layout.Second.Command)
+
+	t.Run("global template from an explicit layout", func(t *testing.T) {
+		w := do("POST", "/api/layout-templates/", `{"name":"single shell","layout":{"type":"leaf","pane_type":"shell"}}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var list []model.LayoutTemplate
+		require.NoError(t, json.Unmarshal(do("GET", "/api/layout-templates/?project_id=proj-1", "").Body.Bytes(), &list))
+		assert.Len(t, list, 2)
+		require.NoError(t, json.Unmarshal(do("GET", "/api/layout-templates/?project_id=proj-2", "").Body.Bytes(), &list))
+		assert.Len(t, list, 1, "project templates stay in their project")
+	})
+
+	t.Run("rejects bad input", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/layout-templates/", `{"project_id":"proj-1","session_id":"s1"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/layout-templates/", `{"name":"x"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/layout-templates/", `{"name":"x","layout":{"type":"split","direction":"horizontal","ratio":0.5}}`).Code)
+		assert.Equal(t, http.StatusNotFound, do("POST", "/api/layout-templates/", `{"project_id":"proj-2","name":"x","session_id":"s1"}`).Code)
+	})
+
+	t.Run("update and delete are scoped to project_id", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, do("PUT", "/api/layout-templates/"+tpl.ID+"?project_id=proj-2", `{"name":"stolen"}`).Code)
+		assert.Equal(t, http.StatusNotFound, do("DELETE", "/api/layout-templates/"+tpl.ID, "").Code)
+
+		w := do("PUT", "/api/layout-templates/"+tpl.ID+"?project_id=proj-1", `{"name":"Dev trio"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		got, _ := h.layoutTemplates.Get(tpl.ID)
+		assert.Equal(t, "Dev trio", got.Name)
+		assert.NotNil(t, got.Layout)
+	})
+
+	t.Run("creates a session from a template", func(t *testing.T) {
+		form := url.Values{"name": {"From template"}, "template_id": {tpl.ID}}
+		req := httptest.NewRequest("POST", "/projects/proj-1/sessions/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusSeeOther, w.Code, w.Body.String())
+
+		var created model.Session
+		for _, s := range st.GetSessions("proj-1") {
+			if s.Name == "From template" {
+				created = s
+			}
+		}
+		require.NotNil(t, created.Layout)
+		leaves := created.Layout.CollectLeaves()
+		require.Len(t, leaves, 2)
+		assert.NotContains(t, leaves, "tpl-agent")
+		assert.Equal(t, "npm run dev", created.Layout.Second.Command)
+		assert.Equal(t, 0.6, created.Layout.Ratio)
+
+		// Another project's template is not usable here.
+		form.Set("template_id", tpl.ID)
+		req = httptest.NewRequest("POST", "/projects/proj-2/sessions/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	require.Equal(t, http.StatusNoContent, do("DELETE", "/api/layout-templates/"+tpl.ID+"?project_id=proj-1", "").Code)
+	_, ok := h.layoutTemplates.Get(tpl.ID)
+	assert.False(t, ok)
+}
@@ -58,6 +58,9 @@ func (h *Handlers) CreateNote(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid JSON", http.StatusBadRequest)
 		return
 	}
+	if body.ProjectID == "" {
+		body.ProjectID = r.URL.Query().Get("project_id")
+	}
 	if body.Title == "" {
 		http.Error(w, "title is required", http.StatusBadRequest)
 		return
@@ -116,6 +119,9 @@ func (h *Handlers) UpdateNote(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid JSON", http.StatusBadRequest)
 		return
 	}
+	if body.ProjectID == "" {
+		body.ProjectID = r.URL.Query().Get("project_id")
+	}
 
 	if body.ProjectID != "" {
 		ps, err := h.getProjectNoteStore(body.ProjectID)
@@ -56,6 +56,12 @@ func (h *Handlers) CreateNotification(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Posting into a project shows up for all its members, so it takes
+	// write access there.
+	if req.ProjectID != "" && !h.requireProjectRole(w, r, req.ProjectID, model.RoleCollaborator) {
+		return
+	}
+
 	n := model.Notification{
 		ID:             uuid.New().String(),
 		Title:          req.Title,
@@ -72,15 +78,15 @@ func (h *Handlers) CreateNotification(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:      time.Now(),
 	}
 
-	if err := h.notificationStore.Add(n); err != nil {
+	// Stored and broadcast (to SSE clients, webhooks and Web Push) as the
+	// notification rules decide
+	n, _, err := h.notifier.Post(n)
+	if err != nil {
 		log.Printf("Failed to add notification: %v", err)
 		http.Error(w, "failed to store notification", http.StatusInternalServerError)
 		return
 	}
 
-	// Broadcast to SSE clients
-	h.sseHub.Broadcast(&n)
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(n)
@@ -96,23 +102,53 @@ func (h *Handlers) ListNotifications(w http.ResponseWriter, r *http.Request) {
 		notifications = h.notificationStore.GetAll()
 	}
 
-	if notifications == nil {
-		notifications = []model.Notification{}
+	visible := make([]model.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		if h.canSeeNotification(r, n) {
+			visible = append(visible, n)
+		}
 	}
+	notifications = visible
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(notifications)
 }
 
 func (h *Handlers) UnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
-	count := h.notificationStore.UnreadCount()
+	count := h.unreadNotificationCount(r)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]int{"count": count})
 }
 
-func (h *Handlers) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+// unreadNotificationCount counts the unread notifications the caller can see.
+func (h *Handlers) unreadNotificationCount(r *http.Request) int {
+	count := 0
+	for _, n := range h.notificationStore.GetUnread() {
+		if h.canSeeNotification(r, n) {
+			count++
+		}
+	}
+	return count
+}
+
+// visibleNotification returns the notification in the notifID URL parameter,
+// writing a 404 unless it exists and the caller can see it.
+func (h *Handlers) visibleNotification(w http.ResponseWriter, r *http.Request) (model.Notification, bool) {
 	notifID := chi.URLParam(r, "notifID")
-	if err := h.notificationStore.MarkRead(notifID); err != nil {
+	n, ok := h.notificationStore.Get(notifID)
+	if !ok || !h.canSeeNotification(r, n) {
+		http.Error(w, fmt.Sprintf("notification %s not found", notifID), http.StatusNotFound)
+		return model.Notification{}, false
+	}
+	return n, true
+}
+
+func (h *Handlers) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.visibleNotification(w, r)
+	if !ok {
+		return
+	}
+	if err := h.notificationStore.MarkRead(n.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
@@ -120,7 +156,9 @@ func (h *Handlers) MarkNotificationRead(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *Handlers) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
-	if err := h.notificationStore.MarkAllRead(); err != nil {
+	// Only those the caller can see; other projects' stay unread.
+	visible := func(n model.Notification) bool { return h.canSeeNotification(r, n) }
+	if err := h.notificationStore.MarkReadWhere(visible); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -128,8 +166,11 @@ func (h *Handlers) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Reque
 }
 
 func (h *Handlers) DeleteNotification(w http.ResponseWriter, r *http.Request) {
-	notifID := chi.URLParam(r, "notifID")
-	if err := h.notificationStore.Delete(notifID); err != nil {
+	n, ok := h.visibleNotification(w, r)
+	if !ok {
+		return
+	}
+	if err := h.notificationStore.Delete(n.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
@@ -154,9 +195,11 @@ func (h *Handlers) NotificationStream(w http.ResponseWriter, r *http.Request) {
 	clientID := uuid.New().String()
 	ch := h.sseHub.Subscribe(clientID)
 	defer h.sseHub.Unsubscribe(clientID)
+	events := h.sseHub.SubscribeEvents(clientID)
+	defer h.sseHub.UnsubscribeEvents(clientID)
 
 	// Send initial unread count
-	count := h.notificationStore.UnreadCount()
+	count := h.unreadNotificationCount(r)
 	fmt.Fprintf(w, "event: unread-count\ndata: %d\n\n", count)
 	flusher.Flush()
 
@@ -172,12 +215,28 @@ func (h *Handlers) NotificationStream(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
+			if !h.canSeeNotification(r, *n) {
+				continue
+			}
 			data, err := json.Marshal(n)
 			if err != nil {
 				continue
 			}
 			fmt.Fprintf(w, "event: notification\ndata: %s\n\n", data)
 			flusher.Flush()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if e.ProjectID != "" && h.projectRole(r, e.ProjectID) == model.RoleNone {
+				continue
+			}
+			data, err := json.Marshal(e.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
 		case <-ticker.C:
 			fmt.Fprintf(w, ": keepalive\n\n")
 			flusher.Flush()
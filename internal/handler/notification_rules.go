@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// GetNotificationRules returns the notification rules, with the sources
+// seen so far to offer for muting.
+// GET /api/notifications/rules
+func (h *Handlers) GetNotificationRules(w http.ResponseWriter, r *http.Request) {
+	sources := []string{}
+	for _, n := range h.notificationStore.GetAll() {
+		if n.Source != "" && !slices.Contains(sources, n.Source) {
+			sources = append(sources, n.Source)
+		}
+	}
+	slices.Sort(sources)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"rules":   h.notifier.Rules(),
+		"sources": sources,
+	})
+}
+
+// UpdateNotificationRules replaces the notification rules.
+// PUT /api/notifications/rules
+func (h *Handlers) UpdateNotificationRules(w http.ResponseWriter, r *http.Request) {
+	var rules model.NotificationRules
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	rules.GroupBy = compactStrings(rules.GroupBy)
+	rules.MutedSources = compactStrings(rules.MutedSources)
+	rules.MutedProjects = compactStrings(rules.MutedProjects)
+	if err := rules.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.notifier.SetRules(rules); err != nil {
+		log.Printf("notification rules save error: %v", err)
+		http.Error(w, "failed to save notification rules", http.StatusInternalServerError)
+		return
+	}
+	// Quiet hours may have just been switched off.
+	h.notifier.ReleaseHeld()
+	writeJSON(w, http.StatusOK, rules)
+}
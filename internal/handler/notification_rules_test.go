@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationRules(t *testing.T) {
+	h, _ := setupHandlerWithRenderer(t)
+
+	router := chi.NewRouter()
+	router.Post("/api/notifications", h.CreateNotification)
+	router.Get("/api/notifications/rules", h.GetNotificationRules)
+	router.Put("/api/notifications/rules", h.UpdateNotificationRules)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	for _, body := range []string{
+		`not json`,
+		`{"group_by":["level"]}`,
+		`{"escalate_after":1}`,
+		`{"quiet_hours":{"enabled":true,"start":"9pm","end":"07:00"}}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, do("PUT", "/api/notifications/rules", body).Code, body)
+	}
+
+	w := do("PUT", "/api/notifications/rules", `{"group_by":["project"," project"],"repeat_window":60,"collapse":true,"muted_sources":["noisy"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Repeats collapse into the first notification, and muted sources are dropped.
+	var first, second model.Notification
+	w = do("POST", "/api/notifications", `{"title":"Tests failed","source":"claude"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	w = do("POST", "/api/notifications", `{"title":"Tests failed","source":"claude"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, 2, second.Count)
+	assert.Equal(t, "project:", second.GroupKey)
+	do("POST", "/api/notifications", `{"title":"Spam","source":"noisy"}`)
+	assert.Len(t, h.notificationStore.GetAll(), 1)
+
+	w = do("GET", "/api/notifications/rules", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var got struct {
+		Rules   model.NotificationRules `json:"rules"`
+		Sources []string                `json:"sources"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, []string{"project"}, got.Rules.GroupBy)
+	assert.True(t, got.Rules.Collapse)
+	assert.Equal(t, []string{"noisy"}, got.Rules.MutedSources)
+	assert.Equal(t, []string{"claude"}, got.Sources)
+}
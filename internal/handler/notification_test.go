@@ -10,6 +10,7 @@ import (
 
 	"github.com/davydany/ClawIDE/internal/config"
 	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/notify"
 	"github.com/davydany/ClawIDE/internal/sse"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/go-chi/chi/v5"
@@ -37,6 +38,7 @@ func setupTestHandler(t *testing.T) (*Handlers, *store.Store, *store.Notificatio
 		store:             st,
 		notificationStore: notifStore,
 		sseHub:            hub,
+		notifier:          notify.New(notifStore, nil, hub, st),
 	}
 
 	return h, st, notifStore
@@ -247,3 +249,63 @@ func TestCreateNotification_CWDResolution(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &n)
 	assert.Equal(t, "proj-1", n.ProjectID)
 }
+
+func TestNotifications_OnlyVisibleProjects(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	alice := addUser(t, h, "alice")
+	require.NoError(t, st.AddProject(model.Project{ID: "mine", Name: "Mine", Path: "/mine",
+		Members: []model.ProjectMember{{UserID: alice.UserID, Role: model.RoleViewer}}}))
+	require.NoError(t, st.AddProject(model.Project{ID: "theirs", Name: "Theirs", Path: "/theirs"}))
+	notifStore := h.notificationStore
+	require.NoError(t, notifStore.Add(model.Notification{ID: "n1", Title: "Mine", ProjectID: "mine"}))
+	require.NoError(t, notifStore.Add(model.Notification{ID: "n2", Title: "Theirs", ProjectID: "theirs"}))
+	require.NoError(t, notifStore.Add(model.Notification{ID: "n3", Title: "Global"}))
+
+	r := chi.NewRouter()
+	r.Get("/api/notifications/unread-count", h.UnreadNotificationCount)
+	r.Patch("/api/notifications/{notifID}/read", h.MarkNotificationRead)
+	r.Post("/api/notifications/read-all", h.MarkAllNotificationsRead)
+	r.Delete("/api/notifications/{notifID}", h.DeleteNotification)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, as(t, h, httptest.NewRequest(method, path, nil), alice))
+		return w
+	}
+
+	w := do("GET", "/api/notifications/unread-count")
+	assert.JSONEq(t, `{"count":2}`, w.Body.String(), "the other project's notification isn't counted")
+
+	assert.Equal(t, http.StatusNotFound, do("PATCH", "/api/notifications/n2/read").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/api/notifications/n2").Code)
+	assert.Equal(t, http.StatusNoContent, do("POST", "/api/notifications/read-all").Code)
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/notifications/n1").Code)
+
+	n2, ok := notifStore.Get("n2")
+	require.True(t, ok, "not deleted")
+	assert.False(t, n2.Read, "read-all leaves other projects' notifications unread")
+	n3, _ := notifStore.Get("n3")
+	assert.True(t, n3.Read)
+}
+
+func TestCreateNotification_RequiresCollaborator(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	alice := addUser(t, h, "alice")
+	require.NoError(t, st.AddProject(model.Project{ID: "writable", Name: "Writable", Path: "/writable",
+		Members: []model.ProjectMember{{UserID: alice.UserID, Role: model.RoleCollaborator}}}))
+	require.NoError(t, st.AddProject(model.Project{ID: "readonly", Name: "Read-only", Path: "/readonly",
+		Members: []model.ProjectMember{{UserID: alice.UserID, Role: model.RoleViewer}}}))
+	require.NoError(t, st.AddProject(model.Project{ID: "theirs", Name: "Theirs", Path: "/theirs"}))
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		h.CreateNotification(w, as(t, h, httptest.NewRequest("POST", "/api/notifications", bytes.NewBufferString(body)), alice))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, post(`{"title":"done","project_id":"writable"}`))
+	assert.Equal(t, http.StatusCreated, post(`{"title":"done"}`))
+	assert.Equal(t, http.StatusForbidden, post(`{"title":"done","project_id":"readonly"}`))
+	assert.Equal(t, http.StatusNotFound, post(`{"title":"done","project_id":"theirs"}`))
+	assert.Equal(t, http.StatusNotFound, post(`{"title":"done","cwd":"/theirs/src"}`), "also when resolved from the working directory")
+	assert.Len(t, h.notificationStore.GetAll(), 2)
+}
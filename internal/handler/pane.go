@@ -4,8 +4,12 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/model"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -343,3 +347,74 @@ func (h *Handlers) ResizePane(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"ok":true}`))
 }
+
+// paneEnvKeyRe matches portable shell variable names.
+var paneEnvKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type paneStartupRequest struct {
+	Command string            `json:"command"`
+	WorkDir string            `json:"work_dir"`
+	Env     map[string]string `json:"env"`
+}
+
+// SetPaneStartup replaces a pane's startup command, working directory
+// override and extra environment. They take effect the next time the pane's
+// shell is started.
+// PUT /projects/{id}/sessions/{sid}/panes/{pid}/startup
+func (h *Handlers) SetPaneStartup(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	sessionID := chi.URLParam(r, "sid")
+	paneID := chi.URLParam(r, "pid")
+
+	sess, ok := h.store.GetSession(sessionID)
+	if !ok || sess.ProjectID != project.ID {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	target, _ := sess.Layout.FindPane(paneID)
+	if target == nil {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	var body paneStartupRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	for k := range body.Env {
+		if !paneEnvKeyRe.MatchString(k) {
+			http.Error(w, "invalid environment variable name: "+k, http.StatusBadRequest)
+			return
+		}
+		if strings.HasPrefix(k, "CLAWIDE_") {
+			http.Error(w, "CLAWIDE_* variables are set by ClawIDE", http.StatusBadRequest)
+			return
+		}
+	}
+
+	workDir := strings.TrimSpace(body.WorkDir)
+	if workDir != "" {
+		if info, err := os.Stat(resolvePaneDir(sess.WorkDir, workDir)); err != nil || !info.IsDir() {
+			http.Error(w, "work_dir is not a directory", http.StatusBadRequest)
+			return
+		}
+	}
+
+	target.Command = strings.TrimSpace(body.Command)
+	target.WorkDir = workDir
+	target.Env = body.Env
+	if len(target.Env) == 0 {
+		target.Env = nil
+	}
+	sess.UpdatedAt = time.Now()
+
+	if err := h.store.UpdateSession(sess); err != nil {
+		log.Printf("Error saving pane startup settings: %v", err)
+		http.Error(w, "failed to save layout", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(target)
+}
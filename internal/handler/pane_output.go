@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultOutputLines = 100
+	maxOutputLines     = 10000
+)
+
+// PaneOutput returns the plain text of a pane's screen plus up to lines
+// lines of its scrollback (default 100), as tmux has it.
+// GET /projects/{id}/sessions/{sid}/panes/{pid}/output?lines=
+func (h *Handlers) PaneOutput(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	sess, ok := h.store.GetSession(chi.URLParam(r, "sid"))
+	if !ok || sess.ProjectID != project.ID {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	paneID := chi.URLParam(r, "pid")
+	if sess.Layout == nil || !sess.Layout.HasPane(paneID) {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	lines := defaultOutputLines
+	if v := r.URL.Query().Get("lines"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "lines must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		lines = min(n, maxOutputLines)
+	}
+
+	tmuxName := tmux.TmuxName(paneID)
+	if !tmux.HasSession(tmuxName) {
+		http.Error(w, "terminal is not running", http.StatusConflict)
+		return
+	}
+	out, err := tmux.CapturePane(tmuxName, lines)
+	if err != nil {
+		log.Printf("pane output %s: %v", paneID, err)
+		http.Error(w, "failed to read pane", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"session_id": sess.ID,
+		"pane_id":    paneID,
+		"output":     out,
+	})
+}
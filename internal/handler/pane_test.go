@@ -5,9 +5,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/model"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -196,3 +199,50 @@ func TestReplaceNodeInTree(t *testing.T) {
 		assert.Equal(t, "leaf", root.PaneID)
 	})
 }
+
+func TestSetPaneStartup(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	projectDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(projectDir, "web"), 0755))
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: projectDir}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s1", ProjectID: "proj-1", WorkDir: projectDir, Layout: model.NewLeafPane("p1")}))
+
+	router := chi.NewRouter()
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Put("/sessions/{sid}/panes/{pid}/startup", h.SetPaneStartup)
+	})
+	put := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("PUT", "/projects/proj-1/sessions/s1/panes/p1/startup", strings.NewReader(body)))
+		return w
+	}
+
+	w := put(`{"command":" npm run dev ","work_dir":"web","env":{"PORT":"5173"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sess, _ := st.GetSession("s1")
+	assert.Equal(t, "npm run dev", sess.Layout.Command)
+	assert.Equal(t, filepath.Join(projectDir, "web"), paneWorkDir(sess, "p1"))
+
+	env := h.paneEnv(sess, "p1", "tok")
+	assert.Equal(t, "5173", env["PORT"])
+	assert.Equal(t, "p1", env["CLAWIDE_PANE_ID"])
+	assert.Equal(t, "tok", env["CLAWIDE_API_TOKEN"])
+	assert.Equal(t, "npm run dev", h.startupCommand(sess, sess.Layout))
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, put(`{"env":{"BAD-NAME":"x"}}`).Code)
+		assert.Equal(t, http.StatusBadRequest, put(`{"env":{"CLAWIDE_PANE_ID":"spoof"}}`).Code)
+		assert.Equal(t, http.StatusBadRequest, put(`{"work_dir":"missing"}`).Code)
+		sess, _ := st.GetSession("s1")
+		assert.Equal(t, "npm run dev", sess.Layout.Command, "rejected requests change nothing")
+	})
+
+	t.Run("clearing falls back to the session defaults", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, put(`{}`).Code)
+		sess, _ := st.GetSession("s1")
+		assert.Equal(t, projectDir, paneWorkDir(sess, "p1"))
+		assert.Nil(t, sess.Layout.Env)
+		assert.Empty(t, h.startupCommand(sess, sess.Layout), "shell panes have no default command")
+	})
+}
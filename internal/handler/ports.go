@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/portdetect"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/go-chi/chi/v5"
+)
+
+// portsResponse is the body of the ports endpoints.
+type portsResponse struct {
+	Host  string            `json:"host"` // host the URLs are built on
+	Ports []portdetect.Port `json:"ports"`
+}
+
+// panePorts lists the ports opened by processes in the panes of sessions.
+// When the process table or tmux can't be read it logs and returns none, so
+// compose ports are still reported.
+func (h *Handlers) panePorts(sessions []model.Session) []portdetect.Port {
+	if len(sessions) == 0 {
+		return nil
+	}
+	snap, err := h.usageSampler.Sample()
+	if err != nil {
+		log.Printf("ports: %v", err)
+		return nil
+	}
+	listeners, err := portdetect.Listening()
+	if err != nil {
+		log.Printf("ports: %v", err)
+		return nil
+	}
+	pids, err := tmux.PanePIDs()
+	if err != nil {
+		log.Printf("ports: %v", err)
+		return nil
+	}
+	byID := map[string]model.Session{}
+	for _, sess := range sessions {
+		byID[sess.ID] = sess
+	}
+	ports := portdetect.Panes(snap, listeners, sessions, pids)
+	for i, p := range ports {
+		ports[i].PaneURL = paneURL(byID[p.SessionID], p.PaneID)
+	}
+	return ports
+}
+
+func (h *Handlers) writePorts(w http.ResponseWriter, r *http.Request, ports []portdetect.Port) {
+	host := portdetect.URLHost(h.cfg.Host, r.Host)
+	portdetect.SetURLs(ports, host)
+	writeJSON(w, http.StatusOK, portsResponse{Host: host, Ports: ports})
+}
+
+// ProjectPorts lists the ports a project listens on: those opened in any of
+// its panes, its features' included, and those its compose file publishes.
+// GET /projects/{id}/api/ports
+func (h *Handlers) ProjectPorts(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	var sessions []model.Session
+	for _, sess := range h.store.GetAllSessions() {
+		if sess.ProjectID == project.ID {
+			sessions = append(sessions, sess)
+		}
+	}
+	ports := portdetect.Merge(h.panePorts(sessions), portdetect.Compose(project.Path, project.ID, ""))
+	h.writePorts(w, r, ports)
+}
+
+// FeaturePorts lists the ports opened in a feature's panes and published by
+// the compose file in its worktree.
+// GET /projects/{id}/features/{fid}/api/ports
+func (h *Handlers) FeaturePorts(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	feature, ok := h.store.GetFeature(chi.URLParam(r, "fid"))
+	if !ok || feature.ProjectID != project.ID {
+		http.Error(w, "feature not found", http.StatusNotFound)
+		return
+	}
+	ports := portdetect.Merge(
+		h.panePorts(h.store.GetFeatureSessions(feature.ID)),
+		portdetect.Compose(feature.WorktreePath, project.ID, feature.ID),
+	)
+	h.writePorts(w, r, ports)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorts(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	h.cfg.Host = "0.0.0.0"
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yml"),
+		[]byte("services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n"), 0644))
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: dir}))
+	require.NoError(t, st.AddFeature(model.Feature{ID: "f1", ProjectID: "proj-1", WorktreePath: t.TempDir()}))
+
+	router := chi.NewRouter()
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Get("/api/ports", h.ProjectPorts)
+		r.Get("/features/{fid}/api/ports", h.FeaturePorts)
+	})
+	get := func(target string) (*httptest.ResponseRecorder, portsResponse) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Host = "192.168.1.20:9800"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp portsResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp
+	}
+
+	w, resp := get("/projects/proj-1/api/ports")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "192.168.1.20", resp.Host)
+	require.Len(t, resp.Ports, 1)
+	assert.Equal(t, "web", resp.Ports[0].Service)
+	assert.Equal(t, "http://192.168.1.20:8080", resp.Ports[0].URL)
+
+	w, resp = get("/projects/proj-1/features/f1/api/ports")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resp.Ports)
+
+	w, _ = get("/projects/proj-1/features/nope/api/ports")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
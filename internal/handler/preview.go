@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/auth"
+	"github.com/davydany/ClawIDE/internal/devproxy"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// previewPrefix is the path a feature's dev server is served under.
+func previewPrefix(featureID string) string {
+	return "/preview/" + featureID
+}
+
+// Preview proxies a request to the dev server of the feature in the path.
+// Viewers can browse it; anything but GET/HEAD needs a collaborator.
+// /preview/{fid}/*
+func (h *Handlers) Preview(w http.ResponseWriter, r *http.Request) {
+	feature, ok := h.store.GetFeature(chi.URLParam(r, "fid"))
+	if !ok || feature.Preview == nil {
+		http.Error(w, "no preview for this feature", http.StatusNotFound)
+		return
+	}
+	min := model.RoleViewer
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		min = model.RoleCollaborator
+	}
+	if !h.requireProjectRole(w, r, feature.ProjectID, min) {
+		return
+	}
+	devproxy.New(previewPrefix(feature.ID), feature.Preview.Port, devproxy.Options{
+		KeepPrefix:  feature.Preview.KeepPrefix,
+		BaseHref:    feature.Preview.BaseHref,
+		DropCookies: []string{auth.SessionCookieName},
+	}).ServeHTTP(w, r)
+}
+
+// SetFeaturePreview sets the port of a feature's dev server and adds a link
+// to it to the project's bookmark bar. Port 0 removes the preview and its
+// bookmark.
+// PUT /projects/{id}/features/{fid}/preview
+func (h *Handlers) SetFeaturePreview(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	feature, ok := h.store.GetFeature(chi.URLParam(r, "fid"))
+	if !ok || feature.ProjectID != project.ID {
+		http.Error(w, "feature not found", http.StatusNotFound)
+		return
+	}
+	var body struct {
+		Port       int  `json:"port"`
+		KeepPrefix bool `json:"keep_prefix"`
+		BaseHref   bool `json:"base_href"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if body.Port < 0 || body.Port > 65535 {
+		http.Error(w, "port must be between 1 and 65535", http.StatusBadRequest)
+		return
+	}
+
+	if body.Port == 0 {
+		h.removePreviewBookmark(feature)
+		feature.Preview = nil
+	} else {
+		preview := &model.Preview{Port: body.Port, KeepPrefix: body.KeepPrefix, BaseHref: body.BaseHref}
+		if feature.Preview != nil {
+			preview.BookmarkID = feature.Preview.BookmarkID
+		}
+		feature.Preview = preview
+		h.ensurePreviewBookmark(&feature)
+	}
+	feature.UpdatedAt = time.Now()
+	if err := h.store.UpdateFeature(feature); err != nil {
+		log.Printf("Error saving feature preview: %v", err)
+		http.Error(w, "failed to update feature", http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]any{"preview": feature.Preview}
+	if feature.Preview != nil {
+		resp["url"] = previewPrefix(feature.ID) + "/"
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ensurePreviewBookmark makes sure the project's bookmark bar links to the
+// feature's preview, recreating the bookmark if it was deleted, and records
+// its ID in feature.Preview. Failures are logged: the preview works without.
+func (h *Handlers) ensurePreviewBookmark(feature *model.Feature) {
+	ps, err := h.getProjectBookmarkStore(feature.ProjectID)
+	if err != nil {
+		log.Printf("preview bookmark: %v", err)
+		return
+	}
+	now := time.Now()
+	name := feature.Name + " preview"
+	link := previewPrefix(feature.ID) + "/"
+	if b, ok := ps.Get(feature.Preview.BookmarkID); ok {
+		b.Name, b.URL, b.InBar, b.FolderID, b.UpdatedAt = name, link, true, "", now
+		if err := ps.Update(b); err != nil {
+			log.Printf("preview bookmark: %v", err)
+		}
+		return
+	}
+	b := model.Bookmark{
+		ID:        uuid.New().String(),
+		ProjectID: feature.ProjectID,
+		Name:      name,
+		URL:       link,
+		InBar:     true,
+		Order:     len(ps.GetRootBookmarks()),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := ps.Add(b); err != nil {
+		log.Printf("preview bookmark: %v", err)
+		return
+	}
+	feature.Preview.BookmarkID = b.ID
+}
+
+// removePreviewBookmark deletes the bookmark bar link to a feature's
+// preview, if any.
+func (h *Handlers) removePreviewBookmark(feature model.Feature) {
+	if feature.Preview == nil || feature.Preview.BookmarkID == "" {
+		return
+	}
+	ps, err := h.getProjectBookmarkStore(feature.ProjectID)
+	if err != nil {
+		log.Printf("preview bookmark: %v", err)
+		return
+	}
+	if _, ok := ps.Get(feature.Preview.BookmarkID); !ok {
+		return
+	}
+	if err := ps.Delete(feature.Preview.BookmarkID); err != nil {
+		log.Printf("preview bookmark: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeaturePreview(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddFeature(model.Feature{ID: "f1", ProjectID: "proj-1", Name: "login"}))
+
+	dev := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "dev server at "+r.URL.Path)
+	}))
+	defer dev.Close()
+	u, _ := url.Parse(dev.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	router := chi.NewRouter()
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Put("/features/{fid}/preview", h.SetFeaturePreview)
+	})
+	router.HandleFunc("/preview/{fid}/*", h.Preview)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/preview/f1/", "").Code)
+
+	w := do(http.MethodPut, "/projects/proj-1/features/f1/preview", `{"port":`+strconv.Itoa(port)+`}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"url":"/preview/f1/"`)
+
+	feature, _ := st.GetFeature("f1")
+	require.NotNil(t, feature.Preview)
+	ps, err := h.getProjectBookmarkStore("proj-1")
+	require.NoError(t, err)
+	bm, ok := ps.Get(feature.Preview.BookmarkID)
+	require.True(t, ok)
+	assert.Equal(t, "/preview/f1/", bm.URL)
+	assert.Equal(t, "login preview", bm.Name)
+	assert.True(t, bm.InBar)
+
+	w = do(http.MethodGet, "/preview/f1/assets/app.js", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "dev server at /assets/app.js", w.Body.String())
+
+	// Changing the port keeps the one bookmark.
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/projects/proj-1/features/f1/preview", `{"port":`+strconv.Itoa(port)+`,"base_href":true}`).Code)
+	assert.Len(t, ps.GetAll(), 1)
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/projects/proj-1/features/f1/preview", `{"port":70000}`).Code)
+
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/projects/proj-1/features/f1/preview", `{"port":0}`).Code)
+	feature, _ = st.GetFeature("f1")
+	assert.Nil(t, feature.Preview)
+	assert.Empty(t, ps.GetAll())
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/preview/f1/", "").Code)
+}
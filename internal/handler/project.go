@@ -42,7 +42,15 @@ func splitAndSortProjects(projects []model.Project) (starred, nonStarred []model
 }
 
 func (h *Handlers) ListProjects(w http.ResponseWriter, r *http.Request) {
-	starredProjects, unstarredProjects := splitAndSortProjects(h.store.GetProjects())
+	if wantsJSON(r) {
+		projects := visibleProjects(r, h.store.GetProjects())
+		if projects == nil {
+			projects = []model.Project{}
+		}
+		writeJSON(w, http.StatusOK, projects)
+		return
+	}
+	starredProjects, unstarredProjects := splitAndSortProjects(visibleProjects(r, h.store.GetProjects()))
 	data := map[string]any{
 		"Title":           "ClawIDE - Projects",
 		"Theme":           h.cfg.Theme,
@@ -98,6 +106,11 @@ func (h *Handlers) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusCreated, project)
+		return
+	}
+
 	// If htmx, return updated project list
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/")
@@ -218,7 +231,7 @@ func (h *Handlers) ProjectWorkspace(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Collect starred and non-starred projects for quick-switch panel
-	starredProjects, nonStarredProjects := splitAndSortProjects(h.store.GetProjects())
+	starredProjects, nonStarredProjects := splitAndSortProjects(visibleProjects(r, h.store.GetProjects()))
 
 	// Build bar bookmark views for tab bar (project-scoped or global fallback)
 	var barBookmarks []model.Bookmark
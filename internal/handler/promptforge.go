@@ -142,7 +142,7 @@ func (h *Handlers) ListPromptForgePrompts(w http.ResponseWriter, r *http.Request
 		prompts = []model.Prompt{}
 	}
 	// Omit body content in listings to keep payloads small.
-	summaries := make([]promptSummary, 0, len(prompts))
+	summaries := make([]PromptSummary, 0, len(prompts))
 	for _, p := range prompts {
 		summaries = append(summaries, summarize(p))
 	}
@@ -398,8 +398,9 @@ func validatePromptBody(title string, t model.PromptType, vars []model.Variable)
 	return model.ValidateVariables(vars)
 }
 
-// promptSummary strips the markdown body so that list responses stay small.
-type promptSummary struct {
+// PromptSummary is a prompt without its body, which keeps list responses
+// small.
+type PromptSummary struct {
 	ID        string           `json:"id"`
 	FolderID  string           `json:"folder_id,omitempty"`
 	Title     string           `json:"title"`
@@ -409,8 +410,8 @@ type promptSummary struct {
 	UpdatedAt time.Time        `json:"updated_at"`
 }
 
-func summarize(p model.Prompt) promptSummary {
-	return promptSummary{
+func summarize(p model.Prompt) PromptSummary {
+	return PromptSummary{
 		ID:        p.ID,
 		FolderID:  p.FolderID,
 		Title:     p.Title,
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/webpush"
+	"github.com/google/uuid"
+)
+
+// pushSubscriptionRequest is a browser's PushSubscription.toJSON() plus the
+// filters chosen in Settings.
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+	Levels     []string `json:"levels"`
+	ProjectIDs []string `json:"project_ids"`
+}
+
+// GetPushKey returns the VAPID public key browsers subscribe with.
+// GET /api/push/key
+func (h *Handlers) GetPushKey(w http.ResponseWriter, r *http.Request) {
+	if h.pusher == nil {
+		http.Error(w, "web push is not available", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"public_key": h.pusher.PublicKey()})
+}
+
+// ListPushSubscriptions returns the caller's subscribed browsers.
+// GET /api/push/subscriptions
+func (h *Handlers) ListPushSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetPrincipal(r).UserID
+	subs := []model.PushSubscription{}
+	for _, sub := range h.pushSubscriptions.GetAll() {
+		if sub.UserID == userID {
+			subs = append(subs, sub)
+		}
+	}
+	writeJSON(w, http.StatusOK, subs)
+}
+
+// PutPushSubscription subscribes a browser for the caller, or updates its
+// keys and filters if its endpoint is already known.
+// PUT /api/push/subscription
+func (h *Handlers) PutPushSubscription(w http.ResponseWriter, r *http.Request) {
+	var body pushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if u, err := url.Parse(body.Endpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+		http.Error(w, "endpoint must be an https URL", http.StatusBadRequest)
+		return
+	}
+	if key, err := webpush.DecodeKey(body.Keys.P256dh); err != nil || len(key) != 65 {
+		http.Error(w, "keys.p256dh must be a base64url P-256 public key", http.StatusBadRequest)
+		return
+	}
+	if key, err := webpush.DecodeKey(body.Keys.Auth); err != nil || len(key) != 16 {
+		http.Error(w, "keys.auth must be a base64url 16-byte secret", http.StatusBadRequest)
+		return
+	}
+	projectIDs := compactStrings(body.ProjectIDs)
+	for _, id := range projectIDs {
+		if h.projectRole(r, id) == model.RoleNone {
+			http.Error(w, "project "+id+" not found", http.StatusBadRequest)
+			return
+		}
+	}
+
+	now := time.Now()
+	sub, ok := h.pushSubscriptions.GetByEndpoint(body.Endpoint)
+	if !ok {
+		sub = model.PushSubscription{ID: uuid.New().String(), Endpoint: body.Endpoint, CreatedAt: now}
+	}
+	// A browser belongs to whoever subscribed it last.
+	sub.UserID = middleware.GetPrincipal(r).UserID
+	sub.P256dh = body.Keys.P256dh
+	sub.Auth = body.Keys.Auth
+	sub.UserAgent = r.UserAgent()
+	sub.Levels = compactStrings(body.Levels)
+	sub.ProjectIDs = projectIDs
+	sub.UpdatedAt = now
+
+	if err := h.pushSubscriptions.Put(sub); err != nil {
+		log.Printf("push subscription save error: %v", err)
+		http.Error(w, "failed to save push subscription", http.StatusInternalServerError)
+		return
+	}
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, sub)
+}
+
+// DeletePushSubscription unsubscribes the browser with the given endpoint.
+// DELETE /api/push/subscription
+func (h *Handlers) DeletePushSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.ownPushSubscription(w, r)
+	if !ok {
+		return
+	}
+	if err := h.pushSubscriptions.Delete(sub.ID); err != nil {
+		http.Error(w, "push subscription not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestPushSubscription sends a test push to the browser with the given
+// endpoint and reports whether its push service accepted it.
+// POST /api/push/test
+func (h *Handlers) TestPushSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.ownPushSubscription(w, r)
+	if !ok {
+		return
+	}
+	err := h.pusher.Push(sub, webpush.Payload{
+		Title: "ClawIDE",
+		Body:  "Push notifications are working.",
+		Level: "info",
+		Tag:   "clawide-test",
+		URL:   "/",
+	})
+	if err != nil {
+		http.Error(w, "push failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownPushSubscription reads {"endpoint"} from the body and returns that
+// subscription if it belongs to the caller, writing a 404 otherwise.
+func (h *Handlers) ownPushSubscription(w http.ResponseWriter, r *http.Request) (model.PushSubscription, bool) {
+	var body struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Endpoint) == "" {
+		http.Error(w, "endpoint is required", http.StatusBadRequest)
+		return model.PushSubscription{}, false
+	}
+	sub, ok := h.pushSubscriptions.GetByEndpoint(body.Endpoint)
+	if !ok || sub.UserID != middleware.GetPrincipal(r).UserID {
+		http.Error(w, "push subscription not found", http.StatusNotFound)
+		return model.PushSubscription{}, false
+	}
+	return sub, true
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/webpush"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// browserKeys returns p256dh and auth values as a browser would generate.
+func browserKeys(t *testing.T) (string, string) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return base64.RawURLEncoding.EncodeToString(priv.PublicKey().Bytes()), base64.RawURLEncoding.EncodeToString(auth)
+}
+
+func TestPushSubscriptions(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "p1", Name: "One"}))
+
+	var mu sync.Mutex
+	var pushed []string
+	service := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		pushed = append(pushed, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer service.Close()
+	vapid, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	h.pusher = webpush.NewPusher(webpush.NewSenderWithClient(vapid, service.Client()), h.pushSubscriptions, st)
+	h.sseHub.OnBroadcast(h.pusher.Notify)
+
+	router := chi.NewRouter()
+	router.Route("/api/push", func(r chi.Router) {
+		r.Get("/key", h.GetPushKey)
+		r.Get("/subscriptions", h.ListPushSubscriptions)
+		r.Put("/subscription", h.PutPushSubscription)
+		r.Delete("/subscription", h.DeletePushSubscription)
+		r.Post("/test", h.TestPushSubscription)
+	})
+	router.Post("/api/notifications", h.CreateNotification)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("GET", "/api/push/key", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), vapid.PublicKey)
+
+	p256dh, auth := browserKeys(t)
+	endpoint := service.URL + "/push/1"
+	keys := `"keys":{"p256dh":"` + p256dh + `","auth":"` + auth + `"}`
+	for _, body := range []string{
+		`{"endpoint":"http://push.example/1",` + keys + `}`,
+		`{"endpoint":"` + endpoint + `","keys":{"p256dh":"abc","auth":"` + auth + `"}}`,
+		`{"endpoint":"` + endpoint + `","keys":{"p256dh":"` + p256dh + `","auth":"abc"}}`,
+		`{"endpoint":"` + endpoint + `",` + keys + `,"project_ids":["missing"]}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, do("PUT", "/api/push/subscription", body).Code, body)
+	}
+
+	w = do("PUT", "/api/push/subscription", `{"endpoint":"`+endpoint+`",`+keys+`,"levels":["error"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var sub model.PushSubscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sub))
+	assert.Equal(t, []string{"error"}, sub.Levels)
+
+	// Subscribing again updates the same entry.
+	w = do("PUT", "/api/push/subscription", `{"endpoint":"`+endpoint+`",`+keys+`,"levels":["error","warning"],"project_ids":["p1"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = do("GET", "/api/push/subscriptions", "")
+	var subs []model.PushSubscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &subs))
+	require.Len(t, subs, 1)
+	assert.Equal(t, sub.ID, subs[0].ID)
+	assert.Equal(t, []string{"p1"}, subs[0].ProjectIDs)
+
+	assert.Equal(t, http.StatusNoContent, do("POST", "/api/push/test", `{"endpoint":"`+endpoint+`"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/push/test", `{"endpoint":"`+service.URL+`/other"}`).Code)
+
+	// Stored notifications are pushed when they pass the filters.
+	require.Equal(t, http.StatusCreated, do("POST", "/api/notifications", `{"title":"ok","level":"info","project_id":"p1"}`).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/api/notifications", `{"title":"boom","level":"error","project_id":"p1","idempotency_key":"k"}`).Code)
+	do("POST", "/api/notifications", `{"title":"boom","level":"error","project_id":"p1","idempotency_key":"k"}`)
+	h.pusher.Wait()
+	mu.Lock()
+	assert.Len(t, pushed, 2, "the test push and one error; not the info or the duplicate")
+	mu.Unlock()
+
+	// Notifications held during quiet hours are pushed once released.
+	now := time.Now()
+	require.NoError(t, h.notifier.SetRules(model.NotificationRules{QuietHours: model.QuietHours{
+		Enabled: true, Start: now.Add(-time.Hour).Format("15:04"), End: now.Add(time.Hour).Format("15:04")}}))
+	require.Equal(t, http.StatusCreated, do("POST", "/api/notifications", `{"title":"slow","level":"warning","project_id":"p1"}`).Code)
+	h.pusher.Wait()
+	mu.Lock()
+	assert.Len(t, pushed, 2, "held")
+	mu.Unlock()
+	require.NoError(t, h.notifier.SetRules(model.NotificationRules{}))
+	h.notifier.ReleaseHeld()
+	h.pusher.Wait()
+	mu.Lock()
+	assert.Len(t, pushed, 3, "released")
+	mu.Unlock()
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/push/subscription", `{"endpoint":"`+endpoint+`"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/api/push/subscription", `{"endpoint":"`+endpoint+`"}`).Code)
+}
@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// tmuxOrphan is a clawide- tmux session that no pane in state.json refers
+// to, typically left behind by a deleted session or a crash.
+type tmuxOrphan struct {
+	tmux.SessionInfo
+	PaneID string `json:"pane_id"`
+}
+
+// deadPane is a pane in state.json whose tmux session is not running. It
+// shows up blank until something starts its shell again.
+type deadPane struct {
+	ProjectID   string `json:"project_id"`
+	SessionID   string `json:"session_id"`
+	FeatureID   string `json:"feature_id,omitempty"`
+	SessionName string `json:"session_name"`
+	PaneID      string `json:"pane_id"`
+	PaneName    string `json:"pane_name,omitempty"`
+	PaneType    string `json:"pane_type"`
+	Command     string `json:"command,omitempty"` // startup command a recreate runs
+}
+
+type tmuxReconcileReport struct {
+	Orphans   []tmuxOrphan `json:"orphans"`
+	DeadPanes []deadPane   `json:"dead_panes"`
+	CheckedAt time.Time    `json:"checked_at"`
+}
+
+// reconcileTmux compares the panes of sessions with the running tmux
+// sessions.
+func reconcileTmux(sessions []model.Session, running []tmux.SessionInfo) tmuxReconcileReport {
+	report := tmuxReconcileReport{
+		Orphans:   []tmuxOrphan{},
+		DeadPanes: []deadPane{},
+		CheckedAt: time.Now(),
+	}
+
+	alive := make(map[string]bool, len(running))
+	for _, info := range running {
+		alive[info.Name] = true
+	}
+
+	known := make(map[string]bool)
+	for _, sess := range sessions {
+		if sess.Layout == nil {
+			continue
+		}
+		for _, paneID := range sess.Layout.CollectLeaves() {
+			name := tmux.TmuxName(paneID)
+			known[name] = true
+			if alive[name] {
+				continue
+			}
+			pane, _ := sess.Layout.FindPane(paneID)
+			report.DeadPanes = append(report.DeadPanes, deadPane{
+				ProjectID:   sess.ProjectID,
+				SessionID:   sess.ID,
+				FeatureID:   sess.FeatureID,
+				SessionName: sess.Name,
+				PaneID:      paneID,
+				PaneName:    pane.Name,
+				PaneType:    pane.EffectivePaneType(),
+			})
+		}
+	}
+
+	for _, info := range running {
+		if known[info.Name] {
+			continue
+		}
+		paneID, ok := tmux.PaneID(info.Name)
+		if !ok {
+			continue
+		}
+		report.Orphans = append(report.Orphans, tmuxOrphan{SessionInfo: info, PaneID: paneID})
+	}
+	return report
+}
+
+// scanTmux builds a reconcile report from the live tmux server.
+func (h *Handlers) scanTmux() (tmuxReconcileReport, error) {
+	running, err := tmux.ListClawIDESessionInfo()
+	if err != nil {
+		return tmuxReconcileReport{}, err
+	}
+	report := reconcileTmux(h.store.GetAllSessions(), running)
+	for i, dp := range report.DeadPanes {
+		sess, ok := h.store.GetSession(dp.SessionID)
+		if !ok {
+			continue
+		}
+		pane, _ := sess.Layout.FindPane(dp.PaneID)
+		report.DeadPanes[i].Command = h.startupCommand(sess, pane)
+	}
+	return report, nil
+}
+
+// ReportTmuxSessions runs a reconcile pass at startup. Orphans are left
+// running so they can still be adopted; a notification points at them.
+func (h *Handlers) ReportTmuxSessions() {
+	report, err := h.scanTmux()
+	if err != nil {
+		log.Printf("Warning: could not list %s sessions: %v", tmux.Binary(), err)
+		return
+	}
+	for _, o := range report.Orphans {
+		log.Printf("Orphan %s session %s (%s in %s) is not part of any ClawIDE session", tmux.Binary(), o.Name, o.Command, o.Path)
+	}
+	if len(report.Orphans) == 0 {
+		return
+	}
+
+	notif := model.Notification{
+		ID:        uuid.New().String(),
+		Title:     "Orphaned terminal sessions found",
+		Body:      fmt.Sprintf("%d %s session(s) are still running but belong to no ClawIDE session. Adopt or kill them under Settings > Terminal Sessions.", len(report.Orphans), tmux.Binary()),
+		Source:    "clawide",
+		Level:     "warning",
+		CreatedAt: time.Now(),
+	}
+	if _, _, err := h.notifier.Post(notif); err != nil {
+		log.Printf("Error adding orphan session notification: %v", err)
+	}
+}
+
+// ReconcileTmux lists orphaned tmux sessions and dead panes.
+// GET /api/tmux/reconcile
+func (h *Handlers) ReconcileTmux(w http.ResponseWriter, r *http.Request) {
+	report, err := h.scanTmux()
+	if err != nil {
+		log.Printf("tmux reconcile error: %v", err)
+		http.Error(w, "failed to list terminal sessions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// findOrphan returns the named orphan if it is still running and still
+// unreferenced.
+func (h *Handlers) findOrphan(name string) (tmuxOrphan, bool, error) {
+	report, err := h.scanTmux()
+	if err != nil {
+		return tmuxOrphan{}, false, err
+	}
+	for _, o := range report.Orphans {
+		if o.Name == name {
+			return o, true, nil
+		}
+	}
+	return tmuxOrphan{}, false, nil
+}
+
+// AdoptTmuxOrphan wraps an orphaned tmux session in a new single-pane
+// session of a project. The pane keeps the orphan's ID, so attaching to it
+// reconnects to the running shell instead of starting a new one.
+// POST /api/tmux/orphans/{name}/adopt
+func (h *Handlers) AdoptTmuxOrphan(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ProjectID string `json:"project_id"`
+		Name      string `json:"name"`
+		PaneType  string `json:"pane_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	project, ok := h.store.GetProject(body.ProjectID)
+	if !ok {
+		http.Error(w, "project not found", http.StatusBadRequest)
+		return
+	}
+	switch body.PaneType {
+	case "":
+		body.PaneType = model.PaneTypeShell
+	case model.PaneTypeShell, model.PaneTypeAgent:
+	default:
+		http.Error(w, "pane_type must be agent or shell", http.StatusBadRequest)
+		return
+	}
+
+	orphan, ok, err := h.findOrphan(chi.URLParam(r, "name"))
+	if err != nil {
+		log.Printf("tmux reconcile error: %v", err)
+		http.Error(w, "failed to list terminal sessions", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "orphaned session not found", http.StatusNotFound)
+		return
+	}
+
+	name := body.Name
+	if name == "" {
+		name = "Adopted " + orphan.Command
+	}
+	pane := model.NewLeafPane(orphan.PaneID)
+	pane.PaneType = body.PaneType
+	now := time.Now()
+	sess := model.Session{
+		ID:        uuid.New().String(),
+		ProjectID: project.ID,
+		Name:      name,
+		WorkDir:   project.Path,
+		Layout:    pane,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.store.AddSession(sess); err != nil {
+		log.Printf("Error adopting %s: %v", orphan.Name, err)
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sess)
+}
+
+// KillTmuxOrphan kills an orphaned tmux session. Sessions that belong to a
+// pane cannot be killed here.
+// DELETE /api/tmux/orphans/{name}
+func (h *Handlers) KillTmuxOrphan(w http.ResponseWriter, r *http.Request) {
+	orphan, ok, err := h.findOrphan(chi.URLParam(r, "name"))
+	if err != nil {
+		log.Printf("tmux reconcile error: %v", err)
+		http.Error(w, "failed to list terminal sessions", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "orphaned session not found", http.StatusNotFound)
+		return
+	}
+	if err := h.ptyManager.DestroySession(orphan.PaneID); err != nil {
+		log.Printf("Error killing orphan %s: %v", orphan.Name, err)
+		http.Error(w, "failed to kill session", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type recreateResult struct {
+	PaneID    string `json:"pane_id"`
+	SessionID string `json:"session_id"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RecreateDeadPanes starts a new tmux session for dead panes and runs their
+// startup commands. pane_ids limits it to some panes; by default every dead
+// pane is recreated.
+// POST /api/tmux/dead-panes/recreate
+func (h *Handlers) RecreateDeadPanes(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		PaneIDs []string `json:"pane_ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	wanted := make(map[string]bool, len(body.PaneIDs))
+	for _, id := range body.PaneIDs {
+		wanted[id] = true
+	}
+
+	report, err := h.scanTmux()
+	if err != nil {
+		log.Printf("tmux reconcile error: %v", err)
+		http.Error(w, "failed to list terminal sessions", http.StatusInternalServerError)
+		return
+	}
+
+	results := []recreateResult{}
+	for _, dp := range report.DeadPanes {
+		if len(wanted) > 0 && !wanted[dp.PaneID] {
+			continue
+		}
+		res := recreateResult{PaneID: dp.PaneID, SessionID: dp.SessionID}
+		sess, ok := h.store.GetSession(dp.SessionID)
+		if !ok {
+			res.Error = "session not found"
+		} else if _, err := h.attachPane(sess, dp.PaneID, middleware.GetPrincipal(r)); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.OK = true
+		}
+		results = append(results, res)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileTmux(t *testing.T) {
+	agent := model.NewAgentPane("p-agent")
+	agent.Name = "Claude"
+	sessions := []model.Session{
+		{
+			ID: "s1", ProjectID: "proj", Name: "Main",
+			Layout: &model.PaneNode{
+				Type: "split", Direction: "horizontal", Ratio: 0.5,
+				First:  agent,
+				Second: model.NewLeafPane("p-shell"),
+			},
+		},
+		{ID: "s2", ProjectID: "proj", FeatureID: "f1", Name: "Feature", Layout: model.NewLeafPane("p-feature")},
+		{ID: "s3", ProjectID: "proj", Name: "Empty"},
+	}
+	running := []tmux.SessionInfo{
+		{Name: "clawide-p-agent", Command: "claude"},
+		{Name: "clawide-p-feature", Command: "zsh"},
+		{Name: "clawide-gone", Command: "node", Path: "/srv/app"},
+	}
+
+	report := reconcileTmux(sessions, running)
+
+	require.Len(t, report.Orphans, 1)
+	assert.Equal(t, "clawide-gone", report.Orphans[0].Name)
+	assert.Equal(t, "gone", report.Orphans[0].PaneID)
+	assert.Equal(t, "/srv/app", report.Orphans[0].Path)
+
+	require.Len(t, report.DeadPanes, 1)
+	assert.Equal(t, deadPane{
+		ProjectID:   "proj",
+		SessionID:   "s1",
+		SessionName: "Main",
+		PaneID:      "p-shell",
+		PaneType:    model.PaneTypeShell,
+	}, report.DeadPanes[0])
+	assert.False(t, report.CheckedAt.IsZero())
+}
+
+func TestReconcileTmuxNothingRunning(t *testing.T) {
+	report := reconcileTmux(nil, nil)
+	assert.NotNil(t, report.Orphans, "encodes as [] rather than null")
+	assert.NotNil(t, report.DeadPanes)
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/asciicast"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/pty"
+	"github.com/go-chi/chi/v5"
+)
+
+const recordingTimeFormat = "20060102T150405Z"
+
+// recordingNameRe matches the files startPaneRecording creates; anything else
+// in the directory is ignored and cannot be addressed through the API. A
+// pane attached again within the same second gets a numbered name
+// (p1-20260101T020304Z-2.cast).
+var recordingNameRe = regexp.MustCompile(`^([A-Za-z0-9-]+)-(\d{8}T\d{6}Z)(?:-\d+)?\.cast$`)
+
+type recordingInfo struct {
+	Name      string    `json:"name"`
+	PaneID    string    `json:"pane_id"`
+	Title     string    `json:"title,omitempty"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	StartedAt time.Time `json:"started_at"`
+	Size      int64     `json:"size"`
+	Active    bool      `json:"active"`
+}
+
+// recordingsDir is where a project's pane recordings live. Feature sessions
+// record into their parent project, not the worktree.
+func recordingsDir(project model.Project) string {
+	return filepath.Join(project.Path, clawideDirName, "recordings")
+}
+
+// startPaneRecording opens a new recording file for the pane. The directory
+// gets a catch-all .gitignore because recordings can contain secrets typed
+// or printed in the terminal.
+func (h *Handlers) startPaneRecording(sess model.Session, paneID string, ptySess *pty.Session) error {
+	project, ok := h.store.GetProject(sess.ProjectID)
+	if !ok {
+		return fmt.Errorf("project %s not found", sess.ProjectID)
+	}
+	dir := recordingsDir(project)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+
+	title := sess.Name
+	if node, _ := sess.Layout.FindPane(paneID); node != nil && node.Name != "" {
+		title += " / " + node.Name
+	}
+	stamp := time.Now().UTC().Format(recordingTimeFormat)
+	name := fmt.Sprintf("%s-%s.cast", paneID, stamp)
+	for n := 2; ; n++ {
+		err := ptySess.StartRecording(filepath.Join(dir, name), title)
+		if !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		name = fmt.Sprintf("%s-%s-%d.cast", paneID, stamp, n)
+	}
+}
+
+// resumePaneRecording starts recording on a freshly attached PTY when the
+// pane has recording turned on.
+func (h *Handlers) resumePaneRecording(sess model.Session, paneID string, ptySess *pty.Session) {
+	node, _ := sess.Layout.FindPane(paneID)
+	if node == nil || !node.Record {
+		return
+	}
+	if err := h.startPaneRecording(sess, paneID, ptySess); err != nil {
+		log.Printf("Failed to start recording for pane %s: %v", paneID, err)
+	}
+}
+
+// SetPaneRecording turns recording on or off for a pane. The choice is saved
+// in the layout so it survives reconnects and restarts; each attach starts a
+// new file.
+// POST /projects/{id}/sessions/{sid}/panes/{pid}/recording {"enabled": true}
+func (h *Handlers) SetPaneRecording(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	sessionID := chi.URLParam(r, "sid")
+	paneID := chi.URLParam(r, "pid")
+
+	sess, ok := h.store.GetSession(sessionID)
+	if !ok || sess.ProjectID != project.ID {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	target, _ := sess.Layout.FindPane(paneID)
+	if target == nil {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	target.Record = req.Enabled
+	sess.UpdatedAt = time.Now()
+	if err := h.store.UpdateSession(sess); err != nil {
+		log.Printf("Error saving pane recording flag: %v", err)
+		http.Error(w, "failed to save layout", http.StatusInternalServerError)
+		return
+	}
+
+	if ptySess, ok := h.ptyManager.GetSession(paneID); ok {
+		if req.Enabled && ptySess.RecordingPath() == "" {
+			if err := h.startPaneRecording(sess, paneID, ptySess); err != nil {
+				log.Printf("Failed to start recording for pane %s: %v", paneID, err)
+				http.Error(w, "failed to start recording", http.StatusInternalServerError)
+				return
+			}
+		} else if !req.Enabled {
+			if err := ptySess.StopRecording(); err != nil {
+				log.Printf("Error stopping recording for pane %s: %v", paneID, err)
+			}
+		}
+	}
+
+	file := ""
+	if ptySess, ok := h.ptyManager.GetSession(paneID); ok {
+		file = filepath.Base(ptySess.RecordingPath())
+		if file == "." {
+			file = ""
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"recording": req.Enabled, "file": file})
+}
+
+// ListRecordings returns the project's recordings, newest first.
+// GET /projects/{id}/recordings
+func (h *Handlers) ListRecordings(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	dir := recordingsDir(project)
+
+	out := []recordingInfo{}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, e := range entries {
+		m := recordingNameRe.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		info := recordingInfo{Name: e.Name(), PaneID: m[1]}
+		if started, err := time.Parse(recordingTimeFormat, m[2]); err == nil {
+			info.StartedAt = started
+		}
+		if fi, err := e.Info(); err == nil {
+			info.Size = fi.Size()
+		}
+		if f, err := os.Open(filepath.Join(dir, e.Name())); err == nil {
+			if hdr, err := asciicast.ReadHeader(f); err == nil {
+				info.Title = hdr.Title
+				info.Width = hdr.Width
+				info.Height = hdr.Height
+			}
+			f.Close()
+		}
+		info.Active = h.recordingActive(dir, info)
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartedAt.Equal(out[j].StartedAt) {
+			return out[i].StartedAt.After(out[j].StartedAt)
+		}
+		return out[i].Name > out[j].Name
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func (h *Handlers) recordingActive(dir string, info recordingInfo) bool {
+	ptySess, ok := h.ptyManager.GetSession(info.PaneID)
+	return ok && ptySess.RecordingPath() == filepath.Join(dir, info.Name)
+}
+
+// recordingPath resolves the {name} URL parameter to a file in the project's
+// recordings directory, writing 404 for names that aren't recordings.
+func recordingPath(w http.ResponseWriter, r *http.Request, project model.Project) (string, recordingInfo, bool) {
+	name := chi.URLParam(r, "name")
+	m := recordingNameRe.FindStringSubmatch(name)
+	if m == nil {
+		http.Error(w, "recording not found", http.StatusNotFound)
+		return "", recordingInfo{}, false
+	}
+	path := filepath.Join(recordingsDir(project), name)
+	if _, err := os.Stat(path); err != nil {
+		http.Error(w, "recording not found", http.StatusNotFound)
+		return "", recordingInfo{}, false
+	}
+	return path, recordingInfo{Name: name, PaneID: m[1]}, true
+}
+
+// DownloadRecording serves the raw .cast file, playable with asciinema.
+// GET /projects/{id}/recordings/{name}
+func (h *Handlers) DownloadRecording(w http.ResponseWriter, r *http.Request) {
+	path, info, ok := recordingPath(w, r, middleware.GetProject(r))
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name))
+	http.ServeFile(w, r, path)
+}
+
+// DeleteRecording removes a finished recording.
+// DELETE /projects/{id}/recordings/{name}
+func (h *Handlers) DeleteRecording(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	path, info, ok := recordingPath(w, r, project)
+	if !ok {
+		return
+	}
+	if h.recordingActive(recordingsDir(project), info) {
+		http.Error(w, "recording is in progress; stop it first", http.StatusConflict)
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReplayRecording returns a recording's events as JSON for the in-browser
+// player. The optional from/to query parameters (seconds) select a window so
+// the player can fetch a long recording in chunks while scrubbing; duration
+// is always that of the whole recording.
+// GET /projects/{id}/recordings/{name}/replay?from=0&to=60
+func (h *Handlers) ReplayRecording(w http.ResponseWriter, r *http.Request) {
+	path, _, ok := recordingPath(w, r, middleware.GetProject(r))
+	if !ok {
+		return
+	}
+
+	from, err := parseSeconds(r.URL.Query().Get("from"), 0)
+	if err != nil {
+		http.Error(w, "invalid from", http.StatusBadRequest)
+		return
+	}
+	to, err := parseSeconds(r.URL.Query().Get("to"), -1)
+	if err != nil {
+		http.Error(w, "invalid to", http.StatusBadRequest)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	header, events, err := asciicast.Read(f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	duration := 0.0
+	if len(events) > 0 {
+		duration = events[len(events)-1].Time
+	}
+	window := []asciicast.Event{}
+	for _, e := range events {
+		if e.Time >= from && (to < 0 || e.Time < to) {
+			window = append(window, e)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"header":   header,
+		"duration": duration,
+		"events":   window,
+	})
+}
+
+func parseSeconds(s string, def float64) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/pty"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordings(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	h.ptyManager = pty.NewManager(10, 4096, "")
+	project := model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}
+	require.NoError(t, st.AddProject(project))
+	require.NoError(t, st.AddSession(model.Session{
+		ID:        "s1",
+		ProjectID: project.ID,
+		Name:      "Overnight",
+		Layout:    model.NewLeafPane("p1"),
+	}))
+
+	router := chi.NewRouter()
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Post("/sessions/{sid}/panes/{pid}/recording", h.SetPaneRecording)
+		r.Get("/recordings", h.ListRecordings)
+		r.Get("/recordings/{name}", h.DownloadRecording)
+		r.Get("/recordings/{name}/replay", h.ReplayRecording)
+		r.Delete("/recordings/{name}", h.DeleteRecording)
+	})
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	t.Run("toggle is saved on the pane", func(t *testing.T) {
+		w := do("POST", "/projects/proj-1/sessions/s1/panes/p1/recording", `{"enabled": true}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		sess, _ := st.GetSession("s1")
+		assert.True(t, sess.Layout.Record)
+
+		assert.Equal(t, http.StatusNotFound, do("POST", "/projects/proj-1/sessions/s1/panes/nope/recording", `{"enabled": true}`).Code)
+	})
+
+	dir := recordingsDir(project)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	name := "p1-20260101T020304Z.cast"
+	cast := `{"version": 2, "width": 100, "height": 30, "title": "Overnight"}` + "\n" +
+		`[0.5, "o", "one"]` + "\n" +
+		`[2.0, "r", "120x40"]` + "\n" +
+		`[4.25, "o", "two"]` + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(cast), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600))
+
+	w := do("GET", "/projects/proj-1/recordings", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []recordingInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "p1", list[0].PaneID)
+	assert.Equal(t, "Overnight", list[0].Title)
+	assert.Equal(t, 100, list[0].Width)
+	assert.Equal(t, 2026, list[0].StartedAt.Year())
+	assert.False(t, list[0].Active)
+
+	w = do("GET", "/projects/proj-1/recordings/"+name+"/replay?from=1&to=5", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var replay struct {
+		Duration float64 `json:"duration"`
+		Events   [][]any `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replay))
+	assert.Equal(t, 4.25, replay.Duration)
+	require.Len(t, replay.Events, 2)
+	assert.Equal(t, "r", replay.Events[0][1])
+	assert.Equal(t, "two", replay.Events[1][2])
+
+	w = do("GET", "/projects/proj-1/recordings/"+name, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), name)
+	assert.Equal(t, cast, w.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, do("GET", "/projects/proj-1/recordings/notes.txt", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/projects/proj-1/recordings/..%2Fstate.cast", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/projects/proj-1/recordings/"+name, "").Code)
+	_, err := os.Stat(filepath.Join(dir, name))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStartPaneRecording_SameSecond(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	project := model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}
+	require.NoError(t, st.AddProject(project))
+	sess := model.Session{ID: "s1", ProjectID: project.ID, Name: "main", Layout: model.NewLeafPane("p1")}
+
+	// Reattaching a pane right away must not collide with the file the
+	// previous attach just created.
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		ptySess := pty.NewSession("p1", project.Path, "sh", nil, 4096, nil)
+		require.NoError(t, h.startPaneRecording(sess, "p1", ptySess))
+		name := filepath.Base(ptySess.RecordingPath())
+		require.NoError(t, ptySess.StopRecording())
+		assert.Regexp(t, recordingNameRe, name)
+		assert.False(t, seen[name], "reused %s", name)
+		seen[name] = true
+	}
+}
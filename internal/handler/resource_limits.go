@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+)
+
+// decodeResourceLimits reads and validates a ResourceLimits body. A body
+// with no limits set clears them (nil).
+func decodeResourceLimits(w http.ResponseWriter, r *http.Request) (*model.ResourceLimits, bool) {
+	var limits model.ResourceLimits
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return nil, false
+	}
+	if err := limits.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if limits.IsZero() {
+		return nil, true
+	}
+	return &limits, true
+}
+
+// SetProjectResourceLimits sets the default limits for every pane in a
+// project.
+// PUT /projects/{id}/resource-limits
+func (h *Handlers) SetProjectResourceLimits(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	limits, ok := decodeResourceLimits(w, r)
+	if !ok {
+		return
+	}
+	project.ResourceLimits = limits
+	project.UpdatedAt = time.Now()
+	if err := h.store.UpdateProject(project); err != nil {
+		log.Printf("Error saving project resource limits: %v", err)
+		http.Error(w, "failed to update project", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"resource_limits": limits})
+}
+
+// SetPaneResourceLimits sets a pane's own limits. Each limit it sets
+// replaces the project's; the others still apply.
+// PUT /projects/{id}/sessions/{sid}/panes/{pid}/resource-limits
+func (h *Handlers) SetPaneResourceLimits(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	sess, ok := h.store.GetSession(chi.URLParam(r, "sid"))
+	if !ok || sess.ProjectID != project.ID {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	target, _ := sess.Layout.FindPane(chi.URLParam(r, "pid"))
+	if target == nil {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	limits, ok := decodeResourceLimits(w, r)
+	if !ok {
+		return
+	}
+	target.ResourceLimits = limits
+	sess.UpdatedAt = time.Now()
+	if err := h.store.UpdateSession(sess); err != nil {
+		log.Printf("Error saving pane resource limits: %v", err)
+		http.Error(w, "failed to save layout", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"resource_limits": limits})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceLimits(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s1", ProjectID: "proj-1", Layout: model.NewLeafPane("p1")}))
+
+	router := chi.NewRouter()
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Put("/resource-limits", h.SetProjectResourceLimits)
+		r.Put("/sessions/{sid}/panes/{pid}/resource-limits", h.SetPaneResourceLimits)
+	})
+	do := func(target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("/projects/proj-1/resource-limits", `{"max_rss_mb":2048,"rss_action":"kill","max_cpu_percent":150}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	project, _ := st.GetProject("proj-1")
+	require.NotNil(t, project.ResourceLimits)
+	assert.Equal(t, model.ResourceLimits{
+		MaxRSSMB: 2048, RSSAction: model.LimitActionKill,
+		MaxCPUPercent: 150, CPUSeconds: model.DefaultCPUSeconds, CPUAction: model.LimitActionNotify,
+		WallAction: model.LimitActionNotify,
+	}, *project.ResourceLimits)
+
+	w = do("/projects/proj-1/sessions/s1/panes/p1/resource-limits", `{"max_wall_seconds":600,"wall_action":"interrupt"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sess, _ := st.GetSession("s1")
+	require.NotNil(t, sess.Layout.ResourceLimits)
+	assert.Equal(t, 600, sess.Layout.ResourceLimits.MaxWallSeconds)
+
+	t.Run("validation", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do("/projects/proj-1/resource-limits", `{"max_rss_mb":100,"rss_action":"explode"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do("/projects/proj-1/resource-limits", `{"max_rss_mb":-1}`).Code)
+		assert.Equal(t, http.StatusNotFound, do("/projects/proj-1/sessions/s1/panes/nope/resource-limits", `{}`).Code)
+	})
+
+	t.Run("empty body clears", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do("/projects/proj-1/sessions/s1/panes/p1/resource-limits", `{}`).Code)
+		sess, _ := st.GetSession("s1")
+		assert.Nil(t, sess.Layout.ResourceLimits)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/model"
 	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/davydany/ClawIDE/internal/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -206,11 +207,11 @@ func (h *Handlers) StartScheduledJob(w http.ResponseWriter, r *http.Request) {
 	case "cron":
 		h.startCronJob(w, project, job)
 	default: // "loop"
-		h.startLoopJob(w, job)
+		h.startLoopJob(w, project, job)
 	}
 }
 
-func (h *Handlers) startLoopJob(w http.ResponseWriter, job model.ScheduledJob) {
+func (h *Handlers) startLoopJob(w http.ResponseWriter, project model.Project, job model.ScheduledJob) {
 	if job.TargetPaneID == "" {
 		http.Error(w, "no target pane configured", http.StatusBadRequest)
 		return
@@ -242,6 +243,7 @@ func (h *Handlers) startLoopJob(w http.ResponseWriter, job model.ScheduledJob) {
 
 	job.Status = "running"
 	job.LastRunAt = &now
+	h.webhooks.Emit(webhook.ScheduledJobEvent(project, job))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(job)
 }
@@ -276,6 +278,7 @@ func (h *Handlers) startCronJob(w http.ResponseWriter, project model.Project, jo
 
 	job.Status = "running"
 	job.LastRunAt = &now
+	h.webhooks.Emit(webhook.ScheduledJobEvent(project, job))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(job)
 }
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/termsearch"
+	"github.com/davydany/ClawIDE/internal/tmux"
+)
+
+const (
+	scrollbackSearchDefaultContext = 2
+	scrollbackSearchMaxContext     = 10
+	scrollbackSearchPaneLimit      = 50
+	scrollbackSearchTotalLimit     = 500
+	// scrollbackCaptureLines matches the history-limit tmux.PrepareSession sets.
+	scrollbackCaptureLines = 50000
+)
+
+type scrollbackPaneResult struct {
+	PaneID  string             `json:"pane_id"`
+	Name    string             `json:"name,omitempty"`
+	Source  string             `json:"source"` // "live" (attached PTY) or "tmux" (capture-pane)
+	URL     string             `json:"url"`
+	Matches []termsearch.Match `json:"matches"`
+}
+
+type scrollbackSessionResult struct {
+	SessionID string                 `json:"session_id"`
+	Name      string                 `json:"name"`
+	FeatureID string                 `json:"feature_id,omitempty"`
+	Panes     []scrollbackPaneResult `json:"panes"`
+}
+
+type scrollbackProjectResult struct {
+	ProjectID string                    `json:"project_id"`
+	Name      string                    `json:"name"`
+	Sessions  []scrollbackSessionResult `json:"sessions"`
+}
+
+type scrollbackSearchResponse struct {
+	Query     string                    `json:"query"`
+	Total     int                       `json:"total"`
+	Truncated bool                      `json:"truncated"`
+	Projects  []scrollbackProjectResult `json:"projects"`
+}
+
+// SearchScrollback searches the terminal output of every pane the caller can
+// see. Attached panes are searched in their in-memory scrollback; detached
+// panes whose tmux session is still running are searched via capture-pane.
+// GET /api/search/scrollback?q=...&regex=1&case=1&context=2&project_id=...
+func (h *Handlers) SearchScrollback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := q.Get("q")
+	if strings.TrimSpace(query) == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	opts := termsearch.Options{
+		Regex:         q.Get("regex") == "1" || q.Get("regex") == "true",
+		CaseSensitive: q.Get("case") == "1" || q.Get("case") == "true",
+		Context:       scrollbackSearchDefaultContext,
+		Limit:         scrollbackSearchPaneLimit,
+	}
+	if c := q.Get("context"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n < 0 || n > scrollbackSearchMaxContext {
+			http.Error(w, "context must be between 0 and 10", http.StatusBadRequest)
+			return
+		}
+		opts.Context = n
+	}
+	match, err := termsearch.Compile(query, opts)
+	if err != nil {
+		http.Error(w, "invalid regular expression: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	projects := visibleProjects(r, h.store.GetProjects())
+	if only := q.Get("project_id"); only != "" {
+		filtered := projects[:0]
+		for _, p := range projects {
+			if p.ID == only {
+				filtered = append(filtered, p)
+			}
+		}
+		projects = filtered
+	}
+	byID := make(map[string]int, len(projects))
+	resp := scrollbackSearchResponse{Query: query, Projects: []scrollbackProjectResult{}}
+	results := make([]scrollbackProjectResult, len(projects))
+	for i, p := range projects {
+		byID[p.ID] = i
+		results[i] = scrollbackProjectResult{ProjectID: p.ID, Name: p.Name}
+	}
+
+	for _, sess := range h.store.GetAllSessions() {
+		pi, ok := byID[sess.ProjectID]
+		if !ok || sess.Layout == nil {
+			continue
+		}
+		sr := scrollbackSessionResult{SessionID: sess.ID, Name: sess.Name, FeatureID: sess.FeatureID}
+		for _, paneID := range sess.Layout.CollectLeaves() {
+			if resp.Total >= scrollbackSearchTotalLimit {
+				resp.Truncated = true
+				break
+			}
+			lines, source := h.paneScrollbackLines(paneID)
+			if source == "" {
+				continue
+			}
+			paneOpts := opts
+			if remaining := scrollbackSearchTotalLimit - resp.Total; remaining < paneOpts.Limit {
+				paneOpts.Limit = remaining
+			}
+			matches := termsearch.Search(lines, match, paneOpts)
+			if len(matches) == 0 {
+				continue
+			}
+			if len(matches) == paneOpts.Limit {
+				resp.Truncated = true
+			}
+			resp.Total += len(matches)
+
+			pr := scrollbackPaneResult{PaneID: paneID, Source: source, URL: paneURL(sess, paneID), Matches: matches}
+			if node, _ := sess.Layout.FindPane(paneID); node != nil {
+				pr.Name = node.Name
+			}
+			sr.Panes = append(sr.Panes, pr)
+		}
+		if len(sr.Panes) > 0 {
+			results[pi].Sessions = append(results[pi].Sessions, sr)
+		}
+	}
+
+	for _, pr := range results {
+		if len(pr.Sessions) > 0 {
+			resp.Projects = append(resp.Projects, pr)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// paneScrollbackLines returns a pane's output as plain lines and where it
+// came from, or an empty source when the pane has no running terminal.
+func (h *Handlers) paneScrollbackLines(paneID string) ([]string, string) {
+	if h.ptyManager != nil {
+		if ptySess, ok := h.ptyManager.GetSession(paneID); ok {
+			return termsearch.Lines(ptySess.Scrollback()), "live"
+		}
+	}
+	tmuxName := tmux.TmuxName(paneID)
+	if !tmux.HasSession(tmuxName) {
+		return nil, ""
+	}
+	out, err := tmux.CapturePane(tmuxName, scrollbackCaptureLines)
+	if err != nil {
+		return nil, ""
+	}
+	return termsearch.Lines([]byte(out)), "tmux"
+}
+
+// paneURL is the workspace link that focuses a pane, in the same form the
+// notification dropdown builds.
+func paneURL(sess model.Session, paneID string) string {
+	u := "/projects/" + sess.ProjectID + "/"
+	if sess.FeatureID != "" {
+		u += "features/" + sess.FeatureID + "/"
+	}
+	return u + "?pane=" + url.QueryEscape(paneID)
+}
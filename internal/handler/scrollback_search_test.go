@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchScrollback_Validation(t *testing.T) {
+	h, _ := setupHandlerWithRenderer(t)
+
+	for _, target := range []string{
+		"/api/search/scrollback",
+		"/api/search/scrollback?q=x&context=99",
+		"/api/search/scrollback?q=(&regex=1",
+	} {
+		w := httptest.NewRecorder()
+		h.SearchScrollback(w, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+func TestSearchScrollback_NoRunningPanes(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s1", ProjectID: "proj-1", Layout: model.NewLeafPane("not-running")}))
+
+	w := httptest.NewRecorder()
+	h.SearchScrollback(w, httptest.NewRequest("GET", "/api/search/scrollback?q=panic", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp scrollbackSearchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "panic", resp.Query)
+	assert.Zero(t, resp.Total)
+	assert.Empty(t, resp.Projects)
+}
+
+func TestPaneURL(t *testing.T) {
+	assert.Equal(t, "/projects/p/?pane=x", paneURL(model.Session{ProjectID: "p"}, "x"))
+	assert.Equal(t, "/projects/p/features/f/?pane=x", paneURL(model.Session{ProjectID: "p", FeatureID: "f"}, "x"))
+}
@@ -13,6 +13,18 @@ import (
 
 func (h *Handlers) ListSessions(w http.ResponseWriter, r *http.Request) {
 	project := middleware.GetProject(r)
+	if wantsJSON(r) {
+		// JSON lists the features' sessions too; feature_id narrows it to one.
+		featureID := r.URL.Query().Get("feature_id")
+		sessions := []model.Session{}
+		for _, sess := range h.store.GetAllSessions() {
+			if sess.ProjectID == project.ID && (featureID == "" || sess.FeatureID == featureID) {
+				sessions = append(sessions, sess)
+			}
+		}
+		writeJSON(w, http.StatusOK, sessions)
+		return
+	}
 	sessions := h.store.GetSessions(project.ID)
 
 	data := map[string]any{
@@ -47,7 +59,11 @@ func (h *Handlers) CreateSession(w http.ResponseWriter, r *http.Request) {
 		workDir = project.Path
 	}
 
-	paneID := uuid.New().String()
+	layout, err := h.newSessionLayout(r, project.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	now := time.Now()
 
 	sess := model.Session{
@@ -56,7 +72,7 @@ func (h *Handlers) CreateSession(w http.ResponseWriter, r *http.Request) {
 		Name:      name,
 		Branch:    branch,
 		WorkDir:   workDir,
-		Layout:    model.NewAgentPane(paneID),
+		Layout:    layout,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -67,6 +83,10 @@ func (h *Handlers) CreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusCreated, sess)
+		return
+	}
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/projects/"+project.ID+"/")
 		w.WriteHeader(http.StatusOK)
@@ -106,6 +126,10 @@ func (h *Handlers) RenameSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, sess)
+		return
+	}
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/projects/"+project.ID+"/")
 		w.WriteHeader(http.StatusOK)
@@ -135,6 +159,10 @@ func (h *Handlers) DeleteSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	if r.Header.Get("HX-Request") == "true" {
 		w.WriteHeader(http.StatusOK)
 		return
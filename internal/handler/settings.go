@@ -8,17 +8,30 @@ import (
 	"path/filepath"
 
 	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
 	"github.com/davydany/ClawIDE/internal/version"
 	"github.com/davydany/ClawIDE/internal/wizard"
 )
 
 func (h *Handlers) SettingsPage(w http.ResponseWriter, r *http.Request) {
+	// Projects whose membership the caller may manage.
+	var ownedProjects []model.Project
+	for _, p := range h.store.GetProjects() {
+		if middleware.ProjectRoleFor(r, p) == model.RoleOwner {
+			ownedProjects = append(ownedProjects, p)
+		}
+	}
+
 	data := map[string]any{
 		"Title":   "Settings - ClawIDE",
 		"Theme":           h.cfg.Theme,
 		"Mode":            h.cfg.Mode,
 		"Config":  h.cfg,
 		"Version": version.Version,
+		"IsAdmin":       middleware.GetPrincipal(r).IsAdmin(),
+		"OwnedProjects": ownedProjects,
+		"Projects":      visibleProjects(r, h.store.GetProjects()),
 	}
 
 	if err := h.renderer.RenderHTMX(w, r, "settings", "settings", data); err != nil {
@@ -57,6 +70,8 @@ func (h *Handlers) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 		"sidebar_position": true,
 		"sidebar_width":      true,
 		"auto_update_check": true,
+		"approval_notify_delay": true,
+		"persist_scrollback": true,
 		"preferred_editor":   true,
 		"theme":             true,
 		"mode":              true,
@@ -78,6 +93,13 @@ func (h *Handlers) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 					http.Error(w, "max_sessions must be a number", http.StatusBadRequest)
 					return
 				}
+			} else if k == "approval_notify_delay" {
+				val, ok := v.(float64)
+				if !ok || val < 0 || val > 3600 {
+					http.Error(w, "approval_notify_delay must be between 0 and 3600 seconds", http.StatusBadRequest)
+					return
+				}
+				existing[k] = int(val)
 			} else if k == "claude_command" {
 				// Map old claude_command key to agent_command
 				existing["agent_command"] = v
@@ -113,6 +135,11 @@ func (h *Handlers) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 	h.cfg.Host = newCfg.Host
 	h.cfg.Port = newCfg.Port
 	h.cfg.AutoUpdateCheck = newCfg.AutoUpdateCheck
+	h.cfg.ApprovalNotifyDelay = newCfg.ApprovalNotifyDelay
+	if _, ok := updates["persist_scrollback"]; ok {
+		h.cfg.PersistScrollback = newCfg.PersistScrollback
+		h.applyScrollbackPersistence()
+	}
 	h.cfg.PreferredEditor = newCfg.PreferredEditor
 	h.cfg.Theme = newCfg.Theme
 	h.cfg.Mode = newCfg.Mode
@@ -260,3 +287,16 @@ func (h *Handlers) VerifyAICredentials(w http.ResponseWriter, r *http.Request) {
 		"message": "Configuration appears valid. Note: Full verification requires API testing which we don't perform for security.",
 	})
 }
+
+// applyScrollbackPersistence points the PTY manager at the scrollback
+// directory, or turns persistence off and deletes what was saved.
+func (h *Handlers) applyScrollbackPersistence() {
+	if h.cfg.PersistScrollback {
+		h.ptyManager.SetScrollbackDir(h.cfg.ScrollbackDir())
+		return
+	}
+	h.ptyManager.SetScrollbackDir("")
+	if err := os.RemoveAll(h.cfg.ScrollbackDir()); err != nil {
+		log.Printf("Error removing saved scrollback: %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/auth"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	shareTokenPrefix     = "cshare_"
+	defaultShareDuration = time.Hour
+	maxShareDuration     = 7 * 24 * time.Hour
+)
+
+// shareLinkView is the JSON shape of a share link. The token and URL are
+// only present in the create response.
+type shareLinkView struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	PaneID    string    `json:"pane_id"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Viewers   int       `json:"viewers"`
+	Token     string    `json:"token,omitempty"`
+	URL       string    `json:"url,omitempty"`
+}
+
+// shareViewers tracks open viewer WebSockets per share link so revoking a
+// link disconnects everyone watching through it.
+type shareViewers struct {
+	mu    sync.Mutex
+	conns map[string]map[*websocket.Conn]struct{}
+}
+
+func newShareViewers() *shareViewers {
+	return &shareViewers{conns: make(map[string]map[*websocket.Conn]struct{})}
+}
+
+func (v *shareViewers) add(linkID string, conn *websocket.Conn) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.conns[linkID] == nil {
+		v.conns[linkID] = make(map[*websocket.Conn]struct{})
+	}
+	v.conns[linkID][conn] = struct{}{}
+}
+
+func (v *shareViewers) remove(linkID string, conn *websocket.Conn) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.conns[linkID], conn)
+	if len(v.conns[linkID]) == 0 {
+		delete(v.conns, linkID)
+	}
+}
+
+func (v *shareViewers) count(linkID string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.conns[linkID])
+}
+
+func (v *shareViewers) closeAll(linkID string) {
+	v.mu.Lock()
+	conns := v.conns[linkID]
+	delete(v.conns, linkID)
+	v.mu.Unlock()
+	for conn := range conns {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "share link revoked"),
+			time.Now().Add(time.Second))
+		conn.Close()
+	}
+}
+
+func (h *Handlers) shareLinkView(l model.ShareLink) shareLinkView {
+	return shareLinkView{
+		ID:        l.ID,
+		SessionID: l.SessionID,
+		PaneID:    l.PaneID,
+		CreatedBy: l.CreatedBy,
+		CreatedAt: l.CreatedAt,
+		ExpiresAt: l.ExpiresAt,
+		Viewers:   h.shareViewers.count(l.ID),
+	}
+}
+
+// CreateShareLink mints a read-only link for one pane.
+// POST /projects/{id}/sessions/{sid}/panes/{pid}/share {"expires_in_minutes": 60}
+func (h *Handlers) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	sessionID := chi.URLParam(r, "sid")
+	paneID := chi.URLParam(r, "pid")
+
+	sess, ok := h.store.GetSession(sessionID)
+	if !ok || sess.ProjectID != project.ID {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	if sess.Layout == nil || !sess.Layout.HasPane(paneID) {
+		http.Error(w, "pane not found in session layout", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		ExpiresInMinutes int `json:"expires_in_minutes"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+	ttl := time.Duration(req.ExpiresInMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = defaultShareDuration
+	}
+	if ttl > maxShareDuration {
+		http.Error(w, fmt.Sprintf("share links can last at most %s", maxShareDuration), http.StatusBadRequest)
+		return
+	}
+
+	secret, hash, err := auth.NewSecret(shareTokenPrefix)
+	if err != nil {
+		log.Printf("Failed to create share token: %v", err)
+		http.Error(w, "failed to create share link", http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	link := model.ShareLink{
+		ID:        uuid.New().String(),
+		TokenHash: hash,
+		ProjectID: project.ID,
+		SessionID: sess.ID,
+		PaneID:    paneID,
+		CreatedBy: middleware.GetPrincipal(r).Username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if _, err := h.shareLinkStore.DeleteExpired(now); err != nil {
+		log.Printf("Failed to prune expired share links: %v", err)
+	}
+	if err := h.shareLinkStore.Add(link); err != nil {
+		log.Printf("Failed to save share link: %v", err)
+		http.Error(w, "failed to create share link", http.StatusInternalServerError)
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	view := h.shareLinkView(link)
+	view.Token = secret
+	view.URL = fmt.Sprintf("%s://%s/share/%s", scheme, r.Host, secret)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(view)
+}
+
+// ListShareLinks returns the project's active share links.
+func (h *Handlers) ListShareLinks(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	now := time.Now()
+	out := []shareLinkView{}
+	for _, l := range h.shareLinkStore.GetAll() {
+		if l.ProjectID == project.ID && !l.Expired(now) {
+			out = append(out, h.shareLinkView(l))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// RevokeShareLink deletes a link and disconnects its viewers.
+func (h *Handlers) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	project := middleware.GetProject(r)
+	linkID := chi.URLParam(r, "shareID")
+
+	link, ok := h.shareLinkStore.Get(linkID)
+	if !ok || link.ProjectID != project.ID {
+		http.Error(w, "share link not found", http.StatusNotFound)
+		return
+	}
+	if err := h.shareLinkStore.Delete(linkID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.shareViewers.closeAll(linkID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookupShareLink resolves a share token, writing 404 for unknown or expired
+// tokens (they are indistinguishable to the viewer on purpose).
+func (h *Handlers) lookupShareLink(w http.ResponseWriter, token string) (model.ShareLink, bool) {
+	link, ok := h.shareLinkStore.FindByHash(auth.HashToken(token))
+	if !ok || link.Expired(time.Now()) {
+		http.Error(w, "this share link is invalid or has expired", http.StatusNotFound)
+		return model.ShareLink{}, false
+	}
+	return link, true
+}
+
+// SharePage renders the read-only terminal viewer. It is reachable without
+// a login; the token in the URL is the credential.
+func (h *Handlers) SharePage(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	link, ok := h.lookupShareLink(w, token)
+	if !ok {
+		return
+	}
+
+	sessionName := ""
+	if sess, ok := h.store.GetSession(link.SessionID); ok {
+		sessionName = sess.Name
+	}
+
+	// Keep the token out of Referer headers sent to the CDNs base.html loads.
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	data := map[string]any{
+		"Title":       "Shared terminal - ClawIDE",
+		"Theme":       h.cfg.Theme,
+		"Mode":        h.cfg.Mode,
+		"Token":       token,
+		"SessionName": sessionName,
+		"CreatedBy":   link.CreatedBy,
+		"ExpiresAt":   link.ExpiresAt,
+	}
+	if err := h.renderer.Render(w, "share", data); err != nil {
+		log.Printf("Error rendering share page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// ShareWS streams a pane's output to a share-link viewer. Input and resize
+// messages from the viewer are read (so control frames are processed) and
+// dropped; nothing reaches Session.Write or Session.Resize.
+func (h *Handlers) ShareWS(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.lookupShareLink(w, chi.URLParam(r, "token"))
+	if !ok {
+		return
+	}
+
+	sess, ok := h.store.GetSession(link.SessionID)
+	if !ok || sess.Layout == nil || !sess.Layout.HasPane(link.PaneID) {
+		http.Error(w, "the shared pane no longer exists", http.StatusGone)
+		return
+	}
+
+	// Attach to a running pane only; a viewer must never start a shell.
+	ptySess, ok := h.ptyManager.GetSession(link.PaneID)
+	if !ok {
+		if !tmux.HasSession(tmux.TmuxName(link.PaneID)) {
+			http.Error(w, "terminal is not running", http.StatusConflict)
+			return
+		}
+		// The tmux session already runs with its own environment, so the
+		// viewer's client gets no API token.
+		var err error
+		ptySess, err = h.ptyManager.CreateSession(link.PaneID, paneWorkDir(sess, link.PaneID), h.paneEnv(sess, link.PaneID, ""))
+		if err != nil {
+			log.Printf("Failed to attach to pane %s for share viewer: %v", link.PaneID, err)
+			http.Error(w, "Failed to attach to terminal", http.StatusInternalServerError)
+			return
+		}
+		h.resumePaneRecording(sess, link.PaneID, ptySess)
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	h.shareViewers.add(link.ID, conn)
+	defer h.shareViewers.remove(link.ID, conn)
+
+	// Disconnect when the link expires mid-stream.
+	expiry := time.AfterFunc(time.Until(link.ExpiresAt), func() {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "share link expired"),
+			time.Now().Add(time.Second))
+		conn.Close()
+	})
+	defer expiry.Stop()
+
+	clientID := "share-" + uuid.New().String()
+	dataCh, history := ptySess.Subscribe(clientID)
+	defer ptySess.Unsubscribe(clientID)
+
+	if len(history) > 0 {
+		if err := conn.WriteMessage(websocket.BinaryMessage, history); err != nil {
+			return
+		}
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case data, ok := <-dataCh:
+			if !ok {
+				return
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shareRouter(h *Handlers) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(middleware.ProjectLoader(h.store))
+		r.Post("/sessions/{sid}/panes/{pid}/share", h.CreateShareLink)
+		r.Get("/shares", h.ListShareLinks)
+		r.Delete("/shares/{shareID}", h.RevokeShareLink)
+	})
+	r.Get("/share/{token}", h.SharePage)
+	return r
+}
+
+func TestShareLinks(t *testing.T) {
+	h, st := setupHandlerWithRenderer(t)
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", Path: t.TempDir()}))
+	require.NoError(t, st.AddSession(model.Session{
+		ID:        "s1",
+		ProjectID: "proj-1",
+		Name:      "Pairing",
+		Layout:    model.NewLeafPane("p1"),
+	}))
+	router := shareRouter(h)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("rejects unknown panes and overlong links", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, do("POST", "/projects/proj-1/sessions/s1/panes/nope/share", "").Code)
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/projects/proj-1/sessions/s1/panes/p1/share", `{"expires_in_minutes": 20160}`).Code)
+	})
+
+	w := do("POST", "/projects/proj-1/sessions/s1/panes/p1/share", `{"expires_in_minutes": 30}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created shareLinkView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Token, shareTokenPrefix))
+	assert.Contains(t, created.URL, "/share/"+created.Token)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), created.ExpiresAt, time.Minute)
+
+	// The token is only returned once and never stored in clear.
+	stored, ok := h.shareLinkStore.Get(created.ID)
+	require.True(t, ok)
+	assert.NotContains(t, stored.TokenHash, created.Token)
+
+	w = do("GET", "/projects/proj-1/shares", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Token)
+	var listed []shareLinkView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, "p1", listed[0].PaneID)
+
+	w = do("GET", "/share/"+created.Token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "share:Pairing")
+	assert.Equal(t, http.StatusNotFound, do("GET", "/share/cshare_bogus", "").Code)
+
+	t.Run("expired links stop working", func(t *testing.T) {
+		stale := stored
+		stale.ID = "stale"
+		stale.TokenHash = "stale-hash"
+		stale.ExpiresAt = time.Now().Add(-time.Minute)
+		require.NoError(t, h.shareLinkStore.Add(stale))
+		_, ok := h.lookupShareLink(httptest.NewRecorder(), "stale")
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusNotFound, do("DELETE", "/projects/proj-2/shares/stale", "").Code)
+	})
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/projects/proj-1/shares/"+created.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/share/"+created.Token, "").Code)
+}
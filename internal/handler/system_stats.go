@@ -4,8 +4,12 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/procstats"
 	"github.com/davydany/ClawIDE/internal/sysinfo"
+	"github.com/davydany/ClawIDE/internal/tmux"
 )
 
 // SystemStats returns system-level statistics as JSON.
@@ -18,3 +22,61 @@ func (h *Handlers) SystemStats(w http.ResponseWriter, r *http.Request) {
 		log.Printf("system stats JSON encode error: %v", err)
 	}
 }
+
+type resourceUsageReport struct {
+	Panes     []procstats.PaneUsage  `json:"panes"`
+	Sessions  []procstats.GroupUsage `json:"sessions"`
+	Features  []procstats.GroupUsage `json:"features"`
+	Projects  []procstats.GroupUsage `json:"projects"`
+	SampledAt time.Time              `json:"sampled_at"`
+}
+
+// ResourceUsage returns the CPU, memory and listening ports of every running
+// pane's process tree, rolled up per session, feature and project. Each list
+// is sorted busiest first. project_id limits it to one project.
+// GET /api/system/usage?project_id=
+func (h *Handlers) ResourceUsage(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Query().Get("project_id")
+	visible := map[string]string{}
+	for _, p := range visibleProjects(r, h.store.GetProjects()) {
+		if projectID == "" || p.ID == projectID {
+			visible[p.ID] = p.Name
+		}
+	}
+	var sessions []model.Session
+	byID := map[string]model.Session{}
+	for _, sess := range h.store.GetAllSessions() {
+		if _, ok := visible[sess.ProjectID]; ok {
+			sessions = append(sessions, sess)
+			byID[sess.ID] = sess
+		}
+	}
+
+	snap, err := h.usageSampler.Sample()
+	if err != nil {
+		log.Printf("resource usage: %v", err)
+		http.Error(w, "failed to read process table", http.StatusInternalServerError)
+		return
+	}
+	pids, err := tmux.PanePIDs()
+	if err != nil {
+		log.Printf("resource usage: %v", err)
+		http.Error(w, "failed to list terminal panes", http.StatusInternalServerError)
+		return
+	}
+
+	report := resourceUsageReport{Panes: procstats.Panes(snap, sessions, pids), SampledAt: snap.At}
+	for i, p := range report.Panes {
+		report.Panes[i].URL = paneURL(byID[p.SessionID], p.PaneID)
+	}
+	report.Sessions, report.Features, report.Projects = procstats.Rollup(report.Panes)
+	for i, f := range report.Features {
+		if feature, ok := h.store.GetFeature(f.ID); ok {
+			report.Features[i].Name = feature.Name
+		}
+	}
+	for i, p := range report.Projects {
+		report.Projects[i].Name = visible[p.ID]
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/model"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/go-chi/chi/v5"
@@ -59,15 +60,21 @@ func (h *Handlers) GetAggregatedTaskBoard(w http.ResponseWriter, r *http.Request
 		Projects []projectBoard `json:"projects"`
 	}
 
-	globalBoard, err := h.globalTaskStore.Board()
-	if err != nil {
-		log.Printf("aggregated: global board error: %v", err)
-		http.Error(w, "failed to load global board", http.StatusInternalServerError)
-		return
+	// The global board belongs to the instance admin; other users only see
+	// the boards of projects they are members of.
+	globalBoard := model.Board{Columns: []model.Column{}}
+	if middleware.GetPrincipal(r).IsAdmin() {
+		var err error
+		globalBoard, err = h.globalTaskStore.Board()
+		if err != nil {
+			log.Printf("aggregated: global board error: %v", err)
+			http.Error(w, "failed to load global board", http.StatusInternalServerError)
+			return
+		}
 	}
 	out := aggregated{Global: globalBoard, Projects: []projectBoard{}}
 
-	for _, proj := range h.store.GetProjects() {
+	for _, proj := range visibleProjects(r, h.store.GetProjects()) {
 		ps, err := h.getProjectTaskStore(proj.ID)
 		if err != nil {
 			log.Printf("aggregated: project %s store error: %v", proj.ID, err)
@@ -189,12 +196,14 @@ func (h *Handlers) MoveTask(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// AddTaskComment appends a user-authored comment to a task.
+// AddTaskComment appends a comment to a task, by the user unless the body
+// names another author.
 // POST /api/tasks/{taskID}/comments?project_id=<id>
 func (h *Handlers) AddTaskComment(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "taskID")
 	var body struct {
-		Body string `json:"body"`
+		Body   string `json:"body"`
+		Author string `json:"author"` // defaults to "user"
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "invalid JSON", http.StatusBadRequest)
@@ -204,12 +213,19 @@ func (h *Handlers) AddTaskComment(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "comment body is required", http.StatusBadRequest)
 		return
 	}
+	if body.Author = strings.TrimSpace(body.Author); body.Author == "" {
+		body.Author = "user"
+	} else if strings.ContainsAny(body.Author, "\r\n[]*") {
+		// The author goes into the comment's markdown header.
+		http.Error(w, "author may not contain line breaks, brackets or asterisks", http.StatusBadRequest)
+		return
+	}
 	s, _, err := h.resolveTaskStore(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	c, err := s.AppendComment(id, model.Comment{Author: "user", Body: body.Body})
+	c, err := s.AppendComment(id, model.Comment{Author: body.Author, Body: body.Body})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -414,3 +430,9 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 		log.Printf("writeJSON encode error: %v", err)
 	}
 }
+
+// wantsJSON reports whether the client asked for JSON (Accept:
+// application/json) from a handler that renders HTML by default.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
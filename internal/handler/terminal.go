@@ -2,14 +2,20 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
-	"time"
+	"path/filepath"
+	"strings"
 
+	"github.com/davydany/ClawIDE/internal/agentprofile"
+	"github.com/davydany/ClawIDE/internal/auth"
 	"github.com/davydany/ClawIDE/internal/mcpserver"
+	"github.com/davydany/ClawIDE/internal/middleware"
 	"github.com/davydany/ClawIDE/internal/model"
+	ptyPkg "github.com/davydany/ClawIDE/internal/pty"
+	"github.com/davydany/ClawIDE/internal/tlscert"
 	"github.com/davydany/ClawIDE/internal/tmux"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -21,9 +27,24 @@ const mcpServerName = "clawide"
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  4096,
 	WriteBufferSize: 4096,
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for local development
-	},
+	CheckOrigin:     sameOrigin,
+}
+
+// sameOrigin rejects cross-site WebSocket upgrades. Browsers attach the
+// session cookie to WebSocket handshakes from any page, so without this check
+// a malicious site could open a terminal on the user's behalf. Non-browser
+// clients (CLI, scripts) send no Origin header and are allowed through; they
+// authenticate with a bearer token instead.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
 }
 
 type resizeMsg struct {
@@ -53,49 +74,30 @@ func (h *Handlers) TerminalWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Viewers may watch a terminal but never type into it or resize it.
+	role := h.projectRole(r, sess.ProjectID)
+	if role == model.RoleNone {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	readOnly := !role.Allows(model.RoleCollaborator)
+
 	// Get or create PTY session keyed by paneID
 	ptySess, ok := h.ptyManager.GetSession(paneID)
 	if !ok {
-		tmuxName := "clawide-" + paneID
-		isNewSession := !tmux.HasSession(tmuxName)
-
-		env := map[string]string{
-			"CLAWIDE_PROJECT_ID": sess.ProjectID,
-			"CLAWIDE_SESSION_ID": sess.ID,
-			"CLAWIDE_PANE_ID":    paneID,
-			"CLAWIDE_API_URL":    fmt.Sprintf("http://localhost:%d", h.cfg.Port),
-		}
-		if sess.FeatureID != "" {
-			env["CLAWIDE_FEATURE_ID"] = sess.FeatureID
+		if readOnly && !tmux.HasSession(tmux.TmuxName(paneID)) {
+			// Starting a fresh shell (and possibly the agent) is not read-only.
+			http.Error(w, "terminal is not running", http.StatusConflict)
+			return
 		}
 
 		var err error
-		ptySess, err = h.ptyManager.CreateSession(paneID, sess.WorkDir, env)
+		ptySess, err = h.attachPane(sess, paneID, middleware.GetPrincipal(r))
 		if err != nil {
 			log.Printf("Failed to create PTY session for pane %s: %v", paneID, err)
 			http.Error(w, "Failed to create terminal session", http.StatusInternalServerError)
 			return
 		}
-
-		// Auto-launch the agent command for new agent panes.
-		if isNewSession && h.cfg.AgentCommand != "" {
-			paneNode, _ := sess.Layout.FindPane(paneID)
-			if paneNode != nil && paneNode.EffectivePaneType() == model.PaneTypeAgent {
-				agentCmd := h.cfg.AgentCommand
-				if h.cfg.AgentArgs != "" {
-					agentCmd = agentCmd + " " + h.cfg.AgentArgs
-				}
-				go func() {
-					time.Sleep(300 * time.Millisecond)
-					if err := tmux.SendKeys(tmuxName, agentCmd); err != nil {
-						log.Printf("Failed to send agent command to %s: %v", tmuxName, err)
-					}
-				}()
-
-				// Auto-register ClawIDE MCP server in the project's .mcp.json
-				go h.ensureMCPServerRegistered(sess.WorkDir)
-			}
-		}
 	}
 
 	// Upgrade to WebSocket
@@ -125,6 +127,9 @@ func (h *Handlers) TerminalWS(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				return
 			}
+			if readOnly {
+				continue
+			}
 
 			if msgType == websocket.TextMessage {
 				// Check for resize messages
@@ -148,6 +153,121 @@ func (h *Handlers) TerminalWS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// attachPane returns the pane's PTY session, creating it when none is
+// attached. If the pane's tmux session had to be started too, the pane's
+// startup command is run in it, with an API token acting as owner.
+func (h *Handlers) attachPane(sess model.Session, paneID string, owner auth.Principal) (*ptyPkg.Session, error) {
+	if ptySess, ok := h.ptyManager.GetSession(paneID); ok {
+		return ptySess, nil
+	}
+	tmuxName := tmux.TmuxName(paneID)
+	isNewSession := !tmux.HasSession(tmuxName)
+
+	ptySess, err := h.ptyManager.CreateSession(paneID, paneWorkDir(sess, paneID), h.paneEnv(sess, paneID, h.paneAPIToken(owner, sess.ProjectID)))
+	if err != nil {
+		return nil, err
+	}
+	h.resumePaneRecording(sess, paneID, ptySess)
+	if !isNewSession {
+		return ptySess, nil
+	}
+
+	// Run the pane's startup command in new panes: its own command if
+	// it has one, otherwise the agent command for agent panes.
+	paneNode, _ := sess.Layout.FindPane(paneID)
+	startup := h.startupCommand(sess, paneNode)
+	if startup != "" {
+		go func() {
+			if !tmux.WaitForShell(tmuxName, tmux.ShellReadyTimeout) {
+				log.Printf("Shell in %s not ready after %s, sending startup command anyway", tmuxName, tmux.ShellReadyTimeout)
+			}
+			if err := tmux.SendKeys(tmuxName, startup); err != nil {
+				log.Printf("Failed to send startup command to %s: %v", tmuxName, err)
+			}
+		}()
+	}
+
+	// Auto-register ClawIDE MCP server in the project's .mcp.json for agent
+	// panes that start an agent, whether from a profile, the pane's own
+	// command or the configured agent command.
+	if startup != "" && paneNode.EffectivePaneType() == model.PaneTypeAgent {
+		go h.ensureMCPServerRegistered(sess.WorkDir)
+	}
+	return ptySess, nil
+}
+
+// startupCommand returns the command typed into a pane when its shell is
+// first started, or "" for none. Agent panes without their own command run
+// their agent profile, or the configured agent command.
+func (h *Handlers) startupCommand(sess model.Session, pane *model.PaneNode) string {
+	if pane == nil {
+		return ""
+	}
+	if pane.Command != "" {
+		return pane.Command
+	}
+	if prof, ok := h.paneAgentProfile(sess, pane); ok {
+		return agentprofile.CommandLine(prof)
+	}
+	if pane.EffectivePaneType() != model.PaneTypeAgent || h.cfg.AgentCommand == "" {
+		return ""
+	}
+	if h.cfg.AgentArgs != "" {
+		return h.cfg.AgentCommand + " " + h.cfg.AgentArgs
+	}
+	return h.cfg.AgentCommand
+}
+
+// paneEnv is the environment a pane's shell starts with: its agent
+// profile's variables, then the pane's own, then the CLAWIDE_* ones, which
+// let tools inside the pane (clawide mcp-serve, scripts) identify the pane
+// and call back into the API with token. Later sources win.
+func (h *Handlers) paneEnv(sess model.Session, paneID, token string) map[string]string {
+	env := map[string]string{}
+	if pane, _ := sess.Layout.FindPane(paneID); pane != nil {
+		if prof, ok := h.paneAgentProfile(sess, pane); ok {
+			for k, v := range prof.Env {
+				env[k] = v
+			}
+		}
+		for k, v := range pane.Env {
+			env[k] = v
+		}
+	}
+	env["CLAWIDE_PROJECT_ID"] = sess.ProjectID
+	env["CLAWIDE_SESSION_ID"] = sess.ID
+	env["CLAWIDE_PANE_ID"] = paneID
+	env["CLAWIDE_API_URL"] = h.cfg.LocalURL()
+	if ca := h.localCAFile(); ca != "" {
+		env["CLAWIDE_CA_CERT"] = ca
+	}
+	if token != "" {
+		env["CLAWIDE_API_TOKEN"] = token
+	}
+	if sess.FeatureID != "" {
+		env["CLAWIDE_FEATURE_ID"] = sess.FeatureID
+	}
+	return env
+}
+
+// paneWorkDir is the directory a pane's shell starts in: the pane's WorkDir,
+// resolved against the session directory when relative, or the session
+// directory itself.
+func paneWorkDir(sess model.Session, paneID string) string {
+	pane, _ := sess.Layout.FindPane(paneID)
+	if pane == nil || pane.WorkDir == "" {
+		return sess.WorkDir
+	}
+	return resolvePaneDir(sess.WorkDir, pane.WorkDir)
+}
+
+func resolvePaneDir(sessionDir, dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(sessionDir, dir)
+}
+
 // TmuxPasteBuffer returns the contents of the most recent tmux paste buffer.
 func (h *Handlers) TmuxPasteBuffer(w http.ResponseWriter, r *http.Request) {
 	buf, err := tmux.GetPasteBuffer()
@@ -179,9 +299,12 @@ func (h *Handlers) ensureMCPServerRegistered(projectDir string) {
 		Command: execPath,
 		Args:    []string{"mcp-serve"},
 		Env: map[string]string{
-			"CLAWIDE_API_URL": fmt.Sprintf("http://localhost:%d", h.cfg.Port),
+			"CLAWIDE_API_URL": h.cfg.LocalURL(),
 		},
 	}
+	if ca := h.localCAFile(); ca != "" {
+		server.Env["CLAWIDE_CA_CERT"] = ca
+	}
 
 	if err := mcpserver.CreateServer(mcpFilePath, server); err != nil {
 		log.Printf("Failed to register MCP server in %s: %v", mcpFilePath, err)
@@ -189,3 +312,12 @@ func (h *Handlers) ensureMCPServerRegistered(projectDir string) {
 		log.Printf("Registered ClawIDE MCP server in %s", mcpFilePath)
 	}
 }
+
+// localCAFile returns the auto-generated CA certificate that in-pane clients
+// must trust to reach the API over HTTPS, or "" when no local CA is in use.
+func (h *Handlers) localCAFile() string {
+	if !h.cfg.TLSEnabled || h.cfg.TLSCert != "" {
+		return ""
+	}
+	return tlscert.FilesIn(h.cfg.TLSDir()).CAFile
+}
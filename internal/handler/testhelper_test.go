@@ -1,15 +1,23 @@
 package handler
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"testing"
 	"testing/fstest"
 
 	"github.com/davydany/ClawIDE/internal/aicli"
+	"github.com/davydany/ClawIDE/internal/auth"
 	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/notify"
+	"github.com/davydany/ClawIDE/internal/procstats"
 	"github.com/davydany/ClawIDE/internal/sse"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/davydany/ClawIDE/internal/tmpl"
+	"github.com/davydany/ClawIDE/internal/webhook"
+	"github.com/davydany/ClawIDE/internal/webpush"
 	"github.com/davydany/ClawIDE/internal/wizard"
 	"github.com/stretchr/testify/require"
 )
@@ -22,7 +30,7 @@ func minimalTemplateFS() fstest.MapFS {
 			Data: []byte(`{{define "base.html"}}<!DOCTYPE html><html><body>{{block "body" .}}{{end}}</body></html>{{end}}`),
 		},
 		"templates/pages/project-list.html": &fstest.MapFile{
-			Data: []byte(`{{define "body"}}projects:{{if .Projects}}{{len .Projects}}{{else}}0{{end}}{{end}}`),
+			Data: []byte(`{{define "body"}}projects:{{if .Projects}}{{len .Projects}}{{else}}0{{end}}{{if .Discovered}} discovered:{{len .Discovered}}{{end}}{{end}}`),
 		},
 		"templates/pages/workspace.html": &fstest.MapFile{
 			Data: []byte(`{{define "body"}}workspace:{{.Project.Name}}{{end}}`),
@@ -30,6 +38,9 @@ func minimalTemplateFS() fstest.MapFS {
 		"templates/pages/settings.html": &fstest.MapFile{
 			Data: []byte(`{{define "body"}}settings{{end}}`),
 		},
+		"templates/pages/share.html": &fstest.MapFile{
+			Data: []byte(`{{define "body"}}share:{{.SessionName}}{{end}}`),
+		},
 		"templates/partials/project-list.html": &fstest.MapFile{
 			Data: []byte(`projects-partial:{{if .Projects}}{{len .Projects}}{{else}}0{{end}}`),
 		},
@@ -98,6 +109,65 @@ func setupHandlerWithRenderer(t *testing.T) (*Handlers, *store.Store) {
 	require.NoError(t, err)
 	wizGen := wizard.NewGenerator(wizReg, wizJobs)
 
-	h := New(cfg, st, renderer, nil, snippetSt, notifSt, noteSt, bookmarkSt, voiceBoxSt, scratchpadSt, promptForgeSt, globalTaskSt, aiReg, sse.NewHub(), nil, wizJobs, wizGen)
+	tokenSt, err := store.NewTokenStore(filepath.Join(storeDir, "tokens.json"))
+	require.NoError(t, err)
+	userSt, err := store.NewUserStore(filepath.Join(storeDir, "users.json"))
+	require.NoError(t, err)
+	authMgr, err := auth.NewManager(cfg, tokenSt, userSt)
+	require.NoError(t, err)
+
+	shareSt, err := store.NewShareLinkStore(filepath.Join(storeDir, "share_links.json"))
+	require.NoError(t, err)
+
+	layoutSt, err := store.NewLayoutTemplateStore(filepath.Join(storeDir, "layout_templates.json"))
+	require.NoError(t, err)
+
+	profileSt, err := store.NewAgentProfileStore(filepath.Join(storeDir, "agent_profiles.json"))
+	require.NoError(t, err)
+
+	webhookSt, err := store.NewWebhookStore(filepath.Join(storeDir, "webhooks.json"), filepath.Join(storeDir, "webhook_deliveries.json"), 100)
+	require.NoError(t, err)
+
+	pushSubSt, err := store.NewPushSubscriptionStore(filepath.Join(storeDir, "push_subscriptions.json"))
+	require.NoError(t, err)
+	vapid, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	pusher := webpush.NewPusher(webpush.NewSender(vapid), pushSubSt, st)
+
+	notifRulesSt, err := store.NewNotificationRulesStore(filepath.Join(storeDir, "notification_rules.json"))
+	require.NoError(t, err)
+	hub := sse.NewHub()
+
+	h := New(cfg, st, renderer, nil, snippetSt, notifSt, noteSt, bookmarkSt, voiceBoxSt, scratchpadSt, promptForgeSt, globalTaskSt, aiReg, hub, nil, wizJobs, wizGen, authMgr, shareSt, nil, layoutSt, profileSt, procstats.NewSampler(), webhookSt, webhook.New(webhookSt), pushSubSt, pusher, notify.New(notifSt, notifRulesSt, hub, st))
 	return h, st
 }
+
+// addUser creates a non-admin user, turning authentication on first.
+func addUser(t *testing.T, h *Handlers, username string) auth.Principal {
+	t.Helper()
+	if !h.auth.Enabled() {
+		require.NoError(t, h.auth.SetPassword("admin-password"))
+	}
+	u, err := h.auth.CreateUser(username, username+"-password")
+	require.NoError(t, err)
+	return auth.Principal{UserID: u.ID, Username: u.Username}
+}
+
+// as returns r as RequireAuth passes it on for p: with a pane token when p
+// is confined to a project, otherwise with a bearer token of p's.
+func as(t *testing.T, h *Handlers, r *http.Request, p auth.Principal) *http.Request {
+	t.Helper()
+	token := h.auth.PaneToken(p, p.Project)
+	if p.Project == "" {
+		var err error
+		_, token, err = h.auth.CreateToken("test", p)
+		require.NoError(t, err)
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	var authed *http.Request
+	middleware.RequireAuth(h.auth)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authed = r
+	})).ServeHTTP(httptest.NewRecorder(), r)
+	require.NotNil(t, authed, "request was not authenticated")
+	return authed
+}
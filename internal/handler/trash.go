@@ -85,6 +85,13 @@ func (h *Handlers) RestoreTrashedFeature(w http.ResponseWriter, r *http.Request)
 	restored.ID = uuid.New().String()
 	restored.WorktreePath = workDir
 	restored.UpdatedAt = now
+	if restored.Preview != nil {
+		// The preview URL contains the feature ID, so it needs a new link.
+		preview := *restored.Preview
+		preview.BookmarkID = ""
+		restored.Preview = &preview
+		h.ensurePreviewBookmark(&restored)
+	}
 
 	if err := h.store.AddFeature(restored); err != nil {
 		log.Printf("Error restoring feature: %v", err)
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/notify"
 	"github.com/davydany/ClawIDE/internal/sse"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/davydany/ClawIDE/internal/updater"
@@ -42,7 +43,7 @@ func setupUpdateHandler(t *testing.T, githubHandler http.Handler) *Handlers {
 		baseURL = ts.URL
 	}
 
-	upd := updater.NewWithBaseURL(cfg, notifStore, hub, baseURL)
+	upd := updater.NewWithBaseURL(cfg, notify.New(notifStore, nil, hub, nil), baseURL)
 
 	return &Handlers{
 		cfg:     cfg,
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// webhookRequest is the body of POST and PUT /api/webhooks. On update a nil
+// Secret keeps the current one and "" removes it.
+type webhookRequest struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Secret     *string  `json:"secret"`
+	Enabled    *bool    `json:"enabled"`
+	Events     []string `json:"events"`
+	Levels     []string `json:"levels"`
+	Sources    []string `json:"sources"`
+	ProjectIDs []string `json:"project_ids"`
+}
+
+// webhookView is a webhook as the API shows it: the secret is never sent
+// back, only whether one is set.
+type webhookView struct {
+	model.Webhook
+	SecretSet bool `json:"secret_set"`
+}
+
+func newWebhookView(w model.Webhook) webhookView {
+	v := webhookView{Webhook: w, SecretSet: w.Secret != ""}
+	v.Secret = ""
+	return v
+}
+
+// ListWebhooks returns the configured webhooks and the event types they can
+// subscribe to.
+// GET /api/webhooks
+func (h *Handlers) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks := []webhookView{}
+	for _, hook := range h.webhookStore.GetAll() {
+		hooks = append(hooks, newWebhookView(hook))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"webhooks":    hooks,
+		"event_types": model.WebhookEventTypes,
+	})
+}
+
+// CreateWebhook adds a webhook, enabled unless the body says otherwise.
+// POST /api/webhooks
+func (h *Handlers) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var body webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	hook := model.Webhook{ID: uuid.New().String(), Enabled: true, CreatedAt: now}
+	if msg := applyWebhookRequest(&hook, body); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	hook.UpdatedAt = now
+
+	if err := h.webhookStore.Add(hook); err != nil {
+		log.Printf("webhook create error: %v", err)
+		http.Error(w, "failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newWebhookView(hook))
+}
+
+// UpdateWebhook replaces a webhook's settings.
+// PUT /api/webhooks/{webhookID}
+func (h *Handlers) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.webhookStore.Get(chi.URLParam(r, "webhookID"))
+	if !ok {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return
+	}
+	var body webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if msg := applyWebhookRequest(&hook, body); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	hook.UpdatedAt = time.Now()
+
+	if err := h.webhookStore.Update(hook); err != nil {
+		log.Printf("webhook update error: %v", err)
+		http.Error(w, "failed to update webhook", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, newWebhookView(hook))
+}
+
+// DeleteWebhook removes a webhook and its delivery log. Retries still
+// pending for it are dropped.
+// DELETE /api/webhooks/{webhookID}
+func (h *Handlers) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhookStore.Delete(chi.URLParam(r, "webhookID")); err != nil {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries returns a webhook's logged delivery attempts, newest
+// first.
+// GET /api/webhooks/{webhookID}/deliveries
+func (h *Handlers) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "webhookID")
+	if _, ok := h.webhookStore.Get(id); !ok {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.webhookStore.Deliveries(id))
+}
+
+// TestWebhook sends a "ping" event to a webhook once, whether or not it is
+// enabled or its filters match, and returns the logged attempt.
+// POST /api/webhooks/{webhookID}/test
+func (h *Handlers) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.webhookStore.Get(chi.URLParam(r, "webhookID"))
+	if !ok {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return
+	}
+	del, err := h.webhooks.Deliver(hook, webhook.Event{
+		Type:   "ping",
+		Level:  "info",
+		Source: "clawide",
+		Text:   "ClawIDE webhook test: " + hook.Name,
+	})
+	if err != nil {
+		log.Printf("webhook test error: %v", err)
+	}
+	writeJSON(w, http.StatusOK, del)
+}
+
+// applyWebhookRequest validates body and copies it into hook. It returns a
+// client error message, or "" on success.
+func applyWebhookRequest(hook *model.Webhook, body webhookRequest) string {
+	body.Name = strings.TrimSpace(body.Name)
+	body.URL = strings.TrimSpace(body.URL)
+	if body.Name == "" {
+		return "name is required"
+	}
+	u, err := url.Parse(body.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "url must be an http or https URL"
+	}
+	for _, e := range body.Events {
+		if !slices.Contains(model.WebhookEventTypes, e) {
+			return "unknown event type " + e
+		}
+	}
+
+	hook.Name = body.Name
+	hook.URL = body.URL
+	if body.Secret != nil {
+		hook.Secret = *body.Secret
+	}
+	if body.Enabled != nil {
+		hook.Enabled = *body.Enabled
+	}
+	hook.Events = compactStrings(body.Events)
+	hook.Levels = compactStrings(body.Levels)
+	hook.Sources = compactStrings(body.Sources)
+	hook.ProjectIDs = compactStrings(body.ProjectIDs)
+	return ""
+}
+
+// compactStrings trims each value and drops blanks and duplicates.
+func compactStrings(in []string) []string {
+	var out []string
+	for _, s := range in {
+		s = strings.TrimSpace(s)
+		if s != "" && !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
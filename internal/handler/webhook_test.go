@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooks(t *testing.T) {
+	h, _ := setupHandlerWithRenderer(t)
+
+	var gotSignature string
+	var gotBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(webhook.HeaderSignature)
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+
+	router := chi.NewRouter()
+	router.Route("/api/webhooks", func(r chi.Router) {
+		r.Get("/", h.ListWebhooks)
+		r.Post("/", h.CreateWebhook)
+		r.Put("/{webhookID}", h.UpdateWebhook)
+		r.Delete("/{webhookID}", h.DeleteWebhook)
+		r.Get("/{webhookID}/deliveries", h.ListWebhookDeliveries)
+		r.Post("/{webhookID}/test", h.TestWebhook)
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	for _, body := range []string{
+		`{"url":"http://example.com"}`,
+		`{"name":"x","url":"ftp://example.com"}`,
+		`{"name":"x","url":"http://example.com","events":["nope"]}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, do("POST", "/api/webhooks/", body).Code, body)
+	}
+
+	w := do("POST", "/api/webhooks/", `{"name":" Team chat ","url":"`+receiver.URL+`","secret":"s3cret","events":["notification.created","feature.merged"],"levels":["error"," error",""]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "s3cret")
+	var created webhookView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "Team chat", created.Name)
+	assert.True(t, created.Enabled)
+	assert.True(t, created.SecretSet)
+	assert.Equal(t, []string{"error"}, created.Levels)
+	stored, _ := h.webhookStore.Get(created.ID)
+	assert.Equal(t, "s3cret", stored.Secret)
+
+	w = do("GET", "/api/webhooks/", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Webhooks   []webhookView `json:"webhooks"`
+		EventTypes []string      `json:"event_types"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Webhooks, 1)
+	assert.Empty(t, list.Webhooks[0].Secret)
+	assert.Equal(t, model.WebhookEventTypes, list.EventTypes)
+
+	// The test ping goes out signed and is logged.
+	w = do("POST", "/api/webhooks/"+created.ID+"/test", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var del model.WebhookDelivery
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &del))
+	assert.True(t, del.Success)
+	assert.Equal(t, "ping", del.EventType)
+	assert.Equal(t, webhook.Sign("s3cret", gotBody), gotSignature)
+
+	w = do("GET", "/api/webhooks/"+created.ID+"/deliveries", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var dels []model.WebhookDelivery
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &dels))
+	require.Len(t, dels, 1)
+	assert.Equal(t, del.ID, dels[0].ID)
+
+	// Omitting the secret keeps it; an empty one removes it.
+	w = do("PUT", "/api/webhooks/"+created.ID, `{"name":"Team chat","url":"`+receiver.URL+`","enabled":false}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored, _ = h.webhookStore.Get(created.ID)
+	assert.False(t, stored.Enabled)
+	assert.Equal(t, "s3cret", stored.Secret)
+	assert.Empty(t, stored.Events)
+	w = do("PUT", "/api/webhooks/"+created.ID, `{"name":"Team chat","url":"`+receiver.URL+`","secret":""}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	stored, _ = h.webhookStore.Get(created.ID)
+	assert.Empty(t, stored.Secret)
+
+	assert.Equal(t, http.StatusNotFound, do("PUT", "/api/webhooks/missing", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/webhooks/missing/test", "").Code)
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/webhooks/"+created.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/webhooks/"+created.ID+"/deliveries", "").Code)
+}
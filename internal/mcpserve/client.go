@@ -2,15 +2,32 @@ package mcpserve
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/tlscert"
 )
 
+// apiPrefix is where the versioned API the tools use is served.
+const apiPrefix = "/api/v1"
+
+// maxPage is the largest page the API returns; the tools fetch lists whole.
+const maxPage = 500
+
 type Client struct {
 	baseURL    string
+	token      string
 	httpClient *http.Client
 }
 
@@ -32,11 +49,21 @@ func NewClient() *Client {
 		baseURL = "http://localhost:9800"
 	}
 
+	httpClient := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	if caFile := os.Getenv("CLAWIDE_CA_CERT"); caFile != "" {
+		if pool, err := tlscert.CertPoolWith(caFile); err != nil {
+			log.Printf("Ignoring CLAWIDE_CA_CERT: %v", err)
+		} else {
+			httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+		}
+	}
+
 	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		baseURL:    baseURL,
+		token:      os.Getenv("CLAWIDE_API_TOKEN"),
+		httpClient: httpClient,
 	}
 }
 
@@ -46,11 +73,14 @@ func (c *Client) PostNotification(req notificationRequest) error {
 		return fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		c.baseURL+"/api/notifications",
-		"application/json",
-		bytes.NewReader(body),
-	)
+	httpReq, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/notifications", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	c.authorize(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("posting notification: %w", err)
 	}
@@ -62,3 +92,130 @@ func (c *Client) PostNotification(req notificationRequest) error {
 
 	return nil
 }
+
+// authorize attaches the pane's API token (CLAWIDE_API_TOKEN) when the
+// ClawIDE instance has authentication enabled.
+func (c *Client) authorize(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// apiError is an error response from the API.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("ClawIDE API returned status %d: %s", e.Status, e.Message)
+}
+
+// do sends a request to the /api/v1 path and decodes the JSON response into
+// out (if non-nil).
+func (c *Client) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+apiPrefix+path, reader)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &e) == nil && e.Error.Message != "" {
+			msg = e.Error.Message
+		}
+		return &apiError{Status: resp.StatusCode, Message: msg}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// list fetches a paginated list whole into out, a pointer to a slice.
+func (c *Client) list(path string, query url.Values, out any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", fmt.Sprint(maxPage))
+	page := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	return c.do(http.MethodGet, path+"?"+query.Encode(), nil, &page)
+}
+
+// Project returns the project the tools act on: $CLAWIDE_PROJECT_ID, or the
+// one whose directory, or one of whose feature worktrees, contains the
+// working directory.
+func (c *Client) Project() (model.Project, error) {
+	if id := os.Getenv("CLAWIDE_PROJECT_ID"); id != "" {
+		var p model.Project
+		err := c.do(http.MethodGet, "/projects/"+url.PathEscape(id), nil, &p)
+		return p, err
+	}
+
+	cwd := getCWD()
+	if cwd == "" {
+		return model.Project{}, errors.New("CLAWIDE_PROJECT_ID is not set and the working directory is unknown")
+	}
+	var projects []model.Project
+	if err := c.list("/projects", nil, &projects); err != nil {
+		return model.Project{}, err
+	}
+	// The deepest project containing the working directory.
+	var best model.Project
+	for _, p := range projects {
+		if p.Path != "" && within(cwd, p.Path) && len(p.Path) > len(best.Path) {
+			best = p
+		}
+	}
+	if best.ID != "" {
+		return best, nil
+	}
+	// Feature worktrees live outside their project's directory.
+	for _, p := range projects {
+		var features []model.Feature
+		if err := c.list("/projects/"+p.ID+"/features", nil, &features); err != nil {
+			return model.Project{}, err
+		}
+		for _, f := range features {
+			if f.WorktreePath != "" && within(cwd, f.WorktreePath) {
+				return p, nil
+			}
+		}
+	}
+	return model.Project{}, fmt.Errorf("CLAWIDE_PROJECT_ID is not set and %s is in no ClawIDE project", cwd)
+}
+
+func within(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
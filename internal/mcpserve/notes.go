@@ -0,0 +1,101 @@
+package mcpserve
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+func handleListNotes(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if q := stringArg(args, "query"); q != "" {
+		query.Set("q", q)
+	}
+	var notes []model.Note
+	if err := client.list("/projects/"+project.ID+"/notes", query, &notes); err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	if len(notes) == 0 {
+		return textResult("No notes in %s.", project.Name), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Notes in %s (%d):\n", project.Name, len(notes))
+	for _, n := range notes {
+		fmt.Fprintf(&b, "\n- %s (id: %s, updated %s)", n.Title, n.ID, n.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	return textResult("%s", b.String()), nil
+}
+
+func handleReadNote(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	name, err := requiredArg(args, "note")
+	if err != nil {
+		return nil, err
+	}
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	note, ok, err := findNote(client, project.ID, name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("no note %q in %s", name, project.Name)
+	}
+	return textResult("# %s\n\n%s", note.Title, note.Content), nil
+}
+
+func handleWriteNote(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	title, err := requiredArg(args, "title")
+	if err != nil {
+		return nil, err
+	}
+	content, _ := args["content"].(string)
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	note, ok, err := findNote(client, project.ID, title)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		req := map[string]string{"title": title, "content": content}
+		if err := client.do(http.MethodPost, "/projects/"+project.ID+"/notes", req, &note); err != nil {
+			return nil, fmt.Errorf("failed to create note: %w", err)
+		}
+		return textResult("Created note %s (id: %s).", note.Title, note.ID), nil
+	}
+
+	if boolArg(args, "append") && note.Content != "" {
+		content = strings.TrimRight(note.Content, "\n") + "\n\n" + content
+	}
+	req := map[string]string{"content": content}
+	if err := client.do(http.MethodPut, "/projects/"+project.ID+"/notes/"+url.PathEscape(note.ID), req, &note); err != nil {
+		return nil, fmt.Errorf("failed to update note: %w", err)
+	}
+	return textResult("Updated note %s (id: %s).", note.Title, note.ID), nil
+}
+
+// findNote looks a project's note up by ID or title.
+func findNote(client *Client, projectID, name string) (model.Note, bool, error) {
+	var notes []model.Note
+	if err := client.list("/projects/"+projectID+"/notes", nil, &notes); err != nil {
+		return model.Note{}, false, fmt.Errorf("failed to list notes: %w", err)
+	}
+	for _, n := range notes {
+		if n.ID == name || n.Title == name {
+			return n, true, nil
+		}
+	}
+	return model.Note{}, false, nil
+}
@@ -0,0 +1,302 @@
+package mcpserve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+type promptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type promptDefinition struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []promptArgument `json:"arguments,omitempty"`
+}
+
+type promptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type promptsListResult struct {
+	Prompts []promptDefinition `json:"prompts"`
+}
+
+type promptGetParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type promptGetResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []promptMessage `json:"messages"`
+}
+
+type promptMessage struct {
+	Role    string       `json:"role"`
+	Content contentBlock `json:"content"`
+}
+
+// listPrompts lists the PromptForge prompts without their content.
+func listPrompts(client *Client) ([]model.Prompt, error) {
+	var prompts []model.Prompt
+	if err := client.list("/prompts", nil, &prompts); err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	return prompts, nil
+}
+
+// promptNames names each prompt after its title, adding the start of the ID
+// to titles that more than one prompt shares.
+func promptNames(prompts []model.Prompt) []string {
+	names := make([]string, len(prompts))
+	count := map[string]int{}
+	for _, p := range prompts {
+		count[p.Title]++
+	}
+	for i, p := range prompts {
+		names[i] = p.Title
+		if count[p.Title] > 1 {
+			id := p.ID
+			if len(id) > 8 {
+				id = id[:8]
+			}
+			names[i] += "-" + id
+		}
+	}
+	return names
+}
+
+func promptDescription(p model.Prompt) string {
+	if p.Type == model.PromptTypeJinja {
+		return "A PromptForge Jinja prompt"
+	}
+	return "A PromptForge prompt"
+}
+
+// promptArguments describes a Jinja prompt's declared variables, then any
+// it uses without declaring them, which the editor also asks for.
+func promptArguments(p model.Prompt) []promptArgument {
+	if p.Type != model.PromptTypeJinja {
+		return nil
+	}
+	var args []promptArgument
+	declared := map[string]bool{}
+	for _, v := range p.Variables {
+		declared[v.Name] = true
+		desc := v.Label
+		if desc == "" {
+			desc = v.Name
+		}
+		var notes []string
+		switch {
+		case len(v.Options) > 0:
+			notes = append(notes, "one of: "+strings.Join(v.Options, ", "))
+		case v.Type != "" && v.Type != model.VariableTypeString:
+			notes = append(notes, string(v.Type))
+		}
+		if v.Default != "" {
+			notes = append(notes, "default: "+v.Default)
+		}
+		if len(notes) > 0 {
+			desc += " (" + strings.Join(notes, "; ") + ")"
+		}
+		args = append(args, promptArgument{Name: v.Name, Description: desc, Required: v.Required && v.Default == ""})
+	}
+	for _, name := range templateVariables(p.Content) {
+		if !declared[name] {
+			args = append(args, promptArgument{Name: name})
+		}
+	}
+	return args
+}
+
+// Patterns the editor's detectTemplateVariables uses to find the variables a
+// template refers to: {{ name ... }}, {% for x in name %} and {% if name %}.
+var (
+	exprVarRe = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)`)
+	forVarRe  = regexp.MustCompile(`\{%\s*for\s+[a-zA-Z_][a-zA-Z0-9_]*\s+in\s+([a-zA-Z_][a-zA-Z0-9_]*)`)
+	ifVarRe   = regexp.MustCompile(`\{%\s*(?:if|elif)\s+([a-zA-Z_][a-zA-Z0-9_]*)`)
+)
+
+var reservedNames = map[string]bool{
+	"true": true, "false": true, "null": true, "none": true, "loop": true, "self": true,
+	"if": true, "else": true, "elif": true, "endif": true, "for": true, "endfor": true, "in": true,
+	"and": true, "or": true, "not": true, "block": true, "endblock": true, "extends": true,
+	"include": true, "set": true, "macro": true, "endmacro": true, "raw": true, "endraw": true,
+}
+
+// templateVariables returns the variables a Jinja template refers to, in
+// the order the editor finds them.
+func templateVariables(content string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, re := range []*regexp.Regexp{exprVarRe, forVarRe, ifVarRe} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			name := m[1]
+			if reservedNames[strings.ToLower(name)] || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// getPrompts lists every prompt, fetching Jinja ones' content as well to
+// find the variables they use without declaring them.
+func getPrompts(client *Client) ([]model.Prompt, error) {
+	prompts, err := listPrompts(client)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range prompts {
+		if p.Type != model.PromptTypeJinja {
+			continue
+		}
+		if err := client.do(http.MethodGet, "/prompts/"+url.PathEscape(p.ID), nil, &prompts[i]); err != nil {
+			return nil, fmt.Errorf("failed to get prompt %s: %w", p.Title, err)
+		}
+	}
+	return prompts, nil
+}
+
+// renderPrompt returns a prompt's text. A Jinja prompt is not rendered
+// here: its template comes back as written, followed by the argument values
+// converted to its variables' types as the editor's compile dialog does, for
+// the agent to fill in.
+func renderPrompt(p model.Prompt, args map[string]string) (string, error) {
+	if p.Type != model.PromptTypeJinja {
+		return p.Content, nil
+	}
+	vars := map[string]any{}
+	for _, name := range templateVariables(p.Content) {
+		vars[name] = ""
+	}
+	for name, value := range args {
+		vars[name] = value
+	}
+	for _, v := range p.Variables {
+		raw, ok := args[v.Name]
+		if !ok || raw == "" {
+			raw = v.Default
+		}
+		if v.Required && raw == "" {
+			return "", fmt.Errorf("argument %q is required", v.Name)
+		}
+		switch v.Type {
+		case model.VariableTypeBoolean:
+			switch strings.ToLower(raw) {
+			case "true", "1", "yes", "on":
+				vars[v.Name] = true
+			default:
+				vars[v.Name] = false
+			}
+		case model.VariableTypeNumber:
+			if raw == "" {
+				vars[v.Name] = nil
+				continue
+			}
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return "", fmt.Errorf("argument %q must be a number", v.Name)
+			}
+			vars[v.Name] = f
+		default:
+			vars[v.Name] = raw
+		}
+	}
+	if len(vars) == 0 {
+		return p.Content, nil
+	}
+	values, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode arguments of prompt %s: %w", p.Title, err)
+	}
+	return "Fill in this Jinja (Nunjucks) template with the values below, then follow the result.\n\n" +
+		"<template>\n" + p.Content + "\n</template>\n\n" +
+		"<values>\n" + string(values) + "\n</values>", nil
+}
+
+func handlePromptsList(req *jsonRPCRequest, client *Client) *jsonRPCResponse {
+	prompts, err := getPrompts(client)
+	if err != nil {
+		return &jsonRPCResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      req.ID,
+			Error:   &jsonRPCError{Code: -32603, Message: err.Error()},
+		}
+	}
+	names := promptNames(prompts)
+	defs := make([]promptDefinition, len(prompts))
+	for i, p := range prompts {
+		defs[i] = promptDefinition{Name: names[i], Description: promptDescription(p), Arguments: promptArguments(p)}
+	}
+	return &jsonRPCResponse{
+		JSONRPC: jsonrpcVersion,
+		ID:      req.ID,
+		Result:  promptsListResult{Prompts: defs},
+	}
+}
+
+func handlePromptsGet(req *jsonRPCRequest, client *Client) *jsonRPCResponse {
+	var params promptGetParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+		return &jsonRPCResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      req.ID,
+			Error:   &jsonRPCError{Code: -32602, Message: "Invalid params"},
+		}
+	}
+	text, title, err := getPrompt(client, params.Name, params.Arguments)
+	if err != nil {
+		return &jsonRPCResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      req.ID,
+			Error:   &jsonRPCError{Code: -32602, Message: err.Error()},
+		}
+	}
+	return &jsonRPCResponse{
+		JSONRPC: jsonrpcVersion,
+		ID:      req.ID,
+		Result: promptGetResult{
+			Description: title,
+			Messages:    []promptMessage{{Role: "user", Content: contentBlock{Type: "text", Text: text}}},
+		},
+	}
+}
+
+// getPrompt returns the text of the prompt listed under name, or with that ID.
+func getPrompt(client *Client, name string, args map[string]string) (text, title string, err error) {
+	prompts, err := listPrompts(client)
+	if err != nil {
+		return "", "", err
+	}
+	id := ""
+	for i, n := range promptNames(prompts) {
+		if n == name || prompts[i].ID == name {
+			id = prompts[i].ID
+			break
+		}
+	}
+	if id == "" {
+		return "", "", fmt.Errorf("no prompt %q", name)
+	}
+	var prompt model.Prompt
+	if err := client.do(http.MethodGet, "/prompts/"+url.PathEscape(id), nil, &prompt); err != nil {
+		return "", "", fmt.Errorf("failed to get prompt: %w", err)
+	}
+	text, err = renderPrompt(prompt, args)
+	return text, prompt.Title, err
+}
@@ -0,0 +1,83 @@
+package mcpserve
+
+import (
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompts(t *testing.T) {
+	api := newFakeAPI(t)
+	api.prompts = []model.Prompt{
+		{ID: "pr1", Title: "code-review", Type: model.PromptTypeJinja,
+			Variables: []model.Variable{
+				{Name: "file", Type: model.VariableTypeString, Label: "File to review", Required: true},
+				{Name: "strict", Type: model.VariableTypeBoolean, Default: "false"},
+				{Name: "max_issues", Type: model.VariableTypeNumber},
+				{Name: "tone", Type: model.VariableTypeSelect, Options: []string{"kind", "blunt"}, Default: "kind"},
+			},
+			Content: "Review {{ file }} in a {{ tone }} tone.{% if strict %} Be strict.{% endif %}" +
+				"{% if max_issues %} List at most {{ max_issues + 1 }} issues.{% endif %}{{ focus }}"},
+		{ID: "pr2abcdef99", Title: "standup", Type: model.PromptTypePlain, Content: "What did you do {{ yesterday }}?"},
+		{ID: "pr3abcdef99", Title: "standup", Type: model.PromptTypePlain, Content: "Another standup."},
+	}
+
+	resp := call(t, "prompts/list", nil)
+	require.Nil(t, resp.Error)
+	prompts := resp.Result.(promptsListResult).Prompts
+	require.Len(t, prompts, 3)
+	assert.Equal(t, "code-review", prompts[0].Name)
+	assert.Equal(t, "A PromptForge Jinja prompt", prompts[0].Description)
+	assert.Equal(t, []promptArgument{
+		{Name: "file", Description: "File to review", Required: true},
+		{Name: "strict", Description: "strict (boolean; default: false)"},
+		{Name: "max_issues", Description: "max_issues (number)"},
+		{Name: "tone", Description: "tone (one of: kind, blunt; default: kind)"},
+		{Name: "focus"},
+	}, prompts[0].Arguments)
+	assert.Equal(t, "standup-pr2abcde", prompts[1].Name, "shared names get an ID suffix")
+	assert.Equal(t, "standup-pr3abcde", prompts[2].Name)
+	assert.Empty(t, prompts[1].Arguments, "plain prompts take no arguments")
+
+	get := func(name string, args map[string]string) *jsonRPCResponse {
+		return call(t, "prompts/get", promptGetParams{Name: name, Arguments: args})
+	}
+	text := func(resp *jsonRPCResponse) string {
+		require.Nil(t, resp.Error)
+		result := resp.Result.(promptGetResult)
+		require.Len(t, result.Messages, 1)
+		assert.Equal(t, "user", result.Messages[0].Role)
+		return result.Messages[0].Content.Text
+	}
+
+	template := api.prompts[0].Content
+	assert.Equal(t, "Fill in this Jinja (Nunjucks) template with the values below, then follow the result.\n\n"+
+		"<template>\n"+template+"\n</template>\n\n"+
+		"<values>\n{\n  \"file\": \"main.go\",\n  \"focus\": \"\",\n  \"max_issues\": null,\n  \"strict\": false,\n  \"tone\": \"kind\"\n}\n</values>",
+		text(get("code-review", map[string]string{"file": "main.go"})))
+	assert.Contains(t, text(get("code-review", map[string]string{"file": "main.go", "tone": "blunt", "strict": "yes", "max_issues": "3", "focus": " Focus on errors."})),
+		"<values>\n{\n  \"file\": \"main.go\",\n  \"focus\": \" Focus on errors.\",\n  \"max_issues\": 3,\n  \"strict\": true,\n  \"tone\": \"blunt\"\n}\n</values>",
+		"arguments take their variables' types")
+	assert.Equal(t, "What did you do {{ yesterday }}?", text(get("pr2abcdef99", nil)), "plain prompts are returned as is")
+
+	api.prompts[2].Type = model.PromptTypeJinja
+	assert.Equal(t, "Another standup.", text(get("pr3abcdef99", nil)), "so are Jinja prompts without variables")
+
+	resp = get("code-review", nil)
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, `argument "file" is required`)
+	resp = get("code-review", map[string]string{"file": "x", "max_issues": "lots"})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, `argument "max_issues" must be a number`)
+	resp = get("nope", nil)
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, `no prompt "nope"`)
+}
+
+func TestTemplateVariables(t *testing.T) {
+	assert.Equal(t, []string{"name", "user", "items", "strict"},
+		templateVariables("Hi {{ name | upper }} {{user.email}} {{ loop.index }}{% for x in items %}{% endfor %}{% if strict %}{% elif name %}{% endif %}{{ true }}"))
+	assert.Empty(t, templateVariables("No variables here."))
+}
@@ -0,0 +1,275 @@
+package mcpserve
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/store"
+)
+
+// Resource URIs:
+//
+//	clawide://projects/{id}/tasks           the task board as tasks.md markdown
+//	clawide://projects/{id}/notes/{noteID}  a note
+//	clawide://prompts/{promptID}            a PromptForge prompt's template
+const resourceScheme = "clawide://"
+
+const markdownMIME = "text/markdown"
+
+// pollInterval is how often subscribed resources are checked for changes.
+const pollInterval = 5 * time.Second
+
+type resourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type resourcesListResult struct {
+	Resources []resource `json:"resources"`
+}
+
+type resourceParams struct {
+	URI string `json:"uri"`
+}
+
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+type resourceReadResult struct {
+	Contents []resourceContents `json:"contents"`
+}
+
+// listResources lists the current project's task board and notes, when the
+// working directory is in a project, and every PromptForge prompt.
+func listResources(client *Client) ([]resource, error) {
+	resources := []resource{}
+	if project, err := client.Project(); err == nil {
+		base := resourceScheme + "projects/" + project.ID
+		resources = append(resources, resource{
+			URI:         base + "/tasks",
+			Name:        project.Name + " tasks",
+			Description: "The task board of " + project.Name + " as markdown",
+			MimeType:    markdownMIME,
+		})
+		var notes []model.Note
+		if err := client.list("/projects/"+project.ID+"/notes", nil, &notes); err != nil {
+			return nil, fmt.Errorf("failed to list notes: %w", err)
+		}
+		for _, n := range notes {
+			resources = append(resources, resource{
+				URI:         base + "/notes/" + url.PathEscape(n.ID),
+				Name:        n.Title,
+				Description: "A note in " + project.Name,
+				MimeType:    markdownMIME,
+			})
+		}
+	}
+
+	prompts, err := listPrompts(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prompts {
+		resources = append(resources, resource{
+			URI:         resourceScheme + "prompts/" + url.PathEscape(p.ID),
+			Name:        p.Title,
+			Description: promptDescription(p),
+			MimeType:    markdownMIME,
+		})
+	}
+	return resources, nil
+}
+
+// readResource returns the text of the resource at uri.
+func readResource(client *Client, uri string) (string, error) {
+	parts, ok := strings.CutPrefix(uri, resourceScheme)
+	if !ok {
+		return "", fmt.Errorf("unknown resource %s", uri)
+	}
+	segs := strings.Split(parts, "/")
+	for i, s := range segs {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil || unescaped == "" {
+			return "", fmt.Errorf("unknown resource %s", uri)
+		}
+		segs[i] = unescaped
+	}
+
+	switch {
+	case len(segs) == 3 && segs[0] == "projects" && segs[2] == "tasks":
+		var board model.Board
+		if err := client.do(http.MethodGet, "/projects/"+url.PathEscape(segs[1])+"/tasks/board", nil, &board); err != nil {
+			return "", fmt.Errorf("failed to get task board: %w", err)
+		}
+		return string(store.SerializeBoard(board)), nil
+
+	case len(segs) == 4 && segs[0] == "projects" && segs[2] == "notes":
+		note, ok, err := findNote(client, url.PathEscape(segs[1]), segs[3])
+		if err != nil {
+			return "", err
+		}
+		if !ok || note.ID != segs[3] {
+			return "", fmt.Errorf("note %s not found", segs[3])
+		}
+		return note.Content, nil
+
+	case len(segs) == 2 && segs[0] == "prompts":
+		var prompt model.Prompt
+		if err := client.do(http.MethodGet, "/prompts/"+url.PathEscape(segs[1]), nil, &prompt); err != nil {
+			return "", fmt.Errorf("failed to get prompt: %w", err)
+		}
+		return prompt.Content, nil
+	}
+	return "", fmt.Errorf("unknown resource %s", uri)
+}
+
+func handleResourcesList(req *jsonRPCRequest, client *Client) *jsonRPCResponse {
+	resources, err := listResources(client)
+	if err != nil {
+		return &jsonRPCResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      req.ID,
+			Error:   &jsonRPCError{Code: -32603, Message: err.Error()},
+		}
+	}
+	return &jsonRPCResponse{
+		JSONRPC: jsonrpcVersion,
+		ID:      req.ID,
+		Result:  resourcesListResult{Resources: resources},
+	}
+}
+
+func handleResourcesRead(req *jsonRPCRequest, client *Client) *jsonRPCResponse {
+	var params resourceParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return &jsonRPCResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      req.ID,
+			Error:   &jsonRPCError{Code: -32602, Message: "Invalid params"},
+		}
+	}
+	text, err := readResource(client, params.URI)
+	if err != nil {
+		return &jsonRPCResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      req.ID,
+			Error:   &jsonRPCError{Code: -32002, Message: err.Error()},
+		}
+	}
+	return &jsonRPCResponse{
+		JSONRPC: jsonrpcVersion,
+		ID:      req.ID,
+		Result: resourceReadResult{Contents: []resourceContents{
+			{URI: params.URI, MimeType: markdownMIME, Text: text},
+		}},
+	}
+}
+
+// subscriptions tracks the resources the client subscribed to and polls
+// them, calling notify with the URI of each one whose content changed.
+type subscriptions struct {
+	client *Client
+	notify func(uri string)
+
+	mu     sync.Mutex
+	hashes map[string][sha256.Size]byte // zero when the resource can't be read
+}
+
+func newSubscriptions(client *Client, notify func(uri string)) *subscriptions {
+	return &subscriptions{client: client, notify: notify, hashes: map[string][sha256.Size]byte{}}
+}
+
+// handleRequest answers resources/subscribe and resources/unsubscribe.
+func (s *subscriptions) handleRequest(req *jsonRPCRequest) *jsonRPCResponse {
+	var params resourceParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return &jsonRPCResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      req.ID,
+			Error:   &jsonRPCError{Code: -32602, Message: "Invalid params"},
+		}
+	}
+
+	if req.Method == "resources/unsubscribe" {
+		s.mu.Lock()
+		delete(s.hashes, params.URI)
+		s.mu.Unlock()
+	} else {
+		text, err := readResource(s.client, params.URI)
+		if err != nil {
+			return &jsonRPCResponse{
+				JSONRPC: jsonrpcVersion,
+				ID:      req.ID,
+				Error:   &jsonRPCError{Code: -32002, Message: err.Error()},
+			}
+		}
+		s.mu.Lock()
+		s.hashes[params.URI] = sha256.Sum256([]byte(text))
+		s.mu.Unlock()
+	}
+	return &jsonRPCResponse{
+		JSONRPC: jsonrpcVersion,
+		ID:      req.ID,
+		Result:  map[string]interface{}{},
+	}
+}
+
+// poll reads every subscribed resource once and notifies about the ones
+// that changed since the last read. A resource that can no longer be read
+// counts as changed once.
+func (s *subscriptions) poll() {
+	s.mu.Lock()
+	uris := make([]string, 0, len(s.hashes))
+	for uri := range s.hashes {
+		uris = append(uris, uri)
+	}
+	s.mu.Unlock()
+
+	for _, uri := range uris {
+		var hash [sha256.Size]byte
+		if text, err := readResource(s.client, uri); err == nil {
+			hash = sha256.Sum256([]byte(text))
+		}
+		s.mu.Lock()
+		old, subscribed := s.hashes[uri]
+		changed := subscribed && old != hash
+		if changed {
+			s.hashes[uri] = hash
+		}
+		s.mu.Unlock()
+		if changed {
+			s.notify(uri)
+		}
+	}
+}
+
+// watch polls every interval until done is closed.
+func (s *subscriptions) watch(done <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.poll()
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package mcpserve
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// call sends a request through handleRequest.
+func call(t *testing.T, method string, params any) *jsonRPCResponse {
+	t.Helper()
+	raw, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp := handleRequest(&jsonRPCRequest{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: method, Params: raw}, NewClient())
+	require.NotNil(t, resp)
+	return resp
+}
+
+func newResourcesAPI(t *testing.T) *fakeAPI {
+	api := newFakeAPI(t)
+	api.board = model.Board{Columns: []model.Column{{ID: "todo", Title: "To Do", Groups: []model.Group{{Tasks: []model.Task{{ID: "t1", Title: "Fix login"}}}}}}}
+	api.notes = []model.Note{{ID: "n1", Title: "plan", Content: "Ship it."}}
+	api.prompts = []model.Prompt{{ID: "pr1", Title: "Code review", Type: model.PromptTypeJinja, Content: "Review {{ file }}."}}
+	return api
+}
+
+func TestResources(t *testing.T) {
+	newResourcesAPI(t)
+
+	resp := call(t, "resources/list", nil)
+	require.Nil(t, resp.Error)
+	var uris []string
+	for _, r := range resp.Result.(resourcesListResult).Resources {
+		uris = append(uris, r.URI)
+	}
+	assert.Equal(t, []string{"clawide://projects/p1/tasks", "clawide://projects/p1/notes/n1", "clawide://prompts/pr1"}, uris)
+
+	for uri, want := range map[string]string{
+		"clawide://projects/p1/tasks":    "# To Do\n\n### Fix login <!-- id: t1 -->\n",
+		"clawide://projects/p1/notes/n1": "Ship it.",
+		"clawide://prompts/pr1":          "Review {{ file }}.",
+	} {
+		resp := call(t, "resources/read", resourceParams{URI: uri})
+		require.Nil(t, resp.Error, uri)
+		contents := resp.Result.(resourceReadResult).Contents
+		require.Len(t, contents, 1)
+		assert.Equal(t, uri, contents[0].URI)
+		assert.Contains(t, contents[0].Text, want, uri)
+	}
+
+	for _, uri := range []string{"clawide://projects/p1/notes/plan", "clawide://prompts/nope", "clawide://elsewhere", "https://example.com"} {
+		resp := call(t, "resources/read", resourceParams{URI: uri})
+		require.NotNil(t, resp.Error, uri)
+		assert.Equal(t, -32002, resp.Error.Code, uri)
+	}
+	assert.Equal(t, -32602, call(t, "resources/read", nil).Error.Code)
+}
+
+func TestResourcesOutsideProjects(t *testing.T) {
+	newResourcesAPI(t)
+	t.Setenv("CLAWIDE_PROJECT_ID", "")
+	t.Chdir(t.TempDir())
+
+	resp := call(t, "resources/list", nil)
+	require.Nil(t, resp.Error)
+	resources := resp.Result.(resourcesListResult).Resources
+	require.Len(t, resources, 1, "only prompts belong to no project")
+	assert.Equal(t, "clawide://prompts/pr1", resources[0].URI)
+}
+
+func TestSubscriptions(t *testing.T) {
+	api := newResourcesAPI(t)
+	var updated []string
+	subs := newSubscriptions(NewClient(), func(uri string) { updated = append(updated, uri) })
+	subscribe := func(method, uri string) *jsonRPCResponse {
+		raw, _ := json.Marshal(resourceParams{URI: uri})
+		return subs.handleRequest(&jsonRPCRequest{ID: json.RawMessage(`1`), Method: method, Params: raw})
+	}
+
+	require.Nil(t, subscribe("resources/subscribe", "clawide://projects/p1/notes/n1").Error)
+	require.Nil(t, subscribe("resources/subscribe", "clawide://prompts/pr1").Error)
+	assert.NotNil(t, subscribe("resources/subscribe", "clawide://prompts/nope").Error)
+
+	subs.poll()
+	assert.Empty(t, updated, "nothing changed")
+
+	api.notes[0].Content = "Ship it today."
+	api.prompts[0].Content = "Review {{ files }}."
+	subs.poll()
+	assert.ElementsMatch(t, []string{"clawide://projects/p1/notes/n1", "clawide://prompts/pr1"}, updated)
+
+	updated = nil
+	require.Nil(t, subscribe("resources/unsubscribe", "clawide://prompts/pr1").Error)
+	api.prompts[0].Content = "Review everything."
+	api.notes = nil
+	subs.poll()
+	subs.poll()
+	assert.Equal(t, []string{"clawide://projects/p1/notes/n1"}, updated, "a deleted note is reported once")
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/davydany/ClawIDE/internal/version"
 )
@@ -47,7 +48,9 @@ type initializeResult struct {
 }
 
 type capabilities struct {
-	Tools *toolsCapability `json:"tools,omitempty"`
+	Tools     *toolsCapability     `json:"tools,omitempty"`
+	Resources *resourcesCapability `json:"resources,omitempty"`
+	Prompts   *promptsCapability   `json:"prompts,omitempty"`
 }
 
 type toolsCapability struct {
@@ -90,6 +93,25 @@ type contentBlock struct {
 	Text string `json:"text"`
 }
 
+type jsonRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// syncEncoder lets the subscription watcher write notifications between
+// responses.
+type syncEncoder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (e *syncEncoder) Encode(v interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(v)
+}
+
 // Run starts the MCP stdio server. It reads JSON-RPC requests from stdin
 // and writes responses to stdout. All logging goes to stderr.
 func Run() {
@@ -102,7 +124,18 @@ func Run() {
 	// MCP messages can be large; increase buffer to 1MB
 	scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
 
-	encoder := json.NewEncoder(os.Stdout)
+	encoder := &syncEncoder{enc: json.NewEncoder(os.Stdout)}
+
+	subs := newSubscriptions(client, func(uri string) {
+		encoder.Encode(jsonRPCNotification{
+			JSONRPC: jsonrpcVersion,
+			Method:  "notifications/resources/updated",
+			Params:  resourceParams{URI: uri},
+		})
+	})
+	done := make(chan struct{})
+	defer close(done)
+	go subs.watch(done, pollInterval)
 
 	for scanner.Scan() {
 		line := scanner.Bytes()
@@ -117,7 +150,13 @@ func Run() {
 			continue
 		}
 
-		resp := handleRequest(&req, client)
+		var resp *jsonRPCResponse
+		switch req.Method {
+		case "resources/subscribe", "resources/unsubscribe":
+			resp = subs.handleRequest(&req)
+		default:
+			resp = handleRequest(&req, client)
+		}
 		if resp != nil {
 			if err := encoder.Encode(resp); err != nil {
 				log.Printf("Failed to write response: %v", err)
@@ -139,7 +178,9 @@ func handleRequest(req *jsonRPCRequest, client *Client) *jsonRPCResponse {
 			Result: initializeResult{
 				ProtocolVersion: protocolVersion,
 				Capabilities: capabilities{
-					Tools: &toolsCapability{},
+					Tools:     &toolsCapability{},
+					Resources: &resourcesCapability{Subscribe: true},
+					Prompts:   &promptsCapability{},
 				},
 				ServerInfo: serverInfo{
 					Name:    serverName,
@@ -162,6 +203,18 @@ func handleRequest(req *jsonRPCRequest, client *Client) *jsonRPCResponse {
 	case "tools/call":
 		return handleToolCall(req, client)
 
+	case "resources/list":
+		return handleResourcesList(req, client)
+
+	case "resources/read":
+		return handleResourcesRead(req, client)
+
+	case "prompts/list":
+		return handlePromptsList(req, client)
+
+	case "prompts/get":
+		return handlePromptsGet(req, client)
+
 	case "ping":
 		return &jsonRPCResponse{
 			JSONRPC: jsonrpcVersion,
@@ -208,7 +261,7 @@ func handleToolCall(req *jsonRPCRequest, client *Client) *jsonRPCResponse {
 	}
 }
 
-func writeError(encoder *json.Encoder, id json.RawMessage, code int, message string) {
+func writeError(encoder *syncEncoder, id json.RawMessage, code int, message string) {
 	encoder.Encode(&jsonRPCResponse{
 		JSONRPC: jsonrpcVersion,
 		ID:      id,
@@ -31,6 +31,9 @@ func TestHandleRequest_Initialize(t *testing.T) {
 	assert.Equal(t, protocolVersion, result.ProtocolVersion)
 	assert.Equal(t, "clawide", result.ServerInfo.Name)
 	assert.NotNil(t, result.Capabilities.Tools)
+	require.NotNil(t, result.Capabilities.Resources)
+	assert.True(t, result.Capabilities.Resources.Subscribe)
+	assert.NotNil(t, result.Capabilities.Prompts)
 }
 
 func TestHandleRequest_Initialized(t *testing.T) {
@@ -45,6 +48,10 @@ func TestHandleRequest_Initialized(t *testing.T) {
 }
 
 func TestHandleRequest_ToolsList(t *testing.T) {
+	mockServer := httptest.NewServer(http.NotFoundHandler())
+	defer mockServer.Close()
+	t.Setenv("CLAWIDE_API_URL", mockServer.URL)
+
 	client := NewClient()
 	req := &jsonRPCRequest{
 		JSONRPC: "2.0",
@@ -58,9 +65,15 @@ func TestHandleRequest_ToolsList(t *testing.T) {
 
 	result, ok := resp.Result.(toolsListResult)
 	require.True(t, ok)
-	require.Len(t, result.Tools, 1)
+	require.Len(t, result.Tools, 12)
 	assert.Equal(t, "clawide_notify", result.Tools[0].Name)
 	assert.Contains(t, result.Tools[0].InputSchema.Required, "title")
+	for _, tool := range result.Tools {
+		_, err := dispatchTool(tool.Name, map[string]interface{}{}, client)
+		if err != nil {
+			assert.NotContains(t, err.Error(), "unknown tool", "%s is listed but not dispatched", tool.Name)
+		}
+	}
 }
 
 func TestHandleRequest_Ping(t *testing.T) {
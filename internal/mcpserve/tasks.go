@@ -0,0 +1,126 @@
+package mcpserve
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// taskItem mirrors the tasks listed by GET /api/v1/projects/{id}/tasks.
+type taskItem struct {
+	model.Task
+	Column string `json:"column"`
+	Group  string `json:"group"`
+}
+
+func handleListTasks(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if column := stringArg(args, "column"); column != "" {
+		query.Set("column", column)
+	}
+	var tasks []taskItem
+	if err := client.list("/projects/"+project.ID+"/tasks", query, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return textResult("No tasks on the %s board.", project.Name), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tasks on the %s board (%d):\n", project.Name, len(tasks))
+	for _, t := range tasks {
+		place := t.Column
+		if t.Group != "" {
+			place += " / " + t.Group
+		}
+		fmt.Fprintf(&b, "\n- [%s] %s (id: %s)", place, t.Title, t.ID)
+		if len(t.Comments) > 0 {
+			fmt.Fprintf(&b, ", comments: %d", len(t.Comments))
+		}
+		if t.Description != "" {
+			fmt.Fprintf(&b, "\n  %s", strings.ReplaceAll(strings.TrimSpace(t.Description), "\n", "\n  "))
+		}
+	}
+	return textResult("%s", b.String()), nil
+}
+
+func handleCreateTask(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	title, err := requiredArg(args, "title")
+	if err != nil {
+		return nil, err
+	}
+	column := stringArg(args, "column")
+	if column == "" {
+		column = "backlog"
+	}
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]string{
+		"title":       title,
+		"description": stringArg(args, "description"),
+		"column":      column,
+		"group":       stringArg(args, "group"),
+	}
+	var task model.Task
+	if err := client.do(http.MethodPost, "/projects/"+project.ID+"/tasks", req, &task); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	return textResult("Added task %q (id: %s) to %s.", task.Title, task.ID, column), nil
+}
+
+func handleMoveTask(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	taskID, err := requiredArg(args, "task_id")
+	if err != nil {
+		return nil, err
+	}
+	column, err := requiredArg(args, "column")
+	if err != nil {
+		return nil, err
+	}
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]interface{}{
+		"to_column": column,
+		"to_group":  stringArg(args, "group"),
+		"to_index":  intArg(args, "position", -1), // negative appends
+	}
+	if err := client.do(http.MethodPost, "/projects/"+project.ID+"/tasks/"+url.PathEscape(taskID)+"/move", req, nil); err != nil {
+		return nil, fmt.Errorf("failed to move task: %w", err)
+	}
+	return textResult("Moved task %s to %s.", taskID, column), nil
+}
+
+func handleCommentTask(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	taskID, err := requiredArg(args, "task_id")
+	if err != nil {
+		return nil, err
+	}
+	body, err := requiredArg(args, "body")
+	if err != nil {
+		return nil, err
+	}
+	author := stringArg(args, "author")
+	if author == "" {
+		author = "claude"
+	}
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]string{"body": body, "author": author}
+	if err := client.do(http.MethodPost, "/projects/"+project.ID+"/tasks/"+url.PathEscape(taskID)+"/comments", req, nil); err != nil {
+		return nil, fmt.Errorf("failed to comment on task: %w", err)
+	}
+	return textResult("Commented on task %s.", taskID), nil
+}
@@ -3,6 +3,7 @@ package mcpserve
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 func getToolDefinitions() []toolDefinition {
@@ -34,13 +35,179 @@ func getToolDefinitions() []toolDefinition {
 				Required: []string{"title"},
 			},
 		},
+		{
+			Name:        "clawide_list_tasks",
+			Description: "List the tasks on the current ClawIDE project's task board, in board order, with their IDs, columns and groups.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"column": stringProp("Only list tasks in this column ID (e.g. 'backlog', 'in-progress', 'done')"),
+				},
+			},
+		},
+		{
+			Name:        "clawide_create_task",
+			Description: "Add a task to the current ClawIDE project's task board.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"title":       stringProp("Task title"),
+					"description": stringProp("Optional markdown description"),
+					"column":      stringProp("Column ID to add it to. Defaults to 'backlog'"),
+					"group":       stringProp("Optional group within the column"),
+				},
+				Required: []string{"title"},
+			},
+		},
+		{
+			Name:        "clawide_move_task",
+			Description: "Move a task on the current ClawIDE project's board to another column, group or position. Use clawide_list_tasks to find task and column IDs.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"task_id": stringProp("ID of the task to move"),
+					"column":  stringProp("Destination column ID (e.g. 'in-progress', 'done')"),
+					"group":   stringProp("Destination group within the column. Defaults to the ungrouped tasks"),
+					"position": map[string]interface{}{
+						"type":        "integer",
+						"description": "Zero-based position in the destination. Defaults to the end",
+					},
+				},
+				Required: []string{"task_id", "column"},
+			},
+		},
+		{
+			Name:        "clawide_comment_task",
+			Description: "Append a comment to a task on the current ClawIDE project's board, e.g. to record progress or findings.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"task_id": stringProp("ID of the task"),
+					"body":    stringProp("Comment text (markdown)"),
+					"author":  stringProp("Author shown on the comment. Defaults to 'claude'"),
+				},
+				Required: []string{"task_id", "body"},
+			},
+		},
+		{
+			Name:        "clawide_list_notes",
+			Description: "List the current ClawIDE project's notes by title.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"query": stringProp("Only list notes whose title or content contains this text"),
+				},
+			},
+		},
+		{
+			Name:        "clawide_read_note",
+			Description: "Read one of the current ClawIDE project's notes.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"note": stringProp("Note title or ID"),
+				},
+				Required: []string{"note"},
+			},
+		},
+		{
+			Name:        "clawide_write_note",
+			Description: "Write a note in the current ClawIDE project: replace or append to the note with this title, or create it.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"title":   stringProp("Note title; letters, digits, '.', '_' and '-' only"),
+					"content": stringProp("Markdown content"),
+					"append": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Append to the note's content instead of replacing it",
+					},
+				},
+				Required: []string{"title", "content"},
+			},
+		},
+		{
+			Name:        "clawide_create_feature",
+			Description: "Create a feature in the current ClawIDE project: a git branch with its own worktree and terminal session. Returns the worktree path.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"name":        stringProp("Feature name; also used for the branch name"),
+					"base_branch": stringProp("Branch to start from. Defaults to the project's current branch"),
+				},
+				Required: []string{"name"},
+			},
+		},
+		{
+			Name:        "clawide_docker_status",
+			Description: "Show the status of the current ClawIDE project's Docker Compose services.",
+			InputSchema: inputSchema{Type: "object"},
+		},
+		{
+			Name:        "clawide_open_bookmark",
+			Description: "Open one of the current ClawIDE project's bookmarks in the user's browser, in the tab showing ClawIDE.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"bookmark": stringProp("Bookmark name or ID"),
+				},
+				Required: []string{"bookmark"},
+			},
+		},
+		{
+			Name:        "clawide_recent_notifications",
+			Description: "Read the most recent ClawIDE notifications for the current project, newest first.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many to return. Defaults to 10",
+					},
+					"unread_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Only unread notifications",
+					},
+					"all_projects": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Include every project's notifications, not just the current one's",
+					},
+				},
+			},
+		},
 	}
 }
 
+func stringProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
 func dispatchTool(name string, args map[string]interface{}, client *Client) (*toolCallResult, error) {
 	switch name {
 	case "clawide_notify":
 		return handleNotify(args, client)
+	case "clawide_list_tasks":
+		return handleListTasks(args, client)
+	case "clawide_create_task":
+		return handleCreateTask(args, client)
+	case "clawide_move_task":
+		return handleMoveTask(args, client)
+	case "clawide_comment_task":
+		return handleCommentTask(args, client)
+	case "clawide_list_notes":
+		return handleListNotes(args, client)
+	case "clawide_read_note":
+		return handleReadNote(args, client)
+	case "clawide_write_note":
+		return handleWriteNote(args, client)
+	case "clawide_create_feature":
+		return handleCreateFeature(args, client)
+	case "clawide_docker_status":
+		return handleDockerStatus(args, client)
+	case "clawide_open_bookmark":
+		return handleOpenBookmark(args, client)
+	case "clawide_recent_notifications":
+		return handleRecentNotifications(args, client)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
@@ -99,6 +266,38 @@ func handleNotify(args map[string]interface{}, client *Client) (*toolCallResult,
 	}, nil
 }
 
+// textResult is a successful tool result with one text block.
+func textResult(format string, a ...any) *toolCallResult {
+	return &toolCallResult{Content: []contentBlock{{Type: "text", Text: fmt.Sprintf(format, a...)}}}
+}
+
+// stringArg returns a string argument, trimmed; requiredArg also fails when
+// it is empty.
+func stringArg(args map[string]interface{}, name string) string {
+	v, _ := args[name].(string)
+	return strings.TrimSpace(v)
+}
+
+func requiredArg(args map[string]interface{}, name string) (string, error) {
+	if v := stringArg(args, name); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%s is required", name)
+}
+
+// intArg returns an integer argument, which JSON delivers as a float64.
+func intArg(args map[string]interface{}, name string, def int) int {
+	if v, ok := args[name].(float64); ok {
+		return int(v)
+	}
+	return def
+}
+
+func boolArg(args map[string]interface{}, name string) bool {
+	v, _ := args[name].(bool)
+	return v
+}
+
 func getCWD() string {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -0,0 +1,254 @@
+package mcpserve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI serves the /api/v1 routes the tools use from canned data and
+// records the bodies it is sent.
+type fakeAPI struct {
+	*httptest.Server
+	projects      []model.Project
+	features      map[string][]model.Feature
+	tasks         []taskItem
+	notes         []model.Note
+	bookmarks     []model.Bookmark
+	notifications []model.Notification
+	docker        dockerStatus
+	board         model.Board
+	prompts       []model.Prompt
+
+	requests map[string]map[string]interface{} // "METHOD path" -> body
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+	api := &fakeAPI{features: map[string][]model.Feature{}, requests: map[string]map[string]interface{}{}}
+	page := func(w http.ResponseWriter, data any) {
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/projects", func(w http.ResponseWriter, r *http.Request) { page(w, api.projects) })
+	mux.HandleFunc("GET /api/v1/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range api.projects {
+			if p.ID == r.PathValue("id") {
+				json.NewEncoder(w).Encode(p)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"status":404,"code":"not_found","message":"project not found"}}`))
+	})
+	mux.HandleFunc("GET /api/v1/projects/{id}/features", func(w http.ResponseWriter, r *http.Request) {
+		page(w, api.features[r.PathValue("id")])
+	})
+	mux.HandleFunc("GET /api/v1/projects/{id}/tasks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "500", r.URL.Query().Get("limit"))
+		page(w, api.tasks)
+	})
+	mux.HandleFunc("GET /api/v1/projects/{id}/tasks/board", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(api.board)
+	})
+	mux.HandleFunc("GET /api/v1/projects/{id}/notes", func(w http.ResponseWriter, r *http.Request) { page(w, api.notes) })
+	mux.HandleFunc("GET /api/v1/projects/{id}/bookmarks", func(w http.ResponseWriter, r *http.Request) { page(w, api.bookmarks) })
+	mux.HandleFunc("GET /api/v1/projects/{id}/docker", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(api.docker)
+	})
+	mux.HandleFunc("GET /api/v1/notifications", func(w http.ResponseWriter, r *http.Request) { page(w, api.notifications) })
+	mux.HandleFunc("GET /api/v1/prompts", func(w http.ResponseWriter, r *http.Request) {
+		summaries := make([]model.Prompt, len(api.prompts))
+		for i, p := range api.prompts {
+			p.Content = ""
+			summaries[i] = p
+		}
+		page(w, summaries)
+	})
+	mux.HandleFunc("GET /api/v1/prompts/{promptID}", func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range api.prompts {
+			if p.ID == r.PathValue("promptID") {
+				json.NewEncoder(w).Encode(p)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"status":404,"code":"not_found","message":"prompt not found"}}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		api.requests[r.Method+" "+r.URL.Path] = body
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/projects/p1/tasks":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(model.Task{ID: "t9", Title: body["title"].(string)})
+		case "POST /api/v1/projects/p1/tasks/t1/move":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /api/v1/projects/p1/tasks/t1/comments":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case "POST /api/v1/projects/p1/notes":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(model.Note{ID: "n9", Title: body["title"].(string)})
+		case "PUT /api/v1/projects/p1/notes/n1":
+			json.NewEncoder(w).Encode(model.Note{ID: "n1", Title: "plan"})
+		case "POST /api/v1/projects/p1/features":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(model.Feature{ID: "f9", Name: "login", BranchName: "feature/login", BaseBranch: "main", WorktreePath: "/src/app-worktrees/feature/login"})
+		case "POST /api/v1/projects/p1/bookmarks/b2/open":
+			json.NewEncoder(w).Encode(api.bookmarks[1])
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"status":400,"code":"bad_request","message":"unexpected request"}}`))
+		}
+	})
+	api.Server = httptest.NewServer(mux)
+	t.Cleanup(api.Close)
+
+	api.projects = []model.Project{{ID: "p1", Name: "App", Path: "/src/app"}, {ID: "p2", Name: "Other", Path: "/src/other"}}
+	t.Setenv("CLAWIDE_API_URL", api.URL)
+	t.Setenv("CLAWIDE_PROJECT_ID", "p1")
+	return api
+}
+
+func callTool(t *testing.T, name string, args map[string]interface{}) string {
+	t.Helper()
+	result, err := dispatchTool(name, args, NewClient())
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	return result.Content[0].Text
+}
+
+func TestClientProject(t *testing.T) {
+	api := newFakeAPI(t)
+	root := t.TempDir()
+	api.projects = []model.Project{
+		{ID: "p1", Name: "App", Path: filepath.Join(root, "app")},
+		{ID: "p2", Name: "Nested", Path: filepath.Join(root, "app", "nested")},
+	}
+	api.features["p1"] = []model.Feature{{ID: "f1", WorktreePath: filepath.Join(root, "app-worktrees", "login")}}
+	client := NewClient()
+
+	p, err := client.Project()
+	require.NoError(t, err)
+	assert.Equal(t, "App", p.Name, "CLAWIDE_PROJECT_ID comes first")
+
+	t.Setenv("CLAWIDE_PROJECT_ID", "nope")
+	_, err = client.Project()
+	assert.ErrorContains(t, err, "project not found")
+
+	t.Setenv("CLAWIDE_PROJECT_ID", "")
+	for dir, want := range map[string]string{
+		filepath.Join(root, "app", "src"):             "p1",
+		filepath.Join(root, "app", "nested", "x"):     "p2",
+		filepath.Join(root, "app-worktrees", "login"): "p1",
+	} {
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		t.Chdir(dir)
+		p, err := client.Project()
+		require.NoError(t, err, dir)
+		assert.Equal(t, want, p.ID, dir)
+	}
+
+	t.Chdir(root)
+	_, err = client.Project()
+	assert.ErrorContains(t, err, "is in no ClawIDE project")
+}
+
+func TestTaskTools(t *testing.T) {
+	api := newFakeAPI(t)
+	api.tasks = []taskItem{
+		{Task: model.Task{ID: "t1", Title: "Fix login", Description: "Users are\nlogged out", Comments: []model.Comment{{Body: "seen"}}}, Column: "backlog"},
+		{Task: model.Task{ID: "t2", Title: "Ship"}, Column: "done", Group: "v1"},
+	}
+
+	text := callTool(t, "clawide_list_tasks", nil)
+	assert.Contains(t, text, "Tasks on the App board (2)")
+	assert.Contains(t, text, "- [backlog] Fix login (id: t1), comments: 1")
+	assert.Contains(t, text, "- [done / v1] Ship (id: t2)")
+
+	text = callTool(t, "clawide_create_task", map[string]interface{}{"title": "Write docs"})
+	assert.Equal(t, `Added task "Write docs" (id: t9) to backlog.`, text)
+	assert.Equal(t, "backlog", api.requests["POST /api/v1/projects/p1/tasks"]["column"])
+
+	callTool(t, "clawide_move_task", map[string]interface{}{"task_id": "t1", "column": "in-progress"})
+	assert.Equal(t, map[string]interface{}{"to_column": "in-progress", "to_group": "", "to_index": float64(-1)}, api.requests["POST /api/v1/projects/p1/tasks/t1/move"])
+	callTool(t, "clawide_move_task", map[string]interface{}{"task_id": "t1", "column": "done", "position": float64(0)})
+	assert.Equal(t, float64(0), api.requests["POST /api/v1/projects/p1/tasks/t1/move"]["to_index"])
+
+	callTool(t, "clawide_comment_task", map[string]interface{}{"task_id": "t1", "body": "Fixed in abc123"})
+	assert.Equal(t, map[string]interface{}{"body": "Fixed in abc123", "author": "claude"}, api.requests["POST /api/v1/projects/p1/tasks/t1/comments"])
+
+	_, err := dispatchTool("clawide_move_task", map[string]interface{}{"task_id": "t2", "column": "done"}, NewClient())
+	assert.ErrorContains(t, err, "failed to move task: ClawIDE API returned status 400: unexpected request")
+	_, err = dispatchTool("clawide_comment_task", map[string]interface{}{"task_id": "t1"}, NewClient())
+	assert.ErrorContains(t, err, "body is required")
+}
+
+func TestNoteTools(t *testing.T) {
+	api := newFakeAPI(t)
+	api.notes = []model.Note{{ID: "n1", Title: "plan", Content: "1. Design\n"}}
+
+	assert.Contains(t, callTool(t, "clawide_list_notes", nil), "- plan (id: n1")
+	assert.Equal(t, "# plan\n\n1. Design\n", callTool(t, "clawide_read_note", map[string]interface{}{"note": "n1"}))
+	_, err := dispatchTool("clawide_read_note", map[string]interface{}{"note": "missing"}, NewClient())
+	assert.ErrorContains(t, err, `no note "missing" in App`)
+
+	text := callTool(t, "clawide_write_note", map[string]interface{}{"title": "plan", "content": "2. Build", "append": true})
+	assert.Equal(t, "Updated note plan (id: n1).", text)
+	assert.Equal(t, "1. Design\n\n2. Build", api.requests["PUT /api/v1/projects/p1/notes/n1"]["content"])
+
+	text = callTool(t, "clawide_write_note", map[string]interface{}{"title": "findings", "content": "None yet"})
+	assert.Equal(t, "Created note findings (id: n9).", text)
+	assert.Equal(t, map[string]interface{}{"title": "findings", "content": "None yet"}, api.requests["POST /api/v1/projects/p1/notes"])
+}
+
+func TestWorkspaceTools(t *testing.T) {
+	api := newFakeAPI(t)
+
+	text := callTool(t, "clawide_create_feature", map[string]interface{}{"name": "login"})
+	assert.Contains(t, text, "on branch feature/login from main")
+	assert.Contains(t, text, "Worktree: /src/app-worktrees/feature/login")
+
+	assert.Equal(t, "App has no Docker Compose file.", callTool(t, "clawide_docker_status", nil))
+	api.docker = dockerStatus{DaemonRunning: true, ComposeFile: true, WebAppURL: "http://localhost:3000",
+		Services: []model.DockerService{{Service: "web", State: "running", Status: "Up 2 minutes", Health: "healthy", Ports: "3000->3000"}}}
+	text = callTool(t, "clawide_docker_status", nil)
+	assert.Contains(t, text, "- web: running (Up 2 minutes), healthy, ports 3000->3000")
+	assert.Contains(t, text, "Web app: http://localhost:3000")
+
+	api.bookmarks = []model.Bookmark{{ID: "b1", Name: "Staging"}, {ID: "b2", Name: "Docs", URL: "https://docs.example.com"}}
+	assert.Equal(t, "Opened Docs (https://docs.example.com) in ClawIDE.", callTool(t, "clawide_open_bookmark", map[string]interface{}{"bookmark": "docs"}))
+	_, err := dispatchTool("clawide_open_bookmark", map[string]interface{}{"bookmark": "Wiki"}, NewClient())
+	assert.ErrorContains(t, err, `no bookmark "Wiki" in App`)
+}
+
+func TestRecentNotifications(t *testing.T) {
+	api := newFakeAPI(t)
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	api.notifications = []model.Notification{
+		{Title: "Tests failed", Level: "error", Source: "ci", ProjectID: "p1", Count: 3, CreatedAt: at},
+		{Title: "Elsewhere", ProjectID: "p2", CreatedAt: at},
+		{Title: "Deployed", Level: "success", ProjectID: "p1", Read: true, Body: "v1.2", CreatedAt: at},
+	}
+
+	text := callTool(t, "clawide_recent_notifications", nil)
+	assert.Contains(t, text, "Most recent notifications (2)")
+	assert.Contains(t, text, "[error] Tests failed (×3) from ci, unread")
+	assert.Contains(t, text, "[success] Deployed\n  v1.2")
+	assert.NotContains(t, text, "Elsewhere")
+
+	text = callTool(t, "clawide_recent_notifications", map[string]interface{}{"all_projects": true, "limit": float64(2)})
+	assert.Contains(t, text, "Most recent notifications (2)")
+	assert.Contains(t, text, "Elsewhere")
+	assert.NotContains(t, text, "Deployed")
+}
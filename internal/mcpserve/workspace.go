@@ -0,0 +1,171 @@
+package mcpserve
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// dockerStatus mirrors the parts of GET /api/v1/projects/{id}/docker the
+// tool reports.
+type dockerStatus struct {
+	DaemonRunning   bool                  `json:"daemon_running"`
+	ComposeFile     bool                  `json:"compose_file"`
+	Services        []model.DockerService `json:"services"`
+	WebAppURL       string                `json:"web_app_url"`
+	MissingEnvFiles []string              `json:"missing_env_files"`
+	Error           string                `json:"error"`
+}
+
+func handleCreateFeature(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	name, err := requiredArg(args, "name")
+	if err != nil {
+		return nil, err
+	}
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]string{"name": name, "base_branch": stringArg(args, "base_branch")}
+	var feature model.Feature
+	if err := client.do(http.MethodPost, "/projects/"+project.ID+"/features", req, &feature); err != nil {
+		return nil, fmt.Errorf("failed to create feature: %w", err)
+	}
+	return textResult("Created feature %s (id: %s) on branch %s from %s.\nWorktree: %s",
+		feature.Name, feature.ID, feature.BranchName, feature.BaseBranch, feature.WorktreePath), nil
+}
+
+func handleDockerStatus(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	var status dockerStatus
+	if err := client.do(http.MethodGet, "/projects/"+project.ID+"/docker", nil, &status); err != nil {
+		return nil, fmt.Errorf("failed to get Docker status: %w", err)
+	}
+	switch {
+	case !status.ComposeFile:
+		return textResult("%s has no Docker Compose file.", project.Name), nil
+	case !status.DaemonRunning:
+		return textResult("The Docker daemon is not running."), nil
+	}
+
+	var b strings.Builder
+	if len(status.Services) == 0 {
+		fmt.Fprintf(&b, "No Docker Compose services of %s are running.", project.Name)
+	} else {
+		fmt.Fprintf(&b, "Docker Compose services of %s:\n", project.Name)
+		for _, s := range status.Services {
+			fmt.Fprintf(&b, "\n- %s: %s (%s)", s.Service, s.State, s.Status)
+			if s.Health != "" {
+				fmt.Fprintf(&b, ", %s", s.Health)
+			}
+			if s.Ports != "" {
+				fmt.Fprintf(&b, ", ports %s", s.Ports)
+			}
+		}
+	}
+	if status.WebAppURL != "" {
+		fmt.Fprintf(&b, "\n\nWeb app: %s", status.WebAppURL)
+	}
+	if len(status.MissingEnvFiles) > 0 {
+		fmt.Fprintf(&b, "\n\nMissing env files: %s", strings.Join(status.MissingEnvFiles, ", "))
+	}
+	if status.Error != "" {
+		fmt.Fprintf(&b, "\n\nError: %s", status.Error)
+	}
+	return textResult("%s", b.String()), nil
+}
+
+func handleOpenBookmark(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	name, err := requiredArg(args, "bookmark")
+	if err != nil {
+		return nil, err
+	}
+	project, err := client.Project()
+	if err != nil {
+		return nil, err
+	}
+	var bookmarks []model.Bookmark
+	if err := client.list("/projects/"+project.ID+"/bookmarks", nil, &bookmarks); err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks: %w", err)
+	}
+	var match []model.Bookmark
+	for _, b := range bookmarks {
+		if b.ID == name {
+			match = []model.Bookmark{b}
+			break
+		}
+		if strings.EqualFold(b.Name, name) {
+			match = append(match, b)
+		}
+	}
+	switch len(match) {
+	case 0:
+		return nil, fmt.Errorf("no bookmark %q in %s", name, project.Name)
+	case 1:
+	default:
+		return nil, fmt.Errorf("several bookmarks are named %q; use the ID", name)
+	}
+
+	var opened model.Bookmark
+	if err := client.do(http.MethodPost, "/projects/"+project.ID+"/bookmarks/"+url.PathEscape(match[0].ID)+"/open", nil, &opened); err != nil {
+		return nil, fmt.Errorf("failed to open bookmark: %w", err)
+	}
+	return textResult("Opened %s (%s) in ClawIDE.", opened.Name, opened.URL), nil
+}
+
+func handleRecentNotifications(args map[string]interface{}, client *Client) (*toolCallResult, error) {
+	limit := intArg(args, "limit", 10)
+	if limit < 1 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	var project model.Project
+	if !boolArg(args, "all_projects") {
+		var err error
+		if project, err = client.Project(); err != nil {
+			return nil, err
+		}
+	}
+	query := url.Values{}
+	if boolArg(args, "unread_only") {
+		query.Set("unread_only", "true")
+	}
+	var notifications []model.Notification
+	if err := client.list("/notifications", query, &notifications); err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, notif := range notifications {
+		if project.ID != "" && notif.ProjectID != project.ID {
+			continue
+		}
+		if n == limit {
+			break
+		}
+		n++
+		fmt.Fprintf(&b, "\n- %s [%s] %s", notif.CreatedAt.Format("2006-01-02 15:04"), notif.Level, notif.Title)
+		if notif.Count > 1 {
+			fmt.Fprintf(&b, " (×%d)", notif.Count)
+		}
+		if notif.Source != "" {
+			fmt.Fprintf(&b, " from %s", notif.Source)
+		}
+		if !notif.Read {
+			b.WriteString(", unread")
+		}
+		if notif.Body != "" {
+			fmt.Fprintf(&b, "\n  %s", strings.ReplaceAll(strings.TrimSpace(notif.Body), "\n", "\n  "))
+		}
+	}
+	if n == 0 {
+		return textResult("No notifications."), nil
+	}
+	return textResult("Most recent notifications (%d):\n%s", n, b.String()), nil
+}
@@ -0,0 +1,267 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// APIPrefix is where the versioned public API is mounted.
+const APIPrefix = "/api/v1"
+
+// Pagination defaults for list endpoints under /api/v1.
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// APIError is the body of every error response under /api/v1.
+type APIError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"` // snake_case HTTP status text, e.g. "not_found"
+	Message string `json:"message"`
+}
+
+type apiErrorBody struct {
+	Error APIError `json:"error"`
+}
+
+// WriteAPIError writes an /api/v1 error body.
+func WriteAPIError(w http.ResponseWriter, status int, message string) {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	code := strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	if code == "" {
+		code = "error"
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiErrorBody{Error: APIError{Status: status, Code: code, Message: message}})
+}
+
+// APIv1 adapts the handlers mounted under /api/v1, most of which also serve
+// the web UI: requests ask for JSON, and plain-text http.Error responses are
+// rewritten into APIError bodies (chi's 404 and 405 included).
+func APIv1(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Accept", "application/json")
+		ew := &errorWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		ew.finish()
+	})
+}
+
+// errorWriter passes successful responses through and buffers error
+// responses that are not already JSON, so they can be rewritten.
+type errorWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	status      int // set while buffering an error
+	buf         bytes.Buffer
+}
+
+func (w *errorWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if status >= 400 && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status != 0 {
+		return w.buf.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *errorWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *errorWriter) finish() {
+	if w.status != 0 {
+		WriteAPIError(w.ResponseWriter, w.status, strings.TrimSpace(w.buf.String()))
+	}
+}
+
+// Page is the envelope of paginated list responses.
+type Page struct {
+	Data       []json.RawMessage `json:"data"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// Pagination describes the slice of a list a Page holds.
+type Pagination struct {
+	Total      int  `json:"total"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset,omitempty"` // absent on the last page
+}
+
+// Paginate turns a handler that writes a JSON array into one that writes a
+// Page, applying the limit (default 50, at most 500) and offset query
+// parameters. Other responses pass through unchanged.
+func Paginate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, offset := DefaultPageLimit, 0
+		q := r.URL.Query()
+		if v := q.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > MaxPageLimit {
+				WriteAPIError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(MaxPageLimit))
+				return
+			}
+			limit = n
+		}
+		if v := q.Get("offset"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				WriteAPIError(w, http.StatusBadRequest, "offset must be a non-negative number")
+				return
+			}
+			offset = n
+		}
+
+		rec := &recorder{header: http.Header{}, status: http.StatusOK}
+		next(rec, r)
+
+		var items []json.RawMessage
+		if rec.status != http.StatusOK || json.Unmarshal(rec.body.Bytes(), &items) != nil {
+			for k, v := range rec.header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		page := Page{Data: []json.RawMessage{}, Pagination: Pagination{Total: len(items), Limit: limit, Offset: offset}}
+		if offset < len(items) {
+			end := min(offset+limit, len(items))
+			page.Data = items[offset:end]
+			if end < len(items) {
+				page.Pagination.NextOffset = &end
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// recorder buffers a whole response for Paginate.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header         { return r.header }
+func (r *recorder) WriteHeader(status int)      { r.status = status }
+func (r *recorder) Write(p []byte) (int, error) { return r.body.Write(p) }
+
+// JSONForm lets handlers that read form values accept a flat JSON object
+// instead: its strings, numbers and booleans become form values, and arrays
+// of them repeated values.
+func JSONForm(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			next(w, r)
+			return
+		}
+		var fields map[string]any
+		dec := json.NewDecoder(io.LimitReader(r.Body, maxScopeBody))
+		dec.UseNumber()
+		if err := dec.Decode(&fields); err != nil && err != io.EOF {
+			WriteAPIError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+		form := url.Values{}
+		for k, v := range fields {
+			values, ok := formValues(v)
+			if !ok {
+				WriteAPIError(w, http.StatusBadRequest, k+" must be a string, number, boolean or array of them")
+				return
+			}
+			form[k] = values
+		}
+		r.PostForm = form
+		r.Form = url.Values{}
+		for k, v := range r.URL.Query() {
+			r.Form[k] = v
+		}
+		for k, v := range form {
+			r.Form[k] = append(v, r.Form[k]...)
+		}
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		next(w, r)
+	}
+}
+
+func formValues(v any) ([]string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return []string{v}, true
+	case json.Number:
+		return []string{v.String()}, true
+	case bool:
+		return []string{strconv.FormatBool(v)}, true
+	case []any:
+		var out []string
+		for _, e := range v {
+			s, ok := formValues(e)
+			if !ok || len(s) > 1 {
+				return nil, false
+			}
+			out = append(out, s...)
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// ProjectQuery hands the {id} project of an /api/v1 route to handlers that
+// take the project as a project_id query parameter or body field (tasks,
+// notes, bookmarks). Any project_id in a JSON body is replaced, so the
+// project ProjectLoader authorized is the one used.
+func ProjectQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		projectID := chi.URLParam(r, "id")
+		q := r.URL.Query()
+		q.Set("project_id", projectID)
+		r.URL.RawQuery = q.Encode()
+
+		if r.Body != nil && !isSafeMethod(r.Method) {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxScopeBody))
+			if err != nil {
+				WriteAPIError(w, http.StatusBadRequest, "reading request body")
+				return
+			}
+			var fields map[string]json.RawMessage
+			if json.Unmarshal(body, &fields) == nil && fields != nil {
+				fields["project_id"], _ = json.Marshal(projectID)
+				body, _ = json.Marshal(fields)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/davydany/ClawIDE/internal/auth"
+)
+
+// publicPaths are reachable without a session: the login page itself, the
+// login endpoint, the favicon and the push service worker script.
+var publicPaths = map[string]bool{
+	"/login":          true,
+	"/api/auth/login": true,
+	"/favicon.ico":    true,
+	"/sw.js":          true,
+}
+
+const principalKey contextKey = "principal"
+
+// RequireAuth rejects requests that carry neither a valid session cookie nor
+// a bearer token, and stores the caller's principal in the request context.
+// Browser page loads are redirected to /login; htmx requests get an
+// HX-Redirect; /api/v1 gets an APIError; everything else (JSON API,
+// WebSocket upgrades, SSE) gets a plain 401.
+func RequireAuth(a *auth.Manager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := a.Identify(r); ok {
+				ctx := context.WithValue(r.Context(), principalKey, p)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			if publicPaths[r.URL.Path] || hasPublicPrefix(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			loginURL := "/login?next=" + url.QueryEscape(r.URL.RequestURI())
+			switch {
+			case r.Header.Get("HX-Request") == "true":
+				w.Header().Set("HX-Redirect", loginURL)
+				w.WriteHeader(http.StatusUnauthorized)
+			case r.Method == http.MethodGet && wantsHTML(r):
+				http.Redirect(w, r, loginURL, http.StatusSeeOther)
+			case strings.HasPrefix(r.URL.Path, APIPrefix+"/"):
+				w.Header().Set("WWW-Authenticate", `Bearer realm="clawide"`)
+				WriteAPIError(w, http.StatusUnauthorized, "authentication required")
+			default:
+				w.Header().Set("WWW-Authenticate", `Bearer realm="clawide"`)
+				http.Error(w, "authentication required", http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+// publicPrefixes are path prefixes reachable without a session. Share links
+// carry their own token and are validated by the handler.
+var publicPrefixes = []string{"/static/", "/share/", "/ws/share/"}
+
+func hasPublicPrefix(path string) bool {
+	for _, p := range publicPrefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsHTML reports whether the request is a top-level browser navigation.
+func wantsHTML(r *http.Request) bool {
+	if r.Header.Get("Upgrade") != "" {
+		return false
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// GetPrincipal returns the caller set by RequireAuth. Requests that never
+// passed through RequireAuth (handler unit tests, internal calls) are
+// treated as the admin, matching the behaviour with auth disabled.
+func GetPrincipal(r *http.Request) auth.Principal {
+	if p, ok := r.Context().Value(principalKey).(auth.Principal); ok {
+		return p
+	}
+	return auth.Admin
+}
+
+// RequireAdmin limits instance-wide routes (settings, updates, user
+// management) to the admin.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !GetPrincipal(r).IsAdmin() {
+			http.Error(w, "admin access required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
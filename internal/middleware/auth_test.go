@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/auth"
+	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuthManager(t *testing.T, password string) *auth.Manager {
+	t.Helper()
+	dir := t.TempDir()
+	tokens, err := store.NewTokenStore(filepath.Join(dir, "tokens.json"))
+	require.NoError(t, err)
+	users, err := store.NewUserStore(filepath.Join(dir, "users.json"))
+	require.NoError(t, err)
+	m, err := auth.NewManager(&config.Config{DataDir: dir, Password: password}, tokens, users)
+	require.NoError(t, err)
+	return m
+}
+
+func TestRequireAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("disabled passes through", func(t *testing.T) {
+		handler := RequireAuth(setupAuthManager(t, ""))(ok)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws/terminal/s/p", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	handler := RequireAuth(setupAuthManager(t, "s3cret-password"))(ok)
+
+	t.Run("public paths are reachable", func(t *testing.T) {
+		for _, path := range []string{"/login", "/api/auth/login", "/static/js/app.js"} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, http.StatusOK, w.Code, path)
+		}
+	})
+
+	t.Run("browser navigation redirects to login", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/projects/p1/", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login?next=%2Fprojects%2Fp1%2F", w.Header().Get("Location"))
+	})
+
+	t.Run("htmx request gets HX-Redirect", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/projects/", nil)
+		req.Header.Set("HX-Request", "true")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotEmpty(t, w.Header().Get("HX-Redirect"))
+	})
+
+	t.Run("websocket upgrade without credentials is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ws/terminal/s/p", nil)
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestRequireAdmin(t *testing.T) {
+	handler := RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/settings", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/settings", nil)
+	req = req.WithContext(context.WithValue(req.Context(), principalKey, auth.Principal{UserID: "u1", Username: "alice"}))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/davydany/ClawIDE/internal/model"
@@ -9,8 +12,18 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-const projectKey contextKey = "project"
+const (
+	projectKey     contextKey = "project"
+	projectRoleKey contextKey = "project_role"
+)
+
+// maxScopeBody bounds how much of a JSON body ProjectScope buffers while
+// looking for project_id.
+const maxScopeBody = 10 << 20
 
+// ProjectLoader loads the {id} project into the request context and enforces
+// the caller's role in it: projects the caller is not a member of are
+// reported as not found, and viewers may only use safe methods.
 func ProjectLoader(st *store.Store) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +39,18 @@ func ProjectLoader(st *store.Store) func(http.Handler) http.Handler {
 				return
 			}
 
+			role := ProjectRoleFor(r, project)
+			if role == model.RoleNone {
+				http.Error(w, "project not found", http.StatusNotFound)
+				return
+			}
+			if !role.Allows(model.RoleCollaborator) && !isSafeMethod(r.Method) {
+				http.Error(w, "viewers have read-only access to this project", http.StatusForbidden)
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), projectKey, project)
+			ctx = context.WithValue(ctx, projectRoleKey, role)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -36,3 +60,130 @@ func GetProject(r *http.Request) model.Project {
 	p, _ := r.Context().Value(projectKey).(model.Project)
 	return p
 }
+
+// GetProjectRole returns the caller's role in the project loaded by
+// ProjectLoader.
+func GetProjectRole(r *http.Request) model.ProjectRole {
+	role, _ := r.Context().Value(projectRoleKey).(model.ProjectRole)
+	return role
+}
+
+// ProjectRoleFor returns the caller's role in project. The admin owns every project.
+func ProjectRoleFor(r *http.Request, project model.Project) model.ProjectRole {
+	p := GetPrincipal(r)
+	if p.Project != "" && p.Project != project.ID {
+		return model.RoleNone
+	}
+	if p.IsAdmin() {
+		return model.RoleOwner
+	}
+	return project.RoleFor(p.UserID)
+}
+
+// RequireProjectRole rejects callers whose role in the loaded project is
+// below min. It must run after ProjectLoader.
+func RequireProjectRole(min model.ProjectRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !GetProjectRole(r).Allows(min) {
+				http.Error(w, "requires the "+string(min)+" role", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ProjectOwned checks that the {sid}, {fid} and {jid} URL parameters name a
+// session, feature and scheduled job of the project loaded by ProjectLoader,
+// reporting others as not found. Handlers look these up by their own IDs, so
+// without it a member of one project could reach another's through its URL.
+// It must run where the parameters are known: in a subrouter mounted at a
+// pattern that has them, or per route with chi's With.
+func ProjectOwned(st *store.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			project := GetProject(r)
+			if sid := chi.URLParam(r, "sid"); sid != "" {
+				if sess, ok := st.GetSession(sid); !ok || sess.ProjectID != project.ID {
+					http.Error(w, "session not found", http.StatusNotFound)
+					return
+				}
+			}
+			if fid := chi.URLParam(r, "fid"); fid != "" {
+				if f, ok := st.GetFeature(fid); !ok || f.ProjectID != project.ID {
+					http.Error(w, "feature not found", http.StatusNotFound)
+					return
+				}
+			}
+			if jid := chi.URLParam(r, "jid"); jid != "" {
+				if job, ok := st.GetScheduledJob(jid); !ok || job.ProjectID != project.ID {
+					http.Error(w, "scheduled job not found", http.StatusNotFound)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ProjectScope guards APIs that take the project as a project_id query
+// parameter or JSON body field instead of a URL segment (tasks, notes,
+// bookmarks). The same rules as ProjectLoader apply; requests without a
+// project_id address the admin's global scope and are admin-only.
+func ProjectScope(st *store.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetPrincipal(r).IsAdmin() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Handlers read project_id from the query, the body, or both, so
+			// every value present must be authorized.
+			var projectIDs []string
+			if id := r.URL.Query().Get("project_id"); id != "" {
+				projectIDs = append(projectIDs, id)
+			}
+			if r.Body != nil && !isSafeMethod(r.Method) {
+				body, err := io.ReadAll(io.LimitReader(r.Body, maxScopeBody))
+				if err != nil {
+					http.Error(w, "reading request body", http.StatusBadRequest)
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				var scoped struct {
+					ProjectID string `json:"project_id"`
+				}
+				if json.Unmarshal(body, &scoped) == nil && scoped.ProjectID != "" {
+					projectIDs = append(projectIDs, scoped.ProjectID)
+				}
+			}
+			if len(projectIDs) == 0 {
+				http.Error(w, "admin access required", http.StatusForbidden)
+				return
+			}
+
+			for _, projectID := range projectIDs {
+				project, ok := st.GetProject(projectID)
+				role := model.RoleNone
+				if ok {
+					role = ProjectRoleFor(r, project)
+				}
+				if role == model.RoleNone {
+					http.Error(w, "project not found", http.StatusNotFound)
+					return
+				}
+				if !role.Allows(model.RoleCollaborator) && !isSafeMethod(r.Method) {
+					http.Error(w, "viewers have read-only access to this project", http.StatusForbidden)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -2,11 +2,14 @@ package middleware
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/davydany/ClawIDE/internal/auth"
 	"github.com/davydany/ClawIDE/internal/model"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/go-chi/chi/v5"
@@ -92,3 +95,140 @@ func TestGetProject(t *testing.T) {
 		assert.Equal(t, model.Project{}, p)
 	})
 }
+
+func withPrincipal(req *http.Request, p auth.Principal) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), principalKey, p))
+}
+
+func TestProjectLoader_Roles(t *testing.T) {
+	st := setupTestStore(t)
+	require.NoError(t, st.AddProject(model.Project{
+		ID: "proj-1", Name: "Test", Path: "/test",
+		Members: []model.ProjectMember{
+			{UserID: "viewer", Role: model.RoleViewer},
+			{UserID: "collab", Role: model.RoleCollaborator},
+		},
+	}))
+
+	var gotRole model.ProjectRole
+	handler := ProjectLoader(st)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRole = GetProjectRole(r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	ownerOnly := ProjectLoader(st)(RequireProjectRole(model.RoleOwner)(handler))
+
+	serve := func(h http.Handler, method, userID string) int {
+		req := httptest.NewRequest(method, "/projects/proj-1", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "proj-1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		if userID != "" {
+			req = withPrincipal(req, auth.Principal{UserID: userID})
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodDelete, ""), "admin owns every project")
+	assert.Equal(t, model.RoleOwner, gotRole)
+
+	assert.Equal(t, http.StatusNotFound, serve(handler, http.MethodGet, "stranger"))
+
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "viewer"))
+	assert.Equal(t, model.RoleViewer, gotRole)
+	assert.Equal(t, http.StatusForbidden, serve(handler, http.MethodPut, "viewer"))
+
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodPost, "collab"))
+	assert.Equal(t, http.StatusForbidden, serve(ownerOnly, http.MethodPost, "collab"))
+
+	t.Run("a principal confined to another project has no role", func(t *testing.T) {
+		project, _ := st.GetProject("proj-1")
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.Equal(t, model.RoleCollaborator, ProjectRoleFor(withPrincipal(req, auth.Principal{UserID: "collab", Project: "proj-1"}), project))
+		assert.Equal(t, model.RoleNone, ProjectRoleFor(withPrincipal(req, auth.Principal{UserID: "collab", Project: "proj-2"}), project))
+	})
+}
+
+func TestProjectOwned(t *testing.T) {
+	st := setupTestStore(t)
+	collab := []model.ProjectMember{{UserID: "collab", Role: model.RoleCollaborator}}
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "One", Path: "/one", Members: collab}))
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-2", Name: "Two", Path: "/two"}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s1", ProjectID: "proj-1"}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s2", ProjectID: "proj-2"}))
+	require.NoError(t, st.AddFeature(model.Feature{ID: "f1", ProjectID: "proj-1"}))
+	require.NoError(t, st.AddFeature(model.Feature{ID: "f2", ProjectID: "proj-2"}))
+	require.NoError(t, st.AddScheduledJob(model.ScheduledJob{ID: "j1", ProjectID: "proj-1"}))
+	require.NoError(t, st.AddScheduledJob(model.ScheduledJob{ID: "j2", ProjectID: "proj-2"}))
+
+	// Mounted as in the server's routes.
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router := chi.NewRouter()
+	router.Route("/projects/{id}", func(r chi.Router) {
+		r.Use(ProjectLoader(st))
+		r.Route("/sessions/{sid}", func(r chi.Router) {
+			r.Use(ProjectOwned(st))
+			r.Delete("/", ok)
+		})
+		r.Route("/features/{fid}", func(r chi.Router) {
+			r.Use(ProjectOwned(st))
+			r.Put("/api/file", ok)
+		})
+		r.With(ProjectOwned(st)).Post("/api/scheduled-jobs/{jid}/start", ok)
+	})
+
+	serve := func(method, target string) int {
+		req := withPrincipal(httptest.NewRequest(method, target, nil), auth.Principal{UserID: "collab"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodDelete, "/projects/proj-1/sessions/s1"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/projects/proj-1/sessions/s2"), "another project's session")
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/projects/proj-1/sessions/missing"))
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/projects/proj-1/features/f1/api/file"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, "/projects/proj-1/features/f2/api/file"), "another project's feature")
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/projects/proj-1/api/scheduled-jobs/j1/start"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/projects/proj-1/api/scheduled-jobs/j2/start"), "another project's job")
+}
+
+func TestProjectScope(t *testing.T) {
+	st := setupTestStore(t)
+	require.NoError(t, st.AddProject(model.Project{
+		ID: "proj-1", Path: "/a",
+		Members: []model.ProjectMember{{UserID: "collab", Role: model.RoleCollaborator}},
+	}))
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-2", Path: "/b"}))
+
+	var gotBody string
+	handler := ProjectScope(st)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, target, body, userID string) int {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if userID != "" {
+			req = withPrincipal(req, auth.Principal{UserID: userID})
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/notes", "", ""), "admin may use the global scope")
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/notes", "", "collab"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/notes?project_id=proj-1", "", "collab"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/notes?project_id=proj-2", "", "collab"))
+
+	body := `{"project_id":"proj-1","title":"x"}`
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/notes", body, "collab"))
+	assert.Equal(t, body, gotBody, "body is restored for the handler")
+
+	// A permitted query parameter must not smuggle in a foreign body project.
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/api/notes?project_id=proj-1", `{"project_id":"proj-2"}`, "collab"))
+}
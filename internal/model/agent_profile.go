@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// AgentProfile describes how to launch an agent CLI in an agent pane.
+// Built-in profiles are derived from the aicli provider catalog; custom
+// profiles are stored in agent_profiles.json.
+type AgentProfile struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider,omitempty"` // aicli provider ID; empty for other CLIs
+	Command   string            `json:"command"`
+	Args      string            `json:"args,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	ModelFlag string            `json:"model_flag,omitempty"` // e.g. "--model"; empty passes the model as a plain argument
+	Env       map[string]string `json:"env,omitempty"`
+	Builtin   bool              `json:"builtin,omitempty"`
+	Installed bool              `json:"installed"` // computed when listed, not stored
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// APIToken is a per-device bearer token for the REST API, WebSockets and CLI
+// clients. Only the SHA-256 hash of the secret is persisted; the plaintext is
+// shown once at creation time.
+type APIToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id,omitempty"` // empty for the instance admin
+	Prefix     string     `json:"prefix"`
+	TokenHash  string     `json:"token_hash"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
@@ -21,10 +21,21 @@ type Feature struct {
 	BaseBranch   string    `json:"base_branch"`
 	WorktreePath string    `json:"worktree_path"`
 	Color        string    `json:"color"`
+	AgentProfile string    `json:"agent_profile,omitempty"` // overrides the project's agent profile
+	Preview      *Preview  `json:"preview,omitempty"`       // dev server proxied at /preview/{id}/
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// Preview is a feature's dev server, reached through ClawIDE's own origin at
+// /preview/{feature id}/.
+type Preview struct {
+	Port       int    `json:"port"`                  // local port the dev server listens on
+	KeepPrefix bool   `json:"keep_prefix,omitempty"` // forward /preview/{id} as is, for servers configured with that base path
+	BaseHref   bool   `json:"base_href,omitempty"`   // inject <base href> into HTML pages
+	BookmarkID string `json:"bookmark_id,omitempty"` // the bookmark bar link to the preview
+}
+
 // IsClone returns true if the workspace is backed by a full git clone
 // rather than a worktree.
 func (f Feature) IsClone() bool {
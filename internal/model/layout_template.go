@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LayoutTemplate is a named, reusable pane layout. Its Layout is a PaneNode
+// tree without pane IDs; each session created from it gets fresh panes.
+// Templates with an empty ProjectID are global.
+type LayoutTemplate struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Layout      *PaneNode `json:"layout"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TemplateFrom returns a copy of the layout with its pane IDs and tmux names
+// removed, keeping split geometry, pane names, types and startup commands.
+func TemplateFrom(n *PaneNode) *PaneNode {
+	if n == nil {
+		return nil
+	}
+	c := n.Clone()
+	c.stripIDs()
+	return c
+}
+
+func (n *PaneNode) stripIDs() {
+	if n == nil {
+		return
+	}
+	n.PaneID = ""
+	n.TmuxName = ""
+	n.First.stripIDs()
+	n.Second.stripIDs()
+}
+
+// Instantiate returns a copy of a template layout with a new pane ID for
+// every leaf.
+func (t LayoutTemplate) Instantiate() *PaneNode {
+	c := t.Layout.Clone()
+	c.assignIDs()
+	return c
+}
+
+func (n *PaneNode) assignIDs() {
+	if n == nil {
+		return
+	}
+	if n.Type == "leaf" {
+		n.PaneID = uuid.New().String()
+		n.TmuxName = "clawide-" + n.PaneID
+		return
+	}
+	n.First.assignIDs()
+	n.Second.assignIDs()
+}
+
+// ValidateTemplateLayout checks that a template tree is well formed: splits
+// have two children, a known direction and a ratio in 0.1-0.9, and leaves
+// have a known pane type.
+func ValidateTemplateLayout(n *PaneNode) error {
+	if n == nil {
+		return fmt.Errorf("layout is required")
+	}
+	switch n.Type {
+	case "leaf":
+		if n.PaneType != "" && n.PaneType != PaneTypeAgent && n.PaneType != PaneTypeShell {
+			return fmt.Errorf("pane_type must be 'agent' or 'shell'")
+		}
+		return nil
+	case "split":
+		if n.Direction != "horizontal" && n.Direction != "vertical" {
+			return fmt.Errorf("direction must be 'horizontal' or 'vertical'")
+		}
+		if n.Ratio < 0.1 || n.Ratio > 0.9 {
+			return fmt.Errorf("ratio must be between 0.1 and 0.9")
+		}
+		if n.First == nil || n.Second == nil {
+			return fmt.Errorf("split must have two children")
+		}
+		if err := ValidateTemplateLayout(n.First); err != nil {
+			return err
+		}
+		return ValidateTemplateLayout(n.Second)
+	default:
+		return fmt.Errorf("node type must be 'leaf' or 'split'")
+	}
+}
@@ -16,4 +16,12 @@ type Notification struct {
 	IdempotencyKey string    `json:"idempotency_key,omitempty"`
 	Read           bool      `json:"read"`
 	CreatedAt      time.Time `json:"created_at"`
+
+	// Set by the notification rules.
+	GroupKey  string    `json:"group_key,omitempty"` // notifications with the same key are listed together
+	Group     string    `json:"group,omitempty"`     // readable name of the group
+	Count     int       `json:"count,omitempty"`     // times this repeat was seen, when above 1
+	Escalated bool      `json:"escalated,omitempty"` // raised to error because it kept repeating
+	Held      bool      `json:"held,omitempty"`      // arrived during quiet hours and not yet announced
+	UpdatedAt time.Time `json:"updated_at,omitzero"` // last repeat
 }
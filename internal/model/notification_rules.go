@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Fields notifications can be grouped by.
+const (
+	GroupByProject = "project"
+	GroupBySource  = "source"
+	GroupByFeature = "feature"
+)
+
+// NotificationRules decide what happens to a notification between being
+// raised and reaching the bell. The zero value changes nothing.
+type NotificationRules struct {
+	// GroupBy lists the fields whose values form a notification's group
+	// key, in the order of GroupByProject, GroupByFeature, GroupBySource.
+	GroupBy []string `json:"group_by"`
+	// RepeatWindow is how many seconds apart two notifications with the same
+	// title, source, project and feature still count as repeats. 0 turns
+	// collapsing and escalation off.
+	RepeatWindow int `json:"repeat_window"`
+	// Collapse folds repeats into the earlier notification, counting them,
+	// instead of adding a new one.
+	Collapse bool `json:"collapse"`
+	// EscalateAfter raises a notification to error once it has been seen
+	// this many times within the repeat window. 0 disables.
+	EscalateAfter int `json:"escalate_after"`
+
+	MutedSources  []string `json:"muted_sources"`
+	MutedProjects []string `json:"muted_projects"`
+
+	QuietHours QuietHours `json:"quiet_hours"`
+}
+
+// QuietHours is a daily local-time window in which only errors are
+// announced. Start and End are "HH:MM"; a window may span midnight.
+type QuietHours struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// Validate checks the rules' values.
+func (r NotificationRules) Validate() error {
+	for _, g := range r.GroupBy {
+		if g != GroupByProject && g != GroupBySource && g != GroupByFeature {
+			return fmt.Errorf("group_by: unknown field %q", g)
+		}
+	}
+	if r.RepeatWindow < 0 || r.RepeatWindow > 86400 {
+		return fmt.Errorf("repeat_window must be between 0 and 86400 seconds")
+	}
+	if r.EscalateAfter < 0 || r.EscalateAfter == 1 {
+		return fmt.Errorf("escalate_after must be 0 or at least 2")
+	}
+	if r.QuietHours.Enabled {
+		if _, err := parseClock(r.QuietHours.Start); err != nil {
+			return fmt.Errorf("quiet_hours.start: %w", err)
+		}
+		if _, err := parseClock(r.QuietHours.End); err != nil {
+			return fmt.Errorf("quiet_hours.end: %w", err)
+		}
+	}
+	return nil
+}
+
+// Muted reports whether n's source or project is muted.
+func (r NotificationRules) Muted(n Notification) bool {
+	return slices.Contains(r.MutedSources, n.Source) ||
+		(n.ProjectID != "" && slices.Contains(r.MutedProjects, n.ProjectID))
+}
+
+// GroupKey returns n's group key, or "" when the rules don't group.
+func (r NotificationRules) GroupKey(n Notification) string {
+	var parts []string
+	for _, g := range []string{GroupByProject, GroupByFeature, GroupBySource} {
+		if !slices.Contains(r.GroupBy, g) {
+			continue
+		}
+		switch g {
+		case GroupByProject:
+			parts = append(parts, g+":"+n.ProjectID)
+		case GroupByFeature:
+			parts = append(parts, g+":"+n.FeatureID)
+		case GroupBySource:
+			parts = append(parts, g+":"+n.Source)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Contains reports whether t falls within the quiet hours.
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+	start, err1 := parseClock(q.Start)
+	end, err2 := parseClock(q.End)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// parseClock returns the minutes after midnight of an "HH:MM" time.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 1, 1, hour, minute, 0, 0, time.Local) }
+
+	overnight := QuietHours{Enabled: true, Start: "22:00", End: "07:30"}
+	assert.True(t, overnight.Contains(at(22, 0)))
+	assert.True(t, overnight.Contains(at(3, 0)))
+	assert.True(t, overnight.Contains(at(7, 29)))
+	assert.False(t, overnight.Contains(at(7, 30)))
+	assert.False(t, overnight.Contains(at(12, 0)))
+
+	lunch := QuietHours{Enabled: true, Start: "12:00", End: "13:00"}
+	assert.True(t, lunch.Contains(at(12, 30)))
+	assert.False(t, lunch.Contains(at(13, 0)))
+
+	lunch.Enabled = false
+	assert.False(t, lunch.Contains(at(12, 30)))
+}
+
+func TestNotificationRulesValidate(t *testing.T) {
+	assert.NoError(t, NotificationRules{}.Validate())
+	assert.NoError(t, NotificationRules{GroupBy: []string{"project", "source"}, RepeatWindow: 60, EscalateAfter: 3}.Validate())
+	assert.Error(t, NotificationRules{GroupBy: []string{"level"}}.Validate())
+	assert.Error(t, NotificationRules{RepeatWindow: -1}.Validate())
+	assert.Error(t, NotificationRules{EscalateAfter: 1}.Validate())
+	assert.Error(t, NotificationRules{QuietHours: QuietHours{Enabled: true, Start: "25:00", End: "07:00"}}.Validate())
+	// Unchecked while disabled, so a half-filled form can be saved.
+	assert.NoError(t, NotificationRules{QuietHours: QuietHours{Start: "bad"}}.Validate())
+}
@@ -11,15 +11,21 @@ const (
 // PaneNode represents a node in the binary tree of pane splits.
 // A node is either a Leaf (single terminal pane) or a Split (two children).
 type PaneNode struct {
-	Type      string    `json:"type"`                 // "leaf" or "split"
-	PaneID    string    `json:"pane_id,omitempty"`    // leaf only
-	TmuxName  string    `json:"tmux_name,omitempty"`  // leaf only: "clawide-{PaneID}"
-	Name      string    `json:"name,omitempty"`        // leaf only: user-assigned display name
-	PaneType  string    `json:"pane_type,omitempty"`   // leaf only: "agent" or "shell"
-	Direction string    `json:"direction,omitempty"`   // split only: "horizontal" or "vertical"
-	Ratio     float64   `json:"ratio,omitempty"`       // split only: 0.1-0.9
-	First     *PaneNode `json:"first,omitempty"`       // split only
-	Second    *PaneNode `json:"second,omitempty"`      // split only
+	Type           string            `json:"type"`                      // "leaf" or "split"
+	PaneID         string            `json:"pane_id,omitempty"`         // leaf only
+	TmuxName       string            `json:"tmux_name,omitempty"`       // leaf only: "clawide-{PaneID}"
+	Name           string            `json:"name,omitempty"`            // leaf only: user-assigned display name
+	PaneType       string            `json:"pane_type,omitempty"`       // leaf only: "agent" or "shell"
+	Record         bool              `json:"record,omitempty"`          // leaf only: record output to an asciicast file
+	Command        string            `json:"command,omitempty"`         // leaf only: run when the pane's shell first starts
+	WorkDir        string            `json:"work_dir,omitempty"`        // leaf only: overrides the session dir; relative to it
+	Env            map[string]string `json:"env,omitempty"`             // leaf only: extra variables for the pane's shell
+	AgentProfile   string            `json:"agent_profile,omitempty"`   // leaf only: agent profile, overriding feature and project
+	ResourceLimits *ResourceLimits   `json:"resource_limits,omitempty"` // leaf only: overrides the project's limits one by one
+	Direction      string            `json:"direction,omitempty"`       // split only: "horizontal" or "vertical"
+	Ratio          float64           `json:"ratio,omitempty"`           // split only: 0.1-0.9
+	First          *PaneNode         `json:"first,omitempty"`           // split only
+	Second         *PaneNode         `json:"second,omitempty"`          // split only
 }
 
 // NewLeafPane creates a new leaf pane node with the given pane ID.
@@ -120,13 +126,27 @@ func (n *PaneNode) Clone() *PaneNode {
 		return nil
 	}
 	c := &PaneNode{
-		Type:      n.Type,
-		PaneID:    n.PaneID,
-		TmuxName:  n.TmuxName,
-		Name:      n.Name,
-		PaneType:  n.PaneType,
-		Direction: n.Direction,
-		Ratio:     n.Ratio,
+		Type:         n.Type,
+		PaneID:       n.PaneID,
+		TmuxName:     n.TmuxName,
+		Name:         n.Name,
+		PaneType:     n.PaneType,
+		Record:       n.Record,
+		Command:      n.Command,
+		WorkDir:      n.WorkDir,
+		AgentProfile: n.AgentProfile,
+		Direction:    n.Direction,
+		Ratio:        n.Ratio,
+	}
+	if n.ResourceLimits != nil {
+		limits := *n.ResourceLimits
+		c.ResourceLimits = &limits
+	}
+	if n.Env != nil {
+		c.Env = make(map[string]string, len(n.Env))
+		for k, v := range n.Env {
+			c.Env[k] = v
+		}
 	}
 	if n.First != nil {
 		c.First = n.First.Clone()
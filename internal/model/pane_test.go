@@ -531,3 +531,64 @@ func TestHasPane(t *testing.T) {
 	assert.True(t, tree.HasPane("other"))
 	assert.False(t, tree.HasPane("missing"))
 }
+
+func TestLayoutTemplateRoundTrip(t *testing.T) {
+	agent := NewAgentPane("a1")
+	agent.Name = "claude"
+	shell := NewLeafPane("s1")
+	shell.Command = "npm run dev"
+	shell.Record = true
+	layout := &PaneNode{Type: "split", Direction: "horizontal", Ratio: 0.6, First: agent, Second: shell}
+
+	tpl := TemplateFrom(layout)
+	require.NoError(t, ValidateTemplateLayout(tpl))
+	assert.Empty(t, tpl.First.PaneID)
+	assert.Empty(t, tpl.Second.TmuxName)
+	assert.Equal(t, "claude", tpl.First.Name)
+	assert.Equal(t, "npm run dev", tpl.Second.Command)
+	assert.Equal(t, "a1", layout.First.PaneID, "source layout is not modified")
+
+	inst := LayoutTemplate{Layout: tpl}.Instantiate()
+	leaves := inst.CollectLeaves()
+	require.Len(t, leaves, 2)
+	assert.NotEqual(t, "a1", leaves[0])
+	assert.NotEqual(t, leaves[0], leaves[1])
+	assert.Equal(t, "clawide-"+leaves[1], inst.Second.TmuxName)
+	assert.Equal(t, PaneTypeAgent, inst.First.PaneType)
+	assert.True(t, inst.Second.Record)
+	assert.Equal(t, 0.6, inst.Ratio)
+	assert.Empty(t, tpl.First.PaneID, "template is not modified")
+}
+
+func TestValidateTemplateLayout(t *testing.T) {
+	assert.Error(t, ValidateTemplateLayout(nil))
+	assert.Error(t, ValidateTemplateLayout(&PaneNode{Type: "leaf", PaneType: "bogus"}))
+	assert.Error(t, ValidateTemplateLayout(&PaneNode{Type: "split", Direction: "horizontal", Ratio: 0.5, First: &PaneNode{Type: "leaf"}}))
+	assert.Error(t, ValidateTemplateLayout(&PaneNode{Type: "split", Direction: "diagonal", Ratio: 0.5}))
+	assert.Error(t, ValidateTemplateLayout(&PaneNode{Type: "split", Direction: "vertical", Ratio: 1.5}))
+	assert.Error(t, ValidateTemplateLayout(&PaneNode{Type: "other"}))
+	assert.NoError(t, ValidateTemplateLayout(&PaneNode{Type: "split", Direction: "vertical", Ratio: 0.5,
+		First: &PaneNode{Type: "leaf"}, Second: &PaneNode{Type: "leaf", PaneType: PaneTypeShell}}))
+}
+
+func TestResourceLimitsMerge(t *testing.T) {
+	project := ResourceLimits{MaxRSSMB: 1024, RSSAction: LimitActionNotify, MaxCPUPercent: 200, CPUSeconds: 60, CPUAction: LimitActionInterrupt}
+	pane := ResourceLimits{MaxRSSMB: 4096, RSSAction: LimitActionKill, MaxWallSeconds: 300, WallAction: LimitActionInterrupt}
+
+	got := project.Merge(pane)
+	assert.Equal(t, ResourceLimits{
+		MaxRSSMB: 4096, RSSAction: LimitActionKill,
+		MaxCPUPercent: 200, CPUSeconds: 60, CPUAction: LimitActionInterrupt,
+		MaxWallSeconds: 300, WallAction: LimitActionInterrupt,
+	}, got)
+	assert.True(t, ResourceLimits{RSSAction: LimitActionKill}.IsZero())
+}
+
+func TestCloneCopiesResourceLimits(t *testing.T) {
+	n := NewLeafPane("p1")
+	n.ResourceLimits = &ResourceLimits{MaxRSSMB: 512}
+	c := n.Clone()
+	require.NotNil(t, c.ResourceLimits)
+	c.ResourceLimits.MaxRSSMB = 1
+	assert.Equal(t, 512, n.ResourceLimits.MaxRSSMB)
+}
@@ -27,6 +27,9 @@ type Project struct {
 	ActiveBranch    string          `json:"active_branch,omitempty"`
 	SortOrder       int             `json:"sort_order"`
 	TaskStorage     TaskStorageMode `json:"task_storage,omitempty"`
+	Members         []ProjectMember `json:"members,omitempty"`
+	AgentProfile    string          `json:"agent_profile,omitempty"` // default for the project's agent panes
+	ResourceLimits  *ResourceLimits `json:"resource_limits,omitempty"` // default for the project's panes
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 }
@@ -41,6 +44,16 @@ func (p Project) TaskStorageDir(globalDataDir string) string {
 	return p.Path
 }
 
+// RoleFor returns the role userID holds in the project, or RoleNone.
+func (p Project) RoleFor(userID string) ProjectRole {
+	for _, m := range p.Members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return RoleNone
+}
+
 // TrashedProject holds a soft-deleted project. The project directory has been
 // moved into the ClawIDE trash folder on disk; OriginalPath records where to
 // put it back on restore. Entries are auto-purged after 30 days.
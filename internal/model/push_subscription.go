@@ -0,0 +1,31 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// PushSubscription is one browser's Web Push subscription. Levels and
+// ProjectIDs limit which notifications it receives; empty means all.
+type PushSubscription struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id,omitempty"` // "" for the admin
+	Endpoint   string    `json:"endpoint"`
+	P256dh     string    `json:"p256dh"`
+	Auth       string    `json:"auth"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Levels     []string  `json:"levels,omitempty"`
+	ProjectIDs []string  `json:"project_ids,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Matches reports whether a notification with the given level and project
+// passes the subscription's filters. Notifications without a project pass
+// the project filter.
+func (s PushSubscription) Matches(level, projectID string) bool {
+	if len(s.Levels) > 0 && !slices.Contains(s.Levels, level) {
+		return false
+	}
+	return projectID == "" || len(s.ProjectIDs) == 0 || slices.Contains(s.ProjectIDs, projectID)
+}
@@ -0,0 +1,66 @@
+package model
+
+import "fmt"
+
+// Actions taken when a pane goes over a resource limit.
+const (
+	LimitActionNotify    = "notify"    // raise a notification only
+	LimitActionInterrupt = "interrupt" // send Ctrl-C to the pane
+	LimitActionKill      = "kill"      // kill the pane's whole process tree
+)
+
+// DefaultCPUSeconds is how long a pane must stay over MaxCPUPercent before
+// the CPU limit fires, when CPUSeconds is not set.
+const DefaultCPUSeconds = 60
+
+// ResourceLimits caps what a pane's process tree may use. A zero maximum
+// means no limit. Each limit has its own action, defaulting to notify.
+type ResourceLimits struct {
+	MaxRSSMB       int     `json:"max_rss_mb,omitempty"` // resident memory of the whole tree
+	RSSAction      string  `json:"rss_action,omitempty"`
+	MaxCPUPercent  float64 `json:"max_cpu_percent,omitempty"` // percent of one core
+	CPUSeconds     int     `json:"cpu_seconds,omitempty"`     // how long CPU must stay over the max
+	CPUAction      string  `json:"cpu_action,omitempty"`
+	MaxWallSeconds int     `json:"max_wall_seconds,omitempty"` // shell panes: how long one command may run
+	WallAction     string  `json:"wall_action,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l ResourceLimits) IsZero() bool {
+	return l.MaxRSSMB == 0 && l.MaxCPUPercent == 0 && l.MaxWallSeconds == 0
+}
+
+// Validate checks the limits and fills in default actions and CPUSeconds.
+func (l *ResourceLimits) Validate() error {
+	if l.MaxRSSMB < 0 || l.MaxCPUPercent < 0 || l.CPUSeconds < 0 || l.MaxWallSeconds < 0 {
+		return fmt.Errorf("limits cannot be negative")
+	}
+	for _, a := range []*string{&l.RSSAction, &l.CPUAction, &l.WallAction} {
+		switch *a {
+		case "":
+			*a = LimitActionNotify
+		case LimitActionNotify, LimitActionInterrupt, LimitActionKill:
+		default:
+			return fmt.Errorf("unknown limit action %q (want notify, interrupt or kill)", *a)
+		}
+	}
+	if l.MaxCPUPercent > 0 && l.CPUSeconds == 0 {
+		l.CPUSeconds = DefaultCPUSeconds
+	}
+	return nil
+}
+
+// Merge returns l with every limit that override sets replaced by
+// override's, so a pane can tighten or loosen single limits of its project.
+func (l ResourceLimits) Merge(override ResourceLimits) ResourceLimits {
+	if override.MaxRSSMB > 0 {
+		l.MaxRSSMB, l.RSSAction = override.MaxRSSMB, override.RSSAction
+	}
+	if override.MaxCPUPercent > 0 {
+		l.MaxCPUPercent, l.CPUSeconds, l.CPUAction = override.MaxCPUPercent, override.CPUSeconds, override.CPUAction
+	}
+	if override.MaxWallSeconds > 0 {
+		l.MaxWallSeconds, l.WallAction = override.MaxWallSeconds, override.WallAction
+	}
+	return l
+}
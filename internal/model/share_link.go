@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ShareLink grants read-only access to a single terminal pane until
+// ExpiresAt. Only the SHA-256 of the token is stored.
+type ShareLink struct {
+	ID        string    `json:"id"`
+	TokenHash string    `json:"token_hash"`
+	ProjectID string    `json:"project_id"`
+	SessionID string    `json:"session_id"`
+	PaneID    string    `json:"pane_id"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the link is no longer valid at now.
+func (l ShareLink) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
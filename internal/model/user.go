@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// User is a non-admin login for shared ClawIDE instances. The instance admin
+// is not a User: it signs in with the password stored in config.json and has
+// owner rights on every project.
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ProjectRole is a user's permission level within one project.
+type ProjectRole string
+
+const (
+	// RoleNone means the user cannot see the project at all.
+	RoleNone ProjectRole = ""
+	// RoleViewer can browse files and watch terminals but not change anything.
+	RoleViewer ProjectRole = "viewer"
+	// RoleCollaborator can do everything except delete the project or manage members.
+	RoleCollaborator ProjectRole = "collaborator"
+	// RoleOwner has full control over the project.
+	RoleOwner ProjectRole = "owner"
+)
+
+var roleRank = map[ProjectRole]int{
+	RoleNone:         0,
+	RoleViewer:       1,
+	RoleCollaborator: 2,
+	RoleOwner:        3,
+}
+
+// Valid reports whether r is one of the assignable roles.
+func (r ProjectRole) Valid() bool {
+	return r == RoleViewer || r == RoleCollaborator || r == RoleOwner
+}
+
+// Allows reports whether r grants at least the permissions of min.
+func (r ProjectRole) Allows(min ProjectRole) bool {
+	return roleRank[r] >= roleRank[min]
+}
+
+// ProjectMember grants a User a role in a Project.
+type ProjectMember struct {
+	UserID string      `json:"user_id"`
+	Role   ProjectRole `json:"role"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Webhook event types.
+const (
+	WebhookEventNotification    = "notification.created"
+	WebhookEventFeatureCreated  = "feature.created"
+	WebhookEventFeatureMerged   = "feature.merged"
+	WebhookEventFeatureDeleted  = "feature.deleted"
+	WebhookEventScheduledJobRun = "scheduled_job.run"
+	WebhookEventUpdateAvailable = "update.available"
+)
+
+// WebhookEventTypes lists every event type a webhook can subscribe to.
+var WebhookEventTypes = []string{
+	WebhookEventNotification,
+	WebhookEventFeatureCreated,
+	WebhookEventFeatureMerged,
+	WebhookEventFeatureDeleted,
+	WebhookEventScheduledJobRun,
+	WebhookEventUpdateAvailable,
+}
+
+// Webhook posts events to an outside URL. Each filter that is empty matches
+// everything; otherwise the event's value must be in it.
+type Webhook struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"` // HMAC-SHA256 key for X-ClawIDE-Signature
+	Enabled    bool      `json:"enabled"`
+	Events     []string  `json:"events,omitempty"`
+	Levels     []string  `json:"levels,omitempty"`
+	Sources    []string  `json:"sources,omitempty"`
+	ProjectIDs []string  `json:"project_ids,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Matches reports whether the webhook is enabled and its filters accept an
+// event with the given type, level, source and project.
+func (w Webhook) Matches(eventType, level, source, projectID string) bool {
+	return w.Enabled &&
+		filterMatches(w.Events, eventType) &&
+		filterMatches(w.Levels, level) &&
+		filterMatches(w.Sources, source) &&
+		filterMatches(w.ProjectIDs, projectID)
+}
+
+func filterMatches(filter []string, v string) bool {
+	return len(filter) == 0 || slices.Contains(filter, v)
+}
+
+// WebhookDelivery records one attempt to deliver an event to a webhook.
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	Retrying   bool      `json:"retrying"` // another attempt is scheduled
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,333 @@
+// Package notify is the single way notifications enter ClawIDE. It applies
+// the notification rules (mute, grouping, collapsing repeats, escalation and
+// quiet hours) before a notification is stored and broadcast.
+package notify
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/sse"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/google/uuid"
+)
+
+// ReleaseInterval is how often the end of quiet hours is checked for.
+const ReleaseInterval = time.Minute
+
+// Outcome says what Post did with a notification.
+type Outcome int
+
+const (
+	// Delivered: stored and broadcast.
+	Delivered Outcome = iota
+	// Held: stored, but announced only when quiet hours end.
+	Held
+	// Collapsed: counted as a repeat of an earlier notification.
+	Collapsed
+	// Escalated: a repeat raised to error and broadcast again.
+	Escalated
+	// Muted: dropped because its source or project is muted.
+	Muted
+	// Duplicate: its idempotency key was already used.
+	Duplicate
+)
+
+// Center stores and broadcasts notifications through the rules.
+type Center struct {
+	notifications *store.NotificationStore
+	rules         *store.NotificationRulesStore // nil applies no rules
+	hub           *sse.Hub                      // nil stores without broadcasting
+	projects      *store.Store                  // names groups; may be nil
+
+	now func() time.Time // swapped out in tests
+
+	mu   sync.Mutex // serializes Post so repeats are seen
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a Center. Call Start to release held notifications when
+// quiet hours end.
+func New(notifications *store.NotificationStore, rules *store.NotificationRulesStore, hub *sse.Hub, projects *store.Store) *Center {
+	return &Center{
+		notifications: notifications,
+		rules:         rules,
+		hub:           hub,
+		projects:      projects,
+		now:           time.Now,
+	}
+}
+
+// Rules returns the rules in effect.
+func (c *Center) Rules() model.NotificationRules {
+	if c.rules == nil {
+		return model.NotificationRules{}
+	}
+	return c.rules.Get()
+}
+
+// SetRules validates and saves new rules. They apply to the next Post.
+func (c *Center) SetRules(rules model.NotificationRules) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+	if c.rules == nil {
+		return fmt.Errorf("notification rules are not configurable")
+	}
+	return c.rules.Set(rules)
+}
+
+// Post applies the rules to n, then stores and broadcasts it as they
+// decide. It returns the notification as stored: for a collapsed repeat,
+// the earlier notification it was folded into.
+func (c *Center) Post(n model.Notification) (model.Notification, Outcome, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n.IdempotencyKey != "" {
+		if existing, ok := c.notifications.FindByIdempotencyKey(n.IdempotencyKey); ok {
+			return existing, Duplicate, nil
+		}
+	}
+	rules := c.Rules()
+	if rules.Muted(n) {
+		return n, Muted, nil
+	}
+	now := c.now()
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = now
+	}
+	if n.GroupKey = rules.GroupKey(n); n.GroupKey != "" {
+		n.Group = c.groupName(rules, n)
+	}
+	quiet := rules.QuietHours.Contains(now)
+
+	if rules.RepeatWindow > 0 {
+		window := time.Duration(rules.RepeatWindow) * time.Second
+		if repeats := c.notifications.Repeats(n, now.Add(-window)); len(repeats) > 0 {
+			if rules.Collapse {
+				return c.collapse(rules, repeats[0], n, now)
+			}
+			seen := len(repeats) + 1
+			if rules.EscalateAfter > 0 && seen >= rules.EscalateAfter && n.Level != "error" {
+				n.Level = "error"
+				n.Escalated = true
+			}
+		}
+	}
+
+	if quiet && n.Level != "error" {
+		n.Held = true
+		return n, Held, c.notifications.Add(n)
+	}
+	if err := c.notifications.Add(n); err != nil {
+		return n, Delivered, err
+	}
+	c.broadcast(&n)
+	if n.Escalated {
+		return n, Escalated, nil
+	}
+	return n, Delivered, nil
+}
+
+// collapse folds n into prev, the latest notification it repeats. Only a
+// repeat that escalates prev is broadcast again; as an error it is not held
+// by quiet hours.
+func (c *Center) collapse(rules model.NotificationRules, prev, n model.Notification, now time.Time) (model.Notification, Outcome, error) {
+	prev.Count = max(prev.Count, 1) + 1
+	prev.Body = n.Body
+	prev.PaneID = n.PaneID
+	prev.SessionID = n.SessionID
+	prev.GroupKey = n.GroupKey
+	prev.Group = n.Group
+	prev.UpdatedAt = now
+	prev.Read = false
+
+	outcome := Collapsed
+	if rules.EscalateAfter > 0 && prev.Count >= rules.EscalateAfter && prev.Level != "error" {
+		prev.Level = "error"
+		prev.Escalated = true
+		prev.Held = false
+		outcome = Escalated
+	}
+	if err := c.notifications.Update(prev); err != nil {
+		return prev, outcome, err
+	}
+	if outcome == Escalated {
+		c.broadcast(&prev)
+	}
+	return prev, outcome, nil
+}
+
+// groupName describes n's group for people, using project and feature names
+// where they are known.
+func (c *Center) groupName(rules model.NotificationRules, n model.Notification) string {
+	var parts []string
+	for _, g := range []string{model.GroupByProject, model.GroupByFeature, model.GroupBySource} {
+		if !slices.Contains(rules.GroupBy, g) {
+			continue
+		}
+		switch g {
+		case model.GroupByProject:
+			name := n.ProjectID
+			if c.projects != nil && n.ProjectID != "" {
+				if p, ok := c.projects.GetProject(n.ProjectID); ok {
+					name = p.Name
+				}
+			}
+			if name == "" {
+				name = "No project"
+			}
+			parts = append(parts, name)
+		case model.GroupByFeature:
+			if n.FeatureID == "" {
+				continue
+			}
+			name := n.FeatureID
+			if c.projects != nil {
+				if f, ok := c.projects.GetFeature(n.FeatureID); ok {
+					name = f.Name
+				}
+			}
+			parts = append(parts, name)
+		case model.GroupBySource:
+			parts = append(parts, n.Source)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (c *Center) broadcast(n *model.Notification) {
+	if c.hub != nil {
+		c.hub.Broadcast(n)
+	}
+}
+
+// ReleaseHeld announces the notifications held during quiet hours, once
+// they are over. Each project's are announced separately, so only its
+// members see them: a single one is broadcast as is, several as one summary.
+func (c *Center) ReleaseHeld() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Rules().QuietHours.Contains(c.now()) {
+		return
+	}
+	held, err := c.notifications.ReleaseHeld()
+	if err != nil {
+		log.Printf("[notify] releasing held notifications: %v", err)
+	}
+
+	var projectIDs []string
+	byProject := map[string][]model.Notification{}
+	for _, n := range held {
+		if _, ok := byProject[n.ProjectID]; !ok {
+			projectIDs = append(projectIDs, n.ProjectID)
+		}
+		byProject[n.ProjectID] = append(byProject[n.ProjectID], n)
+	}
+	for _, id := range projectIDs {
+		c.releaseProject(id, byProject[id])
+	}
+}
+
+// releaseProject announces the held notifications of one project (or of
+// none, for projectID "").
+func (c *Center) releaseProject(projectID string, held []model.Notification) {
+	if len(held) == 1 {
+		c.broadcast(&held[0])
+		return
+	}
+
+	title := fmt.Sprintf("%d notifications during quiet hours", len(held))
+	if c.projects != nil && projectID != "" {
+		if p, ok := c.projects.GetProject(projectID); ok {
+			title = fmt.Sprintf("%d notifications in %s during quiet hours", len(held), p.Name)
+		}
+	}
+	summary := model.Notification{
+		ID:        uuid.New().String(),
+		Title:     title,
+		Body:      summarize(held),
+		Source:    "clawide",
+		Level:     highestLevel(held),
+		ProjectID: projectID,
+		CreatedAt: c.now(),
+	}
+	if err := c.notifications.Add(summary); err != nil {
+		log.Printf("[notify] quiet hours summary: %v", err)
+		return
+	}
+	c.broadcast(&summary)
+}
+
+// summarize lists the most frequent titles among held notifications.
+func summarize(held []model.Notification) string {
+	counts := map[string]int{}
+	var titles []string
+	for _, n := range held {
+		if counts[n.Title] == 0 {
+			titles = append(titles, n.Title)
+		}
+		counts[n.Title] += max(n.Count, 1)
+	}
+	sort.SliceStable(titles, func(i, j int) bool { return counts[titles[i]] > counts[titles[j]] })
+	var parts []string
+	for i, t := range titles {
+		if i == 3 {
+			parts = append(parts, fmt.Sprintf("and %d more", len(titles)-3))
+			break
+		}
+		if counts[t] > 1 {
+			t = fmt.Sprintf("%s (×%d)", t, counts[t])
+		}
+		parts = append(parts, t)
+	}
+	return strings.Join(parts, ", ")
+}
+
+var levelRank = map[string]int{"info": 0, "success": 1, "warning": 2, "error": 3}
+
+func highestLevel(held []model.Notification) string {
+	level := "info"
+	for _, n := range held {
+		if levelRank[n.Level] > levelRank[level] {
+			level = n.Level
+		}
+	}
+	return level
+}
+
+// Start releases held notifications every ReleaseInterval until Stop.
+func (c *Center) Start() {
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(ReleaseInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.ReleaseHeld()
+			}
+		}
+	}()
+}
+
+// Stop ends the loop started by Start.
+func (c *Center) Stop() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+}
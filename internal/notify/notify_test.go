@@ -0,0 +1,232 @@
+package notify
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/sse"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCenter struct {
+	*Center
+	store      *store.NotificationStore
+	broadcasts <-chan *model.Notification
+	clock      time.Time
+}
+
+func newTestCenter(t *testing.T, rules model.NotificationRules) *testCenter {
+	t.Helper()
+	dir := t.TempDir()
+	st, err := store.New(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+	require.NoError(t, st.AddProject(model.Project{ID: "p1", Name: "Website"}))
+	notifs, err := store.NewNotificationStore(filepath.Join(dir, "notifications.json"), 100)
+	require.NoError(t, err)
+	rulesSt, err := store.NewNotificationRulesStore(filepath.Join(dir, "notification_rules.json"))
+	require.NoError(t, err)
+	hub := sse.NewHub()
+
+	tc := &testCenter{
+		store:      notifs,
+		broadcasts: hub.Subscribe("test"),
+		clock:      time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local),
+	}
+	tc.Center = New(notifs, rulesSt, hub, st)
+	tc.now = func() time.Time { return tc.clock }
+	require.NoError(t, tc.SetRules(rules))
+	return tc
+}
+
+func (tc *testCenter) post(t *testing.T, title, level string) (model.Notification, Outcome) {
+	t.Helper()
+	n, outcome, err := tc.Post(model.Notification{ID: title + tc.clock.String(), Title: title, Source: "claude", Level: level, ProjectID: "p1"})
+	require.NoError(t, err)
+	return n, outcome
+}
+
+func (tc *testCenter) drain() int {
+	count := 0
+	for {
+		select {
+		case <-tc.broadcasts:
+			count++
+		default:
+			return count
+		}
+	}
+}
+
+func TestPost_NoRules(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{})
+	_, outcome := tc.post(t, "Done", "info")
+	assert.Equal(t, Delivered, outcome)
+	_, outcome = tc.post(t, "Done", "info")
+	assert.Equal(t, Delivered, outcome)
+	assert.Len(t, tc.store.GetAll(), 2)
+	assert.Equal(t, 2, tc.drain())
+}
+
+func TestPost_Duplicate(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{})
+	n := model.Notification{ID: "a", Title: "Update", IdempotencyKey: "k"}
+	_, outcome, err := tc.Post(n)
+	require.NoError(t, err)
+	assert.Equal(t, Delivered, outcome)
+	n.ID = "b"
+	got, outcome, err := tc.Post(n)
+	require.NoError(t, err)
+	assert.Equal(t, Duplicate, outcome)
+	assert.Equal(t, "a", got.ID)
+	assert.Equal(t, 1, tc.drain())
+}
+
+func TestPost_Mute(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{MutedSources: []string{"claude"}})
+	_, outcome := tc.post(t, "Done", "error")
+	assert.Equal(t, Muted, outcome)
+	assert.Empty(t, tc.store.GetAll())
+	assert.Equal(t, 0, tc.drain())
+
+	require.NoError(t, tc.SetRules(model.NotificationRules{MutedProjects: []string{"p1"}}))
+	_, outcome = tc.post(t, "Done", "error")
+	assert.Equal(t, Muted, outcome)
+	_, outcome, _ = tc.Post(model.Notification{ID: "x", Title: "Global"})
+	assert.Equal(t, Delivered, outcome, "notifications without a project are not muted by project")
+}
+
+func TestPost_Group(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{GroupBy: []string{"source", "project"}})
+	n, _ := tc.post(t, "Done", "info")
+	assert.Equal(t, "project:p1/source:claude", n.GroupKey)
+	assert.Equal(t, "Website · claude", n.Group)
+}
+
+func TestPost_CollapseAndEscalate(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{RepeatWindow: 60, Collapse: true, EscalateAfter: 3})
+	first, outcome := tc.post(t, "Tests failed", "warning")
+	assert.Equal(t, Delivered, outcome)
+	require.NoError(t, tc.store.MarkRead(first.ID))
+
+	tc.clock = tc.clock.Add(30 * time.Second)
+	n, outcome := tc.post(t, "Tests failed", "warning")
+	assert.Equal(t, Collapsed, outcome)
+	assert.Equal(t, first.ID, n.ID)
+	assert.Equal(t, 2, n.Count)
+	assert.False(t, n.Read, "a repeat makes it unread again")
+
+	// The window runs from the last repeat.
+	tc.clock = tc.clock.Add(50 * time.Second)
+	n, outcome = tc.post(t, "Tests failed", "warning")
+	assert.Equal(t, Escalated, outcome)
+	assert.Equal(t, 3, n.Count)
+	assert.Equal(t, "error", n.Level)
+	assert.True(t, n.Escalated)
+
+	all := tc.store.GetAll()
+	require.Len(t, all, 1)
+	assert.Equal(t, 3, all[0].Count)
+	assert.Equal(t, 2, tc.drain(), "the first and the escalation")
+
+	// Outside the window it starts over.
+	tc.clock = tc.clock.Add(2 * time.Minute)
+	_, outcome = tc.post(t, "Tests failed", "warning")
+	assert.Equal(t, Delivered, outcome)
+	_, outcome = tc.post(t, "Other", "warning")
+	assert.Equal(t, Delivered, outcome)
+}
+
+func TestPost_EscalateWithoutCollapse(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{RepeatWindow: 60, EscalateAfter: 2})
+	n, _ := tc.post(t, "Tests failed", "info")
+	assert.Equal(t, "info", n.Level)
+	n, outcome := tc.post(t, "Tests failed", "info")
+	assert.Equal(t, Escalated, outcome)
+	assert.Equal(t, "error", n.Level)
+	assert.Len(t, tc.store.GetAll(), 2)
+}
+
+func TestQuietHours(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{QuietHours: model.QuietHours{Enabled: true, Start: "22:00", End: "07:00"}})
+	tc.clock = time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local)
+
+	n, outcome := tc.post(t, "Done", "info")
+	assert.Equal(t, Held, outcome)
+	assert.True(t, n.Held)
+	_, outcome = tc.post(t, "Done again", "warning")
+	assert.Equal(t, Held, outcome)
+	_, outcome = tc.post(t, "Crashed", "error")
+	assert.Equal(t, Delivered, outcome, "errors get through")
+	assert.Equal(t, 1, tc.drain())
+	assert.Len(t, tc.store.GetAll(), 3, "held notifications are still listed")
+
+	tc.ReleaseHeld()
+	assert.Equal(t, 0, tc.drain(), "still quiet")
+
+	tc.clock = time.Date(2026, 1, 2, 7, 0, 0, 0, time.Local)
+	tc.ReleaseHeld()
+	summary := <-tc.broadcasts
+	assert.Equal(t, "2 notifications in Website during quiet hours", summary.Title)
+	assert.Equal(t, "warning", summary.Level)
+	assert.Equal(t, "p1", summary.ProjectID)
+	for _, n := range tc.store.GetAll() {
+		assert.False(t, n.Held)
+	}
+	tc.ReleaseHeld()
+	assert.Equal(t, 0, tc.drain())
+}
+
+func TestQuietHours_ReleaseOne(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{QuietHours: model.QuietHours{Enabled: true, Start: "22:00", End: "07:00"}})
+	tc.clock = time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local)
+	tc.post(t, "Done", "info")
+
+	require.NoError(t, tc.SetRules(model.NotificationRules{}))
+	tc.ReleaseHeld()
+	released := <-tc.broadcasts
+	assert.Equal(t, "Done", released.Title)
+	assert.False(t, released.Held)
+}
+
+func TestQuietHours_SummaryPerProject(t *testing.T) {
+	tc := newTestCenter(t, model.NotificationRules{QuietHours: model.QuietHours{Enabled: true, Start: "22:00", End: "07:00"}})
+	tc.clock = time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local)
+	tc.post(t, "Done", "info")
+	tc.post(t, "Done again", "info")
+	for _, title := range []string{"Secret build", "Secret deploy"} {
+		_, _, err := tc.Post(model.Notification{ID: title, Title: title, Source: "claude", Level: "info", ProjectID: "p2"})
+		require.NoError(t, err)
+	}
+	_, _, err := tc.Post(model.Notification{ID: "global", Title: "Update available", Source: "clawide", Level: "info"})
+	require.NoError(t, err)
+
+	tc.clock = time.Date(2026, 1, 2, 7, 0, 0, 0, time.Local)
+	tc.ReleaseHeld()
+	require.Equal(t, 3, len(tc.broadcasts))
+	byProject := map[string]*model.Notification{}
+	for range 3 {
+		n := <-tc.broadcasts
+		byProject[n.ProjectID] = n
+	}
+
+	require.Contains(t, byProject, "p1")
+	assert.Equal(t, "2 notifications in Website during quiet hours", byProject["p1"].Title)
+	assert.ElementsMatch(t, []string{"Done", "Done again"}, strings.Split(byProject["p1"].Body, ", "))
+	require.Contains(t, byProject, "p2")
+	assert.Equal(t, "2 notifications during quiet hours", byProject["p2"].Title, "unknown projects go unnamed")
+	assert.ElementsMatch(t, []string{"Secret build", "Secret deploy"}, strings.Split(byProject["p2"].Body, ", "))
+	require.Contains(t, byProject, "")
+	assert.Equal(t, "Update available", byProject[""].Title, "a lone notification is released as is")
+}
+
+func TestSummarize(t *testing.T) {
+	held := []model.Notification{
+		{Title: "a"}, {Title: "b", Count: 3}, {Title: "c"}, {Title: "d"}, {Title: "a"},
+	}
+	assert.Equal(t, "b (×3), a (×2), c, and 1 more", summarize(held))
+}
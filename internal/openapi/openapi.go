@@ -0,0 +1,285 @@
+// Package openapi builds an OpenAPI 3.0 document from route descriptions,
+// deriving the schemas from the Go types the handlers read and write.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation describes one route.
+type Operation struct {
+	Method  string
+	Path    string // chi pattern relative to the server URL, e.g. /projects/{id}
+	Tag     string
+	Summary string
+	Query   []Param
+	Form    bool // the body may also be sent as a form
+	Request any  // body type (a zero value), nil for none
+	// Response is the success body type, nil for none. With List it is the
+	// element type of a paginated list.
+	Response any
+	List     bool
+	Status   int // success status; defaults to 200, or 204 without a Response
+}
+
+// Param is a query parameter.
+type Param struct {
+	Name        string
+	Type        string // "string" (default), "integer" or "boolean"
+	Description string
+}
+
+// SuccessStatus returns the status the operation answers with on success.
+func (op Operation) SuccessStatus() int {
+	switch {
+	case op.Status != 0:
+		return op.Status
+	case op.Response == nil:
+		return http.StatusNoContent
+	}
+	return http.StatusOK
+}
+
+// Info is the document's title, version and description.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document is an OpenAPI 3.0 document, kept as plain maps so it marshals
+// to exactly what is served.
+type Document map[string]any
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Build returns the document for ops served under serverURL. errorType is
+// the body of every error response, pageType the envelope of paginated
+// lists; its "data" property is replaced with an array of each list's
+// element type.
+func Build(info Info, serverURL string, ops []Operation, errorType, pageType any) Document {
+	g := &generator{schemas: map[string]any{}, names: map[string]reflect.Type{}}
+	errorSchema := g.schema(reflect.TypeOf(errorType))
+	pageSchema := g.schema(reflect.TypeOf(pageType))
+
+	paths := map[string]any{}
+	for _, op := range ops {
+		item, _ := paths[op.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = g.operation(op, errorSchema, pageSchema)
+	}
+
+	return Document{
+		"openapi": "3.0.3",
+		"info":    info,
+		"servers": []any{map[string]any{"url": serverURL}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "description": "An API token from Settings > API Tokens"},
+				"cookieAuth": map[string]any{"type": "apiKey", "in": "cookie", "name": "clawide_session"},
+			},
+		},
+		"security": []any{map[string]any{"bearerAuth": []any{}}, map[string]any{"cookieAuth": []any{}}},
+	}
+}
+
+func (g *generator) operation(op Operation, errorSchema, pageSchema map[string]any) map[string]any {
+	var params []any
+	for _, m := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+		params = append(params, map[string]any{
+			"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	query := op.Query
+	if op.List {
+		query = append([]Param{
+			{Name: "limit", Type: "integer", Description: "Items per page (1-500, default 50)"},
+			{Name: "offset", Type: "integer", Description: "Items to skip"},
+		}, query...)
+	}
+	for _, q := range query {
+		typ := q.Type
+		if typ == "" {
+			typ = "string"
+		}
+		p := map[string]any{"name": q.Name, "in": "query", "schema": map[string]any{"type": typ}}
+		if q.Description != "" {
+			p["description"] = q.Description
+		}
+		params = append(params, p)
+	}
+
+	out := map[string]any{
+		"summary":     op.Summary,
+		"operationId": operationID(op),
+		"responses": map[string]any{
+			strconv.Itoa(op.SuccessStatus()): g.success(op, pageSchema),
+			"default": map[string]any{
+				"description": "Error",
+				"content":     map[string]any{"application/json": map[string]any{"schema": errorSchema}},
+			},
+		},
+	}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+	if params != nil {
+		out["parameters"] = params
+	}
+	if op.Request != nil {
+		schema := g.schema(reflect.TypeOf(op.Request))
+		content := map[string]any{"application/json": map[string]any{"schema": schema}}
+		if op.Form {
+			content["application/x-www-form-urlencoded"] = map[string]any{"schema": schema}
+		}
+		out["requestBody"] = map[string]any{"required": true, "content": content}
+	}
+	return out
+}
+
+func (g *generator) success(op Operation, pageSchema map[string]any) map[string]any {
+	resp := map[string]any{"description": http.StatusText(op.SuccessStatus())}
+	if op.Response == nil {
+		return resp
+	}
+	schema := g.schema(reflect.TypeOf(op.Response))
+	if op.List {
+		schema = map[string]any{
+			"allOf": []any{
+				pageSchema,
+				map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"data": map[string]any{"type": "array", "items": schema}},
+				},
+			},
+		}
+	}
+	resp["content"] = map[string]any{"application/json": map[string]any{"schema": schema}}
+	return resp
+}
+
+// operationID derives a stable camelCase ID such as getProjectsIdSessions.
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool { return r == '/' || r == '{' || r == '}' || r == '-' || r == '_' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+type generator struct {
+	schemas map[string]any
+	names   map[string]reflect.Type
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schema returns the schema for t, registering named structs as components
+// and referring to them by $ref, which also covers recursive types.
+func (g *generator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := g.name(t)
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = map[string]any{} // placeholder while recursing
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+// name picks the component name for t, qualifying it with its package when
+// two packages use the same type name.
+func (g *generator) name(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ToUpper(name[:1]) + name[1:]
+	if other, ok := g.names[name]; ok && other != t {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndexByte(pkg, '/')+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	g.names[name] = t
+	return name
+}
+
+func (g *generator) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	g.fields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+// fields adds t's JSON fields to props, flattening embedded structs the way
+// encoding/json does.
+func (g *generator) fields(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			g.fields(ft, props)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type node struct {
+	ID       string    `json:"id"`
+	Children []*node   `json:"children,omitempty"`
+	Hidden   string    `json:"-"`
+	Created  time.Time `json:"created"`
+	Meta
+	private string
+}
+
+type Meta struct {
+	Tags map[string]int `json:"tags"`
+}
+
+type errBody struct {
+	Message string `json:"message"`
+}
+
+type page struct {
+	Data  []json.RawMessage `json:"data"`
+	Total int               `json:"total"`
+}
+
+// roundTrip returns the document as a client sees it.
+func roundTrip(t *testing.T, doc Document) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+func dig(t *testing.T, v any, path ...string) any {
+	t.Helper()
+	for _, p := range path {
+		m, ok := v.(map[string]any)
+		require.True(t, ok, "at %q in %v", p, path)
+		v = m[p]
+	}
+	return v
+}
+
+func TestBuild(t *testing.T) {
+	doc := roundTrip(t, Build(Info{Title: "T", Version: "1"}, "/api/v1", []Operation{
+		{Method: "GET", Path: "/nodes", Tag: "nodes", Summary: "List", Response: node{}, List: true,
+			Query: []Param{{Name: "q"}}},
+		{Method: "POST", Path: "/nodes/{nodeID}", Summary: "Create", Request: node{}, Form: true, Response: &node{}, Status: 201},
+		{Method: "DELETE", Path: "/nodes/{nodeID}", Summary: "Delete"},
+	}, errBody{}, page{}))
+
+	assert.Equal(t, "3.0.3", doc["openapi"])
+	assert.Equal(t, "/api/v1", dig(t, doc, "servers").([]any)[0].(map[string]any)["url"])
+
+	schema := dig(t, doc, "components", "schemas", "Node", "properties").(map[string]any)
+	assert.ElementsMatch(t, []string{"id", "children", "created", "tags"}, keys(schema), "embedded fields flattened, hidden ones dropped")
+	assert.Equal(t, "#/components/schemas/Node", dig(t, schema, "children", "items", "$ref"), "recursion via $ref")
+	assert.Equal(t, "date-time", dig(t, schema, "created", "format"))
+	assert.Equal(t, "integer", dig(t, schema, "tags", "additionalProperties", "type"))
+
+	list := dig(t, doc, "paths", "/nodes", "get").(map[string]any)
+	assert.Equal(t, "getNodes", list["operationId"])
+	var params []string
+	for _, p := range list["parameters"].([]any) {
+		params = append(params, p.(map[string]any)["name"].(string))
+	}
+	assert.Equal(t, []string{"limit", "offset", "q"}, params)
+	allOf := dig(t, list, "responses", "200", "content", "application/json", "schema", "allOf").([]any)
+	assert.Equal(t, "#/components/schemas/Page", dig(t, allOf[0], "$ref"))
+	assert.Equal(t, "#/components/schemas/Node", dig(t, allOf[1], "properties", "data", "items", "$ref"))
+	assert.Equal(t, "#/components/schemas/ErrBody", dig(t, list, "responses", "default", "content", "application/json", "schema", "$ref"))
+
+	create := dig(t, doc, "paths", "/nodes/{nodeID}", "post").(map[string]any)
+	assert.Equal(t, "path", dig(t, create, "parameters").([]any)[0].(map[string]any)["in"])
+	assert.NotNil(t, dig(t, create, "requestBody", "content", "application/x-www-form-urlencoded"))
+	assert.NotNil(t, dig(t, create, "responses", "201", "content"))
+
+	del := dig(t, doc, "paths", "/nodes/{nodeID}", "delete").(map[string]any)
+	assert.Nil(t, dig(t, del, "responses", "204", "content"))
+}
+
+func keys(m map[string]any) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -19,18 +19,35 @@ func Read(path string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	first, _, _ := strings.Cut(string(data), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(first))
 	if err != nil {
 		return 0, fmt.Errorf("invalid pid file content: %w", err)
 	}
 	return pid, nil
 }
 
+// ReadURL returns the URL recorded by SetURL, or "" if there is none.
+func ReadURL(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	_, rest, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(rest), nil
+}
+
 // Write writes the current process PID to the given file path.
 func Write(path string) error {
 	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0644)
 }
 
+// SetURL records, after the PID, the URL the running server is reachable at
+// from this machine, so `clawide ctl` can find it.
+func SetURL(path, url string) error {
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"+url+"\n"), 0644)
+}
+
 // Remove deletes the PID file at the given path.
 func Remove(path string) {
 	os.Remove(path)
@@ -92,3 +92,22 @@ func TestWriteContainsCurrentPID(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, strconv.Itoa(os.Getpid()), string(data))
 }
+
+func TestSetURL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.pid")
+
+	require.NoError(t, SetURL(path, "https://localhost:9800"))
+
+	pid, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+	url, err := ReadURL(path)
+	require.NoError(t, err)
+	assert.Equal(t, "https://localhost:9800", url)
+
+	require.NoError(t, Write(path))
+	url, err = ReadURL(path)
+	require.NoError(t, err)
+	assert.Empty(t, url)
+}
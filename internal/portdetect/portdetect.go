@@ -0,0 +1,265 @@
+// Package portdetect finds the TCP ports a project listens on: those opened
+// by processes running in its terminal panes (vite, go run, rails s, ...)
+// and those published by its compose file.
+package portdetect
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/davydany/ClawIDE/internal/docker"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/procstats"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	gnet "github.com/shirou/gopsutil/v4/net"
+)
+
+// Sources of a detected port.
+const (
+	SourcePane    = "pane"
+	SourceCompose = "compose"
+)
+
+// Listener is one listening TCP socket.
+type Listener struct {
+	PID     int32
+	Address string // bind address: "0.0.0.0", "::", "127.0.0.1", ...
+	Port    uint32
+}
+
+// Listening returns every listening TCP socket whose owner is known. It
+// reads /proc on Linux and lsof on macOS.
+func Listening() ([]Listener, error) {
+	conns, err := gnet.Connections("tcp")
+	if err != nil {
+		return nil, err
+	}
+	var out []Listener
+	for _, c := range conns {
+		if c.Status != "LISTEN" || c.Pid == 0 {
+			continue
+		}
+		out = append(out, Listener{PID: c.Pid, Address: c.Laddr.IP, Port: c.Laddr.Port})
+	}
+	return out, nil
+}
+
+// Port is a port something in a project listens on.
+type Port struct {
+	Port      uint32 `json:"port"`
+	Address   string `json:"address,omitempty"`
+	Source    string `json:"source"` // SourcePane or SourceCompose
+	PID       int32  `json:"pid,omitempty"`
+	Process   string `json:"process,omitempty"`
+	Service   string `json:"service,omitempty"` // compose service
+	ProjectID string `json:"project_id"`
+	FeatureID string `json:"feature_id,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	PaneID    string `json:"pane_id,omitempty"`
+	PaneName  string `json:"pane_name,omitempty"`
+	LocalOnly bool   `json:"local_only"` // bound to loopback, so only reachable from this machine
+	URL       string `json:"url"`
+	PaneURL   string `json:"pane_url,omitempty"` // workspace link, filled in by the API
+}
+
+// Panes attributes listeners to the panes of sessions whose process tree
+// owns them. panePIDs comes from tmux.PanePIDs. A process listening on the
+// same port on several addresses (IPv4 and IPv6) is reported once, under
+// its widest address.
+func Panes(snap *procstats.Snapshot, listeners []Listener, sessions []model.Session, panePIDs map[string]int32) []Port {
+	type owner struct {
+		sess    model.Session
+		pane    *model.PaneNode
+		process string
+	}
+	owners := map[int32]owner{}
+	for _, sess := range sessions {
+		if sess.Layout == nil {
+			continue
+		}
+		for _, paneID := range sess.Layout.CollectLeaves() {
+			pid, ok := panePIDs[tmux.TmuxName(paneID)]
+			if !ok {
+				continue
+			}
+			_, procs, ok := snap.Tree(pid)
+			if !ok {
+				continue
+			}
+			pane, _ := sess.Layout.FindPane(paneID)
+			for _, p := range procs {
+				owners[p.PID] = owner{sess: sess, pane: pane, process: p.Name}
+			}
+		}
+	}
+
+	type key struct {
+		pid  int32
+		port uint32
+	}
+	idx := map[key]int{}
+	out := []Port{}
+	for _, l := range listeners {
+		o, ok := owners[l.PID]
+		if !ok {
+			continue
+		}
+		if i, ok := idx[key{l.PID, l.Port}]; ok {
+			if wider(l.Address, out[i].Address) {
+				out[i].Address = l.Address
+				out[i].LocalOnly = isLoopback(l.Address)
+			}
+			continue
+		}
+		idx[key{l.PID, l.Port}] = len(out)
+		out = append(out, Port{
+			Port:      l.Port,
+			Address:   l.Address,
+			Source:    SourcePane,
+			PID:       l.PID,
+			Process:   o.process,
+			ProjectID: o.sess.ProjectID,
+			FeatureID: o.sess.FeatureID,
+			SessionID: o.sess.ID,
+			PaneID:    o.pane.PaneID,
+			PaneName:  o.pane.Name,
+			LocalOnly: isLoopback(l.Address),
+		})
+	}
+	sortPorts(out)
+	return out
+}
+
+// Compose returns the host ports published by the compose file in dir,
+// tagged with projectID and featureID. A missing or invalid compose file
+// yields none.
+func Compose(dir, projectID, featureID string) []Port {
+	cfg, err := docker.ParseComposeFile(dir)
+	if err != nil {
+		return nil
+	}
+	var out []Port
+	seen := map[uint32]bool{}
+	for _, pm := range docker.ExtractPorts(cfg) {
+		if pm.Protocol != "tcp" {
+			continue
+		}
+		port, err := strconv.ParseUint(pm.HostPort, 10, 16)
+		if err != nil || seen[uint32(port)] {
+			continue
+		}
+		seen[uint32(port)] = true
+		out = append(out, Port{
+			Port:      uint32(port),
+			Source:    SourceCompose,
+			Service:   pm.Service,
+			ProjectID: projectID,
+			FeatureID: featureID,
+		})
+	}
+	sortPorts(out)
+	return out
+}
+
+// Merge adds the compose ports that no pane already listens on to panes.
+func Merge(panes, compose []Port) []Port {
+	taken := map[uint32]bool{}
+	for _, p := range panes {
+		taken[p.Port] = true
+	}
+	out := append([]Port{}, panes...)
+	for _, p := range compose {
+		if !taken[p.Port] {
+			out = append(out, p)
+		}
+	}
+	sortPorts(out)
+	return out
+}
+
+// SetURLs fills in each port's URL on host.
+func SetURLs(ports []Port, host string) {
+	for i := range ports {
+		ports[i].URL = fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(int(ports[i].Port))))
+	}
+}
+
+// URLHost picks the host to build port URLs on. bindHost is ClawIDE's
+// --host and requestHost the Host header the browser used to reach it.
+// When ClawIDE listens on a specific address, that is used. Otherwise
+// (--mobile binds every interface) the browser's host is used, so a phone
+// that opened ClawIDE by LAN address gets LAN links, falling back to the
+// machine's LAN address and then localhost.
+func URLHost(bindHost, requestHost string) string {
+	switch bindHost {
+	case "", "0.0.0.0", "::":
+	case "127.0.0.1", "::1", "localhost":
+		return "localhost"
+	default:
+		return bindHost
+	}
+	if h, _, err := net.SplitHostPort(requestHost); err == nil && h != "" {
+		return h
+	}
+	if requestHost != "" {
+		return requestHost
+	}
+	if ip := LANIP(); ip != "" {
+		return ip
+	}
+	return "localhost"
+}
+
+// LANIP returns the machine's first non-loopback IPv4 address, or "".
+func LANIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipNet.IP
+		if ip.IsLoopback() || ip.To4() == nil {
+			continue
+		}
+		return ip.String()
+	}
+	return ""
+}
+
+func isLoopback(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.IsLoopback()
+}
+
+// wider reports whether address a is reachable from more places than b:
+// any wildcard beats a specific address, which beats loopback.
+func wider(a, b string) bool {
+	rank := func(addr string) int {
+		ip := net.ParseIP(addr)
+		switch {
+		case ip == nil:
+			return 0
+		case ip.IsUnspecified():
+			return 3
+		case ip.IsLoopback():
+			return 1
+		}
+		return 2
+	}
+	return rank(a) > rank(b)
+}
+
+func sortPorts(ports []Port) {
+	sort.SliceStable(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].PID < ports[j].PID
+	})
+}
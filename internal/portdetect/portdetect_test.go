@@ -0,0 +1,100 @@
+package portdetect
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/procstats"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanes_LiveListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	port := uint32(ln.Addr().(*net.TCPAddr).Port)
+
+	snap, err := procstats.NewSampler().Sample()
+	require.NoError(t, err)
+	listeners, err := Listening()
+	require.NoError(t, err)
+
+	pane := model.NewLeafPane("p1")
+	pane.Name = "dev server"
+	sessions := []model.Session{{ID: "s1", ProjectID: "proj-1", FeatureID: "f1", Layout: pane}}
+	// This test process stands in for the pane's shell.
+	pids := map[string]int32{tmux.TmuxName("p1"): int32(os.Getpid())}
+
+	var found *Port
+	for _, p := range Panes(snap, listeners, sessions, pids) {
+		if p.Port == port {
+			found = &p
+		}
+	}
+	require.NotNil(t, found, "listener on %d not attributed", port)
+	assert.Equal(t, SourcePane, found.Source)
+	assert.Equal(t, int32(os.Getpid()), found.PID)
+	assert.Equal(t, "proj-1", found.ProjectID)
+	assert.Equal(t, "f1", found.FeatureID)
+	assert.Equal(t, "p1", found.PaneID)
+	assert.Equal(t, "dev server", found.PaneName)
+	assert.True(t, found.LocalOnly)
+
+	assert.Empty(t, Panes(snap, listeners, sessions, map[string]int32{}))
+}
+
+func TestCompose(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(`services:
+  web:
+    image: nginx
+    ports: ["8080:80", "127.0.0.1:8443:443", "53:53/udp", "9000"]
+  db:
+    image: postgres
+    ports: ["5432:5432"]
+`), 0644))
+
+	ports := Compose(dir, "proj-1", "")
+	require.Len(t, ports, 3)
+	assert.Equal(t, uint32(5432), ports[0].Port)
+	assert.Equal(t, "db", ports[0].Service)
+	assert.Equal(t, uint32(8080), ports[1].Port)
+	assert.Equal(t, uint32(8443), ports[2].Port)
+	assert.Equal(t, SourceCompose, ports[1].Source)
+
+	assert.Empty(t, Compose(t.TempDir(), "proj-1", ""))
+}
+
+func TestMerge(t *testing.T) {
+	panes := []Port{{Port: 8080, Source: SourcePane, PID: 10}}
+	compose := []Port{{Port: 5432, Source: SourceCompose}, {Port: 8080, Source: SourceCompose}}
+
+	got := Merge(panes, compose)
+	require.Len(t, got, 2)
+	assert.Equal(t, uint32(5432), got[0].Port)
+	assert.Equal(t, SourcePane, got[1].Source)
+}
+
+func TestURLHost(t *testing.T) {
+	assert.Equal(t, "localhost", URLHost("127.0.0.1", "127.0.0.1:9800"))
+	assert.Equal(t, "10.0.0.5", URLHost("10.0.0.5", "example:9800"))
+	// --mobile binds 0.0.0.0: follow the address the browser used.
+	assert.Equal(t, "192.168.1.20", URLHost("0.0.0.0", "192.168.1.20:9800"))
+	assert.Equal(t, "::1", URLHost("::", "[::1]:9800"))
+
+	ports := []Port{{Port: 5173}}
+	SetURLs(ports, "::1")
+	assert.Equal(t, "http://[::1]:5173", ports[0].URL)
+}
+
+func TestWider(t *testing.T) {
+	assert.True(t, wider("0.0.0.0", "127.0.0.1"))
+	assert.True(t, wider("::", "192.168.1.2"))
+	assert.True(t, wider("192.168.1.2", "::1"))
+	assert.False(t, wider("127.0.0.1", "::"))
+}
@@ -0,0 +1,116 @@
+package procstats
+
+import (
+	"sort"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/tmux"
+)
+
+// topProcesses is how many of a pane's processes PaneUsage lists.
+const topProcesses = 5
+
+// PaneUsage is the usage of everything running in one pane: the pane's
+// shell and all of its descendants.
+type PaneUsage struct {
+	ProjectID   string `json:"project_id"`
+	FeatureID   string `json:"feature_id,omitempty"`
+	SessionID   string `json:"session_id"`
+	SessionName string `json:"session_name"`
+	PaneID      string `json:"pane_id"`
+	PaneName    string `json:"pane_name,omitempty"`
+	PaneType    string `json:"pane_type"`
+	PID         int32  `json:"pid"`
+	URL         string `json:"url,omitempty"` // workspace link, filled in by the API
+	Usage
+	Top []Proc `json:"top_processes"` // busiest first
+}
+
+// GroupUsage is the total of the panes in a session, feature or project.
+type GroupUsage struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	Panes     int    `json:"panes"`
+	Usage
+}
+
+// Panes measures every pane of sessions whose tmux session is running.
+// panePIDs comes from tmux.PanePIDs. The result is sorted busiest first.
+func Panes(snap *Snapshot, sessions []model.Session, panePIDs map[string]int32) []PaneUsage {
+	out := []PaneUsage{}
+	for _, sess := range sessions {
+		if sess.Layout == nil {
+			continue
+		}
+		for _, paneID := range sess.Layout.CollectLeaves() {
+			pid, ok := panePIDs[tmux.TmuxName(paneID)]
+			if !ok {
+				continue
+			}
+			usage, procs, ok := snap.Tree(pid)
+			if !ok {
+				continue
+			}
+			pane, _ := sess.Layout.FindPane(paneID)
+			if len(procs) > topProcesses {
+				procs = procs[:topProcesses]
+			}
+			out = append(out, PaneUsage{
+				ProjectID:   sess.ProjectID,
+				FeatureID:   sess.FeatureID,
+				SessionID:   sess.ID,
+				SessionName: sess.Name,
+				PaneID:      paneID,
+				PaneName:    pane.Name,
+				PaneType:    pane.EffectivePaneType(),
+				PID:         pid,
+				Usage:       usage,
+				Top:         procs,
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return busier(out[i].Usage, out[j].Usage) })
+	return out
+}
+
+// Rollup totals panes per session, feature and project, each sorted busiest
+// first. Session names are filled in; feature and project names are left to
+// the caller.
+func Rollup(panes []PaneUsage) (sessions, features, projects []GroupUsage) {
+	sessionIdx := map[string]int{}
+	featureIdx := map[string]int{}
+	projectIdx := map[string]int{}
+	add := func(groups *[]GroupUsage, idx map[string]int, id, name, projectID string, p PaneUsage) {
+		i, ok := idx[id]
+		if !ok {
+			i = len(*groups)
+			idx[id] = i
+			*groups = append(*groups, GroupUsage{ID: id, Name: name, ProjectID: projectID, Usage: Usage{Ports: []uint32{}}})
+		}
+		g := &(*groups)[i]
+		g.Panes++
+		g.Usage.Add(p.Usage)
+	}
+
+	sessions, features, projects = []GroupUsage{}, []GroupUsage{}, []GroupUsage{}
+	for _, p := range panes {
+		add(&sessions, sessionIdx, p.SessionID, p.SessionName, p.ProjectID, p)
+		if p.FeatureID != "" {
+			add(&features, featureIdx, p.FeatureID, "", p.ProjectID, p)
+		}
+		add(&projects, projectIdx, p.ProjectID, "", "", p)
+	}
+	for _, groups := range [][]GroupUsage{sessions, features, projects} {
+		sort.SliceStable(groups, func(i, j int) bool { return busier(groups[i].Usage, groups[j].Usage) })
+	}
+	return sessions, features, projects
+}
+
+// busier orders by CPU, then memory.
+func busier(a, b Usage) bool {
+	if a.CPUPercent != b.CPUPercent {
+		return a.CPUPercent > b.CPUPercent
+	}
+	return a.RSSBytes > b.RSSBytes
+}
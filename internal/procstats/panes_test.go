@@ -0,0 +1,57 @@
+package procstats
+
+import (
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanesAndRollup(t *testing.T) {
+	procs := []Proc{
+		{PID: 10, PPID: 1, Name: "zsh", RSSBytes: 100},
+		{PID: 11, PPID: 10, Name: "claude", RSSBytes: 4000},
+		{PID: 20, PPID: 1, Name: "zsh", RSSBytes: 100},
+		{PID: 21, PPID: 20, Name: "node", RSSBytes: 9000},
+		{PID: 30, PPID: 1, Name: "zsh", RSSBytes: 300},
+	}
+	snap, err := fakeSampler(&procs, map[int32][]uint32{21: {5173}}).Sample()
+	require.NoError(t, err)
+
+	agent := model.NewAgentPane("p-agent")
+	agent.Name = "Claude"
+	sessions := []model.Session{
+		{ID: "s1", ProjectID: "proj", Name: "Main", Layout: &model.PaneNode{
+			Type: "split", Direction: "vertical", Ratio: 0.5,
+			First: agent, Second: model.NewLeafPane("p-dev"),
+		}},
+		{ID: "s2", ProjectID: "proj", FeatureID: "f1", Name: "Feature", Layout: model.NewLeafPane("p-feat")},
+		{ID: "s3", ProjectID: "other", Name: "Not running", Layout: model.NewLeafPane("p-dead")},
+	}
+	pids := map[string]int32{"clawide-p-agent": 10, "clawide-p-dev": 20, "clawide-p-feat": 30, "clawide-p-gone": 99}
+
+	panes := Panes(snap, sessions, pids)
+	require.Len(t, panes, 3)
+	assert.Equal(t, "p-dev", panes[0].PaneID, "busiest first")
+	assert.Equal(t, uint64(9100), panes[0].RSSBytes)
+	assert.Equal(t, []uint32{5173}, panes[0].Ports)
+	assert.Equal(t, "Claude", panes[1].PaneName)
+	assert.Equal(t, model.PaneTypeAgent, panes[1].PaneType)
+	assert.Equal(t, "node", panes[0].Top[0].Name)
+
+	sess, feats, projs := Rollup(panes)
+	require.Len(t, sess, 2)
+	assert.Equal(t, "s1", sess[0].ID)
+	assert.Equal(t, "Main", sess[0].Name)
+	assert.Equal(t, 2, sess[0].Panes)
+	assert.Equal(t, uint64(13200), sess[0].RSSBytes)
+
+	require.Len(t, feats, 1)
+	assert.Equal(t, GroupUsage{ID: "f1", ProjectID: "proj", Panes: 1, Usage: Usage{RSSBytes: 300, Processes: 1, Ports: []uint32{}}}, feats[0])
+
+	require.Len(t, projs, 1)
+	assert.Equal(t, 3, projs[0].Panes)
+	assert.Equal(t, 5, projs[0].Processes)
+	assert.Equal(t, []uint32{5173}, projs[0].Ports)
+}
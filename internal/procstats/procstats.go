@@ -0,0 +1,273 @@
+// Package procstats measures the CPU, memory and listening ports of process
+// trees, such as everything running inside a tmux pane.
+package procstats
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	gnet "github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// Proc is one process at the time of a sample.
+type Proc struct {
+	PID        int32     `json:"pid"`
+	PPID       int32     `json:"ppid"`
+	Name       string    `json:"name"`
+	CPUPercent float64   `json:"cpu_percent"` // of one core, so it can exceed 100
+	RSSBytes   uint64    `json:"rss_bytes"`
+	Ports      []uint32  `json:"ports,omitempty"` // listening TCP ports
+	Started    time.Time `json:"started"`
+
+	cpuSeconds float64 // user+system time used so far
+}
+
+// Usage is the total for a group of processes.
+type Usage struct {
+	CPUPercent float64  `json:"cpu_percent"`
+	RSSBytes   uint64   `json:"rss_bytes"`
+	Processes  int      `json:"processes"`
+	Ports      []uint32 `json:"ports"`
+}
+
+// Add folds o into u.
+func (u *Usage) Add(o Usage) {
+	u.CPUPercent = round1(u.CPUPercent + o.CPUPercent)
+	u.RSSBytes += o.RSSBytes
+	u.Processes += o.Processes
+	u.Ports = mergePorts(u.Ports, o.Ports)
+}
+
+// Snapshot is the process table at one point in time.
+type Snapshot struct {
+	At       time.Time
+	procs    map[int32]*Proc
+	children map[int32][]int32
+}
+
+// Tree returns the total usage of root and all of its descendants, and the
+// processes themselves, busiest first. ok is false when root is not running.
+func (s *Snapshot) Tree(root int32) (Usage, []Proc, bool) {
+	usage := Usage{Ports: []uint32{}}
+	if _, ok := s.procs[root]; !ok {
+		return usage, nil, false
+	}
+
+	var procs []Proc
+	seen := map[int32]bool{}
+	queue := []int32{root}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		p, ok := s.procs[pid]
+		if !ok {
+			continue
+		}
+		procs = append(procs, *p)
+		usage.Add(Usage{CPUPercent: p.CPUPercent, RSSBytes: p.RSSBytes, Processes: 1, Ports: p.Ports})
+		queue = append(queue, s.children[pid]...)
+	}
+
+	sort.SliceStable(procs, func(i, j int) bool {
+		if procs[i].CPUPercent != procs[j].CPUPercent {
+			return procs[i].CPUPercent > procs[j].CPUPercent
+		}
+		return procs[i].RSSBytes > procs[j].RSSBytes
+	})
+	return usage, procs, true
+}
+
+// Children returns the direct children of pid.
+func (s *Snapshot) Children(pid int32) []Proc {
+	var out []Proc
+	for _, c := range s.children[pid] {
+		if p, ok := s.procs[c]; ok {
+			out = append(out, *p)
+		}
+	}
+	return out
+}
+
+// KillTree sends SIGKILL to root and all of its descendants, deepest first
+// so parents cannot respawn children in between. Processes that are already
+// gone are ignored.
+func (s *Snapshot) KillTree(root int32) error {
+	if _, ok := s.procs[root]; !ok {
+		return nil
+	}
+	// Breadth-first, so walking the list backwards visits children before
+	// their parents.
+	order := []int32{root}
+	seen := map[int32]bool{root: true}
+	for i := 0; i < len(order); i++ {
+		for _, c := range s.children[order[i]] {
+			if !seen[c] {
+				seen[c] = true
+				order = append(order, c)
+			}
+		}
+	}
+
+	var firstErr error
+	for i := len(order) - 1; i >= 0; i-- {
+		pid := order[i]
+		proc, err := os.FindProcess(int(pid))
+		if err == nil {
+			err = proc.Kill()
+		}
+		if err != nil && !errors.Is(err, os.ErrProcessDone) && firstErr == nil {
+			firstErr = fmt.Errorf("killing %s (%d): %w", s.procs[pid].Name, pid, err)
+		}
+	}
+	return firstErr
+}
+
+// Sampler takes snapshots of the process table. CPU percentages are worked
+// out from the CPU time each process used since the previous sample, so a
+// Sampler should be long-lived and shared.
+type Sampler struct {
+	mu        sync.Mutex
+	collect   func() ([]Proc, error)
+	listening func() (map[int32][]uint32, error)
+	prevCPU   map[int32]float64
+	prevAt    time.Time
+}
+
+// NewSampler returns a Sampler that reads the live process table.
+func NewSampler() *Sampler {
+	return &Sampler{collect: collectProcs, listening: listeningPorts}
+}
+
+// maxSampleGap is how old the previous sample may be before CPU figures are
+// measured over a fresh, short window instead.
+const maxSampleGap = time.Minute
+
+// warmupWindow is the window used when there is no recent previous sample.
+const warmupWindow = 250 * time.Millisecond
+
+// Sample reads the process table.
+func (s *Sampler) Sample() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prevAt.IsZero() || time.Since(s.prevAt) > maxSampleGap {
+		if _, err := s.sampleLocked(); err != nil {
+			return nil, err
+		}
+		time.Sleep(warmupWindow)
+	}
+	return s.sampleLocked()
+}
+
+func (s *Sampler) sampleLocked() (*Snapshot, error) {
+	procs, err := s.collect()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	ports, err := s.listening()
+	if err != nil {
+		// Ports are a nice-to-have; CPU and memory are still useful.
+		log.Printf("procstats: listening ports: %v", err)
+	}
+
+	snap := &Snapshot{
+		At:       now,
+		procs:    make(map[int32]*Proc, len(procs)),
+		children: make(map[int32][]int32),
+	}
+	elapsed := now.Sub(s.prevAt).Seconds()
+	cpu := make(map[int32]float64, len(procs))
+	for i := range procs {
+		p := &procs[i]
+		cpu[p.PID] = p.cpuSeconds
+		if prev, ok := s.prevCPU[p.PID]; ok && elapsed > 0 && p.cpuSeconds >= prev {
+			p.CPUPercent = round1((p.cpuSeconds - prev) / elapsed * 100)
+		}
+		p.Ports = ports[p.PID]
+		snap.procs[p.PID] = p
+		if p.PPID != p.PID {
+			snap.children[p.PPID] = append(snap.children[p.PPID], p.PID)
+		}
+	}
+	s.prevCPU = cpu
+	s.prevAt = now
+	return snap, nil
+}
+
+// collectProcs reads every process the user can see. Processes that exit
+// while being read are skipped.
+func collectProcs() ([]Proc, error) {
+	ps, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Proc, 0, len(ps))
+	for _, p := range ps {
+		ppid, err := p.Ppid()
+		if err != nil {
+			continue
+		}
+		proc := Proc{PID: p.Pid, PPID: ppid}
+		proc.Name, _ = p.Name()
+		if t, err := p.Times(); err == nil {
+			proc.cpuSeconds = t.User + t.System
+		}
+		if m, err := p.MemoryInfo(); err == nil {
+			proc.RSSBytes = m.RSS
+		}
+		if ms, err := p.CreateTime(); err == nil {
+			proc.Started = time.UnixMilli(ms)
+		}
+		out = append(out, proc)
+	}
+	return out, nil
+}
+
+// listeningPorts maps PIDs to the TCP ports they listen on.
+func listeningPorts() (map[int32][]uint32, error) {
+	conns, err := gnet.Connections("tcp")
+	if err != nil {
+		return nil, err
+	}
+	ports := make(map[int32][]uint32)
+	for _, c := range conns {
+		if c.Status != "LISTEN" || c.Pid == 0 {
+			continue
+		}
+		ports[c.Pid] = mergePorts(ports[c.Pid], []uint32{c.Laddr.Port})
+	}
+	return ports, nil
+}
+
+// mergePorts returns the sorted union of a and b.
+func mergePorts(a, b []uint32) []uint32 {
+	set := make(map[uint32]bool, len(a)+len(b))
+	for _, p := range a {
+		set[p] = true
+	}
+	for _, p := range b {
+		set[p] = true
+	}
+	out := make([]uint32, 0, len(set))
+	for p := range set {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
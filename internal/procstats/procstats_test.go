@@ -0,0 +1,111 @@
+package procstats
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeSampler(procs *[]Proc, ports map[int32][]uint32) *Sampler {
+	return &Sampler{
+		collect: func() ([]Proc, error) {
+			out := make([]Proc, len(*procs))
+			copy(out, *procs)
+			return out, nil
+		},
+		listening: func() (map[int32][]uint32, error) { return ports, nil },
+	}
+}
+
+func TestSnapshotTree(t *testing.T) {
+	procs := []Proc{
+		{PID: 1, PPID: 0, Name: "init"},
+		{PID: 10, PPID: 1, Name: "zsh", RSSBytes: 1000},
+		{PID: 11, PPID: 10, Name: "node", RSSBytes: 5000},
+		{PID: 12, PPID: 11, Name: "esbuild", RSSBytes: 2000},
+		{PID: 20, PPID: 1, Name: "other", RSSBytes: 9000},
+	}
+	s := fakeSampler(&procs, map[int32][]uint32{11: {5173, 3000}, 12: {3000}, 20: {22}})
+
+	snap, err := s.Sample()
+	require.NoError(t, err)
+
+	usage, tree, ok := snap.Tree(10)
+	require.True(t, ok)
+	assert.Equal(t, 3, usage.Processes)
+	assert.Equal(t, uint64(8000), usage.RSSBytes)
+	assert.Equal(t, []uint32{3000, 5173}, usage.Ports)
+	assert.Len(t, tree, 3)
+	assert.Equal(t, "node", tree[0].Name, "ties broken by memory")
+
+	_, _, ok = snap.Tree(99)
+	assert.False(t, ok)
+}
+
+func TestSamplerCPUPercent(t *testing.T) {
+	procs := []Proc{{PID: 10, PPID: 1, cpuSeconds: 1}}
+	s := fakeSampler(&procs, nil)
+
+	_, err := s.Sample()
+	require.NoError(t, err)
+
+	// Pretend the last sample was two seconds ago and the process used one
+	// second of CPU since.
+	s.prevAt = time.Now().Add(-2 * time.Second)
+	procs[0].cpuSeconds = 2
+	snap, err := s.Sample()
+	require.NoError(t, err)
+
+	usage, _, ok := snap.Tree(10)
+	require.True(t, ok)
+	assert.InDelta(t, 50, usage.CPUPercent, 1)
+}
+
+func TestUsageAdd(t *testing.T) {
+	u := Usage{CPUPercent: 1.25, RSSBytes: 10, Processes: 1, Ports: []uint32{80}}
+	u.Add(Usage{CPUPercent: 2.5, RSSBytes: 5, Processes: 2, Ports: []uint32{443, 80}})
+	assert.Equal(t, Usage{CPUPercent: 3.8, RSSBytes: 15, Processes: 3, Ports: []uint32{80, 443}}, u)
+}
+
+func TestSampleLiveProcess(t *testing.T) {
+	snap, err := NewSampler().Sample()
+	require.NoError(t, err)
+
+	usage, tree, ok := snap.Tree(int32(os.Getpid()))
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, usage.Processes, 1)
+	assert.Greater(t, usage.RSSBytes, uint64(0))
+	assert.NotEmpty(t, tree[0].Name)
+}
+
+func TestKillTree(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	require.NoError(t, cmd.Start())
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	// Wait for the shell to start its child.
+	var snap *Snapshot
+	require.Eventually(t, func() bool {
+		var err error
+		snap, err = NewSampler().Sample()
+		return err == nil && len(snap.Children(int32(cmd.Process.Pid))) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	child := snap.Children(int32(cmd.Process.Pid))[0].PID
+
+	require.NoError(t, snap.KillTree(int32(cmd.Process.Pid)))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shell still running")
+	}
+	require.Eventually(t, func() bool {
+		p, _ := os.FindProcess(int(child))
+		return p.Signal(syscall.Signal(0)) != nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -3,6 +3,7 @@ package pty
 import (
 	"fmt"
 	"log"
+	"os"
 	"sync"
 
 	"github.com/davydany/ClawIDE/internal/tmux"
@@ -14,6 +15,7 @@ type Manager struct {
 	maxSessions    int
 	scrollbackSize int
 	agentCommand   string
+	scrollbackDir  string // where pane scrollback persists across restarts; "" disables
 }
 
 func NewManager(maxSessions, scrollbackSize int, agentCommand string) *Manager {
@@ -30,6 +32,22 @@ func (m *Manager) AgentCommand() string {
 	return m.agentCommand
 }
 
+// SetScrollbackDir turns on-disk scrollback persistence on (a directory) or
+// off (""). Detached sessions save their scrollback there, and reattaching
+// to a still-running tmux session seeds the new PTY session with it.
+func (m *Manager) SetScrollbackDir(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scrollbackDir = dir
+	for paneID, sess := range m.sessions {
+		path := ""
+		if dir != "" {
+			path = scrollbackPath(dir, paneID)
+		}
+		sess.setPersistPath(path)
+	}
+}
+
 // CreateSession creates a new PTY session backed by tmux, keyed by paneID.
 func (m *Manager) CreateSession(paneID, workDir string, env map[string]string) (*Session, error) {
 	m.mu.Lock()
@@ -44,11 +62,16 @@ func (m *Manager) CreateSession(paneID, workDir string, env map[string]string) (
 	}
 
 	tmuxName := tmux.TmuxName(paneID)
-	if err := tmux.PrepareSession(tmuxName, workDir); err != nil {
+	reattach := tmux.HasSession(tmuxName)
+	if err := tmux.PrepareSession(tmuxName, workDir, env); err != nil {
 		log.Printf("tmux PrepareSession %s: %v (continuing with attach)", tmuxName, err)
 	}
 	cmd, args := tmux.SessionCommand(tmuxName)
 	sess := NewSession(paneID, workDir, cmd, args, m.scrollbackSize, env)
+	if m.scrollbackDir != "" {
+		sess.persistPath = scrollbackPath(m.scrollbackDir, paneID)
+		m.restoreScrollback(sess, reattach)
+	}
 
 	if err := sess.Start(); err != nil {
 		return nil, fmt.Errorf("starting PTY: %w", err)
@@ -69,6 +92,25 @@ func (m *Manager) CreateSession(paneID, workDir string, env map[string]string) (
 	return sess, nil
 }
 
+// restoreScrollback seeds sess with the scrollback saved when its pane was
+// last detached. History is only kept for a tmux session that is still
+// running, and its file stays until the session saves over it; a fresh shell
+// starts empty and the stale file is removed.
+func (m *Manager) restoreScrollback(sess *Session, reattach bool) {
+	if reattach {
+		history, err := loadScrollback(sess.persistPath, m.scrollbackSize)
+		if err != nil {
+			log.Printf("Error loading scrollback for session %s: %v", sess.ID, err)
+		} else if len(history) > 0 {
+			sess.scrollback.Write(history)
+		}
+		return
+	}
+	if err := os.Remove(sess.persistPath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error removing scrollback file for session %s: %v", sess.ID, err)
+	}
+}
+
 func (m *Manager) GetSession(paneID string) (*Session, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -112,6 +154,12 @@ func (m *Manager) DestroySession(paneID string) error {
 				log.Printf("Error killing orphan tmux session %s: %v", tmuxName, err)
 			}
 		}
+		m.mu.RLock()
+		dir := m.scrollbackDir
+		m.mu.RUnlock()
+		if dir != "" {
+			os.Remove(scrollbackPath(dir, paneID))
+		}
 	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewManager(t *testing.T) {
@@ -26,3 +27,25 @@ func TestGetSessionNotFound(t *testing.T) {
 	assert.False(t, ok)
 	assert.Nil(t, sess)
 }
+
+func TestRestoreScrollback(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(10, 8, "claude")
+	m.SetScrollbackDir(dir)
+	path := scrollbackPath(dir, "pane-1")
+
+	require.NoError(t, saveScrollback(path, []byte("old history")))
+	sess := NewSession("pane-1", dir, "sh", nil, 8, nil)
+	sess.persistPath = path
+	m.restoreScrollback(sess, true)
+	assert.Equal(t, " history", string(sess.Scrollback()), "bounded by the scrollback size")
+	assert.FileExists(t, path, "kept until the session saves again")
+
+	// A fresh tmux session starts with no history.
+	require.NoError(t, saveScrollback(path, []byte("old history")))
+	sess = NewSession("pane-1", dir, "sh", nil, 8, nil)
+	sess.persistPath = path
+	m.restoreScrollback(sess, false)
+	assert.Empty(t, sess.Scrollback())
+	assert.NoFileExists(t, path)
+}
@@ -0,0 +1,73 @@
+package pty
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// scrollbackExt is the file extension of persisted scrollback, one
+// gzip-compressed file per pane.
+const scrollbackExt = ".gz"
+
+// scrollbackPath returns the file a pane's scrollback is persisted to.
+func scrollbackPath(dir, paneID string) string {
+	return filepath.Join(dir, paneID+scrollbackExt)
+}
+
+// saveScrollback writes data gzip-compressed to path, replacing the file
+// atomically. Terminal output can hold secrets, so the file is private to
+// the user.
+func saveScrollback(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// loadScrollback reads a file written by saveScrollback, keeping at most the
+// last limit bytes. A missing file yields no data and no error.
+func loadScrollback(path string, limit int) ([]byte, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	// Stream through a ring buffer so a file written with a larger
+	// scrollback size cannot blow up memory.
+	rb := NewRingBuffer(limit)
+	if _, err := io.Copy(rb, zr); err != nil {
+		return nil, err
+	}
+	return rb.Bytes(), nil
+}
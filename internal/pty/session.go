@@ -1,13 +1,17 @@
 package pty
 
 import (
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/creack/pty"
+	"github.com/davydany/ClawIDE/internal/asciicast"
 	"github.com/davydany/ClawIDE/internal/tmux"
 )
 
@@ -24,8 +28,26 @@ type Session struct {
 	scrollback *RingBuffer
 	done      chan struct{}
 	closed    bool
+	rows      uint16
+	cols      uint16
+	recorder  *asciicast.Writer
+	lastOutput atomic.Int64 // unix nanoseconds of the latest PTY read
+	persistPath string // where the scrollback is saved; "" disables
+	saveMu      sync.Mutex // serializes scrollback saves
+	saveClosed  bool       // set by Close's final save; later saves are skipped
+	idleDelay    time.Duration // see scrollbackIdleDelay; shortened in tests
+	saveInterval time.Duration // see scrollbackSaveInterval
 }
 
+// Scrollback is saved while a session runs, not just when it closes, so a
+// crash of the server loses at most a few seconds of history: once output
+// has paused for scrollbackIdleDelay, and at least every
+// scrollbackSaveInterval while output keeps coming.
+const (
+	scrollbackIdleDelay    = 2 * time.Second
+	scrollbackSaveInterval = 30 * time.Second
+)
+
 type RingBuffer struct {
 	mu   sync.Mutex
 	buf  []byte
@@ -80,6 +102,8 @@ func NewSession(id, workDir, command string, args []string, scrollbackSize int,
 		clients:    make(map[string]chan []byte),
 		scrollback: NewRingBuffer(scrollbackSize),
 		done:       make(chan struct{}),
+		idleDelay:    scrollbackIdleDelay,
+		saveInterval: scrollbackSaveInterval,
 	}
 }
 
@@ -99,10 +123,55 @@ func (s *Session) Start() error {
 
 	// Start fan-out goroutine
 	go s.fanOut()
+	go s.persistLoop()
 
 	return nil
 }
 
+// persistLoop saves the scrollback when output goes idle, or has gone on
+// for the save interval since the last save, until the session ends.
+func (s *Session) persistLoop() {
+	ticker := time.NewTicker(s.idleDelay / 2)
+	defer ticker.Stop()
+	var saved int64 // lastOutput covered by the last save
+	lastSave := time.Now()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			last := s.lastOutput.Load()
+			if last == saved || s.getPersistPath() == "" {
+				continue
+			}
+			idle := now.Sub(time.Unix(0, last)) >= s.idleDelay
+			if idle || now.Sub(lastSave) >= s.saveInterval {
+				s.persistScrollback(false)
+				saved, lastSave = last, now
+			}
+		}
+	}
+}
+
+// persistScrollback saves the scrollback to the persist path, if any. The
+// final save, from Close, stops any later ones so a pane that is destroyed
+// right after does not get its file written again.
+func (s *Session) persistScrollback(final bool) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if s.saveClosed {
+		return
+	}
+	s.saveClosed = final
+	path := s.getPersistPath()
+	if path == "" {
+		return
+	}
+	if err := saveScrollback(path, s.scrollback.Bytes()); err != nil {
+		log.Printf("Error saving scrollback for session %s: %v", s.ID, err)
+	}
+}
+
 func (s *Session) fanOut() {
 	defer func() {
 		close(s.done)
@@ -115,11 +184,18 @@ func (s *Session) fanOut() {
 			data := make([]byte, n)
 			copy(data, buf[:n])
 
+			s.lastOutput.Store(time.Now().UnixNano())
+
 			// Write to scrollback
 			s.scrollback.Write(data)
 
 			// Fan out to all clients
 			s.mu.RLock()
+			if s.recorder != nil {
+				if err := s.recorder.Output(data); err != nil {
+					log.Printf("Recording write error for session %s: %v", s.ID, err)
+				}
+			}
 			for _, ch := range s.clients {
 				select {
 				case ch <- data:
@@ -149,12 +225,64 @@ func (s *Session) Resize(rows, cols uint16) error {
 	if s.ptmx == nil {
 		return nil
 	}
+	s.mu.Lock()
+	s.rows, s.cols = rows, cols
+	if s.recorder != nil {
+		s.recorder.Resize(int(cols), int(rows))
+	}
+	s.mu.Unlock()
 	return pty.Setsize(s.ptmx, &pty.Winsize{
 		Rows: rows,
 		Cols: cols,
 	})
 }
 
+// StartRecording begins writing the session's output and resizes to a new
+// asciicast v2 file at path. It fails if a recording is already running.
+func (s *Session) StartRecording(path, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+	if s.recorder != nil {
+		return fmt.Errorf("session %s is already recording to %s", s.ID, s.recorder.Path())
+	}
+	rec, err := asciicast.Create(path, asciicast.Header{
+		Width:  int(s.cols),
+		Height: int(s.rows),
+		Title:  title,
+		Env:    map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		return err
+	}
+	s.recorder = rec
+	return nil
+}
+
+// StopRecording closes the current recording, if any.
+func (s *Session) StopRecording() error {
+	s.mu.Lock()
+	rec := s.recorder
+	s.recorder = nil
+	s.mu.Unlock()
+	if rec == nil {
+		return nil
+	}
+	return rec.Close()
+}
+
+// RecordingPath returns the file the session is recording to, or "".
+func (s *Session) RecordingPath() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.recorder == nil {
+		return ""
+	}
+	return s.recorder.Path()
+}
+
 func (s *Session) Subscribe(clientID string) (<-chan []byte, []byte) {
 	ch := make(chan []byte, 256)
 
@@ -167,6 +295,33 @@ func (s *Session) Subscribe(clientID string) (<-chan []byte, []byte) {
 	return ch, history
 }
 
+// LastOutput returns when the session last produced output, or the zero
+// time if it hasn't yet.
+func (s *Session) LastOutput() time.Time {
+	ns := s.lastOutput.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// Scrollback returns a copy of the buffered output without subscribing.
+func (s *Session) Scrollback() []byte {
+	return s.scrollback.Bytes()
+}
+
+func (s *Session) setPersistPath(path string) {
+	s.mu.Lock()
+	s.persistPath = path
+	s.mu.Unlock()
+}
+
+func (s *Session) getPersistPath() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.persistPath
+}
+
 func (s *Session) Unsubscribe(clientID string) {
 	s.mu.Lock()
 	if ch, ok := s.clients[clientID]; ok {
@@ -197,6 +352,10 @@ func (s *Session) Close() error {
 	}
 	s.mu.Unlock()
 
+	if err := s.StopRecording(); err != nil {
+		log.Printf("Error closing recording for session %s: %v", s.ID, err)
+	}
+
 	// Close PTY
 	if s.ptmx != nil {
 		s.ptmx.Close()
@@ -208,6 +367,9 @@ func (s *Session) Close() error {
 		s.cmd.Wait()
 	}
 
+	// The tmux session lives on, so keep its history for the next attach.
+	s.persistScrollback(true)
+
 	return nil
 }
 
@@ -217,6 +379,9 @@ func (s *Session) Destroy(tmuxName string) error {
 	if err := s.Close(); err != nil {
 		log.Printf("Error closing PTY session %s: %v", s.ID, err)
 	}
+	if path := s.getPersistPath(); path != "" {
+		os.Remove(path)
+	}
 	// Kill the actual tmux server-side session
 	if tmux.HasSession(tmuxName) {
 		return tmux.KillSession(tmuxName)
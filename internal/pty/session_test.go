@@ -1,7 +1,13 @@
 package pty
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/asciicast"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -114,3 +120,98 @@ func TestSubscribeMultipleClients(t *testing.T) {
 	assert.Len(t, sess.clients, 1)
 	sess.mu.RUnlock()
 }
+
+func TestSessionRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane-1.cast")
+	sess := NewSession("pane-1", t.TempDir(), "sh", []string{"-c", "printf recorded"}, 4096, nil)
+
+	require.NoError(t, sess.StartRecording(path, "pane-1"))
+	assert.Error(t, sess.StartRecording(path, "pane-1"), "only one recording at a time")
+	assert.Equal(t, path, sess.RecordingPath())
+
+	require.NoError(t, sess.Start())
+	select {
+	case <-sess.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not exit")
+	}
+	require.NoError(t, sess.Close())
+	assert.Empty(t, sess.RecordingPath())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	h, events, err := asciicast.Read(f)
+	require.NoError(t, err)
+	assert.Equal(t, "pane-1", h.Title)
+
+	var out strings.Builder
+	for _, e := range events {
+		out.WriteString(e.Data)
+	}
+	assert.Contains(t, out.String(), "recorded")
+}
+
+func TestSessionPersistsScrollbackOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrollback", "pane-1.gz")
+	sess := NewSession("pane-1", t.TempDir(), "sh", []string{"-c", "printf persisted"}, 4096, nil)
+	sess.persistPath = path
+
+	require.NoError(t, sess.Start())
+	select {
+	case <-sess.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not exit")
+	}
+	require.NoError(t, sess.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	history, err := loadScrollback(path, 4096)
+	require.NoError(t, err)
+	assert.Contains(t, string(history), "persisted")
+
+	require.NoError(t, sess.Destroy("clawide-test-no-such-session"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Destroy drops saved scrollback")
+}
+
+func TestSessionSavesScrollbackWhenIdle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane-1.gz")
+	sess := NewSession("pane-1", t.TempDir(), "sh", []string{"-c", "printf saved; sleep 5"}, 4096, nil)
+	sess.persistPath = path
+	sess.idleDelay = 50 * time.Millisecond
+	require.NoError(t, sess.Start())
+	defer sess.Close()
+
+	// Saved while the command still runs, so a crash would not lose it.
+	require.Eventually(t, func() bool {
+		history, err := loadScrollback(path, 4096)
+		return err == nil && strings.Contains(string(history), "saved")
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestLoadScrollback(t *testing.T) {
+	dir := t.TempDir()
+
+	history, err := loadScrollback(scrollbackPath(dir, "missing"), 16)
+	require.NoError(t, err)
+	assert.Nil(t, history)
+
+	path := scrollbackPath(dir, "pane-1")
+	require.NoError(t, saveScrollback(path, []byte("0123456789abcdef")))
+	history, err = loadScrollback(path, 16)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", string(history))
+
+	// A smaller scrollback size keeps only the newest bytes.
+	history, err = loadScrollback(path, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "cdef", string(history))
+
+	require.NoError(t, os.WriteFile(path, []byte("not gzip"), 0600))
+	_, err = loadScrollback(path, 16)
+	assert.Error(t, err)
+}
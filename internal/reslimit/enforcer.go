@@ -0,0 +1,310 @@
+// Package reslimit enforces per-project and per-pane resource limits on the
+// process trees running in terminal panes.
+package reslimit
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/notify"
+	"github.com/davydany/ClawIDE/internal/procstats"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/google/uuid"
+)
+
+// CheckInterval is how often pane usage is compared with the limits.
+const CheckInterval = 10 * time.Second
+
+// Limit names, used in notifications and to track breaches.
+const (
+	limitRSS  = "memory"
+	limitCPU  = "CPU"
+	limitWall = "run time"
+)
+
+// Enforcer samples the panes that have limits and acts on those over them.
+// Each breach is acted on once; the pane must drop back under the limit
+// before the same limit fires again.
+type Enforcer struct {
+	store    *store.Store
+	sampler  *procstats.Sampler
+	notifier *notify.Center
+
+	// Swapped out in tests.
+	panePIDs  func() (map[string]int32, error)
+	interrupt func(tmuxName string) error
+	kill      func(snap *procstats.Snapshot, pid int32) error
+	now       func() time.Time
+
+	mu      sync.Mutex
+	cpuOver map[string]time.Time // pane ID -> when CPU first went over the max
+	tripped map[string]string    // pane ID + limit -> breach already acted on
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewEnforcer returns an Enforcer. Call Start to begin checking.
+func NewEnforcer(st *store.Store, sampler *procstats.Sampler, notifier *notify.Center) *Enforcer {
+	return &Enforcer{
+		store:     st,
+		sampler:   sampler,
+		notifier:  notifier,
+		panePIDs:  tmux.PanePIDs,
+		interrupt: func(name string) error { return tmux.SendControl(name, "C-c") },
+		kill:      func(snap *procstats.Snapshot, pid int32) error { return snap.KillTree(pid) },
+		now:       time.Now,
+		cpuOver:   make(map[string]time.Time),
+		tripped:   make(map[string]string),
+	}
+}
+
+// Start checks the limits every CheckInterval until Stop.
+func (e *Enforcer) Start() {
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				e.Check()
+			}
+		}
+	}()
+}
+
+// Stop ends the loop started by Start.
+func (e *Enforcer) Stop() {
+	if e.stop == nil {
+		return
+	}
+	close(e.stop)
+	<-e.done
+}
+
+// limitedPane is a pane together with the limits that apply to it.
+type limitedPane struct {
+	sess   model.Session
+	pane   *model.PaneNode
+	limits model.ResourceLimits
+}
+
+// Effective returns the limits for a pane: the project's, with any the pane
+// sets itself taking precedence.
+func Effective(project, pane *model.ResourceLimits) model.ResourceLimits {
+	var l model.ResourceLimits
+	if project != nil {
+		l = *project
+	}
+	if pane != nil {
+		l = l.Merge(*pane)
+	}
+	return l
+}
+
+// limitedPanes lists every pane with at least one limit.
+func (e *Enforcer) limitedPanes() []limitedPane {
+	projects := map[string]*model.ResourceLimits{}
+	for _, p := range e.store.GetProjects() {
+		projects[p.ID] = p.ResourceLimits
+	}
+	var out []limitedPane
+	for _, sess := range e.store.GetAllSessions() {
+		if sess.Layout == nil {
+			continue
+		}
+		for _, paneID := range sess.Layout.CollectLeaves() {
+			pane, _ := sess.Layout.FindPane(paneID)
+			limits := Effective(projects[sess.ProjectID], pane.ResourceLimits)
+			if !limits.IsZero() {
+				out = append(out, limitedPane{sess: sess, pane: pane, limits: limits})
+			}
+		}
+	}
+	return out
+}
+
+// Check compares every limited pane's usage with its limits once.
+func (e *Enforcer) Check() {
+	panes := e.limitedPanes()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(panes) == 0 {
+		e.cpuOver = make(map[string]time.Time)
+		e.tripped = make(map[string]string)
+		return
+	}
+
+	snap, err := e.sampler.Sample()
+	if err != nil {
+		log.Printf("[reslimit] sample: %v", err)
+		return
+	}
+	pids, err := e.panePIDs()
+	if err != nil {
+		log.Printf("[reslimit] %s panes: %v", tmux.Binary(), err)
+		return
+	}
+
+	now := e.now()
+	seen := map[string]bool{}
+	for _, lp := range panes {
+		paneID := lp.pane.PaneID
+		pid, ok := pids[tmux.TmuxName(paneID)]
+		if !ok {
+			continue
+		}
+		usage, _, ok := snap.Tree(pid)
+		if !ok {
+			continue
+		}
+		seen[paneID] = true
+		l := lp.limits
+
+		// Memory
+		if l.MaxRSSMB > 0 && usage.RSSBytes > uint64(l.MaxRSSMB)<<20 {
+			e.breach(lp, snap, pid, limitRSS, "", l.RSSAction,
+				fmt.Sprintf("using %s of memory, over its %d MB limit", formatBytes(usage.RSSBytes), l.MaxRSSMB))
+		} else {
+			e.clear(paneID, limitRSS)
+		}
+
+		// CPU, sustained
+		if l.MaxCPUPercent > 0 && usage.CPUPercent > l.MaxCPUPercent {
+			since, ok := e.cpuOver[paneID]
+			if !ok {
+				since = now
+				e.cpuOver[paneID] = now
+			}
+			if now.Sub(since) >= time.Duration(l.CPUSeconds)*time.Second {
+				e.breach(lp, snap, pid, limitCPU, "", l.CPUAction,
+					fmt.Sprintf("at %.0f%% CPU for over %ds, above its %.0f%% limit", usage.CPUPercent, l.CPUSeconds, l.MaxCPUPercent))
+			}
+		} else {
+			delete(e.cpuOver, paneID)
+			e.clear(paneID, limitCPU)
+		}
+
+		// Wall clock of the running command, shell panes only
+		if cmd, ok := foreground(snap, pid); ok && l.MaxWallSeconds > 0 && lp.pane.EffectivePaneType() == model.PaneTypeShell &&
+			now.Sub(cmd.Started) >= time.Duration(l.MaxWallSeconds)*time.Second {
+			// Keyed by PID so the next command gets its own allowance.
+			e.breach(lp, snap, pid, limitWall, fmt.Sprint(cmd.PID), l.WallAction,
+				fmt.Sprintf("running %s for %s, over its %ds limit", cmd.Name, now.Sub(cmd.Started).Round(time.Second), l.MaxWallSeconds))
+		} else {
+			e.clear(paneID, limitWall)
+		}
+	}
+
+	// Forget panes that went away.
+	for paneID := range e.cpuOver {
+		if !seen[paneID] {
+			delete(e.cpuOver, paneID)
+		}
+	}
+	for key := range e.tripped {
+		if paneID, _, _ := strings.Cut(key, "\x00"); !seen[paneID] {
+			delete(e.tripped, key)
+		}
+	}
+}
+
+// foreground returns the oldest child of the pane's shell: the command the
+// shell is running. ok is false when the shell is idle.
+func foreground(snap *procstats.Snapshot, shell int32) (procstats.Proc, bool) {
+	var oldest procstats.Proc
+	found := false
+	for _, c := range snap.Children(shell) {
+		if c.Started.IsZero() {
+			continue
+		}
+		if !found || c.Started.Before(oldest.Started) {
+			oldest, found = c, true
+		}
+	}
+	return oldest, found
+}
+
+func trippedKey(paneID, limit string) string { return paneID + "\x00" + limit }
+
+// clear re-arms a limit once the pane is back under it. Callers hold e.mu.
+func (e *Enforcer) clear(paneID, limit string) {
+	delete(e.tripped, trippedKey(paneID, limit))
+}
+
+// breach acts on a limit unless this breach (identified by episode) was
+// already acted on. Callers hold e.mu.
+func (e *Enforcer) breach(lp limitedPane, snap *procstats.Snapshot, pid int32, limit, episode, action, detail string) {
+	key := trippedKey(lp.pane.PaneID, limit)
+	if acted, ok := e.tripped[key]; ok && acted == episode {
+		return
+	}
+	e.tripped[key] = episode
+
+	tmuxName := tmux.TmuxName(lp.pane.PaneID)
+	title := "Pane over " + limit + " limit"
+	level := "warning"
+	switch action {
+	case model.LimitActionInterrupt:
+		if err := e.interrupt(tmuxName); err != nil {
+			log.Printf("[reslimit] interrupt %s: %v", tmuxName, err)
+			detail += fmt.Sprintf(". Sending Ctrl-C failed: %v", err)
+		} else {
+			detail += ". Sent Ctrl-C."
+		}
+		title = "Pane interrupted: " + limit + " limit"
+		level = "error"
+	case model.LimitActionKill:
+		if err := e.kill(snap, pid); err != nil {
+			log.Printf("[reslimit] kill %s: %v", tmuxName, err)
+			detail += fmt.Sprintf(". Killing it failed: %v", err)
+		} else {
+			detail += ". Killed its processes."
+		}
+		title = "Pane killed: " + limit + " limit"
+		level = "error"
+	default:
+		detail += "."
+	}
+	log.Printf("[reslimit] %s %s", tmuxName, detail)
+	e.notify(lp, title, level, detail)
+}
+
+func (e *Enforcer) notify(lp limitedPane, title, level, detail string) {
+	where := lp.sess.Name
+	if lp.pane.Name != "" {
+		where += " / " + lp.pane.Name
+	}
+	notif := model.Notification{
+		ID:        uuid.New().String(),
+		Title:     title,
+		Body:      fmt.Sprintf("%s is %s", where, detail),
+		Source:    "clawide",
+		Level:     level,
+		ProjectID: lp.sess.ProjectID,
+		SessionID: lp.sess.ID,
+		FeatureID: lp.sess.FeatureID,
+		PaneID:    lp.pane.PaneID,
+		CreatedAt: e.now(),
+	}
+	if _, _, err := e.notifier.Post(notif); err != nil {
+		log.Printf("[reslimit] notification add: %v", err)
+	}
+}
+
+func formatBytes(b uint64) string {
+	if b >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(b)/(1<<30))
+	}
+	return fmt.Sprintf("%d MB", b>>20)
+}
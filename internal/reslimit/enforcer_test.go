@@ -0,0 +1,121 @@
+package reslimit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/notify"
+	"github.com/davydany/ClawIDE/internal/procstats"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/davydany/ClawIDE/internal/tmux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEnforcer returns an Enforcer whose one pane, p1, is this test
+// process, and records the interrupts and kills it would send.
+func newTestEnforcer(t *testing.T, project, pane *model.ResourceLimits, paneType string) (*Enforcer, *store.NotificationStore, *[]string) {
+	t.Helper()
+	dir := t.TempDir()
+	st, err := store.New(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+	notifs, err := store.NewNotificationStore(filepath.Join(dir, "notifications.json"), 200)
+	require.NoError(t, err)
+
+	layout := model.NewLeafPane("p1")
+	layout.PaneType = paneType
+	layout.ResourceLimits = pane
+	require.NoError(t, st.AddProject(model.Project{ID: "proj-1", Name: "Test", ResourceLimits: project}))
+	require.NoError(t, st.AddSession(model.Session{ID: "s1", ProjectID: "proj-1", Name: "Main", Layout: layout}))
+
+	var actions []string
+	e := NewEnforcer(st, procstats.NewSampler(), notify.New(notifs, nil, nil, nil))
+	e.panePIDs = func() (map[string]int32, error) {
+		return map[string]int32{tmux.TmuxName("p1"): int32(os.Getpid())}, nil
+	}
+	e.interrupt = func(name string) error {
+		actions = append(actions, "interrupt "+name)
+		return nil
+	}
+	e.kill = func(_ *procstats.Snapshot, pid int32) error {
+		actions = append(actions, "kill")
+		return nil
+	}
+	return e, notifs, &actions
+}
+
+func TestEffective(t *testing.T) {
+	project := &model.ResourceLimits{MaxRSSMB: 1024, RSSAction: model.LimitActionNotify}
+	pane := &model.ResourceLimits{MaxRSSMB: 256, RSSAction: model.LimitActionKill}
+
+	assert.True(t, Effective(nil, nil).IsZero())
+	assert.Equal(t, *project, Effective(project, nil))
+	assert.Equal(t, *pane, Effective(project, pane))
+}
+
+func TestCheck_MemoryActsOncePerBreach(t *testing.T) {
+	// Any test binary uses more than 1 MB.
+	e, notifs, actions := newTestEnforcer(t, &model.ResourceLimits{MaxRSSMB: 1, RSSAction: model.LimitActionKill}, nil, "")
+
+	e.Check()
+	e.Check()
+
+	assert.Equal(t, []string{"kill"}, *actions)
+	all := notifs.GetAll()
+	require.Len(t, all, 1)
+	assert.Equal(t, "Pane killed: memory limit", all[0].Title)
+	assert.Equal(t, "error", all[0].Level)
+	assert.Equal(t, "p1", all[0].PaneID)
+	assert.Equal(t, "s1", all[0].SessionID)
+}
+
+func TestCheck_PaneOverridesProject(t *testing.T) {
+	e, notifs, actions := newTestEnforcer(t,
+		&model.ResourceLimits{MaxRSSMB: 1, RSSAction: model.LimitActionKill},
+		&model.ResourceLimits{MaxRSSMB: 1 << 20, RSSAction: model.LimitActionKill}, "")
+
+	e.Check()
+
+	assert.Empty(t, *actions)
+	assert.Empty(t, notifs.GetAll())
+}
+
+func TestCheck_WallClockShellOnly(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	limits := &model.ResourceLimits{MaxWallSeconds: 60, WallAction: model.LimitActionInterrupt}
+	later := func() time.Time { return time.Now().Add(time.Hour) }
+
+	t.Run("shell", func(t *testing.T) {
+		e, notifs, actions := newTestEnforcer(t, nil, limits, model.PaneTypeShell)
+		e.now = later
+		e.Check()
+		e.Check()
+
+		assert.Equal(t, []string{"interrupt " + tmux.TmuxName("p1")}, *actions)
+		require.Len(t, notifs.GetAll(), 1)
+		assert.Equal(t, "Pane interrupted: run time limit", notifs.GetAll()[0].Title)
+	})
+
+	t.Run("agent", func(t *testing.T) {
+		e, _, actions := newTestEnforcer(t, nil, limits, model.PaneTypeAgent)
+		e.now = later
+		e.Check()
+		assert.Empty(t, *actions)
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		e, _, actions := newTestEnforcer(t, nil, limits, model.PaneTypeShell)
+		e.Check()
+		assert.Empty(t, *actions)
+	})
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/davydany/ClawIDE/internal/handler"
+	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/openapi"
+	"github.com/davydany/ClawIDE/internal/version"
+	"github.com/go-chi/chi/v5"
+)
+
+// Request and response bodies of /api/v1 that the handlers declare inline.
+// They only describe the API in the OpenAPI document.
+type (
+	nameBody struct {
+		Name string `json:"name"`
+	}
+	projectCreateBody struct {
+		Name string `json:"name"`
+		Path string `json:"path"` // existing directory; ~ is expanded
+	}
+	sessionCreateBody struct {
+		Name       string `json:"name"`
+		Branch     string `json:"branch"`
+		WorkDir    string `json:"work_dir"`
+		TemplateID string `json:"template_id"` // layout template
+	}
+	paneSplitBody struct {
+		Direction string `json:"direction"` // horizontal or vertical
+		PaneType  string `json:"pane_type"` // agent (default) or shell
+	}
+	paneSplitResult struct {
+		Layout    *model.PaneNode `json:"layout"`
+		NewPaneID string          `json:"new_pane_id"`
+	}
+	paneCloseResult struct {
+		Layout        *model.PaneNode `json:"layout"`
+		SessionClosed bool            `json:"session_closed"`
+	}
+	paneOutput struct {
+		SessionID string `json:"session_id"`
+		PaneID    string `json:"pane_id"`
+		Output    string `json:"output"`
+	}
+	featureCreateBody struct {
+		Name       string `json:"name"`
+		BaseBranch string `json:"base_branch"`
+		Type       string `json:"type"` // feature (default), bugfix, ...
+		Prefix     string `json:"prefix"`
+	}
+	taskCreateBody struct {
+		Column      string `json:"column"` // column ID, e.g. backlog
+		Group       string `json:"group"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+	taskUpdateBody struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+	taskMoveBody struct {
+		ToColumn string `json:"to_column"`
+		ToGroup  string `json:"to_group"`
+		ToIndex  int    `json:"to_index"`
+	}
+	commentBody struct {
+		Body   string `json:"body"`
+		Author string `json:"author"` // defaults to "user"
+	}
+	noteBody struct {
+		FolderID string `json:"folder_id"`
+		Title    string `json:"title"`
+		Content  string `json:"content"`
+	}
+	bookmarkBody struct {
+		FolderID string `json:"folder_id"`
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		Emoji    string `json:"emoji"`
+	}
+	notificationBody struct {
+		Title          string `json:"title"`
+		Body           string `json:"body"`
+		Source         string `json:"source"`
+		Level          string `json:"level"` // info, success, warning or error
+		ProjectID      string `json:"project_id"`
+		SessionID      string `json:"session_id"`
+		FeatureID      string `json:"feature_id"`
+		PaneID         string `json:"pane_id"`
+		CWD            string `json:"cwd"`
+		IdempotencyKey string `json:"idempotency_key"`
+	}
+	scheduledJobBody struct {
+		Name           string `json:"name"`
+		JobType        string `json:"job_type"` // loop (default) or cron
+		Agent          string `json:"agent"`
+		Interval       string `json:"interval"`
+		CronExpression string `json:"cron_expression"`
+		Prompt         string `json:"prompt"`
+		TargetPaneID   string `json:"target_pane_id"`
+	}
+)
+
+// apiRoute is an /api/v1 route: its handler, the middleware between the
+// v1 router and the handler, and its description for the OpenAPI document.
+type apiRoute struct {
+	openapi.Operation
+	handler    http.HandlerFunc
+	middleware []func(http.Handler) http.Handler
+}
+
+// apiV1Routes lists every /api/v1 route. Most reuse the handlers behind the
+// web UI; middleware.APIv1 makes their responses JSON and their errors
+// APIError bodies.
+func (s *Server) apiV1Routes() []apiRoute {
+	h := s.handlers
+	project := []func(http.Handler) http.Handler{middleware.ProjectLoader(s.store), middleware.ProjectOwned(s.store)}
+	scoped := append(project[:2:2], middleware.ProjectQuery)
+	admin := []func(http.Handler) http.Handler{middleware.RequireAdmin}
+
+	return []apiRoute{
+		// Projects
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects", Tag: "projects", Summary: "List the projects you can see", Response: model.Project{}, List: true},
+			handler: middleware.Paginate(h.ListProjects)},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects", Tag: "projects", Summary: "Add a project for an existing directory", Request: projectCreateBody{}, Form: true, Response: model.Project{}, Status: http.StatusCreated},
+			handler: middleware.JSONForm(h.CreateProject), middleware: admin},
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}", Tag: "projects", Summary: "Get a project", Response: model.Project{}},
+			handler: h.GetProjectAPI, middleware: project},
+		{Operation: openapi.Operation{Method: "PATCH", Path: "/projects/{id}", Tag: "projects", Summary: "Rename a project", Request: nameBody{}, Response: model.Project{}},
+			handler: h.RenameProject, middleware: project},
+
+		// Sessions
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/sessions", Tag: "sessions", Summary: "List a project's sessions, features' included", Response: model.Session{}, List: true,
+			Query: []openapi.Param{{Name: "feature_id", Description: "Only this feature's sessions"}}},
+			handler: middleware.Paginate(h.ListSessions), middleware: project},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/sessions", Tag: "sessions", Summary: "Create a session", Request: sessionCreateBody{}, Form: true, Response: model.Session{}, Status: http.StatusCreated},
+			handler: middleware.JSONForm(h.CreateSession), middleware: project},
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/sessions/{sid}", Tag: "sessions", Summary: "Get a session and its pane layout", Response: model.Session{}},
+			handler: h.GetSessionAPI, middleware: project},
+		{Operation: openapi.Operation{Method: "PATCH", Path: "/projects/{id}/sessions/{sid}", Tag: "sessions", Summary: "Rename a session", Request: nameBody{}, Form: true, Response: model.Session{}},
+			handler: middleware.JSONForm(h.RenameSession), middleware: project},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/projects/{id}/sessions/{sid}", Tag: "sessions", Summary: "Delete a session and kill its panes"},
+			handler: h.DeleteSession, middleware: project},
+
+		// Panes
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/sessions/{sid}/panes", Tag: "panes", Summary: "List a session's panes", Response: handler.PaneInfo{}, List: true},
+			handler: middleware.Paginate(h.ListPanesAPI), middleware: project},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/sessions/{sid}/panes/{pid}/split", Tag: "panes", Summary: "Split a pane", Request: paneSplitBody{}, Form: true, Response: paneSplitResult{}},
+			handler: middleware.JSONForm(h.SplitPane), middleware: project},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/projects/{id}/sessions/{sid}/panes/{pid}", Tag: "panes", Summary: "Close a pane (the session too, if it is the last)", Response: paneCloseResult{}},
+			handler: h.ClosePane, middleware: project},
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/sessions/{sid}/panes/{pid}/output", Tag: "panes", Summary: "Read a pane's screen and scrollback as text", Response: paneOutput{},
+			Query: []openapi.Param{{Name: "lines", Type: "integer", Description: "Scrollback lines (default 100, max 10000)"}}},
+			handler: h.PaneOutput, middleware: project},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/sessions/{sid}/panes/{pid}/input", Tag: "panes", Summary: "Type input into a pane", Request: handler.PaneInput{}},
+			handler: h.SendPaneInput, middleware: project},
+
+		// Features
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/features", Tag: "features", Summary: "List a project's features", Response: model.Feature{}, List: true},
+			handler: middleware.Paginate(h.ListFeatures), middleware: project},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/features", Tag: "features", Summary: "Create a feature: a branch, worktree and session", Request: featureCreateBody{}, Form: true, Response: model.Feature{}, Status: http.StatusCreated},
+			handler: middleware.JSONForm(h.CreateFeature), middleware: project},
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/features/{fid}", Tag: "features", Summary: "Get a feature", Response: model.Feature{}},
+			handler: h.GetFeatureAPI, middleware: project},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/projects/{id}/features/{fid}", Tag: "features", Summary: "Move a feature and its worktree to the trash"},
+			handler: h.DeleteFeature, middleware: project},
+
+		// Tasks
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/tasks", Tag: "tasks", Summary: "List a project's tasks in board order", Response: handler.TaskItem{}, List: true,
+			Query: []openapi.Param{{Name: "column", Description: "Only tasks in this column ID"}}},
+			handler: middleware.Paginate(h.ListTasksAPI), middleware: scoped},
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/tasks/board", Tag: "tasks", Summary: "Get the whole task board", Response: model.Board{}},
+			handler: h.GetTaskBoard, middleware: scoped},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/tasks", Tag: "tasks", Summary: "Add a task", Request: taskCreateBody{}, Response: model.Task{}, Status: http.StatusCreated},
+			handler: h.CreateTask, middleware: scoped},
+		{Operation: openapi.Operation{Method: "PUT", Path: "/projects/{id}/tasks/{taskID}", Tag: "tasks", Summary: "Update a task's title and description", Request: taskUpdateBody{}, Response: model.Task{}},
+			handler: h.UpdateTask, middleware: scoped},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/projects/{id}/tasks/{taskID}", Tag: "tasks", Summary: "Delete a task"},
+			handler: h.DeleteTask, middleware: scoped},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/tasks/{taskID}/move", Tag: "tasks", Summary: "Move a task to another column, group or position", Request: taskMoveBody{}},
+			handler: h.MoveTask, middleware: scoped},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/tasks/{taskID}/comments", Tag: "tasks", Summary: "Comment on a task", Request: commentBody{}, Response: model.Comment{}, Status: http.StatusCreated},
+			handler: h.AddTaskComment, middleware: scoped},
+
+		// Notes
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/notes", Tag: "notes", Summary: "List a project's notes", Response: model.Note{}, List: true,
+			Query: []openapi.Param{{Name: "q", Description: "Search text"}, {Name: "folder_id", Description: "Only this folder's notes (empty: top level)"}}},
+			handler: middleware.Paginate(h.ListNotes), middleware: scoped},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/notes", Tag: "notes", Summary: "Create a note", Request: noteBody{}, Response: model.Note{}, Status: http.StatusCreated},
+			handler: h.CreateNote, middleware: scoped},
+		{Operation: openapi.Operation{Method: "PUT", Path: "/projects/{id}/notes/{noteID}", Tag: "notes", Summary: "Update a note", Request: noteBody{}, Response: model.Note{}},
+			handler: h.UpdateNote, middleware: scoped},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/projects/{id}/notes/{noteID}", Tag: "notes", Summary: "Delete a note"},
+			handler: h.DeleteNote, middleware: scoped},
+
+		// Bookmarks
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/bookmarks", Tag: "bookmarks", Summary: "List a project's bookmarks", Response: model.Bookmark{}, List: true,
+			Query: []openapi.Param{{Name: "q", Description: "Search text"}, {Name: "folder_id", Description: "Only this folder's bookmarks (empty: top level)"}}},
+			handler: middleware.Paginate(h.ListBookmarks), middleware: scoped},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/bookmarks", Tag: "bookmarks", Summary: "Create a bookmark", Request: bookmarkBody{}, Response: model.Bookmark{}, Status: http.StatusCreated},
+			handler: h.CreateBookmark, middleware: scoped},
+		{Operation: openapi.Operation{Method: "PUT", Path: "/projects/{id}/bookmarks/{bookmarkID}", Tag: "bookmarks", Summary: "Update a bookmark", Request: bookmarkBody{}, Response: model.Bookmark{}},
+			handler: h.UpdateBookmark, middleware: scoped},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/projects/{id}/bookmarks/{bookmarkID}", Tag: "bookmarks", Summary: "Delete a bookmark"},
+			handler: h.DeleteBookmark, middleware: scoped},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/bookmarks/{bookmarkID}/open", Tag: "bookmarks", Summary: "Open a bookmark in the browser tabs showing ClawIDE", Response: model.Bookmark{}},
+			handler: h.OpenBookmarkAPI, middleware: project},
+
+		// Docker
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/docker", Tag: "docker", Summary: "Get the Docker daemon, compose file and service status", Response: handler.DockerStatusResponse{}},
+			handler: h.DockerStatus, middleware: project},
+
+		// Notifications
+		{Operation: openapi.Operation{Method: "GET", Path: "/notifications", Tag: "notifications", Summary: "List notifications, newest first", Response: model.Notification{}, List: true,
+			Query: []openapi.Param{{Name: "unread_only", Type: "boolean"}}},
+			handler: middleware.Paginate(h.ListNotifications)},
+		{Operation: openapi.Operation{Method: "POST", Path: "/notifications", Tag: "notifications", Summary: "Post a notification", Request: notificationBody{}, Response: model.Notification{}, Status: http.StatusCreated},
+			handler: h.CreateNotification},
+		{Operation: openapi.Operation{Method: "POST", Path: "/notifications/read-all", Tag: "notifications", Summary: "Mark every notification you can see read"},
+			handler: h.MarkAllNotificationsRead},
+		{Operation: openapi.Operation{Method: "POST", Path: "/notifications/{notifID}/read", Tag: "notifications", Summary: "Mark a notification read"},
+			handler: h.MarkNotificationRead},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/notifications/{notifID}", Tag: "notifications", Summary: "Delete a notification"},
+			handler: h.DeleteNotification},
+
+		// PromptForge prompts
+		{Operation: openapi.Operation{Method: "GET", Path: "/prompts", Tag: "prompts", Summary: "List PromptForge prompts without their content", Response: handler.PromptSummary{}, List: true,
+			Query: []openapi.Param{{Name: "q", Description: "Search text"}, {Name: "folder_id", Description: "Only this folder's prompts (empty: top level)"}}},
+			handler: middleware.Paginate(h.ListPromptForgePrompts)},
+		{Operation: openapi.Operation{Method: "GET", Path: "/prompts/{promptID}", Tag: "prompts", Summary: "Get a PromptForge prompt", Response: model.Prompt{}},
+			handler: h.GetPromptForgePrompt},
+
+		// Scheduled jobs
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/scheduled-jobs", Tag: "scheduled-jobs", Summary: "List a project's scheduled jobs", Response: model.ScheduledJob{}, List: true},
+			handler: middleware.Paginate(h.ListScheduledJobs), middleware: project},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/scheduled-jobs", Tag: "scheduled-jobs", Summary: "Create a scheduled job", Request: scheduledJobBody{}, Response: model.ScheduledJob{}, Status: http.StatusCreated},
+			handler: h.CreateScheduledJob, middleware: project},
+		{Operation: openapi.Operation{Method: "GET", Path: "/projects/{id}/scheduled-jobs/{jid}", Tag: "scheduled-jobs", Summary: "Get a scheduled job", Response: model.ScheduledJob{}},
+			handler: h.GetScheduledJob, middleware: project},
+		{Operation: openapi.Operation{Method: "PATCH", Path: "/projects/{id}/scheduled-jobs/{jid}", Tag: "scheduled-jobs", Summary: "Update the fields given of a scheduled job", Request: scheduledJobBody{}, Response: model.ScheduledJob{}},
+			handler: h.UpdateScheduledJob, middleware: project},
+		{Operation: openapi.Operation{Method: "DELETE", Path: "/projects/{id}/scheduled-jobs/{jid}", Tag: "scheduled-jobs", Summary: "Delete a scheduled job"},
+			handler: h.DeleteScheduledJob, middleware: project},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/scheduled-jobs/{jid}/start", Tag: "scheduled-jobs", Summary: "Start a scheduled job", Response: model.ScheduledJob{}},
+			handler: h.StartScheduledJob, middleware: project},
+		{Operation: openapi.Operation{Method: "POST", Path: "/projects/{id}/scheduled-jobs/{jid}/stop", Tag: "scheduled-jobs", Summary: "Stop a scheduled job", Response: model.ScheduledJob{}},
+			handler: h.StopScheduledJob, middleware: project},
+	}
+}
+
+// mountAPIV1 registers /api/v1 and its OpenAPI document at
+// /api/v1/openapi.json.
+func (s *Server) mountAPIV1(r chi.Router) {
+	routes := s.apiV1Routes()
+	ops := make([]openapi.Operation, len(routes))
+	for i, rt := range routes {
+		ops[i] = rt.Operation
+	}
+	doc, err := json.MarshalIndent(openapi.Build(openapi.Info{
+		Title:   "ClawIDE API",
+		Version: "1 (ClawIDE " + version.Version + ")",
+		Description: "Lists are paginated with limit and offset and wrapped as {\"data\": [...], \"pagination\": {...}}. " +
+			"Errors are {\"error\": {\"status\", \"code\", \"message\"}}.",
+	}, middleware.APIPrefix, ops, middleware.APIError{}, middleware.Page{}), "", "  ")
+	if err != nil {
+		log.Fatalf("building OpenAPI document: %v", err)
+	}
+
+	r.Route(middleware.APIPrefix, func(r chi.Router) {
+		r.Use(middleware.APIv1)
+		r.Use(middleware.NoCacheAPI)
+		r.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(doc)
+		})
+		for _, rt := range routes {
+			r.With(rt.middleware...).Method(rt.Method, rt.Path, rt.handler)
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/davydany/ClawIDE/internal/middleware"
+	"github.com/davydany/ClawIDE/internal/model"
 	"github.com/davydany/ClawIDE/web"
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
@@ -20,11 +21,25 @@ func (s *Server) setupRoutes() *chi.Mux {
 	r.Use(chimw.Recoverer)
 	r.Use(chimw.Compress(5))
 	r.Use(middleware.HTMXDetect)
+	r.Use(middleware.RequireAuth(s.auth))
 
 	// Static files
 	staticFS, _ := fs.Sub(web.StaticFS, "static")
 	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
+	// Service worker for Web Push, served from the root so its scope covers
+	// every page
+	r.Get("/sw.js", func(w http.ResponseWriter, r *http.Request) {
+		data, err := fs.ReadFile(staticFS, "js/sw.js")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(data)
+	})
+
 	// Favicon at root — browsers request /favicon.ico automatically
 	r.Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
 		f, err := staticFS.Open("favicon.ico")
@@ -38,51 +53,94 @@ func (s *Server) setupRoutes() *chi.Mux {
 		http.ServeContent(w, r, "favicon.ico", time.Time{}, f.(io.ReadSeeker))
 	})
 
+	// Authentication
+	r.Get("/login", s.handlers.LoginPage)
+	r.Post("/api/auth/login", s.handlers.Login)
+	r.Post("/api/auth/logout", s.handlers.Logout)
+	r.Get("/api/auth/status", s.handlers.AuthStatus)
+	r.Put("/api/auth/password", s.handlers.SetPassword)
+	r.Get("/api/auth/tokens", s.handlers.ListAPITokens)
+	r.Post("/api/auth/tokens", s.handlers.CreateAPIToken)
+	r.Delete("/api/auth/tokens/{tokenID}", s.handlers.DeleteAPIToken)
+
+	// User management. Anyone may list usernames (project owners need them to
+	// add members); changes are admin only.
+	r.Route("/api/auth/users", func(r chi.Router) {
+		r.Get("/", s.handlers.ListUsers)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireAdmin)
+			r.Post("/", s.handlers.CreateUser)
+			r.Put("/{userID}/password", s.handlers.ResetUserPassword)
+			r.Delete("/{userID}", s.handlers.DeleteUser)
+		})
+	})
+
+	// Versioned public JSON API and its OpenAPI document
+	s.mountAPIV1(r)
+
 	// Version
 	r.Get("/api/version", s.handlers.Version)
 
 	// Update
-	r.Post("/api/update/check", s.handlers.CheckForUpdate)
+	r.With(middleware.RequireAdmin).Post("/api/update/check", s.handlers.CheckForUpdate)
 	r.Get("/api/update/status", s.handlers.UpdateStatus)
-	r.Post("/api/update/apply", s.handlers.ApplyUpdate)
+	r.With(middleware.RequireAdmin).Post("/api/update/apply", s.handlers.ApplyUpdate)
 
 	// System stats
 	r.Get("/api/system/stats", s.handlers.SystemStats)
+	r.Get("/api/system/usage", s.handlers.ResourceUsage)
+
+	// Tmux paste buffer (one per server, holding whatever any pane copied)
+	r.With(middleware.RequireAdmin).Get("/api/tmux/buffer", s.handlers.TmuxPasteBuffer)
+
+	// Agent pane activity (working / idle / waiting)
+	r.Get("/api/agent-state", s.handlers.ListAgentStates)
+
+	// Scrollback search across every visible pane
+	r.Get("/api/search/scrollback", s.handlers.SearchScrollback)
 
-	// Tmux paste buffer
-	r.Get("/api/tmux/buffer", s.handlers.TmuxPasteBuffer)
+	// Feature dev server previews, proxied under ClawIDE's origin
+	r.Get("/preview/{fid}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+	})
+	r.HandleFunc("/preview/{fid}/*", s.handlers.Preview)
 
 	// Dashboard
 	r.Get("/", s.handlers.Dashboard)
 
 	// Settings
 	r.Get("/settings", s.handlers.SettingsPage)
-	r.Put("/api/settings", s.handlers.UpdateSettings)
+	r.With(middleware.RequireAdmin).Put("/api/settings", s.handlers.UpdateSettings)
 
 	// AI Settings
 	r.Get("/api/settings/ai", s.handlers.GetAISettings)
-	r.Put("/api/settings/ai", s.handlers.SetAISettings)
-	r.Post("/api/settings/ai/verify", s.handlers.VerifyAICredentials)
+	r.With(middleware.RequireAdmin).Put("/api/settings/ai", s.handlers.SetAISettings)
+	r.With(middleware.RequireAdmin).Post("/api/settings/ai/verify", s.handlers.VerifyAICredentials)
 
 	// Onboarding
-	r.Post("/api/onboarding/complete", s.handlers.CompleteOnboarding)
-	r.Post("/api/onboarding/workspace-tour-complete", s.handlers.CompleteWorkspaceTour)
-	r.Post("/api/onboarding/reset", s.handlers.ResetOnboarding)
+	r.With(middleware.RequireAdmin).Post("/api/onboarding/complete", s.handlers.CompleteOnboarding)
+	r.With(middleware.RequireAdmin).Post("/api/onboarding/workspace-tour-complete", s.handlers.CompleteWorkspaceTour)
+	r.With(middleware.RequireAdmin).Post("/api/onboarding/reset", s.handlers.ResetOnboarding)
 
 	// Project routes
 	r.Route("/projects", func(r chi.Router) {
 		r.Get("/", s.handlers.ListProjects)
-		r.Post("/", s.handlers.CreateProject)
+		// Only the admin adds projects: a project exposes a directory on
+		// the host and terminals running in it.
+		r.With(middleware.RequireAdmin).Post("/", s.handlers.CreateProject)
 		r.Post("/reorder", s.handlers.ReorderProjects)
 
 		// Wizard routes (before /{id} to avoid conflict)
-		r.Get("/wizard", s.handlers.ShowWizard)
-		r.Get("/wizard/languages", s.handlers.GetWizardLanguages)
-		r.Get("/wizard/providers", s.handlers.GetWizardProviders)
-		r.Get("/wizard/models", s.handlers.GetWizardModels)
-		r.Post("/wizard/create", s.handlers.CreateProjectFromWizard)
-		r.Get("/wizard/status/{jobID}", s.handlers.GetWizardStatus)
-		r.Post("/wizard/validate", s.handlers.ValidateWizardField)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireAdmin)
+			r.Get("/wizard", s.handlers.ShowWizard)
+			r.Get("/wizard/languages", s.handlers.GetWizardLanguages)
+			r.Get("/wizard/providers", s.handlers.GetWizardProviders)
+			r.Get("/wizard/models", s.handlers.GetWizardModels)
+			r.Post("/wizard/create", s.handlers.CreateProjectFromWizard)
+			r.Get("/wizard/status/{jobID}", s.handlers.GetWizardStatus)
+			r.Post("/wizard/validate", s.handlers.ValidateWizardField)
+		})
 
 		r.Route("/{id}", func(r chi.Router) {
 			r.Use(middleware.ProjectLoader(s.store))
@@ -90,16 +148,27 @@ func (s *Server) setupRoutes() *chi.Mux {
 
 			r.Get("/", s.handlers.ProjectWorkspace)
 			r.Patch("/", s.handlers.RenameProject)
-			r.Patch("/path", s.handlers.RenameProjectDirectory)
-			r.Delete("/", s.handlers.RemoveProjectFromClawIDE)
-			r.Post("/trash", s.handlers.TrashProject)
+
+			// Owner-only project administration
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireProjectRole(model.RoleOwner))
+				r.Patch("/path", s.handlers.RenameProjectDirectory)
+				r.Delete("/", s.handlers.RemoveProjectFromClawIDE)
+				r.Post("/trash", s.handlers.TrashProject)
+				r.Put("/members", s.handlers.SetProjectMembers)
+			})
+			r.Get("/members", s.handlers.ListProjectMembers)
 			r.Patch("/star", s.handlers.ToggleStar)
 			r.Patch("/color", s.handlers.UpdateProjectColor)
+			r.Put("/agent-profile", s.handlers.SetProjectAgentProfile)
+			r.Put("/resource-limits", s.handlers.SetProjectResourceLimits)
+			r.Get("/api/ports", s.handlers.ProjectPorts)
 
 			// Sessions
 			r.Get("/sessions/", s.handlers.ListSessions)
 			r.Post("/sessions/", s.handlers.CreateSession)
 			r.Route("/sessions/{sid}", func(r chi.Router) {
+				r.Use(middleware.ProjectOwned(s.store))
 				r.Patch("/", s.handlers.RenameSession)
 				r.Delete("/", s.handlers.DeleteSession)
 
@@ -109,8 +178,27 @@ func (s *Server) setupRoutes() *chi.Mux {
 				r.Patch("/panes/{pid}/resize", s.handlers.ResizePane)
 				r.Post("/panes/{pid}/move", s.handlers.MovePane)
 			r.Patch("/panes/{pid}/rename", s.handlers.RenamePane)
+				r.Post("/panes/{pid}/share", s.handlers.CreateShareLink)
+				r.Post("/panes/{pid}/recording", s.handlers.SetPaneRecording)
+				r.Put("/panes/{pid}/startup", s.handlers.SetPaneStartup)
+				r.Put("/panes/{pid}/agent-profile", s.handlers.SetPaneAgentProfile)
+				r.Put("/panes/{pid}/resource-limits", s.handlers.SetPaneResourceLimits)
+				r.Get("/panes/{pid}/output", s.handlers.PaneOutput)
 			})
 
+			// Terminal recordings (asciicast v2)
+			r.Get("/recordings", s.handlers.ListRecordings)
+			r.Get("/recordings/{name}", s.handlers.DownloadRecording)
+			r.Get("/recordings/{name}/replay", s.handlers.ReplayRecording)
+			r.Delete("/recordings/{name}", s.handlers.DeleteRecording)
+
+			// Send the same input to several panes
+			r.Post("/broadcast", s.handlers.BroadcastInput)
+
+			// Read-only share links
+			r.Get("/shares", s.handlers.ListShareLinks)
+			r.Delete("/shares/{shareID}", s.handlers.RevokeShareLink)
+
 			// Skills API
 			r.Get("/api/skills", s.handlers.ListSkills)
 			r.Post("/api/skills", s.handlers.CreateSkill)
@@ -144,11 +232,14 @@ func (s *Server) setupRoutes() *chi.Mux {
 			r.Get("/api/scheduled-jobs", s.handlers.ListScheduledJobs)
 			r.Post("/api/scheduled-jobs", s.handlers.CreateScheduledJob)
 			r.Get("/api/scheduled-jobs/cron-support", s.handlers.CronSupported)
-			r.Get("/api/scheduled-jobs/{jid}", s.handlers.GetScheduledJob)
-			r.Put("/api/scheduled-jobs/{jid}", s.handlers.UpdateScheduledJob)
-			r.Delete("/api/scheduled-jobs/{jid}", s.handlers.DeleteScheduledJob)
-			r.Post("/api/scheduled-jobs/{jid}/start", s.handlers.StartScheduledJob)
-			r.Post("/api/scheduled-jobs/{jid}/stop", s.handlers.StopScheduledJob)
+			r.Route("/api/scheduled-jobs/{jid}", func(r chi.Router) {
+				r.Use(middleware.ProjectOwned(s.store))
+				r.Get("/", s.handlers.GetScheduledJob)
+				r.Put("/", s.handlers.UpdateScheduledJob)
+				r.Delete("/", s.handlers.DeleteScheduledJob)
+				r.Post("/start", s.handlers.StartScheduledJob)
+				r.Post("/stop", s.handlers.StopScheduledJob)
+			})
 
 			// File browser API
 			r.Get("/api/files", s.handlers.ListFiles)
@@ -181,11 +272,16 @@ func (s *Server) setupRoutes() *chi.Mux {
 			r.Post("/api/base-branch", s.handlers.SetBaseBranch)
 
 			// Feature routes
+			r.Get("/features/", s.handlers.ListFeatures)
 			r.Post("/features/", s.handlers.CreateFeature)
 			r.Route("/features/{fid}", func(r chi.Router) {
+				r.Use(middleware.ProjectOwned(s.store))
 				r.Get("/", s.handlers.FeatureWorkspace)
 				r.Delete("/", s.handlers.DeleteFeature)
 				r.Patch("/color", s.handlers.UpdateFeatureColor)
+				r.Put("/agent-profile", s.handlers.SetFeatureAgentProfile)
+				r.Get("/api/ports", s.handlers.FeaturePorts)
+				r.Put("/preview", s.handlers.SetFeaturePreview)
 
 				// Feature sessions
 				r.Post("/sessions/", s.handlers.CreateFeatureSession)
@@ -224,15 +320,27 @@ func (s *Server) setupRoutes() *chi.Mux {
 		})
 	})
 
-	// Trash API (global, spans all projects)
-	r.Get("/api/trash", s.handlers.ListTrashedFeatures)
-	r.Post("/api/trash/{tid}/restore", s.handlers.RestoreTrashedFeature)
-	r.Delete("/api/trash/{tid}", s.handlers.PermanentlyDeleteTrashedFeature)
+	// tmux session reconciliation (global, admin only)
+	r.Route("/api/tmux", func(r chi.Router) {
+		r.Use(middleware.RequireAdmin)
+		r.Get("/reconcile", s.handlers.ReconcileTmux)
+		r.Post("/orphans/{name}/adopt", s.handlers.AdoptTmuxOrphan)
+		r.Delete("/orphans/{name}", s.handlers.KillTmuxOrphan)
+		r.Post("/dead-panes/recreate", s.handlers.RecreateDeadPanes)
+	})
 
-	// Project trash API
-	r.Get("/api/trash/projects", s.handlers.ListTrashedProjects)
-	r.Post("/api/trash/projects/{tid}/restore", s.handlers.RestoreTrashedProject)
-	r.Delete("/api/trash/projects/{tid}", s.handlers.PermanentlyDeleteTrashedProject)
+	// Trash API (global, spans all projects, admin only)
+	r.Route("/api/trash", func(r chi.Router) {
+		r.Use(middleware.RequireAdmin)
+		r.Get("/", s.handlers.ListTrashedFeatures)
+		r.Post("/{tid}/restore", s.handlers.RestoreTrashedFeature)
+		r.Delete("/{tid}", s.handlers.PermanentlyDeleteTrashedFeature)
+
+		// Project trash API
+		r.Get("/projects", s.handlers.ListTrashedProjects)
+		r.Post("/projects/{tid}/restore", s.handlers.RestoreTrashedProject)
+		r.Delete("/projects/{tid}", s.handlers.PermanentlyDeleteTrashedProject)
+	})
 
 	// Scratchpad API (global)
 	r.Get("/api/scratchpad", s.handlers.GetScratchpad)
@@ -272,8 +380,18 @@ func (s *Server) setupRoutes() *chi.Mux {
 		r.Delete("/{snippetID}", s.handlers.DeleteSnippet)
 	})
 
+	// Layout templates (global + project-scoped via project_id)
+	r.Route("/api/layout-templates", func(r chi.Router) {
+		r.Use(middleware.ProjectScope(s.store))
+		r.Get("/", s.handlers.ListLayoutTemplates)
+		r.Post("/", s.handlers.CreateLayoutTemplate)
+		r.Put("/{templateID}", s.handlers.UpdateLayoutTemplate)
+		r.Delete("/{templateID}", s.handlers.DeleteLayoutTemplate)
+	})
+
 	// Notes API (global + project-scoped via query param)
 	r.Route("/api/notes", func(r chi.Router) {
+		r.Use(middleware.ProjectScope(s.store))
 		r.Get("/", s.handlers.ListNotes)
 		r.Post("/", s.handlers.CreateNote)
 		r.Put("/{noteID}", s.handlers.UpdateNote)
@@ -295,9 +413,11 @@ func (s *Server) setupRoutes() *chi.Mux {
 
 	// Tasks API (global + project-scoped via query param). Storage: single tasks.md per scope
 	// under either .clawide/ (project) or ~/.clawide/ (global). Drag/drop rewrites the markdown.
+	// The aggregated board filters projects itself, so it sits outside ProjectScope.
+	r.Get("/api/tasks/board/aggregated", s.handlers.GetAggregatedTaskBoard)
 	r.Route("/api/tasks", func(r chi.Router) {
+		r.Use(middleware.ProjectScope(s.store))
 		r.Get("/board", s.handlers.GetTaskBoard)
-		r.Get("/board/aggregated", s.handlers.GetAggregatedTaskBoard)
 		r.Post("/", s.handlers.CreateTask)
 		r.Put("/{taskID}", s.handlers.UpdateTask)
 		r.Delete("/{taskID}", s.handlers.DeleteTask)
@@ -328,12 +448,46 @@ func (s *Server) setupRoutes() *chi.Mux {
 		r.Post("/commit", s.handlers.TaskGitCommit)
 	})
 
+	// Agent profiles (built-in from the AI CLI catalog + custom). Custom
+	// profiles decide what runs in everyone's agent panes, so edits are admin only.
+	r.Route("/api/agent-profiles", func(r chi.Router) {
+		r.Get("/", s.handlers.ListAgentProfiles)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireAdmin)
+			r.Post("/", s.handlers.CreateAgentProfile)
+			r.Put("/{profileID}", s.handlers.UpdateAgentProfile)
+			r.Delete("/{profileID}", s.handlers.DeleteAgentProfile)
+		})
+	})
+
+	// Outbound webhooks. They receive events from every project, so they are
+	// admin only.
+	r.Route("/api/webhooks", func(r chi.Router) {
+		r.Use(middleware.RequireAdmin)
+		r.Get("/", s.handlers.ListWebhooks)
+		r.Post("/", s.handlers.CreateWebhook)
+		r.Put("/{webhookID}", s.handlers.UpdateWebhook)
+		r.Delete("/{webhookID}", s.handlers.DeleteWebhook)
+		r.Get("/{webhookID}/deliveries", s.handlers.ListWebhookDeliveries)
+		r.Post("/{webhookID}/test", s.handlers.TestWebhook)
+	})
+
+	// Web Push. Each user manages the subscriptions of their own browsers.
+	r.Route("/api/push", func(r chi.Router) {
+		r.Get("/key", s.handlers.GetPushKey)
+		r.Get("/subscriptions", s.handlers.ListPushSubscriptions)
+		r.Put("/subscription", s.handlers.PutPushSubscription)
+		r.Delete("/subscription", s.handlers.DeletePushSubscription)
+		r.Post("/test", s.handlers.TestPushSubscription)
+	})
+
 	// AI CLI provider registry — lists installed providers + their models so the frontend can
 	// populate the Ask AI dropdown.
 	r.Get("/api/ai/providers", s.handlers.ListAIProviders)
 
 	// Bookmarks API (project-scoped via query param)
 	r.Route("/api/bookmarks", func(r chi.Router) {
+		r.Use(middleware.ProjectScope(s.store))
 		r.Get("/", s.handlers.ListBookmarks)
 		r.Post("/", s.handlers.CreateBookmark)
 		r.Put("/{bookmarkID}", s.handlers.UpdateBookmark)
@@ -367,6 +521,8 @@ func (s *Server) setupRoutes() *chi.Mux {
 		r.Get("/", s.handlers.ListNotifications)
 		r.Get("/unread-count", s.handlers.UnreadNotificationCount)
 		r.Get("/stream", s.handlers.NotificationStream)
+		r.With(middleware.RequireAdmin).Get("/rules", s.handlers.GetNotificationRules)
+		r.With(middleware.RequireAdmin).Put("/rules", s.handlers.UpdateNotificationRules)
 		r.Patch("/{notifID}/read", s.handlers.MarkNotificationRead)
 		r.Post("/read-all", s.handlers.MarkAllNotificationsRead)
 		r.Delete("/{notifID}", s.handlers.DeleteNotification)
@@ -374,8 +530,12 @@ func (s *Server) setupRoutes() *chi.Mux {
 
 	// Editor integration API
 	r.Get("/api/editors/available", s.handlers.AvailableEditors)
-	r.Post("/api/editor/open", s.handlers.OpenEditor)
-	r.Post("/api/editor/open-folder", s.handlers.OpenFolder)
+	r.With(middleware.RequireAdmin).Post("/api/editor/open", s.handlers.OpenEditor)
+	r.With(middleware.RequireAdmin).Post("/api/editor/open-folder", s.handlers.OpenFolder)
+
+	// Share links (public; the token is the credential)
+	r.Get("/share/{token}", s.handlers.SharePage)
+	r.Get("/ws/share/{token}", s.handlers.ShareWS)
 
 	// WebSocket endpoints (no project middleware, session ID is in URL)
 	r.Get("/ws/terminal/{sessionID}/{paneID}", s.handlers.TerminalWS)
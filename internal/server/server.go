@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/davydany/ClawIDE/internal/agentstate"
 	"github.com/davydany/ClawIDE/internal/aicli"
+	"github.com/davydany/ClawIDE/internal/auth"
 	"github.com/davydany/ClawIDE/internal/banner"
 	"github.com/davydany/ClawIDE/internal/config"
 	"github.com/davydany/ClawIDE/internal/handler"
 	"github.com/davydany/ClawIDE/internal/migration"
+	"github.com/davydany/ClawIDE/internal/notify"
+	"github.com/davydany/ClawIDE/internal/procstats"
 	"github.com/davydany/ClawIDE/internal/pty"
+	"github.com/davydany/ClawIDE/internal/reslimit"
 	"github.com/davydany/ClawIDE/internal/sse"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/davydany/ClawIDE/internal/tmpl"
@@ -21,6 +25,8 @@ import (
 	"github.com/davydany/ClawIDE/internal/trash"
 	"github.com/davydany/ClawIDE/internal/updater"
 	"github.com/davydany/ClawIDE/internal/version"
+	"github.com/davydany/ClawIDE/internal/webhook"
+	"github.com/davydany/ClawIDE/internal/webpush"
 	"github.com/davydany/ClawIDE/internal/wizard"
 )
 
@@ -30,9 +36,15 @@ type Server struct {
 	renderer      *tmpl.Renderer
 	ptyManager    *pty.Manager
 	handlers      *handler.Handlers
+	auth          *auth.Manager
 	http          *http.Server
 	updater       *updater.Updater
 	trashCleaner  *trash.Cleaner
+	tls           *tlsSetup
+	agentStates   *agentstate.Monitor
+	limits        *reslimit.Enforcer
+	webhooks      *webhook.Dispatcher
+	notifier      *notify.Center
 }
 
 func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
@@ -43,7 +55,18 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
 	}
 	log.Printf("%s check passed", tmux.Binary())
 
+	// Resolve TLS before building handlers: panes are told the API URL
+	// (http vs https) through cfg.LocalURL.
+	tlsCfg, err := setupTLS(cfg)
+	if err != nil {
+		log.Fatalf("failed to set up TLS: %v", err)
+	}
+	cfg.TLSEnabled = tlsCfg != nil
+
 	ptyMgr := pty.NewManager(cfg.MaxSessions, cfg.ScrollbackSize, cfg.AgentCommand)
+	if cfg.PersistScrollback {
+		ptyMgr.SetScrollbackDir(cfg.ScrollbackDir())
+	}
 
 	snippetStore, err := store.NewSnippetStore(cfg.SnippetsFilePath())
 	if err != nil {
@@ -55,6 +78,11 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
 		log.Fatalf("failed to load notification store: %v", err)
 	}
 
+	notificationRulesStore, err := store.NewNotificationRulesStore(cfg.NotificationRulesFilePath())
+	if err != nil {
+		log.Fatalf("failed to load notification rules: %v", err)
+	}
+
 	noteStore, err := store.NewNoteStore(cfg.NotesFilePath())
 	if err != nil {
 		log.Fatalf("failed to load note store: %v", err)
@@ -85,6 +113,52 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
 		log.Fatalf("failed to load global task store: %v", err)
 	}
 
+	tokenStore, err := store.NewTokenStore(cfg.TokensFilePath())
+	if err != nil {
+		log.Fatalf("failed to load api token store: %v", err)
+	}
+
+	userStore, err := store.NewUserStore(cfg.UsersFilePath())
+	if err != nil {
+		log.Fatalf("failed to load user store: %v", err)
+	}
+
+	authMgr, err := auth.NewManager(cfg, tokenStore, userStore)
+	if err != nil {
+		log.Fatalf("failed to initialize authentication: %v", err)
+	}
+
+	shareLinkStore, err := store.NewShareLinkStore(cfg.ShareLinksFilePath())
+	if err != nil {
+		log.Fatalf("failed to load share link store: %v", err)
+	}
+
+	layoutTemplateStore, err := store.NewLayoutTemplateStore(cfg.LayoutTemplatesFilePath())
+	if err != nil {
+		log.Fatalf("failed to load layout template store: %v", err)
+	}
+
+	agentProfileStore, err := store.NewAgentProfileStore(cfg.AgentProfilesFilePath())
+	if err != nil {
+		log.Fatalf("failed to load agent profile store: %v", err)
+	}
+
+	webhookStore, err := store.NewWebhookStore(cfg.WebhooksFilePath(), cfg.WebhookDeliveriesFilePath(), 500)
+	if err != nil {
+		log.Fatalf("failed to load webhook store: %v", err)
+	}
+	webhooks := webhook.New(webhookStore)
+
+	pushSubscriptionStore, err := store.NewPushSubscriptionStore(cfg.PushSubscriptionsFilePath())
+	if err != nil {
+		log.Fatalf("failed to load push subscription store: %v", err)
+	}
+	vapidKeys, err := webpush.LoadOrCreateVAPIDKeys(cfg.VAPIDKeysPath())
+	if err != nil {
+		log.Fatalf("failed to load web push keys: %v", err)
+	}
+	pusher := webpush.NewPusher(webpush.NewSender(vapidKeys), pushSubscriptionStore, st)
+
 	// AI CLI provider registry — probes PATH once at startup and caches the result. Providers
 	// not installed still register (so they appear in the UI as "unavailable" rather than
 	// silently missing), but Ask AI calls to them return 501.
@@ -99,14 +173,21 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
 	}
 
 	sseHub := sse.NewHub()
+	sseHub.OnBroadcast(webhooks.Notify)
+	sseHub.OnBroadcast(pusher.Notify)
+	notifier := notify.New(notificationStore, notificationRulesStore, sseHub, st)
 
 	// Backfill ActiveBranch for projects that don't have one set
 	migration.BackfillActiveBranch(st)
 
-	// Recover tmux sessions from previous run
-	recoverTmuxSessions(st)
+	upd := updater.New(cfg, notifier)
+	upd.SetWebhooks(webhooks)
+
+	agentStates := agentstate.NewMonitor(st, ptyMgr, sseHub)
+	agentstate.NewApprovalNotifier(cfg, agentStates, notifier)
 
-	upd := updater.New(cfg, notificationStore, sseHub)
+	usageSampler := procstats.NewSampler()
+	limitEnforcer := reslimit.NewEnforcer(st, usageSampler, notifier)
 
 	// Initialize wizard components
 	wizardJobs := wizard.NewJobTracker()
@@ -117,14 +198,23 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
 	wizardGen := wizard.NewGenerator(tmplRegistry, wizardJobs)
 
 	s := &Server{
-		cfg:        cfg,
-		store:      st,
-		renderer:   renderer,
-		ptyManager: ptyMgr,
-		handlers:   handler.New(cfg, st, renderer, ptyMgr, snippetStore, notificationStore, noteStore, bookmarkStore, voiceBoxStore, scratchpadStore, promptForgeStore, globalTaskStore, aiRegistry, sseHub, upd, wizardJobs, wizardGen),
-		updater:    upd,
+		cfg:         cfg,
+		store:       st,
+		renderer:    renderer,
+		ptyManager:  ptyMgr,
+		handlers:    handler.New(cfg, st, renderer, ptyMgr, snippetStore, notificationStore, noteStore, bookmarkStore, voiceBoxStore, scratchpadStore, promptForgeStore, globalTaskStore, aiRegistry, sseHub, upd, wizardJobs, wizardGen, authMgr, shareLinkStore, agentStates, layoutTemplateStore, agentProfileStore, usageSampler, webhookStore, webhooks, pushSubscriptionStore, pusher, notifier),
+		auth:        authMgr,
+		updater:     upd,
+		tls:         tlsCfg,
+		agentStates: agentStates,
+		limits:      limitEnforcer,
+		webhooks:    webhooks,
+		notifier:    notifier,
 	}
 
+	// Report tmux sessions left over from the previous run
+	s.handlers.ReportTmuxSessions()
+
 	router := s.setupRoutes()
 
 	s.http = &http.Server{
@@ -137,6 +227,10 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
 
 	upd.Start()
 
+	agentStates.Start()
+	limitEnforcer.Start()
+	notifier.Start()
+
 	tc := trash.NewCleaner(st)
 	tc.Start()
 	s.trashCleaner = tc
@@ -144,54 +238,24 @@ func New(cfg *config.Config, st *store.Store, renderer *tmpl.Renderer) *Server {
 	return s
 }
 
-// recoverTmuxSessions cleans up orphan tmux sessions that are not referenced in state.json.
-func recoverTmuxSessions(st *store.Store) {
-	tmuxSessions, err := tmux.ListClawIDESessions()
-	if err != nil {
-		log.Printf("Warning: could not list %s sessions: %v", tmux.Binary(), err)
-		return
-	}
-
-	if len(tmuxSessions) == 0 {
-		return
-	}
-
-	muxName := tmux.Binary()
-
-	// Build set of all valid pane IDs from stored sessions
-	validPanes := make(map[string]bool)
-	allSessions := st.GetAllSessions()
-	for _, sess := range allSessions {
-		if sess.Layout != nil {
-			for _, paneID := range sess.Layout.CollectLeaves() {
-				validPanes["clawide-"+paneID] = true
-			}
-		}
+func (s *Server) Start() error {
+	if !s.auth.Enabled() && !auth.IsLoopbackHost(s.cfg.Host) {
+		return fmt.Errorf("refusing to listen on %s without a password, since anyone who can reach this port would get a shell: set CLAWIDE_PASSWORD, or start on 127.0.0.1 and set a password under Settings > Security first", s.cfg.Addr())
 	}
 
-	// Kill orphan multiplexer sessions (not referenced in state.json)
-	surviving := 0
-	for _, tmuxSess := range tmuxSessions {
-		if validPanes[tmuxSess] {
-			paneID := strings.TrimPrefix(tmuxSess, "clawide-")
-			log.Printf("Surviving %s session: %s (pane %s) — will reconnect lazily", muxName, tmuxSess, paneID)
-			surviving++
-		} else {
-			log.Printf("Killing orphan %s session: %s", muxName, tmuxSess)
-			if err := tmux.KillSession(tmuxSess); err != nil {
-				log.Printf("Warning: failed to kill orphan %s session %s: %v", muxName, tmuxSess, err)
-			}
-		}
+	var tlsInfo *banner.TLSInfo
+	if s.tls != nil {
+		tlsInfo = &banner.TLSInfo{Fingerprint: s.tls.fingerprint, CAFile: s.tls.caFile}
 	}
+	banner.Print(s.cfg.Host, s.cfg.Port, version.String(), tlsInfo)
 
-	if surviving > 0 {
-		log.Printf("Found %d surviving %s sessions (will reconnect on WebSocket connect)", surviving, muxName)
+	var err error
+	if s.tls != nil {
+		err = s.http.ListenAndServeTLS(s.tls.certFile, s.tls.keyFile)
+	} else {
+		err = s.http.ListenAndServe()
 	}
-}
-
-func (s *Server) Start() error {
-	banner.Print(s.cfg.Host, s.cfg.Port, version.String())
-	if err := s.http.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server error: %w", err)
 	}
 	return nil
@@ -202,6 +266,10 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	s.handlers.StopAllMCPProcesses()
 	s.trashCleaner.Stop()
 	s.updater.Stop()
+	s.agentStates.Stop()
+	s.limits.Stop()
+	s.notifier.Stop()
+	s.webhooks.Stop()
 	s.ptyManager.CloseAll()
 	return s.http.Shutdown(ctx)
 }
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/davydany/ClawIDE/internal/auth"
+	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/sysinfo"
+	"github.com/davydany/ClawIDE/internal/tlscert"
+)
+
+// tlsSetup describes the certificate the server listens with.
+type tlsSetup struct {
+	certFile    string
+	keyFile     string
+	caFile      string // only set for the auto-generated local CA
+	fingerprint string
+}
+
+// wantTLS applies the --tls mode.
+func wantTLS(cfg *config.Config) bool {
+	switch cfg.TLS {
+	case config.TLSOn:
+		return true
+	case config.TLSOff:
+		return false
+	default:
+		return cfg.TLSCert != "" || !auth.IsLoopbackHost(cfg.Host)
+	}
+}
+
+// setupTLS resolves the certificate to serve, generating the local CA and
+// server certificate under DataDir when none was configured. It returns nil
+// when TLS is off.
+func setupTLS(cfg *config.Config) (*tlsSetup, error) {
+	if !wantTLS(cfg) {
+		return nil, nil
+	}
+
+	setup := &tlsSetup{certFile: cfg.TLSCert, keyFile: cfg.TLSKey}
+	if setup.certFile == "" {
+		files, err := tlscert.EnsureSelfSigned(cfg.TLSDir(), certHosts(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("generating self-signed certificate: %w", err)
+		}
+		setup.certFile, setup.keyFile, setup.caFile = files.CertFile, files.KeyFile, files.CAFile
+	}
+
+	fp, err := tlscert.Fingerprint(setup.certFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading TLS certificate: %w", err)
+	}
+	setup.fingerprint = fp
+	return setup, nil
+}
+
+// certHosts lists the names the auto-generated certificate must cover: the
+// loopback names panes use, the machine's hostname, the configured host, and
+// every interface address sysinfo reports (what phones on the LAN connect to).
+func certHosts(cfg *config.Config) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if name, err := os.Hostname(); err == nil && name != "" {
+		hosts = append(hosts, name)
+	}
+	if cfg.Host != "" && cfg.Host != "0.0.0.0" && cfg.Host != "::" {
+		hosts = append(hosts, cfg.Host)
+	}
+	ifaces, err := sysinfo.Interfaces()
+	if err != nil {
+		log.Printf("Warning: listing network interfaces for TLS certificate: %v", err)
+	}
+	for _, iface := range ifaces {
+		hosts = append(hosts, iface.IPv4)
+	}
+
+	seen := make(map[string]bool)
+	out := hosts[:0]
+	for _, h := range hosts {
+		if !seen[h] {
+			seen[h] = true
+			out = append(out, h)
+		}
+	}
+	return out
+}
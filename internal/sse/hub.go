@@ -9,11 +9,24 @@ import (
 type Hub struct {
 	mu      sync.RWMutex
 	clients map[string]chan *model.Notification
+	events  map[string]chan Event
+
+	listeners []func(*model.Notification)
+}
+
+// Event is a named server-sent event other than a notification, such as an
+// agent state change. ProjectID, when set, limits delivery to clients that
+// can see that project.
+type Event struct {
+	Type      string
+	ProjectID string
+	Data      any
 }
 
 func NewHub() *Hub {
 	return &Hub{
 		clients: make(map[string]chan *model.Notification),
+		events:  make(map[string]chan Event),
 	}
 }
 
@@ -34,6 +47,14 @@ func (h *Hub) Unsubscribe(clientID string) {
 	h.mu.Unlock()
 }
 
+// OnBroadcast registers fn to be called with every broadcast notification,
+// for deliveries beyond the browser, such as webhooks. fn must not block.
+func (h *Hub) OnBroadcast(fn func(*model.Notification)) {
+	h.mu.Lock()
+	h.listeners = append(h.listeners, fn)
+	h.mu.Unlock()
+}
+
 func (h *Hub) Broadcast(n *model.Notification) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -44,4 +65,38 @@ func (h *Hub) Broadcast(n *model.Notification) {
 			// Slow consumer, drop notification
 		}
 	}
+	for _, fn := range h.listeners {
+		fn(n)
+	}
+}
+
+// SubscribeEvents registers clientID for Publish'd events.
+func (h *Hub) SubscribeEvents(clientID string) <-chan Event {
+	ch := make(chan Event, 50)
+	h.mu.Lock()
+	h.events[clientID] = ch
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *Hub) UnsubscribeEvents(clientID string) {
+	h.mu.Lock()
+	if ch, ok := h.events[clientID]; ok {
+		close(ch)
+		delete(h.events, clientID)
+	}
+	h.mu.Unlock()
+}
+
+// Publish sends an event to every event subscriber, dropping it for slow
+// consumers like Broadcast does.
+func (h *Hub) Publish(e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, ch := range h.events {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
 }
@@ -108,3 +108,41 @@ func TestHub_BroadcastNoClients(t *testing.T) {
 	// Should not panic
 	hub.Broadcast(&model.Notification{ID: "n1"})
 }
+
+func TestHub_PublishEvents(t *testing.T) {
+	hub := NewHub()
+
+	events := hub.SubscribeEvents("client-1")
+	notifications := hub.Subscribe("client-1")
+
+	hub.Publish(Event{Type: "agent-state", ProjectID: "p1", Data: "working"})
+
+	select {
+	case got := <-events:
+		assert.Equal(t, "agent-state", got.Type)
+		assert.Equal(t, "p1", got.ProjectID)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+	select {
+	case <-notifications:
+		t.Fatal("events must not reach notification subscribers")
+	default:
+	}
+
+	hub.UnsubscribeEvents("client-1")
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func TestHub_OnBroadcast(t *testing.T) {
+	hub := NewHub()
+
+	var got []string
+	hub.OnBroadcast(func(n *model.Notification) { got = append(got, n.ID) })
+
+	hub.Broadcast(&model.Notification{ID: "n1"})
+	hub.Broadcast(&model.Notification{ID: "n2"})
+
+	assert.Equal(t, []string{"n1", "n2"}, got)
+}
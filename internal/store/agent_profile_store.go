@@ -0,0 +1,95 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// AgentProfileStore holds the custom agent profiles. Built-in profiles are
+// not stored; they come from the aicli catalog.
+type AgentProfileStore struct {
+	mu       sync.RWMutex
+	filePath string
+	profiles []model.AgentProfile
+}
+
+func NewAgentProfileStore(filePath string) (*AgentProfileStore, error) {
+	s := &AgentProfileStore{filePath: filePath}
+	if err := s.load(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("loading agent profiles: %w", err)
+		}
+		s.profiles = []model.AgentProfile{}
+	}
+	return s, nil
+}
+
+func (s *AgentProfileStore) GetAll() []model.AgentProfile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]model.AgentProfile, len(s.profiles))
+	copy(out, s.profiles)
+	return out
+}
+
+func (s *AgentProfileStore) Get(id string) (model.AgentProfile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.profiles {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return model.AgentProfile{}, false
+}
+
+func (s *AgentProfileStore) Add(p model.AgentProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles = append(s.profiles, p)
+	return s.save()
+}
+
+func (s *AgentProfileStore) Update(p model.AgentProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.profiles {
+		if existing.ID == p.ID {
+			s.profiles[i] = p
+			return s.save()
+		}
+	}
+	return fmt.Errorf("agent profile %s not found", p.ID)
+}
+
+func (s *AgentProfileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range s.profiles {
+		if p.ID == id {
+			s.profiles = append(s.profiles[:i], s.profiles[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("agent profile %s not found", id)
+}
+
+func (s *AgentProfileStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.profiles)
+}
+
+func (s *AgentProfileStore) save() error {
+	data, err := json.MarshalIndent(s.profiles, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling agent profiles: %w", err)
+	}
+	return os.WriteFile(s.filePath, data, 0644)
+}
@@ -0,0 +1,35 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentProfileStore(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "agent_profiles.json")
+	s, err := NewAgentProfileStore(fp)
+	require.NoError(t, err)
+	assert.Empty(t, s.GetAll())
+
+	require.NoError(t, s.Add(model.AgentProfile{ID: "p1", Name: "Codex", Command: "codex", Env: map[string]string{"A": "1"}}))
+	p, ok := s.Get("p1")
+	require.True(t, ok)
+	p.Model = "o3"
+	require.NoError(t, s.Update(p))
+
+	// Persisted across reloads
+	s2, err := NewAgentProfileStore(fp)
+	require.NoError(t, err)
+	got, ok := s2.Get("p1")
+	require.True(t, ok)
+	assert.Equal(t, "o3", got.Model)
+	assert.Equal(t, "1", got.Env["A"])
+
+	require.NoError(t, s.Delete("p1"))
+	assert.Error(t, s.Delete("p1"))
+	assert.Error(t, s.Update(model.AgentProfile{ID: "missing"}))
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+type LayoutTemplateStore struct {
+	mu        sync.RWMutex
+	filePath  string
+	templates []model.LayoutTemplate
+}
+
+func NewLayoutTemplateStore(filePath string) (*LayoutTemplateStore, error) {
+	s := &LayoutTemplateStore{filePath: filePath}
+	if err := s.load(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("loading layout templates: %w", err)
+		}
+		s.templates = []model.LayoutTemplate{}
+	}
+	return s, nil
+}
+
+// ForProject returns the global templates plus those saved for projectID,
+// sorted by name. An empty projectID returns only the global templates.
+func (s *LayoutTemplateStore) ForProject(projectID string) []model.LayoutTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := []model.LayoutTemplate{}
+	for _, t := range s.templates {
+		if t.ProjectID == "" || t.ProjectID == projectID {
+			out = append(out, t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out
+}
+
+func (s *LayoutTemplateStore) Get(id string) (model.LayoutTemplate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.templates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return model.LayoutTemplate{}, false
+}
+
+func (s *LayoutTemplateStore) Add(t model.LayoutTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = append(s.templates, t)
+	return s.save()
+}
+
+func (s *LayoutTemplateStore) Update(t model.LayoutTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.templates {
+		if existing.ID == t.ID {
+			s.templates[i] = t
+			return s.save()
+		}
+	}
+	return fmt.Errorf("layout template %s not found", t.ID)
+}
+
+func (s *LayoutTemplateStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.templates {
+		if t.ID == id {
+			s.templates = append(s.templates[:i], s.templates[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("layout template %s not found", id)
+}
+
+func (s *LayoutTemplateStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.templates)
+}
+
+func (s *LayoutTemplateStore) save() error {
+	data, err := json.MarshalIndent(s.templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling layout templates: %w", err)
+	}
+	return os.WriteFile(s.filePath, data, 0644)
+}
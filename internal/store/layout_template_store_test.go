@@ -0,0 +1,42 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutTemplateStore(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "layout_templates.json")
+	s, err := NewLayoutTemplateStore(fp)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Add(model.LayoutTemplate{ID: "g", Name: "global", Layout: &model.PaneNode{Type: "leaf"}}))
+	require.NoError(t, s.Add(model.LayoutTemplate{ID: "a", ProjectID: "p1", Name: "Agent + shells", Layout: &model.PaneNode{Type: "leaf"}}))
+	require.NoError(t, s.Add(model.LayoutTemplate{ID: "b", ProjectID: "p2", Name: "other", Layout: &model.PaneNode{Type: "leaf"}}))
+
+	p1 := s.ForProject("p1")
+	require.Len(t, p1, 2)
+	assert.Equal(t, "a", p1[0].ID, "sorted by name, case-insensitively")
+	assert.Equal(t, "g", p1[1].ID)
+	assert.Len(t, s.ForProject(""), 1)
+
+	tpl, ok := s.Get("a")
+	require.True(t, ok)
+	tpl.Name = "renamed"
+	require.NoError(t, s.Update(tpl))
+
+	// Persisted across reloads
+	s2, err := NewLayoutTemplateStore(fp)
+	require.NoError(t, err)
+	got, ok := s2.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "renamed", got.Name)
+
+	require.NoError(t, s.Delete("b"))
+	assert.Error(t, s.Delete("b"))
+	assert.Error(t, s.Update(model.LayoutTemplate{ID: "missing"}))
+}
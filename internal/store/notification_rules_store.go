@@ -0,0 +1,54 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// NotificationRulesStore holds the instance-wide notification rules.
+type NotificationRulesStore struct {
+	mu       sync.RWMutex
+	filePath string
+	rules    model.NotificationRules
+}
+
+func NewNotificationRulesStore(filePath string) (*NotificationRulesStore, error) {
+	s := &NotificationRulesStore{filePath: filePath}
+	if err := s.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("loading notification rules: %w", err)
+	}
+	return s, nil
+}
+
+func (s *NotificationRulesStore) Get() model.NotificationRules {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rules
+}
+
+func (s *NotificationRulesStore) Set(rules model.NotificationRules) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+	return s.save()
+}
+
+func (s *NotificationRulesStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.rules)
+}
+
+func (s *NotificationRulesStore) save() error {
+	data, err := json.MarshalIndent(s.rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling notification rules: %w", err)
+	}
+	return os.WriteFile(s.filePath, data, 0644)
+}
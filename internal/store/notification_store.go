@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/davydany/ClawIDE/internal/model"
 )
@@ -73,6 +74,18 @@ func (s *NotificationStore) Get(id string) (model.Notification, bool) {
 	return model.Notification{}, false
 }
 
+// FindByIdempotencyKey returns the notification created with key, if any.
+func (s *NotificationStore) FindByIdempotencyKey(key string) (model.Notification, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, n := range s.notifications {
+		if n.IdempotencyKey == key {
+			return n, true
+		}
+	}
+	return model.Notification{}, false
+}
+
 func (s *NotificationStore) Add(n model.Notification) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -97,6 +110,60 @@ func (s *NotificationStore) Add(n model.Notification) error {
 	return s.save()
 }
 
+// Repeats returns the notifications with n's title, source, project and
+// feature seen at or after since, newest first.
+func (s *NotificationStore) Repeats(n model.Notification, since time.Time) []model.Notification {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []model.Notification
+	for _, existing := range s.notifications {
+		seen := existing.CreatedAt
+		if existing.UpdatedAt.After(seen) {
+			seen = existing.UpdatedAt
+		}
+		if seen.Before(since) {
+			continue
+		}
+		if existing.Title == n.Title && existing.Source == n.Source &&
+			existing.ProjectID == n.ProjectID && existing.FeatureID == n.FeatureID {
+			out = append(out, existing)
+		}
+	}
+	return out
+}
+
+// Update replaces the notification with n's ID and moves it to the top.
+func (s *NotificationStore) Update(n model.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.notifications {
+		if existing.ID == n.ID {
+			rest := append(s.notifications[:i:i], s.notifications[i+1:]...)
+			s.notifications = append([]model.Notification{n}, rest...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("notification %s not found", n.ID)
+}
+
+// ReleaseHeld clears the Held flag of every held notification and returns
+// them, newest first.
+func (s *NotificationStore) ReleaseHeld() ([]model.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var released []model.Notification
+	for i := range s.notifications {
+		if s.notifications[i].Held {
+			s.notifications[i].Held = false
+			released = append(released, s.notifications[i])
+		}
+	}
+	if len(released) == 0 {
+		return nil, nil
+	}
+	return released, s.save()
+}
+
 func (s *NotificationStore) MarkRead(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -110,10 +177,17 @@ func (s *NotificationStore) MarkRead(id string) error {
 }
 
 func (s *NotificationStore) MarkAllRead() error {
+	return s.MarkReadWhere(func(model.Notification) bool { return true })
+}
+
+// MarkReadWhere marks every notification that match selects as read.
+func (s *NotificationStore) MarkReadWhere(match func(model.Notification) bool) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	for i := range s.notifications {
-		s.notifications[i].Read = true
+	for i, n := range s.notifications {
+		if match(n) {
+			s.notifications[i].Read = true
+		}
 	}
 	return s.save()
 }
@@ -140,6 +140,21 @@ func TestNotificationStore_IdempotencyDedup(t *testing.T) {
 	assert.Equal(t, "n1", all[0].ID)
 }
 
+func TestNotificationStore_FindByIdempotencyKey(t *testing.T) {
+	s := tempNotifStore(t, 200)
+
+	n := makeNotif("n1", "First")
+	n.IdempotencyKey = "key-abc"
+	require.NoError(t, s.Add(n))
+
+	got, ok := s.FindByIdempotencyKey("key-abc")
+	require.True(t, ok)
+	assert.Equal(t, "n1", got.ID)
+
+	_, ok = s.FindByIdempotencyKey("key-missing")
+	assert.False(t, ok)
+}
+
 func TestNotificationStore_Persistence(t *testing.T) {
 	dir := t.TempDir()
 	fp := filepath.Join(dir, "notifications.json")
@@ -187,3 +202,61 @@ func TestNotificationStore_CorruptFile(t *testing.T) {
 	_, err := NewNotificationStore(fp, 200)
 	assert.Error(t, err)
 }
+
+func TestNotificationStore_RepeatsAndUpdate(t *testing.T) {
+	s := tempNotifStore(t, 10)
+	now := time.Now()
+	old := makeNotif("n1", "Build failed")
+	old.CreatedAt = now.Add(-time.Hour)
+	require.NoError(t, s.Add(old))
+	require.NoError(t, s.Add(makeNotif("n2", "Build failed")))
+	require.NoError(t, s.Add(makeNotif("n3", "Other")))
+	other := makeNotif("n4", "Build failed")
+	other.ProjectID = "p1"
+	require.NoError(t, s.Add(other))
+
+	repeats := s.Repeats(makeNotif("", "Build failed"), now.Add(-time.Minute))
+	require.Len(t, repeats, 1)
+	assert.Equal(t, "n2", repeats[0].ID)
+
+	// A recent repeat brings an old notification back into the window.
+	old.UpdatedAt = now
+	old.Count = 2
+	require.NoError(t, s.Update(old))
+	repeats = s.Repeats(makeNotif("", "Build failed"), now.Add(-time.Minute))
+	require.Len(t, repeats, 2)
+	assert.Equal(t, "n1", repeats[0].ID, "updated notifications move to the top")
+	assert.Equal(t, []string{"n1", "n4", "n3", "n2"}, []string{s.GetAll()[0].ID, s.GetAll()[1].ID, s.GetAll()[2].ID, s.GetAll()[3].ID})
+	assert.Error(t, s.Update(makeNotif("missing", "x")))
+}
+
+func TestNotificationStore_ReleaseHeld(t *testing.T) {
+	s := tempNotifStore(t, 10)
+	held := makeNotif("n1", "Quiet")
+	held.Held = true
+	require.NoError(t, s.Add(held))
+	require.NoError(t, s.Add(makeNotif("n2", "Loud")))
+
+	released, err := s.ReleaseHeld()
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, "n1", released[0].ID)
+	assert.False(t, released[0].Held)
+
+	released, err = s.ReleaseHeld()
+	require.NoError(t, err)
+	assert.Empty(t, released)
+}
+
+func TestNotificationRulesStore(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "notification_rules.json")
+	s, err := NewNotificationRulesStore(fp)
+	require.NoError(t, err)
+	assert.Equal(t, model.NotificationRules{}, s.Get())
+
+	rules := model.NotificationRules{GroupBy: []string{"project"}, RepeatWindow: 60, Collapse: true, MutedSources: []string{"claude"}}
+	require.NoError(t, s.Set(rules))
+	s2, err := NewNotificationRulesStore(fp)
+	require.NoError(t, err)
+	assert.Equal(t, rules, s2.Get())
+}
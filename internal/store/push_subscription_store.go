@@ -0,0 +1,91 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// PushSubscriptionStore holds the browsers subscribed to Web Push. Endpoints
+// are unique: a browser subscribing again replaces its old entry.
+type PushSubscriptionStore struct {
+	mu            sync.RWMutex
+	filePath      string
+	subscriptions []model.PushSubscription
+}
+
+func NewPushSubscriptionStore(filePath string) (*PushSubscriptionStore, error) {
+	s := &PushSubscriptionStore{filePath: filePath}
+	if err := s.load(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("loading push subscriptions: %w", err)
+		}
+		s.subscriptions = []model.PushSubscription{}
+	}
+	return s, nil
+}
+
+func (s *PushSubscriptionStore) GetAll() []model.PushSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]model.PushSubscription, len(s.subscriptions))
+	copy(out, s.subscriptions)
+	return out
+}
+
+func (s *PushSubscriptionStore) GetByEndpoint(endpoint string) (model.PushSubscription, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, sub := range s.subscriptions {
+		if sub.Endpoint == endpoint {
+			return sub, true
+		}
+	}
+	return model.PushSubscription{}, false
+}
+
+// Put adds sub, or replaces the subscription with the same endpoint.
+func (s *PushSubscriptionStore) Put(sub model.PushSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.subscriptions {
+		if existing.Endpoint == sub.Endpoint {
+			s.subscriptions[i] = sub
+			return s.save()
+		}
+	}
+	s.subscriptions = append(s.subscriptions, sub)
+	return s.save()
+}
+
+func (s *PushSubscriptionStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sub := range s.subscriptions {
+		if sub.ID == id {
+			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("push subscription %s not found", id)
+}
+
+func (s *PushSubscriptionStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.subscriptions)
+}
+
+func (s *PushSubscriptionStore) save() error {
+	data, err := json.MarshalIndent(s.subscriptions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling push subscriptions: %w", err)
+	}
+	// The auth secrets let anyone push to these browsers.
+	return os.WriteFile(s.filePath, data, 0600)
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushSubscriptionStore(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "push_subscriptions.json")
+	s, err := NewPushSubscriptionStore(fp)
+	require.NoError(t, err)
+	assert.Empty(t, s.GetAll())
+
+	require.NoError(t, s.Put(model.PushSubscription{ID: "s1", Endpoint: "https://push.example/a", Auth: "old"}))
+	require.NoError(t, s.Put(model.PushSubscription{ID: "s2", Endpoint: "https://push.example/b"}))
+	// Same endpoint replaces the entry.
+	require.NoError(t, s.Put(model.PushSubscription{ID: "s1", Endpoint: "https://push.example/a", Auth: "new"}))
+	assert.Len(t, s.GetAll(), 2)
+
+	info, err := os.Stat(fp)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	s2, err := NewPushSubscriptionStore(fp)
+	require.NoError(t, err)
+	sub, ok := s2.GetByEndpoint("https://push.example/a")
+	require.True(t, ok)
+	assert.Equal(t, "new", sub.Auth)
+
+	require.NoError(t, s2.Delete("s1"))
+	assert.Error(t, s2.Delete("s1"))
+	_, ok = s2.GetByEndpoint("https://push.example/a")
+	assert.False(t, ok)
+}
+
+func TestPushSubscriptionMatches(t *testing.T) {
+	all := model.PushSubscription{}
+	assert.True(t, all.Matches("info", "p1"))
+
+	filtered := model.PushSubscription{Levels: []string{"error"}, ProjectIDs: []string{"p1"}}
+	assert.True(t, filtered.Matches("error", "p1"))
+	assert.True(t, filtered.Matches("error", ""))
+	assert.False(t, filtered.Matches("info", "p1"))
+	assert.False(t, filtered.Matches("error", "p2"))
+}
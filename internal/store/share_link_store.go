@@ -0,0 +1,114 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+type ShareLinkStore struct {
+	mu       sync.RWMutex
+	filePath string
+	links    []model.ShareLink
+}
+
+func NewShareLinkStore(filePath string) (*ShareLinkStore, error) {
+	s := &ShareLinkStore{filePath: filePath}
+	if err := s.load(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("loading share links: %w", err)
+		}
+		s.links = []model.ShareLink{}
+	}
+	return s, nil
+}
+
+func (s *ShareLinkStore) GetAll() []model.ShareLink {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]model.ShareLink, len(s.links))
+	copy(out, s.links)
+	return out
+}
+
+func (s *ShareLinkStore) Get(id string) (model.ShareLink, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, l := range s.links {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return model.ShareLink{}, false
+}
+
+func (s *ShareLinkStore) FindByHash(hash string) (model.ShareLink, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, l := range s.links {
+		if l.TokenHash == hash {
+			return l, true
+		}
+	}
+	return model.ShareLink{}, false
+}
+
+func (s *ShareLinkStore) Add(l model.ShareLink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = append(s.links, l)
+	return s.save()
+}
+
+func (s *ShareLinkStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, l := range s.links {
+		if l.ID == id {
+			s.links = append(s.links[:i], s.links[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("share link %s not found", id)
+}
+
+// DeleteExpired drops links that expired before now and returns how many
+// were removed.
+func (s *ShareLinkStore) DeleteExpired(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.links[:0]
+	removed := 0
+	for _, l := range s.links {
+		if l.Expired(now) {
+			removed++
+			continue
+		}
+		kept = append(kept, l)
+	}
+	s.links = kept
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.save()
+}
+
+func (s *ShareLinkStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.links)
+}
+
+func (s *ShareLinkStore) save() error {
+	data, err := json.MarshalIndent(s.links, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling share links: %w", err)
+	}
+	return os.WriteFile(s.filePath, data, 0600)
+}
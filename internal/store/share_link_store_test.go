@@ -0,0 +1,40 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLinkStore(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "share_links.json")
+	s, err := NewShareLinkStore(fp)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, s.Add(model.ShareLink{ID: "live", TokenHash: "h1", PaneID: "p1", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, s.Add(model.ShareLink{ID: "old", TokenHash: "h2", PaneID: "p1", ExpiresAt: now.Add(-time.Minute)}))
+
+	got, ok := s.FindByHash("h1")
+	require.True(t, ok)
+	assert.Equal(t, "live", got.ID)
+	assert.False(t, got.Expired(now))
+
+	removed, err := s.DeleteExpired(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	// Persisted across reloads
+	s2, err := NewShareLinkStore(fp)
+	require.NoError(t, err)
+	all := s2.GetAll()
+	require.Len(t, all, 1)
+	assert.Equal(t, "live", all[0].ID)
+
+	require.NoError(t, s.Delete("live"))
+	assert.Error(t, s.Delete("live"))
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// lastUsedResolution limits how often Touch rewrites tokens.json. Bearer
+// tokens are checked on every request, so persisting each use would turn
+// every API call into a disk write.
+const lastUsedResolution = time.Minute
+
+type TokenStore struct {
+	mu       sync.RWMutex
+	filePath string
+	tokens   []model.APIToken
+}
+
+func NewTokenStore(filePath string) (*TokenStore, error) {
+	s := &TokenStore{filePath: filePath}
+	if err := s.load(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("loading api tokens: %w", err)
+		}
+		s.tokens = []model.APIToken{}
+	}
+	return s, nil
+}
+
+func (s *TokenStore) GetAll() []model.APIToken {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]model.APIToken, len(s.tokens))
+	copy(out, s.tokens)
+	return out
+}
+
+// FindByHash returns the token whose TokenHash equals hash.
+func (s *TokenStore) FindByHash(hash string) (model.APIToken, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.tokens {
+		if t.TokenHash == hash {
+			return t, true
+		}
+	}
+	return model.APIToken{}, false
+}
+
+func (s *TokenStore) Add(t model.APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = append(s.tokens, t)
+	return s.save()
+}
+
+// Touch records that the token was just used.
+func (s *TokenStore) Touch(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.tokens {
+		if t.ID == id {
+			if t.LastUsedAt != nil && at.Sub(*t.LastUsedAt) < lastUsedResolution {
+				return nil
+			}
+			s.tokens[i].LastUsedAt = &at
+			return s.save()
+		}
+	}
+	return fmt.Errorf("api token %s not found", id)
+}
+
+func (s *TokenStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.tokens {
+		if t.ID == id {
+			s.tokens = append(s.tokens[:i], s.tokens[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("api token %s not found", id)
+}
+
+func (s *TokenStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.tokens)
+}
+
+func (s *TokenStore) save() error {
+	data, err := json.MarshalIndent(s.tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling api tokens: %w", err)
+	}
+	return os.WriteFile(s.filePath, data, 0600)
+}
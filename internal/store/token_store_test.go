@@ -0,0 +1,50 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenStore_AddFindDelete(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "tokens.json")
+	s, err := NewTokenStore(fp)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Add(model.APIToken{ID: "t1", Name: "laptop", TokenHash: "abc", CreatedAt: time.Now()}))
+
+	got, ok := s.FindByHash("abc")
+	require.True(t, ok)
+	assert.Equal(t, "laptop", got.Name)
+
+	_, ok = s.FindByHash("nope")
+	assert.False(t, ok)
+
+	// Persisted across reloads
+	s2, err := NewTokenStore(fp)
+	require.NoError(t, err)
+	assert.Len(t, s2.GetAll(), 1)
+
+	require.NoError(t, s.Delete("t1"))
+	assert.Empty(t, s.GetAll())
+	assert.Error(t, s.Delete("t1"))
+}
+
+func TestTokenStore_TouchThrottled(t *testing.T) {
+	s, err := NewTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	require.NoError(t, err)
+	require.NoError(t, s.Add(model.APIToken{ID: "t1", TokenHash: "abc"}))
+
+	first := time.Now()
+	require.NoError(t, s.Touch("t1", first))
+	require.NoError(t, s.Touch("t1", first.Add(10*time.Second)))
+	assert.True(t, s.GetAll()[0].LastUsedAt.Equal(first), "uses within a minute are not re-recorded")
+
+	later := first.Add(2 * time.Minute)
+	require.NoError(t, s.Touch("t1", later))
+	assert.True(t, s.GetAll()[0].LastUsedAt.Equal(later))
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+type UserStore struct {
+	mu       sync.RWMutex
+	filePath string
+	users    []model.User
+}
+
+func NewUserStore(filePath string) (*UserStore, error) {
+	s := &UserStore{filePath: filePath}
+	if err := s.load(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("loading users: %w", err)
+		}
+		s.users = []model.User{}
+	}
+	return s, nil
+}
+
+func (s *UserStore) GetAll() []model.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]model.User, len(s.users))
+	copy(out, s.users)
+	return out
+}
+
+func (s *UserStore) Get(id string) (model.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return model.User{}, false
+}
+
+// GetByUsername looks a user up case-insensitively.
+func (s *UserStore) GetByUsername(username string) (model.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, username) {
+			return u, true
+		}
+	}
+	return model.User{}, false
+}
+
+func (s *UserStore) Add(u model.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.users {
+		if strings.EqualFold(existing.Username, u.Username) {
+			return fmt.Errorf("username %q is already taken", u.Username)
+		}
+	}
+	s.users = append(s.users, u)
+	return s.save()
+}
+
+func (s *UserStore) Update(u model.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.users {
+		if existing.ID == u.ID {
+			s.users[i] = u
+			return s.save()
+		}
+	}
+	return fmt.Errorf("user %s not found", u.ID)
+}
+
+func (s *UserStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.users {
+		if u.ID == id {
+			s.users = append(s.users[:i], s.users[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("user %s not found", id)
+}
+
+func (s *UserStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.users)
+}
+
+func (s *UserStore) save() error {
+	data, err := json.MarshalIndent(s.users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling users: %w", err)
+	}
+	return os.WriteFile(s.filePath, data, 0600)
+}
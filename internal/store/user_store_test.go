@@ -0,0 +1,38 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserStore_CRUD(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "users.json")
+	s, err := NewUserStore(fp)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Add(model.User{ID: "u1", Username: "alice", PasswordHash: "h", CreatedAt: time.Now()}))
+	assert.Error(t, s.Add(model.User{ID: "u2", Username: "Alice"}), "usernames are case-insensitively unique")
+
+	got, ok := s.GetByUsername("ALICE")
+	require.True(t, ok)
+	assert.Equal(t, "u1", got.ID)
+
+	got.PasswordHash = "h2"
+	require.NoError(t, s.Update(got))
+
+	// Persisted across reloads
+	s2, err := NewUserStore(fp)
+	require.NoError(t, err)
+	reloaded, ok := s2.Get("u1")
+	require.True(t, ok)
+	assert.Equal(t, "h2", reloaded.PasswordHash)
+
+	require.NoError(t, s.Delete("u1"))
+	assert.Empty(t, s.GetAll())
+	assert.Error(t, s.Delete("u1"))
+}
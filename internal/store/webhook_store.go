@@ -0,0 +1,153 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/davydany/ClawIDE/internal/model"
+)
+
+// WebhookStore holds the configured webhooks and a log of the most recent
+// delivery attempts, newest first.
+type WebhookStore struct {
+	mu             sync.RWMutex
+	filePath       string
+	deliveriesPath string
+	maxDeliveries  int
+	webhooks       []model.Webhook
+	deliveries     []model.WebhookDelivery
+}
+
+func NewWebhookStore(filePath, deliveriesPath string, maxDeliveries int) (*WebhookStore, error) {
+	s := &WebhookStore{
+		filePath:       filePath,
+		deliveriesPath: deliveriesPath,
+		maxDeliveries:  maxDeliveries,
+		webhooks:       []model.Webhook{},
+		deliveries:     []model.WebhookDelivery{},
+	}
+	if err := loadJSON(filePath, &s.webhooks); err != nil {
+		return nil, fmt.Errorf("loading webhooks: %w", err)
+	}
+	if err := loadJSON(deliveriesPath, &s.deliveries); err != nil {
+		return nil, fmt.Errorf("loading webhook deliveries: %w", err)
+	}
+	return s, nil
+}
+
+// loadJSON reads path into v, leaving v alone if the file does not exist.
+func loadJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (s *WebhookStore) GetAll() []model.Webhook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]model.Webhook, len(s.webhooks))
+	copy(out, s.webhooks)
+	return out
+}
+
+func (s *WebhookStore) Get(id string) (model.Webhook, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, w := range s.webhooks {
+		if w.ID == id {
+			return w, true
+		}
+	}
+	return model.Webhook{}, false
+}
+
+func (s *WebhookStore) Add(w model.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhooks = append(s.webhooks, w)
+	return s.save()
+}
+
+func (s *WebhookStore) Update(w model.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.webhooks {
+		if existing.ID == w.ID {
+			s.webhooks[i] = w
+			return s.save()
+		}
+	}
+	return fmt.Errorf("webhook %s not found", w.ID)
+}
+
+// Delete removes a webhook and its deliveries.
+func (s *WebhookStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.webhooks {
+		if w.ID == id {
+			s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+			kept := s.deliveries[:0]
+			for _, d := range s.deliveries {
+				if d.WebhookID != id {
+					kept = append(kept, d)
+				}
+			}
+			s.deliveries = kept
+			if err := s.saveDeliveries(); err != nil {
+				return err
+			}
+			return s.save()
+		}
+	}
+	return fmt.Errorf("webhook %s not found", id)
+}
+
+// Deliveries returns the logged attempts for webhookID, or for every webhook
+// when it is empty, newest first.
+func (s *WebhookStore) Deliveries(webhookID string) []model.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := []model.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if webhookID == "" || d.WebhookID == webhookID {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// AddDelivery logs an attempt, dropping the oldest beyond the maximum.
+func (s *WebhookStore) AddDelivery(d model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append([]model.WebhookDelivery{d}, s.deliveries...)
+	if len(s.deliveries) > s.maxDeliveries {
+		s.deliveries = s.deliveries[:s.maxDeliveries]
+	}
+	return s.saveDeliveries()
+}
+
+func (s *WebhookStore) save() error {
+	data, err := json.MarshalIndent(s.webhooks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling webhooks: %w", err)
+	}
+	// Secrets are stored here, so keep the file private.
+	return os.WriteFile(s.filePath, data, 0600)
+}
+
+func (s *WebhookStore) saveDeliveries() error {
+	data, err := json.MarshalIndent(s.deliveries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling webhook deliveries: %w", err)
+	}
+	return os.WriteFile(s.deliveriesPath, data, 0644)
+}
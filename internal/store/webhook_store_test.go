@@ -0,0 +1,73 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookStore(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "webhooks.json")
+	dp := filepath.Join(dir, "webhook_deliveries.json")
+	s, err := NewWebhookStore(fp, dp, 3)
+	require.NoError(t, err)
+	assert.Empty(t, s.GetAll())
+
+	require.NoError(t, s.Add(model.Webhook{ID: "w1", Name: "chat", URL: "http://x", Secret: "k"}))
+	require.NoError(t, s.Add(model.Webhook{ID: "w2", Name: "other", URL: "http://y"}))
+	w, ok := s.Get("w1")
+	require.True(t, ok)
+	w.Enabled = true
+	require.NoError(t, s.Update(w))
+	assert.Error(t, s.Update(model.Webhook{ID: "missing"}))
+
+	for i, id := range []string{"d1", "d2", "d3", "d4"} {
+		require.NoError(t, s.AddDelivery(model.WebhookDelivery{ID: id, WebhookID: "w1", Attempt: i + 1}))
+	}
+	require.NoError(t, s.AddDelivery(model.WebhookDelivery{ID: "d5", WebhookID: "w2"}))
+
+	// Capped at 3, newest first.
+	all := s.Deliveries("")
+	require.Len(t, all, 3)
+	assert.Equal(t, []string{"d5", "d4", "d3"}, []string{all[0].ID, all[1].ID, all[2].ID})
+	assert.Len(t, s.Deliveries("w1"), 2)
+
+	info, err := os.Stat(fp)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Reloads from disk.
+	s2, err := NewWebhookStore(fp, dp, 3)
+	require.NoError(t, err)
+	w, ok = s2.Get("w1")
+	require.True(t, ok)
+	assert.True(t, w.Enabled)
+	assert.Equal(t, "k", w.Secret)
+	assert.Len(t, s2.Deliveries(""), 3)
+
+	// Deleting a webhook drops its deliveries.
+	require.NoError(t, s2.Delete("w1"))
+	assert.Len(t, s2.GetAll(), 1)
+	assert.Len(t, s2.Deliveries(""), 1)
+	assert.Error(t, s2.Delete("w1"))
+}
+
+func TestWebhookMatches(t *testing.T) {
+	w := model.Webhook{Enabled: true}
+	assert.True(t, w.Matches("feature.created", "info", "feature", "p1"))
+
+	w.Levels = []string{"warning", "error"}
+	w.ProjectIDs = []string{"p1"}
+	assert.True(t, w.Matches("notification.created", "error", "claude", "p1"))
+	assert.False(t, w.Matches("notification.created", "info", "claude", "p1"))
+	assert.False(t, w.Matches("notification.created", "error", "claude", "p2"))
+	assert.False(t, w.Matches("notification.created", "error", "claude", ""))
+
+	w.Enabled = false
+	assert.False(t, w.Matches("notification.created", "error", "claude", "p1"))
+}
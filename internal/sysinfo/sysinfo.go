@@ -81,36 +81,11 @@ func Gather(st *store.Store) Stats {
 		}
 	}
 
-	// Network interfaces (using stdlib net package for portability).
-	if ifaces, err := net.Interfaces(); err != nil {
+	// Network interfaces.
+	if ifaces, err := Interfaces(); err != nil {
 		log.Printf("sysinfo: network: %v", err)
 	} else {
-		for _, iface := range ifaces {
-			// Skip loopback and interfaces with no addresses.
-			if iface.Flags&net.FlagLoopback != 0 {
-				continue
-			}
-			addrs, err := iface.Addrs()
-			if err != nil || len(addrs) == 0 {
-				continue
-			}
-			ipv4 := ""
-			for _, addr := range addrs {
-				ip := stripCIDR(addr.String())
-				if isIPv4(ip) {
-					ipv4 = ip
-					break
-				}
-			}
-			if ipv4 == "" {
-				continue
-			}
-			s.Network = append(s.Network, NetworkInterface{
-				Name:   iface.Name,
-				IPv4:   ipv4,
-				Status: deriveStatus(iface.Flags),
-			})
-		}
+		s.Network = ifaces
 	}
 
 	// Multiplexer sessions.
@@ -135,6 +110,43 @@ func Gather(st *store.Store) Stats {
 	return s
 }
 
+// Interfaces lists non-loopback interfaces that have an IPv4 address, using
+// the stdlib net package for portability.
+func Interfaces() ([]NetworkInterface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	out := []NetworkInterface{}
+	for _, iface := range ifaces {
+		// Skip loopback and interfaces with no addresses.
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		ipv4 := ""
+		for _, addr := range addrs {
+			ip := stripCIDR(addr.String())
+			if isIPv4(ip) {
+				ipv4 = ip
+				break
+			}
+		}
+		if ipv4 == "" {
+			continue
+		}
+		out = append(out, NetworkInterface{
+			Name:   iface.Name,
+			IPv4:   ipv4,
+			Status: deriveStatus(iface.Flags),
+		})
+	}
+	return out, nil
+}
+
 // round2 rounds a float to 2 decimal places.
 func round2(v float64) float64 {
 	return math.Round(v*100) / 100
@@ -0,0 +1,173 @@
+// Package termsearch turns raw terminal output into plain text lines and
+// searches them.
+package termsearch
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Match is one matching line with its surrounding context.
+type Match struct {
+	Line   int      `json:"line"` // 1-based line number within the searched text
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// Options controls how Search matches.
+type Options struct {
+	Regex         bool // treat the query as a regular expression
+	CaseSensitive bool
+	Context       int // lines of context before and after each match
+	Limit         int // stop after this many matches; 0 means no limit
+}
+
+// Matcher reports whether a line matches a query.
+type Matcher func(line string) bool
+
+// Compile builds a Matcher for query. It only fails for invalid regular
+// expressions.
+func Compile(query string, opts Options) (Matcher, error) {
+	if opts.Regex {
+		expr := query
+		if !opts.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	if opts.CaseSensitive {
+		return func(line string) bool { return strings.Contains(line, query) }, nil
+	}
+	lower := strings.ToLower(query)
+	return func(line string) bool { return strings.Contains(strings.ToLower(line), lower) }, nil
+}
+
+// Search returns the lines that match, each with opts.Context lines around it.
+func Search(lines []string, match Matcher, opts Options) []Match {
+	var out []Match
+	for i, line := range lines {
+		if !match(line) {
+			continue
+		}
+		m := Match{Line: i + 1, Text: line}
+		if opts.Context > 0 {
+			lo := i - opts.Context
+			if lo < 0 {
+				lo = 0
+			}
+			hi := i + 1 + opts.Context
+			if hi > len(lines) {
+				hi = len(lines)
+			}
+			m.Before = append([]string(nil), lines[lo:i]...)
+			m.After = append([]string(nil), lines[i+1:hi]...)
+		}
+		out = append(out, m)
+		if opts.Limit > 0 && len(out) >= opts.Limit {
+			break
+		}
+	}
+	return out
+}
+
+// Lines splits terminal output into display lines. Escape sequences are
+// removed, a carriage return restarts the line (so progress bars collapse to
+// their final state), and backspaces erase the previous character.
+func Lines(raw []byte) []string {
+	text := StripANSI(raw)
+	parts := strings.Split(text, "\n")
+	lines := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimRight(p, "\r")
+		if i := strings.LastIndexByte(p, '\r'); i >= 0 {
+			p = p[i+1:]
+		}
+		lines = append(lines, strings.TrimRight(applyBackspaces(p), " \t"))
+	}
+	// Drop trailing blank lines (an idle prompt or an empty tmux screen).
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// StripANSI removes escape sequences and control characters other than
+// newline, carriage return, tab and backspace. Invalid UTF-8 is dropped.
+func StripANSI(raw []byte) string {
+	var b strings.Builder
+	b.Grow(len(raw))
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		switch {
+		case c == 0x1b:
+			i = skipEscape(raw, i)
+		case c == '\n' || c == '\r' || c == '\t' || c == '\b':
+			b.WriteByte(c)
+			i++
+		case c < 0x20 || c == 0x7f:
+			i++
+		case c < utf8.RuneSelf:
+			b.WriteByte(c)
+			i++
+		default:
+			r, size := utf8.DecodeRune(raw[i:])
+			if r != utf8.RuneError || size > 1 {
+				b.WriteRune(r)
+			}
+			i += size
+		}
+	}
+	return b.String()
+}
+
+// skipEscape returns the index just past the escape sequence starting at i.
+func skipEscape(raw []byte, i int) int {
+	if i+1 >= len(raw) {
+		return len(raw)
+	}
+	switch raw[i+1] {
+	case '[': // CSI: parameters and intermediates, then a final byte 0x40-0x7e
+		j := i + 2
+		for j < len(raw) && (raw[j] < 0x40 || raw[j] > 0x7e) {
+			j++
+		}
+		return j + 1
+	case ']', 'P', '^', '_', 'X': // OSC, DCS, PM, APC, SOS: until BEL or ST
+		for j := i + 2; j < len(raw); j++ {
+			if raw[j] == 0x07 {
+				return j + 1
+			}
+			if raw[j] == 0x1b && j+1 < len(raw) && raw[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(raw)
+	case '(', ')', '*', '+', '#', '%': // charset selection and friends take one more byte
+		return i + 3
+	default:
+		return i + 2
+	}
+}
+
+func applyBackspaces(s string) string {
+	if !strings.Contains(s, "\b") {
+		return s
+	}
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r == '\b' {
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
@@ -0,0 +1,54 @@
+package termsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	raw := []byte("\x1b[1;31mpanic:\x1b[0m boom\r\n" +
+		"\x1b]0;window title\x07prompt$ \r\n" +
+		"progress 10%\rprogress 100%\r\n" +
+		"typo\b\b\bext\r\n" +
+		"\x1b(Bdone\r\n\r\n")
+	assert.Equal(t, []string{
+		"panic: boom",
+		"prompt$",
+		"progress 100%",
+		"text",
+		"done",
+	}, Lines(raw))
+}
+
+func TestStripANSIKeepsUnicode(t *testing.T) {
+	assert.Equal(t, "✓ ok", StripANSI([]byte("\x1b[32m✓\x1b[m ok\x1b[?25h")))
+}
+
+func TestSearch(t *testing.T) {
+	lines := []string{"a", "b", "Error: one", "c", "d", "error: two"}
+
+	m, err := Compile("error", Options{})
+	require.NoError(t, err)
+	got := Search(lines, m, Options{Context: 1})
+	require.Len(t, got, 2)
+	assert.Equal(t, Match{Line: 3, Text: "Error: one", Before: []string{"b"}, After: []string{"c"}}, got[0])
+	assert.Equal(t, []string{"d"}, got[1].Before)
+	assert.Empty(t, got[1].After)
+
+	m, err = Compile("error", Options{CaseSensitive: true})
+	require.NoError(t, err)
+	assert.Len(t, Search(lines, m, Options{}), 1)
+
+	m, err = Compile(`^e.*: t`, Options{Regex: true})
+	require.NoError(t, err)
+	assert.Len(t, Search(lines, m, Options{}), 1)
+
+	m, err = Compile("r", Options{})
+	require.NoError(t, err)
+	assert.Len(t, Search(lines, m, Options{Limit: 1}), 1)
+
+	_, err = Compile("(", Options{Regex: true})
+	assert.Error(t, err)
+}
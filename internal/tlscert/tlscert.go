@@ -0,0 +1,258 @@
+// Package tlscert generates and maintains the self-signed certificates used
+// when ClawIDE serves HTTPS without a user-supplied certificate.
+//
+// It keeps a long-lived local CA (ca.pem / ca-key.pem) and a shorter-lived
+// leaf certificate (server.pem / server-key.pem) signed by it. Devices that
+// should connect without warnings only need to trust the CA once; the leaf
+// is re-issued automatically when it nears expiry or when the machine's
+// addresses change.
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	leafValidity = 397 * 24 * time.Hour // the longest lifetime browsers accept
+	renewBefore  = 30 * 24 * time.Hour
+)
+
+// Files are the PEM files making up a self-signed setup.
+type Files struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+// FilesIn returns the file layout inside dir.
+func FilesIn(dir string) Files {
+	return Files{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: filepath.Join(dir, "server.pem"),
+		KeyFile:  filepath.Join(dir, "server-key.pem"),
+	}
+}
+
+func caKeyFile(dir string) string {
+	return filepath.Join(dir, "ca-key.pem")
+}
+
+// EnsureSelfSigned makes sure dir holds a valid CA and a leaf certificate
+// covering hosts (DNS names or IP literals), creating or re-issuing them as
+// needed.
+func EnsureSelfSigned(dir string, hosts []string) (Files, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Files{}, fmt.Errorf("creating tls dir: %w", err)
+	}
+	files := FilesIn(dir)
+
+	ca, caKey, err := loadCA(files.CAFile, caKeyFile(dir))
+	if err != nil {
+		if ca, caKey, err = createCA(files.CAFile, caKeyFile(dir)); err != nil {
+			return Files{}, err
+		}
+	}
+
+	if leafValid(files.CertFile, files.KeyFile, ca, hosts) {
+		return files, nil
+	}
+	if err := createLeaf(files.CertFile, files.KeyFile, ca, caKey, hosts); err != nil {
+		return Files{}, err
+	}
+	return files, nil
+}
+
+// Fingerprint returns the colon-separated SHA-256 fingerprint of the first
+// certificate in certFile, as browsers display it.
+func Fingerprint(certFile string) (string, error) {
+	cert, err := readCert(certFile)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// CertPoolWith returns the system roots plus the PEM certificates in
+// caFile, so clients trust ClawIDE's auto-generated local CA.
+func CertPoolWith(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no certificates found", caFile)
+	}
+	return pool, nil
+}
+
+func loadCA(certFile, keyFile string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	cert, err := readCert(certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !cert.IsCA || time.Now().Add(renewBefore).After(cert.NotAfter) {
+		return nil, nil, fmt.Errorf("CA certificate is not usable")
+	}
+	key, err := readKey(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func createCA(certFile, keyFile string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating CA key: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{Organization: []string{"ClawIDE"}, CommonName: "ClawIDE Local CA (" + hostname + ")"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating CA certificate: %w", err)
+	}
+	if err := writePEM(certFile, "CERTIFICATE", der, 0644); err != nil {
+		return nil, nil, err
+	}
+	if err := writeKey(keyFile, key); err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// leafValid reports whether the existing leaf is signed by ca, is not about
+// to expire, has a matching key, and covers every host.
+func leafValid(certFile, keyFile string, ca *x509.Certificate, hosts []string) bool {
+	cert, err := readCert(certFile)
+	if err != nil {
+		return false
+	}
+	if _, err := readKey(keyFile); err != nil {
+		return false
+	}
+	if time.Now().Add(renewBefore).After(cert.NotAfter) {
+		return false
+	}
+	if err := cert.CheckSignatureFrom(ca); err != nil {
+		return false
+	}
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func createLeaf(certFile, keyFile string, ca *x509.Certificate, caKey *ecdsa.PrivateKey, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating server key: %w", err)
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject:      pkix.Name{Organization: []string{"ClawIDE"}, CommonName: "ClawIDE"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(leafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("creating server certificate: %w", err)
+	}
+	if err := writeKey(keyFile, key); err != nil {
+		return err
+	}
+	return writePEM(certFile, "CERTIFICATE", der, 0644)
+}
+
+func readCert(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s: no certificate found", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func readKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no key found", path)
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+func writeKey(path string, key *ecdsa.PrivateKey) error {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("marshaling key: %w", err)
+	}
+	return writePEM(path, "EC PRIVATE KEY", der, 0600)
+}
+
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("writing %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func randomSerial() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	return serial
+}
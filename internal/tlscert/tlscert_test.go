@@ -0,0 +1,72 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	hosts := []string{"localhost", "127.0.0.1", "192.168.1.20"}
+
+	files, err := EnsureSelfSigned(dir, hosts)
+	require.NoError(t, err)
+
+	_, err = tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+	require.NoError(t, err)
+
+	caPEM, err := os.ReadFile(files.CAFile)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(caPEM))
+
+	leaf, err := readCert(files.CertFile)
+	require.NoError(t, err)
+	for _, h := range hosts {
+		_, err := leaf.Verify(x509.VerifyOptions{DNSName: h, Roots: pool})
+		assert.NoError(t, err, h)
+	}
+
+	info, err := os.Stat(files.KeyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	t.Run("reuses valid certificates", func(t *testing.T) {
+		before, err := Fingerprint(files.CertFile)
+		require.NoError(t, err)
+		_, err = EnsureSelfSigned(dir, hosts)
+		require.NoError(t, err)
+		after, err := Fingerprint(files.CertFile)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("re-issues the leaf when a host is added, keeping the CA", func(t *testing.T) {
+		caBefore, err := Fingerprint(files.CAFile)
+		require.NoError(t, err)
+		leafBefore, err := Fingerprint(files.CertFile)
+		require.NoError(t, err)
+
+		_, err = EnsureSelfSigned(dir, append(hosts, "10.0.0.5"))
+		require.NoError(t, err)
+
+		caAfter, _ := Fingerprint(files.CAFile)
+		leafAfter, _ := Fingerprint(files.CertFile)
+		assert.Equal(t, caBefore, caAfter)
+		assert.NotEqual(t, leafBefore, leafAfter)
+	})
+}
+
+func TestFingerprintFormat(t *testing.T) {
+	files, err := EnsureSelfSigned(t.TempDir(), []string{"localhost"})
+	require.NoError(t, err)
+	fp, err := Fingerprint(files.CertFile)
+	require.NoError(t, err)
+	assert.Len(t, fp, 32*3-1)
+	assert.Regexp(t, `^([0-9A-F]{2}:){31}[0-9A-F]{2}$`, fp)
+}
@@ -3,7 +3,10 @@ package tmux
 import (
 	"fmt"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const prefix = "clawide-"
@@ -63,6 +66,84 @@ func ListClawIDESessions() ([]string, error) {
 	return sessions, nil
 }
 
+// SessionInfo describes a running session and the active pane inside it.
+type SessionInfo struct {
+	Name         string    `json:"tmux_name"`
+	Created      time.Time `json:"created"`
+	LastActivity time.Time `json:"last_activity"`
+	Path         string    `json:"path"`    // working directory of the active pane
+	Command      string    `json:"command"` // foreground command of the active pane
+}
+
+// sessionInfoFormat is the list-sessions format parsed by parseSessionInfo.
+const sessionInfoFormat = "#{session_name}\t#{session_created}\t#{session_activity}\t#{pane_current_path}\t#{pane_current_command}"
+
+// ListClawIDESessionInfo is ListClawIDESessions with details about each
+// session.
+func ListClawIDESessionInfo() ([]SessionInfo, error) {
+	out, err := exec.Command(binary, "list-sessions", "-F", sessionInfoFormat).Output()
+	if err != nil {
+		if strings.Contains(err.Error(), "exit status") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing %s sessions: %w", binary, err)
+	}
+	return parseSessionInfo(string(out)), nil
+}
+
+// parseSessionInfo parses list-sessions output in sessionInfoFormat, keeping
+// only clawide- sessions.
+func parseSessionInfo(out string) []SessionInfo {
+	var infos []SessionInfo
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], prefix) {
+			continue
+		}
+		info := SessionInfo{Name: fields[0], Path: fields[3], Command: fields[4]}
+		if secs, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			info.Created = time.Unix(secs, 0)
+		}
+		if secs, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			info.LastActivity = time.Unix(secs, 0)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// PanePIDs maps each clawide- session to the PID of the process running in
+// its pane (the shell, or whatever replaced it).
+func PanePIDs() (map[string]int32, error) {
+	out, err := exec.Command(binary, "list-panes", "-a", "-F", "#{session_name}\t#{pane_pid}").Output()
+	if err != nil {
+		if strings.Contains(err.Error(), "exit status") {
+			return map[string]int32{}, nil
+		}
+		return nil, fmt.Errorf("listing %s panes: %w", binary, err)
+	}
+	return parsePanePIDs(string(out)), nil
+}
+
+// parsePanePIDs parses PanePIDs' list-panes output. A session with several
+// panes keeps the first one.
+func parsePanePIDs(out string) map[string]int32 {
+	pids := make(map[string]int32)
+	for _, line := range strings.Split(out, "\n") {
+		name, pid, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, seen := pids[name]; seen {
+			continue
+		}
+		if n, err := strconv.ParseInt(pid, 10, 32); err == nil {
+			pids[name] = int32(n)
+		}
+	}
+	return pids
+}
+
 // KillSession kills a multiplexer session by name.
 func KillSession(name string) error {
 	return exec.Command(binary, "kill-session", "-t", name).Run()
@@ -89,10 +170,13 @@ func SessionCommand(name string) (string, []string) {
 //   - window-size latest: session adopts the attaching client's dimensions
 //     immediately, avoiding a brief paint at tmux's default 80x24.
 //
+// env is exported into a newly created session's shell; it has no effect on
+// a session that already exists.
+//
 // Option errors are swallowed — a failed set-option shouldn't block attach.
-func PrepareSession(name, workDir string) error {
+func PrepareSession(name, workDir string, env map[string]string) error {
 	if !HasSession(name) {
-		if err := exec.Command(binary, "new-session", "-d", "-s", name, "-c", workDir).Run(); err != nil {
+		if err := exec.Command(binary, newSessionArgs(name, workDir, env)...).Run(); err != nil {
 			return fmt.Errorf("creating detached %s session: %w", binary, err)
 		}
 	}
@@ -108,11 +192,75 @@ func PrepareSession(name, workDir string) error {
 	return nil
 }
 
+// newSessionArgs builds the new-session command line. Variables are passed
+// with -e in sorted order so the command is deterministic.
+func newSessionArgs(name, workDir string, env map[string]string) []string {
+	args := []string{"new-session", "-d", "-s", name, "-c", workDir}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-e", k+"="+env[k])
+	}
+	return args
+}
+
+// Shell readiness polling. A shell is considered ready once it has drawn
+// something (its prompt) and the screen has stopped changing between two
+// polls.
+const (
+	shellPollInterval = 100 * time.Millisecond
+	ShellReadyTimeout = 5 * time.Second
+)
+
+// WaitForShell blocks until the session's shell looks ready for input or
+// timeout passes, and reports whether it became ready. Callers typically
+// send their command either way.
+func WaitForShell(name string, timeout time.Duration) bool {
+	return waitForStableScreen(func() (string, error) {
+		return CapturePane(name, 0)
+	}, timeout, shellPollInterval)
+}
+
+func waitForStableScreen(capture func() (string, error), timeout, interval time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	prev := ""
+	for {
+		screen, err := capture()
+		screen = strings.TrimSpace(screen)
+		if err == nil && screen != "" && screen == prev {
+			return true
+		}
+		prev = screen
+		if time.Now().Add(interval).After(deadline) {
+			return false
+		}
+		time.Sleep(interval)
+	}
+}
+
 // SendKeys sends keystrokes to a multiplexer session. The keys are followed by Enter.
 func SendKeys(sessionName, keys string) error {
 	return exec.Command(binary, "send-keys", "-t", sessionName, keys, "Enter").Run()
 }
 
+// SendText types text into a multiplexer session literally (key names such
+// as "Enter" or "C-c" inside it are not interpreted), optionally followed by
+// Enter.
+func SendText(sessionName, text string, enter bool) error {
+	if text != "" {
+		if err := exec.Command(binary, "send-keys", "-t", sessionName, "-l", text).Run(); err != nil {
+			return err
+		}
+	}
+	if enter {
+		return exec.Command(binary, "send-keys", "-t", sessionName, "Enter").Run()
+	}
+	return nil
+}
+
 // SendControl sends a control key (e.g. "C-c") to a multiplexer session
 // without appending Enter. Useful for sending interrupt signals.
 func SendControl(sessionName, key string) error {
@@ -124,6 +272,15 @@ func TmuxName(paneID string) string {
 	return prefix + paneID
 }
 
+// PaneID is the inverse of TmuxName. ok is false for sessions ClawIDE did not
+// name.
+func PaneID(tmuxName string) (string, bool) {
+	if !strings.HasPrefix(tmuxName, prefix) || len(tmuxName) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(tmuxName, prefix), true
+}
+
 // GetPasteBuffer returns the contents of the most recent tmux paste buffer.
 func GetPasteBuffer() (string, error) {
 	out, err := exec.Command(binary, "show-buffer").Output()
@@ -132,3 +289,28 @@ func GetPasteBuffer() (string, error) {
 	}
 	return string(out), nil
 }
+
+// CapturePane returns the text of a session's active pane, including up to
+// historyLines lines of scrollback. Wrapped lines are joined and escape
+// sequences are omitted.
+func CapturePane(sessionName string, historyLines int) (string, error) {
+	out, err := exec.Command(binary, "capture-pane", "-p", "-J", "-t", sessionName, "-S", fmt.Sprintf("-%d", historyLines)).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s capture-pane: %w", binary, err)
+	}
+	return string(out), nil
+}
+
+// LastActivity returns when the session's window last received output.
+// tmux tracks this with one-second resolution.
+func LastActivity(sessionName string) (time.Time, error) {
+	out, err := exec.Command(binary, "display-message", "-p", "-t", sessionName, "#{window_activity}").Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s display-message: %w", binary, err)
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing window_activity %q: %w", strings.TrimSpace(string(out)), err)
+	}
+	return time.Unix(secs, 0), nil
+}
@@ -1,7 +1,9 @@
 package tmux
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -57,3 +59,62 @@ func TestCheckWithNonexistentBinary(t *testing.T) {
 	assert.Contains(t, err.Error(), "nonexistent-mux-binary-12345")
 	assert.Contains(t, err.Error(), "not found")
 }
+
+func TestNewSessionArgs(t *testing.T) {
+	assert.Equal(t,
+		[]string{"new-session", "-d", "-s", "clawide-p", "-c", "/src", "-e", "A=1", "-e", "B=two words"},
+		newSessionArgs("clawide-p", "/src", map[string]string{"B": "two words", "A": "1"}))
+	assert.Equal(t, []string{"new-session", "-d", "-s", "clawide-p", "-c", "/src"}, newSessionArgs("clawide-p", "/src", nil))
+}
+
+func TestWaitForStableScreen(t *testing.T) {
+	t.Run("ready once the prompt stops changing", func(t *testing.T) {
+		screens := []string{"", "Last login", "Last login\n$", "Last login\n$"}
+		calls := 0
+		ok := waitForStableScreen(func() (string, error) {
+			s := screens[min(calls, len(screens)-1)]
+			calls++
+			return s, nil
+		}, time.Second, time.Millisecond)
+		assert.True(t, ok)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("blank or failing screens time out", func(t *testing.T) {
+		assert.False(t, waitForStableScreen(func() (string, error) { return "  \n", nil }, 20*time.Millisecond, time.Millisecond))
+		assert.False(t, waitForStableScreen(func() (string, error) { return "$", errors.New("no session") }, 20*time.Millisecond, time.Millisecond))
+	})
+}
+
+func TestParseSessionInfo(t *testing.T) {
+	out := "clawide-p1\t1700000000\t1700000100\t/home/u/app\tclaude\n" +
+		"work\t1700000000\t1700000000\t/tmp\tbash\n" +
+		"clawide-p2\tbad\t1700000200\t/srv\tnode\n" +
+		"clawide-short\n"
+
+	infos := parseSessionInfo(out)
+	assert.Equal(t, []SessionInfo{
+		{Name: "clawide-p1", Created: time.Unix(1700000000, 0), LastActivity: time.Unix(1700000100, 0), Path: "/home/u/app", Command: "claude"},
+		{Name: "clawide-p2", LastActivity: time.Unix(1700000200, 0), Path: "/srv", Command: "node"},
+	}, infos)
+}
+
+func TestPaneID(t *testing.T) {
+	id, ok := PaneID("clawide-abc-123")
+	assert.True(t, ok)
+	assert.Equal(t, "abc-123", id)
+
+	_, ok = PaneID("clawide-")
+	assert.False(t, ok)
+	_, ok = PaneID("other")
+	assert.False(t, ok)
+}
+
+func TestParsePanePIDs(t *testing.T) {
+	out := "clawide-p1\t4242\n" +
+		"clawide-p1\t4343\n" +
+		"work\t100\n" +
+		"clawide-p2\tnope\n" +
+		"clawide-p3\t77\n"
+	assert.Equal(t, map[string]int32{"clawide-p1": 4242, "clawide-p3": 77}, parsePanePIDs(out))
+}
@@ -13,9 +13,9 @@ import (
 
 	"github.com/davydany/ClawIDE/internal/config"
 	"github.com/davydany/ClawIDE/internal/model"
-	"github.com/davydany/ClawIDE/internal/sse"
-	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/davydany/ClawIDE/internal/notify"
 	"github.com/davydany/ClawIDE/internal/version"
+	"github.com/davydany/ClawIDE/internal/webhook"
 	"github.com/google/uuid"
 )
 
@@ -53,11 +53,11 @@ type githubAsset struct {
 
 // Updater manages checking for and applying updates.
 type Updater struct {
-	cfg               *config.Config
-	notificationStore *store.NotificationStore
-	sseHub            *sse.Hub
-	client            *http.Client
-	baseURL           string
+	cfg      *config.Config
+	notifier *notify.Center
+	client   *http.Client
+	baseURL  string
+	webhooks *webhook.Dispatcher
 
 	mu    sync.RWMutex
 	state State
@@ -67,20 +67,19 @@ type Updater struct {
 }
 
 // New creates an Updater that checks the official GitHub releases API.
-func New(cfg *config.Config, notifStore *store.NotificationStore, hub *sse.Hub) *Updater {
-	return NewWithBaseURL(cfg, notifStore, hub, defaultGitHubAPI)
+func New(cfg *config.Config, notifier *notify.Center) *Updater {
+	return NewWithBaseURL(cfg, notifier, defaultGitHubAPI)
 }
 
 // NewWithBaseURL creates an Updater with a custom API URL (for testing).
-func NewWithBaseURL(cfg *config.Config, notifStore *store.NotificationStore, hub *sse.Hub, baseURL string) *Updater {
+func NewWithBaseURL(cfg *config.Config, notifier *notify.Center, baseURL string) *Updater {
 	u := &Updater{
-		cfg:               cfg,
-		notificationStore: notifStore,
-		sseHub:            hub,
-		client:            &http.Client{Timeout: 15 * time.Second},
-		baseURL:           baseURL,
-		stopCh:            make(chan struct{}),
-		done:              make(chan struct{}),
+		cfg:      cfg,
+		notifier: notifier,
+		client:   &http.Client{Timeout: 15 * time.Second},
+		baseURL:  baseURL,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
 	}
 	u.loadState()
 	return u
@@ -97,6 +96,12 @@ func (u *Updater) Stop() {
 	<-u.done
 }
 
+// SetWebhooks has Check emit an update.available event the first time it
+// sees each new release.
+func (u *Updater) SetWebhooks(d *webhook.Dispatcher) {
+	u.webhooks = d
+}
+
 // Check performs a fresh check against the GitHub API and returns the state.
 func (u *Updater) Check() State {
 	if version.IsDevVersion() {
@@ -123,6 +128,7 @@ func (u *Updater) Check() State {
 	state := u.checkGitHub()
 
 	u.mu.Lock()
+	previous := u.state
 	u.state = state
 	u.mu.Unlock()
 
@@ -130,6 +136,15 @@ func (u *Updater) Check() State {
 
 	if state.UpdateAvailable {
 		u.sendNotification(state)
+		if !previous.UpdateAvailable || previous.LatestVersion != state.LatestVersion {
+			u.webhooks.Emit(webhook.Event{
+				Type:   model.WebhookEventUpdateAvailable,
+				Level:  "info",
+				Source: "system",
+				Text:   fmt.Sprintf("ClawIDE %s is available (running %s)", state.LatestVersion, state.CurrentVersion),
+				Data:   state,
+			})
+		}
 	}
 
 	return state
@@ -248,7 +263,7 @@ func platformAssetName(tag string) string {
 }
 
 func (u *Updater) sendNotification(state State) {
-	if u.notificationStore == nil || u.sseHub == nil {
+	if u.notifier == nil {
 		return
 	}
 
@@ -262,11 +277,9 @@ func (u *Updater) sendNotification(state State) {
 		CreatedAt:      time.Now().UTC(),
 	}
 
-	if err := u.notificationStore.Add(n); err != nil {
+	if _, _, err := u.notifier.Post(n); err != nil {
 		log.Printf("[updater] notification add: %v", err)
-		return
 	}
-	u.sseHub.Broadcast(&n)
 }
 
 func (u *Updater) loadState() {
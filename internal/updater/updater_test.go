@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/davydany/ClawIDE/internal/config"
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/notify"
 	"github.com/davydany/ClawIDE/internal/sse"
 	"github.com/davydany/ClawIDE/internal/store"
 	"github.com/davydany/ClawIDE/internal/version"
@@ -45,7 +47,7 @@ func setupTestUpdater(t *testing.T, handler http.Handler) (*Updater, *httptest.S
 	require.NoError(t, err)
 
 	hub := sse.NewHub()
-	u := NewWithBaseURL(cfg, notifStore, hub, ts.URL)
+	u := NewWithBaseURL(cfg, notify.New(notifStore, nil, hub, nil), ts.URL)
 	return u, ts
 }
 
@@ -133,7 +135,7 @@ func TestCheck_NetworkError(t *testing.T) {
 	notifStore, err := store.NewNotificationStore(notifPath, 200)
 	require.NoError(t, err)
 
-	u := NewWithBaseURL(cfg, notifStore, sse.NewHub(), "http://127.0.0.1:1") // dead port
+	u := NewWithBaseURL(cfg, notify.New(notifStore, nil, sse.NewHub(), nil), "http://127.0.0.1:1") // dead port
 	state := u.Check()
 	assert.Contains(t, state.Error, "network error")
 }
@@ -225,7 +227,7 @@ func TestStateLoadOnInit(t *testing.T) {
 	notifStore, err := store.NewNotificationStore(notifPath, 200)
 	require.NoError(t, err)
 
-	u := NewWithBaseURL(cfg, notifStore, sse.NewHub(), "http://unused")
+	u := NewWithBaseURL(cfg, notify.New(notifStore, nil, sse.NewHub(), nil), "http://unused")
 
 	got := u.State()
 	assert.True(t, got.UpdateAvailable)
@@ -246,7 +248,7 @@ func TestNotificationSent(t *testing.T) {
 
 	u.Check()
 
-	notifs := u.notificationStore.GetAll()
+	notifs := storedNotifications(t, u)
 	require.Len(t, notifs, 1)
 	assert.Contains(t, notifs[0].Title, "Update Available")
 	assert.Contains(t, notifs[0].Body, "v1.1.0")
@@ -284,6 +286,14 @@ func TestNotificationIdempotent(t *testing.T) {
 	u.Check()
 	u.Check() // second check should not duplicate notification
 
-	notifs := u.notificationStore.GetAll()
+	notifs := storedNotifications(t, u)
 	assert.Len(t, notifs, 1)
 }
+
+// storedNotifications reads back the notifications u has saved.
+func storedNotifications(t *testing.T, u *Updater) []model.Notification {
+	t.Helper()
+	notifStore, err := store.NewNotificationStore(u.cfg.NotificationsFilePath(), 200)
+	require.NoError(t, err)
+	return notifStore.GetAll()
+}
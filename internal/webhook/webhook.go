@@ -0,0 +1,266 @@
+// Package webhook posts notifications and lifecycle events to the outside
+// URLs configured under Settings > Webhooks, signing each body and retrying
+// failed deliveries with backoff.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/davydany/ClawIDE/internal/version"
+	"github.com/google/uuid"
+)
+
+// Request headers sent with every delivery.
+const (
+	HeaderEvent     = "X-ClawIDE-Event"
+	HeaderDelivery  = "X-ClawIDE-Delivery" // the event ID, the same on every retry
+	HeaderSignature = "X-ClawIDE-Signature"
+)
+
+// DefaultBackoff is the wait before each retry of a failed delivery.
+var DefaultBackoff = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute, 30 * time.Minute}
+
+// Event is the JSON body posted to a webhook. Text is a one-line summary, so
+// chat services that show a "text" field (Slack, Mattermost, Rocket.Chat)
+// can take the payload as is.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Level     string    `json:"level"`
+	Source    string    `json:"source"`
+	ProjectID string    `json:"project_id,omitempty"`
+	Text      string    `json:"text"`
+	Data      any       `json:"data,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationEvent wraps a notification.
+func NotificationEvent(n *model.Notification) Event {
+	text := n.Title
+	if n.Body != "" {
+		text += ": " + n.Body
+	}
+	return Event{
+		Type:      model.WebhookEventNotification,
+		Level:     n.Level,
+		Source:    n.Source,
+		ProjectID: n.ProjectID,
+		Text:      text,
+		Data:      n,
+	}
+}
+
+// FeatureEvent reports a feature being created, merged or deleted.
+func FeatureEvent(eventType string, project model.Project, f model.Feature) Event {
+	verb := map[string]string{
+		model.WebhookEventFeatureCreated: "created",
+		model.WebhookEventFeatureMerged:  "merged",
+		model.WebhookEventFeatureDeleted: "deleted",
+	}[eventType]
+	return Event{
+		Type:      eventType,
+		Level:     "info",
+		Source:    "feature",
+		ProjectID: project.ID,
+		Text:      fmt.Sprintf("%s: feature %q (%s) %s", project.Name, f.Name, f.BranchName, verb),
+		Data:      f,
+	}
+}
+
+// ScheduledJobEvent reports a scheduled job being run.
+func ScheduledJobEvent(project model.Project, job model.ScheduledJob) Event {
+	return Event{
+		Type:      model.WebhookEventScheduledJobRun,
+		Level:     "info",
+		Source:    "scheduled_job",
+		ProjectID: project.ID,
+		Text:      fmt.Sprintf("%s: scheduled job %q started", project.Name, job.Name),
+		Data:      job,
+	}
+}
+
+// Sign returns the X-ClawIDE-Signature value for body: "sha256=" and the hex
+// HMAC-SHA256 of the body keyed with the webhook's secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers events to the matching webhooks in the background. A
+// nil Dispatcher drops events, so callers need not check for one.
+type Dispatcher struct {
+	store   *store.WebhookStore
+	client  *http.Client
+	backoff []time.Duration
+
+	mu      sync.Mutex
+	stopped bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New returns a Dispatcher for the webhooks in st.
+func New(st *store.WebhookStore) *Dispatcher {
+	return &Dispatcher{
+		store:   st,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		backoff: DefaultBackoff,
+		stop:    make(chan struct{}),
+	}
+}
+
+// SetBackoff replaces the retry schedule; one retry per entry.
+func (d *Dispatcher) SetBackoff(backoff []time.Duration) {
+	d.backoff = backoff
+}
+
+// Notify emits a notification.created event for n.
+func (d *Dispatcher) Notify(n *model.Notification) {
+	d.Emit(NotificationEvent(n))
+}
+
+// Emit sends e to every enabled webhook whose filters match it, each on its
+// own goroutine with retries.
+func (d *Dispatcher) Emit(e Event) {
+	if d == nil {
+		return
+	}
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("[webhook] marshaling %s event: %v", e.Type, err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	for _, hook := range d.store.GetAll() {
+		if !hook.Matches(e.Type, e.Level, e.Source, e.ProjectID) {
+			continue
+		}
+		d.wg.Add(1)
+		go d.run(hook, e, body)
+	}
+}
+
+// run delivers body to hook, retrying per the backoff schedule until an
+// attempt succeeds, fails permanently, or the webhook is disabled or
+// removed in the meantime.
+func (d *Dispatcher) run(hook model.Webhook, e Event, body []byte) {
+	defer d.wg.Done()
+	for attempt := 1; ; attempt++ {
+		del, retryable := d.attempt(hook, e, body, attempt)
+		del.Retrying = retryable && attempt <= len(d.backoff)
+		if err := d.store.AddDelivery(del); err != nil {
+			log.Printf("[webhook] logging delivery: %v", err)
+		}
+		if !del.Retrying {
+			return
+		}
+		select {
+		case <-time.After(d.backoff[attempt-1]):
+		case <-d.stop:
+			return
+		}
+		var ok bool
+		if hook, ok = d.store.Get(hook.ID); !ok || !hook.Enabled {
+			return
+		}
+	}
+}
+
+// Deliver makes a single attempt to send e to hook, logs it and returns the
+// record. Settings uses it to send a test event.
+func (d *Dispatcher) Deliver(hook model.Webhook, e Event) (model.WebhookDelivery, error) {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	del, _ := d.attempt(hook, e, body, 1)
+	return del, d.store.AddDelivery(del)
+}
+
+// attempt posts body once. retryable reports whether a failure may be
+// temporary: a network error, 429 or 5xx.
+func (d *Dispatcher) attempt(hook model.Webhook, e Event, body []byte, n int) (del model.WebhookDelivery, retryable bool) {
+	del = model.WebhookDelivery{
+		ID:        uuid.New().String(),
+		WebhookID: hook.ID,
+		EventID:   e.ID,
+		EventType: e.Type,
+		Attempt:   n,
+		CreatedAt: time.Now().UTC(),
+	}
+	start := time.Now()
+	defer func() { del.DurationMS = time.Since(start).Milliseconds() }()
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		del.Error = err.Error()
+		return del, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ClawIDE-Webhook/"+version.Version)
+	req.Header.Set(HeaderEvent, e.Type)
+	req.Header.Set(HeaderDelivery, e.ID)
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		del.Error = err.Error()
+		return del, true
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	del.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		del.Success = true
+		return del, false
+	}
+	del.Error = resp.Status
+	return del, resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// Stop abandons pending retries and waits for attempts in flight.
+func (d *Dispatcher) Stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.stop)
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a local stand-in for a chat service's incoming webhook. It
+// answers with the queued statuses, then 200.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	got      chan struct{}
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	rc := &receiver{statuses: statuses, got: make(chan struct{}, 16)}
+	rc.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.mu.Lock()
+		rc.requests = append(rc.requests, r)
+		rc.bodies = append(rc.bodies, body)
+		status := http.StatusOK
+		if len(rc.statuses) > 0 {
+			status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+		}
+		rc.mu.Unlock()
+		w.WriteHeader(status)
+		rc.got <- struct{}{}
+	}))
+	t.Cleanup(rc.Close)
+	return rc
+}
+
+func (rc *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rc.got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for request %d", i+1)
+		}
+	}
+}
+
+func newDispatcher(t *testing.T, hooks ...model.Webhook) (*Dispatcher, *store.WebhookStore) {
+	t.Helper()
+	dir := t.TempDir()
+	st, err := store.NewWebhookStore(filepath.Join(dir, "webhooks.json"), filepath.Join(dir, "deliveries.json"), 100)
+	require.NoError(t, err)
+	for _, h := range hooks {
+		require.NoError(t, st.Add(h))
+	}
+	d := New(st)
+	d.SetBackoff([]time.Duration{time.Millisecond, time.Millisecond})
+	return d, st
+}
+
+func TestEmitSignsAndPosts(t *testing.T) {
+	rc := newReceiver(t)
+	d, st := newDispatcher(t, model.Webhook{ID: "w1", URL: rc.URL, Secret: "s3cret", Enabled: true})
+
+	d.Notify(&model.Notification{ID: "n1", Title: "Build failed", Body: "exit 1", Source: "claude", Level: "error", ProjectID: "p1"})
+	rc.wait(t, 1)
+	d.Stop()
+
+	req, body := rc.requests[0], rc.bodies[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, model.WebhookEventNotification, req.Header.Get(HeaderEvent))
+	assert.Equal(t, Sign("s3cret", body), req.Header.Get(HeaderSignature))
+
+	var e struct {
+		ID        string             `json:"id"`
+		Type      string             `json:"type"`
+		Level     string             `json:"level"`
+		ProjectID string             `json:"project_id"`
+		Text      string             `json:"text"`
+		Data      model.Notification `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &e))
+	assert.Equal(t, req.Header.Get(HeaderDelivery), e.ID)
+	assert.Equal(t, "error", e.Level)
+	assert.Equal(t, "p1", e.ProjectID)
+	assert.Equal(t, "Build failed: exit 1", e.Text)
+	assert.Equal(t, "n1", e.Data.ID)
+
+	dels := st.Deliveries("w1")
+	require.Len(t, dels, 1)
+	assert.True(t, dels[0].Success)
+	assert.Equal(t, http.StatusOK, dels[0].StatusCode)
+	assert.Equal(t, e.ID, dels[0].EventID)
+}
+
+func TestEmitFilters(t *testing.T) {
+	rc := newReceiver(t)
+	d, _ := newDispatcher(t,
+		model.Webhook{ID: "errors", URL: rc.URL + "/errors", Enabled: true, Levels: []string{"error"}},
+		model.Webhook{ID: "p2-features", URL: rc.URL + "/features", Enabled: true, Events: []string{model.WebhookEventFeatureCreated}, ProjectIDs: []string{"p2"}},
+		model.Webhook{ID: "off", URL: rc.URL + "/off", Enabled: false},
+	)
+
+	d.Notify(&model.Notification{Title: "fine", Level: "info", ProjectID: "p2"})
+	d.Notify(&model.Notification{Title: "broken", Level: "error", ProjectID: "p1"})
+	d.Emit(FeatureEvent(model.WebhookEventFeatureCreated, model.Project{ID: "p1"}, model.Feature{Name: "a"}))
+	d.Emit(FeatureEvent(model.WebhookEventFeatureCreated, model.Project{ID: "p2"}, model.Feature{Name: "b"}))
+	d.Stop()
+
+	var paths []string
+	for _, r := range rc.requests {
+		paths = append(paths, r.URL.Path)
+	}
+	assert.ElementsMatch(t, []string{"/errors", "/features"}, paths)
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	rc := newReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	d, st := newDispatcher(t, model.Webhook{ID: "w1", URL: rc.URL, Enabled: true})
+
+	d.Emit(Event{Type: model.WebhookEventUpdateAvailable, Level: "info", Source: "system"})
+	rc.wait(t, 3)
+	d.Stop()
+
+	dels := st.Deliveries("w1")
+	require.Len(t, dels, 3)
+	// Newest first.
+	assert.True(t, dels[0].Success)
+	assert.Equal(t, 3, dels[0].Attempt)
+	assert.False(t, dels[0].Retrying)
+	assert.Equal(t, http.StatusTooManyRequests, dels[1].StatusCode)
+	assert.True(t, dels[1].Retrying)
+	assert.Equal(t, http.StatusServiceUnavailable, dels[2].StatusCode)
+	assert.Equal(t, dels[0].EventID, dels[2].EventID)
+	// Every retry carries the same delivery ID.
+	assert.Equal(t, rc.requests[0].Header.Get(HeaderDelivery), rc.requests[2].Header.Get(HeaderDelivery))
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	t.Run("after the last backoff", func(t *testing.T) {
+		rc := newReceiver(t, 500, 500, 500, 500)
+		d, st := newDispatcher(t, model.Webhook{ID: "w1", URL: rc.URL, Enabled: true})
+		d.Emit(Event{Type: model.WebhookEventFeatureDeleted})
+		rc.wait(t, 3)
+		d.Stop()
+		dels := st.Deliveries("w1")
+		require.Len(t, dels, 3)
+		assert.False(t, dels[0].Success)
+		assert.False(t, dels[0].Retrying)
+		assert.Equal(t, "500 Internal Server Error", dels[0].Error)
+	})
+
+	t.Run("on a client error", func(t *testing.T) {
+		rc := newReceiver(t, http.StatusNotFound)
+		d, st := newDispatcher(t, model.Webhook{ID: "w1", URL: rc.URL, Enabled: true})
+		d.Emit(Event{Type: model.WebhookEventFeatureDeleted})
+		d.Stop()
+		dels := st.Deliveries("w1")
+		require.Len(t, dels, 1)
+		assert.False(t, dels[0].Retrying)
+	})
+
+	t.Run("when the webhook is removed", func(t *testing.T) {
+		rc := newReceiver(t, 500, 500, 500)
+		d, st := newDispatcher(t, model.Webhook{ID: "w1", URL: rc.URL, Enabled: true})
+		d.SetBackoff([]time.Duration{200 * time.Millisecond, 200 * time.Millisecond})
+		d.Emit(Event{Type: model.WebhookEventFeatureDeleted})
+		rc.wait(t, 1)
+		require.NoError(t, st.Delete("w1"))
+		time.Sleep(500 * time.Millisecond) // past the first backoff
+		d.Stop()
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		assert.Len(t, rc.requests, 1)
+	})
+}
+
+func TestNilDispatcher(t *testing.T) {
+	var d *Dispatcher
+	d.Emit(Event{Type: model.WebhookEventFeatureCreated})
+	d.Stop()
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "sha256=88a67f24bbcdaed0e6c997404bb79a743baf44c6bab2f4c27328e3009d22e342", Sign("key", []byte(`{"a":1}`)))
+}
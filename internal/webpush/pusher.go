@@ -0,0 +1,123 @@
+package webpush
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"sync"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/store"
+)
+
+// maxBody bounds the notification body sent in a push, well inside
+// MaxPayload.
+const maxBody = 1000
+
+// Payload is the JSON a push carries. The service worker shows it and opens
+// URL when it is clicked.
+type Payload struct {
+	Title     string `json:"title"`
+	Body      string `json:"body,omitempty"`
+	Level     string `json:"level"`
+	Tag       string `json:"tag"` // the notification ID
+	URL       string `json:"url"`
+	ProjectID string `json:"project_id,omitempty"`
+}
+
+// NotificationPayload builds the push for n, linking to its pane, feature
+// or project like the notification bell does.
+func NotificationPayload(n model.Notification) Payload {
+	body := n.Body
+	if r := []rune(body); len(r) > maxBody {
+		body = string(r[:maxBody]) + "…"
+	}
+	link := "/"
+	if n.ProjectID != "" {
+		link = "/projects/" + n.ProjectID + "/"
+		if n.FeatureID != "" {
+			link += "features/" + n.FeatureID + "/"
+		}
+		if n.PaneID != "" {
+			link += "?pane=" + url.QueryEscape(n.PaneID)
+		}
+	}
+	return Payload{Title: n.Title, Body: body, Level: n.Level, Tag: n.ID, URL: link, ProjectID: n.ProjectID}
+}
+
+// Pusher sends notifications to the subscribed browsers whose filters match
+// and whose user can see the notification's project.
+type Pusher struct {
+	sender   *Sender
+	subs     *store.PushSubscriptionStore
+	projects *store.Store
+	wg       sync.WaitGroup
+}
+
+// NewPusher returns a Pusher for the subscriptions in subs.
+func NewPusher(sender *Sender, subs *store.PushSubscriptionStore, projects *store.Store) *Pusher {
+	return &Pusher{sender: sender, subs: subs, projects: projects}
+}
+
+// PublicKey is the VAPID key browsers subscribe with.
+func (p *Pusher) PublicKey() string {
+	return p.sender.PublicKey()
+}
+
+// Notify pushes n in the background. It is registered with the SSE hub, so
+// every announced notification is pushed, including those released when
+// quiet hours end. A nil Pusher does nothing.
+func (p *Pusher) Notify(n *model.Notification) {
+	if p == nil {
+		return
+	}
+	payload := NotificationPayload(*n)
+	for _, sub := range p.subs.GetAll() {
+		if !sub.Matches(n.Level, n.ProjectID) || !p.canSee(sub, n.ProjectID) {
+			continue
+		}
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			if err := p.Push(sub, payload); err != nil {
+				log.Printf("[webpush] %s: %v", sub.ID, err)
+			}
+		}()
+	}
+}
+
+// Push sends payload to one subscription now, deleting the subscription if
+// the push service says it is gone.
+func (p *Pusher) Push(sub model.PushSubscription, payload Payload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	opts := Options{Urgency: "normal"}
+	if payload.Level == "error" || payload.Level == "warning" {
+		opts.Urgency = "high"
+	}
+	err = p.sender.Send(Subscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}, data, opts)
+	if errors.Is(err, ErrGone) {
+		if derr := p.subs.Delete(sub.ID); derr != nil {
+			log.Printf("[webpush] deleting expired subscription %s: %v", sub.ID, derr)
+		}
+	}
+	return err
+}
+
+// Wait blocks until pushes started by Notify have finished.
+func (p *Pusher) Wait() {
+	if p != nil {
+		p.wg.Wait()
+	}
+}
+
+func (p *Pusher) canSee(sub model.PushSubscription, projectID string) bool {
+	if projectID == "" || sub.UserID == "" {
+		return true
+	}
+	project, ok := p.projects.GetProject(projectID)
+	return ok && project.RoleFor(sub.UserID) != model.RoleNone
+}
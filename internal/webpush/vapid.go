@@ -0,0 +1,106 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"time"
+)
+
+// VAPIDKeys identify this server to push services (RFC 8292). Both keys are
+// unpadded base64url: the public key as an uncompressed P-256 point, the
+// private key as its 32-byte scalar.
+type VAPIDKeys struct {
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"private_key"`
+
+	signer *ecdsa.PrivateKey
+}
+
+// GenerateVAPIDKeys returns a new key pair.
+func GenerateVAPIDKeys() (*VAPIDKeys, error) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseVAPIDKeys(b64.EncodeToString(priv.Bytes()))
+}
+
+// LoadOrCreateVAPIDKeys reads the key pair stored at path, generating and
+// saving one (mode 0600) on first use. Browsers tie their subscriptions to
+// the public key, so it must not change once they have subscribed.
+func LoadOrCreateVAPIDKeys(path string) (*VAPIDKeys, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var stored VAPIDKeys
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		return parseVAPIDKeys(stored.PrivateKey)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	keys, err := GenerateVAPIDKeys()
+	if err != nil {
+		return nil, fmt.Errorf("generating VAPID keys: %w", err)
+	}
+	data, _ = json.MarshalIndent(keys, "", "  ")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("saving VAPID keys: %w", err)
+	}
+	return keys, nil
+}
+
+func parseVAPIDKeys(private string) (*VAPIDKeys, error) {
+	raw, err := b64.DecodeString(private)
+	if err != nil {
+		return nil, fmt.Errorf("decoding VAPID private key: %w", err)
+	}
+	priv, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	pub := priv.PublicKey().Bytes()
+	signer := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(raw)}
+	signer.Curve = elliptic.P256()
+	signer.X = new(big.Int).SetBytes(pub[1:33])
+	signer.Y = new(big.Int).SetBytes(pub[33:])
+	return &VAPIDKeys{PublicKey: b64.EncodeToString(pub), PrivateKey: private, signer: signer}, nil
+}
+
+// authorization returns the Authorization header value for a push to
+// endpoint: a JWT for the endpoint's origin, valid for 12 hours, signed with
+// ES256.
+func (k *VAPIDKeys) authorization(endpoint, subject string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid push endpoint %q", endpoint)
+	}
+	header := b64.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, _ := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(12 * time.Hour).Unix(),
+		"sub": subject,
+	})
+	signed := header + "." + b64.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, k.signer, digest[:])
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return "vapid t=" + signed + "." + b64.EncodeToString(sig) + ", k=" + k.PublicKey, nil
+}
+
+var b64 = base64.RawURLEncoding
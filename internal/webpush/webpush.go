@@ -0,0 +1,199 @@
+// Package webpush sends notifications to browsers through their push
+// services (Web Push, RFC 8030), so they arrive with no ClawIDE tab open.
+// Payloads are encrypted per RFC 8291 and requests identified with VAPID
+// (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSubject is the VAPID contact sent to push services.
+const DefaultSubject = "https://github.com/davydany/ClawIDE"
+
+// recordSize is the aes128gcm record size. Payloads must fit in one record.
+const recordSize = 4096
+
+// MaxPayload is the largest plaintext Encrypt accepts: one record less the
+// AEAD tag and padding delimiter.
+const MaxPayload = recordSize - 16 - 1
+
+// ErrGone means the push service no longer knows the subscription (404 or
+// 410); it should be deleted.
+var ErrGone = errors.New("push subscription expired or unsubscribed")
+
+// DecodeKey decodes a subscription key as browsers send it: base64url,
+// usually unpadded, though standard base64 is accepted too.
+func DecodeKey(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return b64.DecodeString(s)
+}
+
+// Encrypt encrypts plaintext for a browser with the given p256dh public key
+// and auth secret, returning an aes128gcm body (RFC 8188) in a single
+// record.
+func Encrypt(p256dh, authSecret, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	local, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(p256dh, authSecret, plaintext, salt, local)
+}
+
+func encrypt(p256dh, authSecret, plaintext, salt []byte, local *ecdh.PrivateKey) ([]byte, error) {
+	if len(plaintext) > MaxPayload {
+		return nil, fmt.Errorf("push payload of %d bytes exceeds %d", len(plaintext), MaxPayload)
+	}
+	if len(authSecret) != 16 {
+		return nil, fmt.Errorf("auth secret must be 16 bytes, got %d", len(authSecret))
+	}
+	remote, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	shared, err := local.ECDH(remote)
+	if err != nil {
+		return nil, err
+	}
+	localPub := local.PublicKey().Bytes()
+
+	// RFC 8291 section 3.4: mix the auth secret and both public keys in.
+	keyInfo := "WebPush: info\x00" + string(p256dh) + string(localPub)
+	ikm, err := hkdf.Key(sha256.New, shared, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, key ID length and the key ID (our public
+	// key), then the record, ending with the last-record delimiter 0x02.
+	var body bytes.Buffer
+	body.Write(salt)
+	binary.Write(&body, binary.BigEndian, uint32(recordSize))
+	body.WriteByte(byte(len(localPub)))
+	body.Write(localPub)
+	record := append(append([]byte{}, plaintext...), 0x02)
+	body.Write(gcm.Seal(nil, nonce, record, nil))
+	return body.Bytes(), nil
+}
+
+// Subscription is where and how to reach one browser.
+type Subscription struct {
+	Endpoint string
+	P256dh   string // base64url
+	Auth     string // base64url
+}
+
+// Options tune a single push.
+type Options struct {
+	TTL     time.Duration // how long the push service keeps it for an offline browser
+	Urgency string        // very-low, low, normal or high
+}
+
+// Sender posts encrypted payloads to push services.
+type Sender struct {
+	keys    *VAPIDKeys
+	subject string
+	client  *http.Client
+}
+
+// NewSender returns a Sender that signs with keys.
+func NewSender(keys *VAPIDKeys) *Sender {
+	return NewSenderWithClient(keys, &http.Client{Timeout: 15 * time.Second})
+}
+
+// NewSenderWithClient returns a Sender using client (for testing).
+func NewSenderWithClient(keys *VAPIDKeys, client *http.Client) *Sender {
+	return &Sender{keys: keys, subject: DefaultSubject, client: client}
+}
+
+// PublicKey is the applicationServerKey browsers subscribe with.
+func (s *Sender) PublicKey() string {
+	return s.keys.PublicKey
+}
+
+// Send delivers payload to sub. It returns ErrGone when the subscription
+// should be dropped.
+func (s *Sender) Send(sub Subscription, payload []byte, opts Options) error {
+	p256dh, err := DecodeKey(sub.P256dh)
+	if err != nil {
+		return fmt.Errorf("decoding p256dh: %w", err)
+	}
+	auth, err := DecodeKey(sub.Auth)
+	if err != nil {
+		return fmt.Errorf("decoding auth: %w", err)
+	}
+	body, err := Encrypt(p256dh, auth, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.keys.authorization(sub.Endpoint, s.subject, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", opts.Urgency)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("push service returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davydany/ClawIDE/internal/model"
+	"github.com/davydany/ClawIDE/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// browser is the user agent side of a subscription: it holds the private
+// key and decrypts what the push service hands it.
+type browser struct {
+	priv *ecdh.PrivateKey
+	auth []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return &browser{priv: priv, auth: auth}
+}
+
+func (b *browser) keys() (p256dh, auth string) {
+	return b64.EncodeToString(b.priv.PublicKey().Bytes()), b64.EncodeToString(b.auth)
+}
+
+// decrypt follows RFC 8291 from the receiving end.
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	require.Greater(t, len(body), 21)
+	salt := body[:16]
+	rs := binary.BigEndian.Uint32(body[16:20])
+	idlen := int(body[20])
+	assert.Equal(t, uint32(4096), rs)
+	require.Equal(t, 65, idlen)
+	serverPub, err := ecdh.P256().NewPublicKey(body[21 : 21+idlen])
+	require.NoError(t, err)
+	shared, err := b.priv.ECDH(serverPub)
+	require.NoError(t, err)
+
+	keyInfo := "WebPush: info\x00" + string(b.priv.PublicKey().Bytes()) + string(serverPub.Bytes())
+	ikm, err := hkdf.Key(sha256.New, shared, b.auth, keyInfo, 32)
+	require.NoError(t, err)
+	cek, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plain, err := gcm.Open(nil, nonce, body[21+idlen:], nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, plain)
+	assert.Equal(t, byte(0x02), plain[len(plain)-1], "last record delimiter")
+	return plain[:len(plain)-1]
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	b := newBrowser(t)
+	body, err := Encrypt(b.priv.PublicKey().Bytes(), b.auth, []byte(`{"title":"hi"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"title":"hi"}`, string(b.decrypt(t, body)))
+
+	// A fresh salt and key every time.
+	again, err := Encrypt(b.priv.PublicKey().Bytes(), b.auth, []byte(`{"title":"hi"}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, body, again)
+
+	_, err = Encrypt(b.priv.PublicKey().Bytes(), b.auth, make([]byte, MaxPayload+1))
+	assert.Error(t, err)
+	_, err = Encrypt([]byte("short"), b.auth, nil)
+	assert.Error(t, err)
+}
+
+// TestEncryptRFC8291 checks encrypt against the example in RFC 8291,
+// Appendix A, which fixes the keys and salt.
+func TestEncryptRFC8291(t *testing.T) {
+	decode := func(s string) []byte {
+		b, err := DecodeKey(s)
+		require.NoError(t, err)
+		return b
+	}
+	asPrivate, err := ecdh.P256().NewPrivateKey(decode("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+	require.Equal(t, decode("BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"), asPrivate.PublicKey().Bytes())
+	uaPublic := decode("BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4")
+	authSecret := decode("BTBZMqHH6r4Tts7J_aSIgg")
+	salt := decode("DGv6ra1nlYgDCS1FRnbzlw")
+	plaintext := []byte("When I grow up, I want to be a watermelon")
+
+	body, err := encrypt(uaPublic, authSecret, plaintext, salt, asPrivate)
+	require.NoError(t, err)
+	assert.Equal(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		b64.EncodeToString(body))
+
+	// And the user agent's private key from the RFC decrypts it.
+	uaPrivate, err := ecdh.P256().NewPrivateKey(decode("q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	require.NoError(t, err)
+	require.Equal(t, uaPublic, uaPrivate.PublicKey().Bytes())
+	assert.Equal(t, plaintext, (&browser{priv: uaPrivate, auth: authSecret}).decrypt(t, body))
+}
+
+func TestDecodeKey(t *testing.T) {
+	for _, s := range []string{"-_8", "+/8=", "+/8"} {
+		got, err := DecodeKey(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, []byte{0xfb, 0xff}, got, s)
+	}
+}
+
+func TestVAPIDKeysPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vapid.json")
+	k1, err := LoadOrCreateVAPIDKeys(path)
+	require.NoError(t, err)
+	k2, err := LoadOrCreateVAPIDKeys(path)
+	require.NoError(t, err)
+	assert.Equal(t, k1.PublicKey, k2.PublicKey)
+	pub, err := DecodeKey(k1.PublicKey)
+	require.NoError(t, err)
+	assert.Len(t, pub, 65)
+}
+
+// verifyVAPID checks an Authorization header the way a push service does
+// and returns the JWT claims.
+func verifyVAPID(t *testing.T, header string) map[string]any {
+	t.Helper()
+	require.True(t, strings.HasPrefix(header, "vapid t="), header)
+	token, key, ok := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	require.True(t, ok)
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	pub, err := DecodeKey(key)
+	require.NoError(t, err)
+	sig, err := DecodeKey(parts[2])
+	require.NoError(t, err)
+	require.Len(t, sig, 64)
+	verifier := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(pub[1:33]), Y: new(big.Int).SetBytes(pub[33:])}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.True(t, ecdsa.Verify(verifier, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])), "signature")
+
+	raw, err := DecodeKey(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(raw, &claims))
+	return claims
+}
+
+// pushService stands in for a browser vendor's push service, recording what
+// it receives and answering with status.
+type pushService struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   map[string][]byte // path -> body
+}
+
+func newPushService(t *testing.T) *pushService {
+	ps := &pushService{status: http.StatusCreated, bodies: map[string][]byte{}}
+	ps.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+		ps.requests = append(ps.requests, r)
+		ps.bodies[r.URL.Path] = body
+		w.WriteHeader(ps.status)
+	}))
+	t.Cleanup(ps.Close)
+	return ps
+}
+
+func TestSend(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	ps := newPushService(t)
+	sender := NewSender(keys)
+	b := newBrowser(t)
+	p256dh, auth := b.keys()
+	sub := Subscription{Endpoint: ps.URL + "/push/abc", P256dh: p256dh, Auth: auth}
+
+	require.NoError(t, sender.Send(sub, []byte("hello"), Options{Urgency: "high"}))
+	req := ps.requests[0]
+	assert.Equal(t, "aes128gcm", req.Header.Get("Content-Encoding"))
+	assert.Equal(t, "86400", req.Header.Get("TTL"))
+	assert.Equal(t, "high", req.Header.Get("Urgency"))
+	assert.Equal(t, "hello", string(b.decrypt(t, ps.bodies["/push/abc"])))
+
+	claims := verifyVAPID(t, req.Header.Get("Authorization"))
+	assert.Equal(t, ps.URL, claims["aud"])
+	assert.Equal(t, DefaultSubject, claims["sub"])
+	exp := time.Unix(int64(claims["exp"].(float64)), 0)
+	assert.WithinDuration(t, time.Now().Add(12*time.Hour), exp, time.Minute)
+	assert.True(t, strings.HasSuffix(req.Header.Get("Authorization"), ", k="+keys.PublicKey))
+
+	ps.status = http.StatusGone
+	assert.ErrorIs(t, sender.Send(sub, []byte("x"), Options{}), ErrGone)
+	ps.status = http.StatusBadRequest
+	err = sender.Send(sub, []byte("x"), Options{})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrGone)
+}
+
+func TestPusher(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.New(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+	require.NoError(t, st.AddProject(model.Project{ID: "p1", Name: "One"}))
+	require.NoError(t, st.AddProject(model.Project{ID: "p2", Name: "Two", Members: []model.ProjectMember{{UserID: "u1", Role: model.RoleViewer}}}))
+	subs, err := store.NewPushSubscriptionStore(filepath.Join(dir, "push.json"))
+	require.NoError(t, err)
+	keys, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	ps := newPushService(t)
+	pusher := NewPusher(NewSender(keys), subs, st)
+
+	browsers := map[string]*browser{}
+	add := func(id, userID string, levels, projects []string) {
+		b := newBrowser(t)
+		browsers[id] = b
+		p256dh, auth := b.keys()
+		require.NoError(t, subs.Put(model.PushSubscription{ID: id, UserID: userID, Endpoint: ps.URL + "/" + id, P256dh: p256dh, Auth: auth, Levels: levels, ProjectIDs: projects}))
+	}
+	add("admin-all", "", nil, nil)
+	add("admin-errors", "", []string{"error"}, nil)
+	add("admin-p2", "", nil, []string{"p2"})
+	add("member", "u1", nil, nil)
+
+	received := func() []string {
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+		var out []string
+		for _, r := range ps.requests {
+			out = append(out, strings.TrimPrefix(r.URL.Path, "/"))
+		}
+		ps.requests = nil
+		return out
+	}
+
+	pusher.Notify(&model.Notification{ID: "n1", Title: "Done", Level: "success", ProjectID: "p1", FeatureID: "f1", PaneID: "pane 1"})
+	pusher.Wait()
+	assert.ElementsMatch(t, []string{"admin-all"}, received(), "u1 is not a member of p1")
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(browsers["admin-all"].decrypt(t, ps.bodies["/admin-all"]), &payload))
+	assert.Equal(t, Payload{Title: "Done", Level: "success", Tag: "n1", URL: "/projects/p1/features/f1/?pane=pane+1", ProjectID: "p1"}, payload)
+
+	pusher.Notify(&model.Notification{ID: "n2", Title: "Failed", Level: "error", ProjectID: "p2"})
+	pusher.Wait()
+	assert.ElementsMatch(t, []string{"admin-all", "admin-errors", "admin-p2", "member"}, received())
+
+	pusher.Notify(&model.Notification{ID: "n3", Title: "Update", Level: "info"})
+	pusher.Wait()
+	assert.ElementsMatch(t, []string{"admin-all", "admin-p2", "member"}, received(), "no project passes the project filter")
+
+	// Expired subscriptions are dropped.
+	ps.status = http.StatusGone
+	pusher.Notify(&model.Notification{ID: "n4", Title: "Gone", Level: "error"})
+	pusher.Wait()
+	assert.Empty(t, subs.GetAll())
+
+	var nilPusher *Pusher
+	nilPusher.Notify(&model.Notification{})
+	nilPusher.Wait()
+}
+
+func TestNotificationPayloadTruncates(t *testing.T) {
+	p := NotificationPayload(model.Notification{Title: "t", Body: strings.Repeat("é", 5000)})
+	assert.Equal(t, "/", p.URL)
+	assert.Len(t, []rune(p.Body), maxBody+1)
+}
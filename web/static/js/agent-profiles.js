@@ -0,0 +1,98 @@
+// ClawIDE Agent Profiles
+// Choose which agent CLI (claude, codex, gemini, custom) a pane, feature or project launches.
+(function() {
+    'use strict';
+
+    var DIALOG_STYLES = 'bg-surface-base text-th-text-secondary rounded-xl shadow-2xl border border-th-border-strong p-0 backdrop:bg-black/60';
+    var INPUT_STYLES = 'w-full bg-surface-raised border border-th-border-strong rounded-lg px-3 py-2 text-sm text-th-text-primary placeholder-th-text-faint focus:outline-none focus:border-accent-border';
+    var BTN_PRIMARY = 'px-4 py-2 text-sm bg-accent hover:bg-accent-hover text-th-text-primary rounded-lg transition-colors font-medium';
+    var BTN_CANCEL = 'px-4 py-2 text-sm text-th-text-muted hover:text-th-text-primary hover:bg-surface-raised rounded-lg transition-colors';
+
+    function escapeHTML(str) {
+        var div = document.createElement('div');
+        div.appendChild(document.createTextNode(str || ''));
+        return div.innerHTML;
+    }
+
+    function currentFeatureID() {
+        var m = window.location.pathname.match(/^\/projects\/[^/]+\/features\/([^/]+)/);
+        return m ? m[1] : '';
+    }
+
+    // open shows the profile picker for an agent pane. node is the pane's
+    // layout node; its agent_profile is updated when the pane scope is saved.
+    function open(projectID, sessionID, node) {
+        fetch('/api/agent-profiles/')
+        .then(function(r) {
+            if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+            return r.json();
+        })
+        .then(function(profiles) { showDialog(projectID, sessionID, node, profiles); })
+        .catch(function(err) {
+            window.ClawIDEDialog.confirm('Agent Profiles', err.message || 'Failed to load agent profiles', { confirmLabel: 'OK' });
+        });
+    }
+
+    function showDialog(projectID, sessionID, node, profiles) {
+        var featureID = currentFeatureID();
+        var dialog = document.createElement('dialog');
+        dialog.className = DIALOG_STYLES;
+        dialog.style.minWidth = '360px';
+
+        var options = '<option value="">Default agent command</option>' + profiles.map(function(p) {
+            var label = p.name + (p.installed ? '' : ' (not installed)');
+            var selected = p.id === node.agent_profile ? ' selected' : '';
+            return '<option value="' + escapeHTML(p.id) + '"' + selected + '>' + escapeHTML(label) + '</option>';
+        }).join('');
+
+        dialog.innerHTML =
+            '<div class="px-6 pt-5 pb-4 space-y-3">' +
+            '  <h3 class="text-base font-semibold text-th-text-primary">Agent Profile</h3>' +
+            '  <select class="ap-profile ' + INPUT_STYLES + '">' + options + '</select>' +
+            '  <select class="ap-scope ' + INPUT_STYLES + '">' +
+            '    <option value="pane">This pane</option>' +
+            (featureID ? '    <option value="feature">All panes in this feature</option>' : '') +
+            '    <option value="project">All panes in this project</option>' +
+            '  </select>' +
+            '  <p class="text-xs text-th-text-faint">A pane\'s own profile wins over its feature\'s, which wins over the project\'s. Applies the next time the agent starts.</p>' +
+            '  <p class="ap-error text-xs text-red-400 hidden"></p>' +
+            '</div>' +
+            '<div class="flex justify-end gap-2 px-6 py-3 border-t border-th-border-strong">' +
+            '  <button type="button" class="ap-cancel ' + BTN_CANCEL + '">Cancel</button>' +
+            '  <button type="button" class="ap-save ' + BTN_PRIMARY + '">Save</button>' +
+            '</div>';
+
+        dialog.querySelector('.ap-cancel').onclick = function() { dialog.close(); };
+        dialog.addEventListener('close', function() { dialog.remove(); });
+        dialog.querySelector('.ap-save').onclick = function() {
+            var profileID = dialog.querySelector('.ap-profile').value;
+            var scope = dialog.querySelector('.ap-scope').value;
+            var url = '/projects/' + projectID + '/agent-profile';
+            if (scope === 'pane') {
+                url = '/projects/' + projectID + '/sessions/' + sessionID + '/panes/' + node.pane_id + '/agent-profile';
+            } else if (scope === 'feature') {
+                url = '/projects/' + projectID + '/features/' + featureID + '/agent-profile';
+            }
+            fetch(url, {
+                method: 'PUT',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ agent_profile: profileID }),
+            })
+            .then(function(r) {
+                if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                if (scope === 'pane') node.agent_profile = profileID;
+                dialog.close();
+            })
+            .catch(function(err) {
+                var el = dialog.querySelector('.ap-error');
+                el.textContent = err.message || 'Failed to save';
+                el.classList.remove('hidden');
+            });
+        };
+
+        document.body.appendChild(dialog);
+        dialog.showModal();
+    }
+
+    window.ClawIDEAgentProfiles = { open: open };
+})();
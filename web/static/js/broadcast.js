@@ -0,0 +1,118 @@
+// ClawIDE Broadcast Input
+// Sends the same input to several panes at once via POST /projects/{id}/broadcast.
+(function() {
+    'use strict';
+
+    var DIALOG_STYLES = 'bg-surface-base text-th-text-secondary rounded-xl shadow-2xl border border-th-border-strong p-0 backdrop:bg-black/60';
+    var INPUT_STYLES = 'w-full bg-surface-raised border border-th-border-strong rounded-lg px-3 py-2 text-sm text-th-text-primary placeholder-th-text-faint focus:outline-none focus:border-accent-border';
+    var BTN_PRIMARY = 'px-4 py-2 text-sm bg-accent hover:bg-accent-hover text-th-text-primary rounded-lg transition-colors font-medium';
+    var BTN_CANCEL = 'px-4 py-2 text-sm text-th-text-muted hover:text-th-text-primary hover:bg-surface-raised rounded-lg transition-colors';
+
+    function escapeHTML(str) {
+        var div = document.createElement('div');
+        div.appendChild(document.createTextNode(str || ''));
+        return div.innerHTML;
+    }
+
+    // currentFeatureID returns the feature of the open feature workspace, if any.
+    function currentFeatureID() {
+        var m = window.location.pathname.match(/^\/projects\/[^/]+\/features\/([^/]+)/);
+        return m ? m[1] : '';
+    }
+
+    function open(projectID, sessionID) {
+        var featureID = currentFeatureID();
+        var dialog = document.createElement('dialog');
+        dialog.className = DIALOG_STYLES;
+        dialog.style.minWidth = '380px';
+        dialog.style.maxWidth = '480px';
+
+        dialog.innerHTML =
+            '<div class="px-6 pt-5 pb-4 space-y-3">' +
+            '  <h3 class="text-base font-semibold text-th-text-primary">Broadcast Input</h3>' +
+            '  <div>' +
+            '    <label class="block text-xs text-th-text-muted mb-1.5">Input</label>' +
+            '    <textarea rows="3" class="bc-input ' + INPUT_STYLES + ' font-mono" placeholder="/clear"></textarea>' +
+            '  </div>' +
+            '  <div class="flex gap-2">' +
+            '    <select class="bc-scope ' + INPUT_STYLES + '">' +
+            '      <option value="session">This session</option>' +
+            (featureID ? '      <option value="feature">This feature</option>' : '') +
+            '      <option value="project">Whole project</option>' +
+            '    </select>' +
+            '    <select class="bc-type ' + INPUT_STYLES + '">' +
+            '      <option value="agent">Agent panes</option>' +
+            '      <option value="shell">Shell panes</option>' +
+            '      <option value="all">All panes</option>' +
+            '    </select>' +
+            '  </div>' +
+            '  <label class="flex items-center gap-2 text-xs text-th-text-muted">' +
+            '    <input type="checkbox" class="bc-enter" checked> Press Enter after the input' +
+            '  </label>' +
+            '  <ul class="bc-results space-y-1 text-xs max-h-48 overflow-y-auto"></ul>' +
+            '</div>' +
+            '<div class="flex justify-end gap-2 px-6 py-3 border-t border-th-border-strong">' +
+            '  <button type="button" class="bc-close ' + BTN_CANCEL + '">Close</button>' +
+            '  <button type="button" class="bc-send ' + BTN_PRIMARY + '">Send</button>' +
+            '</div>';
+
+        var input = dialog.querySelector('.bc-input');
+        var results = dialog.querySelector('.bc-results');
+        var sendBtn = dialog.querySelector('.bc-send');
+
+        function send() {
+            var body = {
+                input: input.value,
+                enter: dialog.querySelector('.bc-enter').checked,
+                scope: dialog.querySelector('.bc-scope').value,
+                pane_type: dialog.querySelector('.bc-type').value,
+                session_id: sessionID,
+                feature_id: featureID,
+            };
+            sendBtn.disabled = true;
+            results.innerHTML = '';
+            fetch('/projects/' + projectID + '/broadcast', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body),
+            })
+            .then(function(r) {
+                if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                return r.json();
+            })
+            .then(function(data) {
+                if (data.results.length === 0) {
+                    results.innerHTML = '<li class="text-th-text-faint">No matching panes.</li>';
+                    return;
+                }
+                data.results.forEach(function(res) {
+                    var li = document.createElement('li');
+                    li.className = res.ok ? 'text-green-400' : 'text-red-400';
+                    li.innerHTML = (res.ok ? '✓ ' : '✗ ') + '<span class="font-mono">' + escapeHTML(res.pane_id.slice(0, 8)) + '</span>' +
+                        (res.error ? ' — ' + escapeHTML(res.error) : '');
+                    results.appendChild(li);
+                });
+            })
+            .catch(function(err) {
+                results.innerHTML = '<li class="text-red-400">' + escapeHTML(err.message || 'Broadcast failed') + '</li>';
+            })
+            .finally(function() { sendBtn.disabled = false; });
+        }
+
+        sendBtn.addEventListener('click', send);
+        dialog.querySelector('.bc-close').addEventListener('click', function() { dialog.close(); });
+        dialog.addEventListener('close', function() { dialog.remove(); });
+        input.addEventListener('keydown', function(e) {
+            if (e.key === 'Enter' && (e.metaKey || e.ctrlKey)) {
+                e.preventDefault();
+                send();
+            }
+        });
+
+        document.body.appendChild(dialog);
+        dialog.showModal();
+        input.focus();
+    }
+
+    window.ClawIDEBroadcast = { open: open };
+})();
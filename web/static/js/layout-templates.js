@@ -0,0 +1,90 @@
+// ClawIDE Layout Templates
+// Save a session's pane layout as a named template and start new sessions from one.
+(function() {
+    'use strict';
+
+    var DIALOG_STYLES = 'bg-surface-base text-th-text-secondary rounded-xl shadow-2xl border border-th-border-strong p-0 backdrop:bg-black/60';
+    var INPUT_STYLES = 'w-full bg-surface-raised border border-th-border-strong rounded-lg px-3 py-2 text-sm text-th-text-primary placeholder-th-text-faint focus:outline-none focus:border-accent-border';
+    var BTN_PRIMARY = 'px-4 py-2 text-sm bg-accent hover:bg-accent-hover text-th-text-primary rounded-lg transition-colors font-medium';
+    var BTN_CANCEL = 'px-4 py-2 text-sm text-th-text-muted hover:text-th-text-primary hover:bg-surface-raised rounded-lg transition-colors';
+
+    function escapeHTML(str) {
+        var div = document.createElement('div');
+        div.appendChild(document.createTextNode(str || ''));
+        return div.innerHTML;
+    }
+
+    function saveFromSession(projectID, sessionID) {
+        window.ClawIDEDialog.prompt('Save Layout as Template', 'Template name', '').then(function(name) {
+            if (!name) return;
+            fetch('/api/layout-templates/', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ project_id: projectID, session_id: sessionID, name: name }),
+            })
+            .then(function(r) {
+                if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                if (window.ClawIDEToast) window.ClawIDEToast.show('Layout template "' + name + '" saved', 'success');
+            })
+            .catch(function(err) {
+                window.ClawIDEDialog.confirm('Save Failed', err.message || 'Failed to save layout template', { confirmLabel: 'OK' });
+            });
+        });
+    }
+
+    // openPicker lets the user choose a template and submits the regular
+    // new-session form to sessionsURL with its template_id.
+    function openPicker(projectID, sessionsURL) {
+        fetch('/api/layout-templates/?project_id=' + encodeURIComponent(projectID))
+        .then(function(r) {
+            if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+            return r.json();
+        })
+        .then(function(templates) {
+            if (templates.length === 0) {
+                window.ClawIDEDialog.confirm('No Layout Templates',
+                    'Save one with "Save Layout as Template..." in a pane menu.', { confirmLabel: 'OK' });
+                return;
+            }
+            showPicker(templates, sessionsURL);
+        })
+        .catch(function(err) {
+            window.ClawIDEDialog.confirm('Layout Templates', err.message || 'Failed to load templates', { confirmLabel: 'OK' });
+        });
+    }
+
+    function showPicker(templates, sessionsURL) {
+        var dialog = document.createElement('dialog');
+        dialog.className = DIALOG_STYLES;
+        dialog.style.minWidth = '340px';
+
+        var options = templates.map(function(t) {
+            var scope = t.project_id ? 'project' : 'global';
+            return '<option value="' + escapeHTML(t.id) + '">' + escapeHTML(t.name) + ' (' + scope + ')</option>';
+        }).join('');
+
+        dialog.innerHTML =
+            '<form method="POST" action="' + escapeHTML(sessionsURL) + '">' +
+            '  <div class="px-6 pt-5 pb-4 space-y-3">' +
+            '    <h3 class="text-base font-semibold text-th-text-primary">New Session from Template</h3>' +
+            '    <select name="template_id" class="' + INPUT_STYLES + '">' + options + '</select>' +
+            '    <input type="text" name="name" class="' + INPUT_STYLES + '" placeholder="Session name (optional)">' +
+            '  </div>' +
+            '  <div class="flex justify-end gap-2 px-6 py-3 border-t border-th-border-strong">' +
+            '    <button type="button" class="lt-cancel ' + BTN_CANCEL + '">Cancel</button>' +
+            '    <button type="submit" class="' + BTN_PRIMARY + '">Create</button>' +
+            '  </div>' +
+            '</form>';
+
+        dialog.querySelector('.lt-cancel').addEventListener('click', function() { dialog.close(); });
+        dialog.addEventListener('close', function() { dialog.remove(); });
+
+        document.body.appendChild(dialog);
+        dialog.showModal();
+    }
+
+    window.ClawIDELayoutTemplates = {
+        saveFromSession: saveFromSession,
+        openPicker: openPicker,
+    };
+})();
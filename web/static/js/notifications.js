@@ -32,19 +32,54 @@ function notificationBell() {
             this.eventSource.addEventListener('notification', (e) => {
                 try {
                     var n = JSON.parse(e.data);
+                    // A repeat that escalated, or one held for quiet hours,
+                    // replaces the entry it updates.
+                    var i = this.notifications.findIndex(x => x.id === n.id);
+                    var counted = i >= 0 && !this.notifications[i].read && !this.notifications[i].held;
+                    if (i >= 0) {
+                        this.notifications.splice(i, 1);
+                    }
                     // Prepend to list
                     this.notifications.unshift(n);
                     // Keep max 50 in memory
                     if (this.notifications.length > 50) {
                         this.notifications = this.notifications.slice(0, 50);
                     }
-                    this.unreadCount++;
+                    if (!counted) {
+                        this.unreadCount++;
+                    }
                     this.showDesktopNotification(n);
                 } catch (err) {
                     console.error('Failed to parse notification:', err);
                 }
             });
 
+            // Agent pane state changes are re-dispatched for the pane toolbar.
+            this.eventSource.addEventListener('agent-state', (e) => {
+                try {
+                    window.dispatchEvent(new CustomEvent('clawide:agent-state', { detail: JSON.parse(e.data) }));
+                } catch (err) {
+                    console.error('Failed to parse agent state:', err);
+                }
+            });
+
+            // A bookmark opened from the API (e.g. by an agent) opens in the
+            // visible tab only, so several open tabs don't all open it.
+            this.eventSource.addEventListener('open-url', (e) => {
+                try {
+                    var b = JSON.parse(e.data);
+                    if (document.hidden || !/^https?:\/\//i.test(b.url)) return;
+                    var win = window.open(b.url, '_blank');
+                    if (win) {
+                        win.opener = null;
+                    } else {
+                        window.ClawIDEToast.show('Allow pop-ups to open ' + b.name + ': ' + b.url, 5000);
+                    }
+                } catch (err) {
+                    console.error('Failed to parse open-url event:', err);
+                }
+            });
+
             this.eventSource.onerror = () => {
                 this.eventSource.close();
                 this.eventSource = null;
@@ -54,6 +89,22 @@ function notificationBell() {
             };
         },
 
+        // Notifications the rules grouped are listed under their group, in
+        // the order the groups last saw activity.
+        get groups() {
+            var groups = [];
+            var byKey = {};
+            this.notifications.forEach(n => {
+                var key = n.group_key || ('id:' + n.id);
+                if (!byKey[key]) {
+                    byKey[key] = { key: key, label: n.group_key ? n.group : '', items: [] };
+                    groups.push(byKey[key]);
+                }
+                byKey[key].items.push(n);
+            });
+            return groups;
+        },
+
         toggle() {
             this.open = !this.open;
             if (this.open) {
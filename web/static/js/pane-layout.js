@@ -183,6 +183,16 @@
             var nameSpan = createPaneName(node, projectID, sessionID);
             toolbarLeft.appendChild(nameSpan);
 
+            // Agent activity indicator, updated from agent-state events
+            if (node.pane_type === 'agent') {
+                var stateDot = document.createElement('span');
+                stateDot.className = 'agent-state-dot w-2 h-2 rounded-full bg-th-text-faint';
+                stateDot.dataset.paneId = node.pane_id;
+                stateDot.title = 'Agent state unknown';
+                toolbarLeft.appendChild(stateDot);
+                applyAgentState(stateDot, agentStates[node.pane_id]);
+            }
+
             toolbar.appendChild(toolbarLeft);
 
             var toolbarRight = document.createElement('div');
@@ -243,6 +253,81 @@
             };
             kebabMenu.appendChild(menuItemPaste);
 
+            // Startup command, working directory and environment
+            var menuItemStartup = document.createElement('button');
+            menuItemStartup.className = 'w-full text-left px-3 py-1.5 text-xs text-th-text-tertiary hover:bg-surface-overlay hover:text-th-text-primary';
+            menuItemStartup.textContent = 'Startup Settings...';
+            menuItemStartup.onclick = function() {
+                kebabMenu.classList.add('hidden');
+                editPaneStartup(projectID, sessionID, node);
+            };
+            kebabMenu.appendChild(menuItemStartup);
+
+            // Agent CLI profile (agent panes only)
+            if (node.pane_type === 'agent') {
+                var menuItemProfile = document.createElement('button');
+                menuItemProfile.className = 'w-full text-left px-3 py-1.5 text-xs text-th-text-tertiary hover:bg-surface-overlay hover:text-th-text-primary';
+                menuItemProfile.textContent = 'Agent Profile...';
+                menuItemProfile.onclick = function() {
+                    kebabMenu.classList.add('hidden');
+                    window.ClawIDEAgentProfiles.open(projectID, sessionID, node);
+                };
+                kebabMenu.appendChild(menuItemProfile);
+            }
+
+            // Memory, CPU and run-time limits
+            var menuItemLimits = document.createElement('button');
+            menuItemLimits.className = 'w-full text-left px-3 py-1.5 text-xs text-th-text-tertiary hover:bg-surface-overlay hover:text-th-text-primary';
+            menuItemLimits.textContent = 'Resource Limits...';
+            menuItemLimits.onclick = function() {
+                kebabMenu.classList.add('hidden');
+                window.ClawIDEResourceLimits.open(projectID, sessionID, node);
+            };
+            kebabMenu.appendChild(menuItemLimits);
+
+            // Broadcast input to several panes
+            var menuItemBroadcast = document.createElement('button');
+            menuItemBroadcast.className = 'w-full text-left px-3 py-1.5 text-xs text-th-text-tertiary hover:bg-surface-overlay hover:text-th-text-primary';
+            menuItemBroadcast.textContent = 'Broadcast Input...';
+            menuItemBroadcast.onclick = function() {
+                kebabMenu.classList.add('hidden');
+                window.ClawIDEBroadcast.open(projectID, sessionID);
+            };
+            kebabMenu.appendChild(menuItemBroadcast);
+
+            // Save the whole session layout as a reusable template
+            var menuItemTemplate = document.createElement('button');
+            menuItemTemplate.className = 'w-full text-left px-3 py-1.5 text-xs text-th-text-tertiary hover:bg-surface-overlay hover:text-th-text-primary';
+            menuItemTemplate.textContent = 'Save Layout as Template...';
+            menuItemTemplate.onclick = function() {
+                kebabMenu.classList.add('hidden');
+                window.ClawIDELayoutTemplates.saveFromSession(projectID, sessionID);
+            };
+            kebabMenu.appendChild(menuItemTemplate);
+
+            // Read-only share link
+            var menuItemShare = document.createElement('button');
+            menuItemShare.className = 'w-full text-left px-3 py-1.5 text-xs text-th-text-tertiary hover:bg-surface-overlay hover:text-th-text-primary';
+            menuItemShare.textContent = 'Share Read-Only Link';
+            menuItemShare.onclick = function() {
+                kebabMenu.classList.add('hidden');
+                sharePane(projectID, sessionID, node.pane_id);
+            };
+            kebabMenu.appendChild(menuItemShare);
+
+            // Asciicast recording toggle
+            var menuItemRecord = document.createElement('button');
+            menuItemRecord.className = 'w-full text-left px-3 py-1.5 text-xs text-th-text-tertiary hover:bg-surface-overlay hover:text-th-text-primary';
+            menuItemRecord.textContent = node.record ? 'Stop Recording' : 'Start Recording';
+            menuItemRecord.onclick = function() {
+                kebabMenu.classList.add('hidden');
+                setPaneRecording(projectID, sessionID, node.pane_id, !node.record).then(function(enabled) {
+                    node.record = enabled;
+                    menuItemRecord.textContent = enabled ? 'Stop Recording' : 'Start Recording';
+                });
+            };
+            kebabMenu.appendChild(menuItemRecord);
+
             kebabBtn.onclick = function(e) {
                 e.stopPropagation();
                 kebabMenu.classList.toggle('hidden');
@@ -690,6 +775,44 @@
         });
     });
 
+    // --- Agent state ---
+
+    var agentStates = {};
+    var agentStateClasses = {
+        working: 'bg-blue-400 animate-pulse',
+        idle: 'bg-green-400',
+        waiting: 'bg-amber-400 animate-pulse',
+        stopped: 'bg-th-text-faint',
+    };
+    var agentStateTitles = {
+        working: 'Agent is working',
+        idle: 'Agent is idle',
+        waiting: 'Agent is waiting for input',
+        stopped: 'Agent is not running',
+    };
+
+    function applyAgentState(dot, st) {
+        if (!st) return;
+        dot.className = 'agent-state-dot w-2 h-2 rounded-full ' + (agentStateClasses[st.state] || 'bg-th-text-faint');
+        dot.title = (agentStateTitles[st.state] || st.state) + (st.prompt ? ': ' + st.prompt : '');
+    }
+
+    function updateAgentState(st) {
+        agentStates[st.pane_id] = st;
+        document.querySelectorAll('.agent-state-dot[data-pane-id="' + st.pane_id + '"]').forEach(function(dot) {
+            applyAgentState(dot, st);
+        });
+    }
+
+    window.addEventListener('clawide:agent-state', function(e) {
+        updateAgentState(e.detail);
+    });
+
+    fetch('/api/agent-state')
+        .then(function(r) { return r.ok ? r.json() : []; })
+        .then(function(list) { list.forEach(updateAgentState); })
+        .catch(function() {});
+
     // --- API functions ---
 
     function splitPane(projectID, sessionID, paneID, direction, paneType) {
@@ -743,6 +866,118 @@
         }, 50);
     }
 
+    function sharePane(projectID, sessionID, paneID) {
+        window.ClawIDEDialog.prompt('Share Read-Only Link', 'Expires after (minutes)', '60').then(function(minutes) {
+            if (minutes === null) return;
+            fetch('/projects/' + projectID + '/sessions/' + sessionID + '/panes/' + paneID + '/share', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ expires_in_minutes: parseInt(minutes, 10) || 0 }),
+            })
+            .then(function(r) {
+                if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                return r.json();
+            })
+            .then(function(data) {
+                if (navigator.clipboard && navigator.clipboard.writeText) {
+                    navigator.clipboard.writeText(data.url).catch(function() {});
+                }
+                window.ClawIDEDialog.prompt('Share Link Created', 'Anyone with this link can watch the pane until it expires', data.url);
+            })
+            .catch(function(err) {
+                window.ClawIDEDialog.confirm('Share Failed', err.message || 'Failed to create share link', { confirmLabel: 'OK' });
+            });
+        });
+    }
+
+    // editPaneStartup edits the command, working directory and extra env a
+    // pane's shell starts with. Changes apply the next time the shell starts.
+    function editPaneStartup(projectID, sessionID, node) {
+        var inputCls = 'w-full bg-surface-raised border border-th-border-strong rounded-lg px-3 py-2 text-sm text-th-text-primary placeholder-th-text-faint focus:outline-none focus:border-accent-border font-mono';
+        var env = node.env || {};
+        var envText = Object.keys(env).sort().map(function(k) { return k + '=' + env[k]; }).join('\n');
+
+        var dialog = document.createElement('dialog');
+        dialog.className = 'bg-surface-base text-th-text-secondary rounded-xl shadow-2xl border border-th-border-strong p-0 backdrop:bg-black/60';
+        dialog.style.minWidth = '380px';
+        dialog.innerHTML =
+            '<div class="px-6 pt-5 pb-4 space-y-3">' +
+            '  <h3 class="text-base font-semibold text-th-text-primary">Pane Startup Settings</h3>' +
+            '  <label class="block text-xs text-th-text-muted">Command<input type="text" class="ps-command mt-1 ' + inputCls + '" placeholder="npm run dev"></label>' +
+            '  <label class="block text-xs text-th-text-muted">Working directory<input type="text" class="ps-workdir mt-1 ' + inputCls + '" placeholder="relative to the session directory"></label>' +
+            '  <label class="block text-xs text-th-text-muted">Environment (KEY=value per line)<textarea rows="3" class="ps-env mt-1 ' + inputCls + '"></textarea></label>' +
+            '  <p class="text-xs text-th-text-faint">Applies the next time this pane\'s shell starts.</p>' +
+            '  <p class="ps-error text-xs text-red-400 hidden"></p>' +
+            '</div>' +
+            '<div class="flex justify-end gap-2 px-6 py-3 border-t border-th-border-strong">' +
+            '  <button type="button" class="ps-cancel px-4 py-2 text-sm text-th-text-muted hover:text-th-text-primary hover:bg-surface-raised rounded-lg transition-colors">Cancel</button>' +
+            '  <button type="button" class="ps-save px-4 py-2 text-sm bg-accent hover:bg-accent-hover text-th-text-primary rounded-lg transition-colors font-medium">Save</button>' +
+            '</div>';
+        dialog.querySelector('.ps-command').value = node.command || '';
+        dialog.querySelector('.ps-workdir').value = node.work_dir || '';
+        dialog.querySelector('.ps-env').value = envText;
+
+        dialog.querySelector('.ps-cancel').onclick = function() { dialog.close(); };
+        dialog.addEventListener('close', function() { dialog.remove(); });
+        dialog.querySelector('.ps-save').onclick = function() {
+            var newEnv = {};
+            dialog.querySelector('.ps-env').value.split('\n').forEach(function(line) {
+                var eq = line.indexOf('=');
+                if (eq > 0) newEnv[line.slice(0, eq).trim()] = line.slice(eq + 1);
+            });
+            var body = {
+                command: dialog.querySelector('.ps-command').value,
+                work_dir: dialog.querySelector('.ps-workdir').value,
+                env: newEnv,
+            };
+            fetch('/projects/' + projectID + '/sessions/' + sessionID + '/panes/' + node.pane_id + '/startup', {
+                method: 'PUT',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body),
+            })
+            .then(function(r) {
+                if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                return r.json();
+            })
+            .then(function(pane) {
+                node.command = pane.command;
+                node.work_dir = pane.work_dir;
+                node.env = pane.env;
+                dialog.close();
+            })
+            .catch(function(err) {
+                var el = dialog.querySelector('.ps-error');
+                el.textContent = err.message || 'Failed to save';
+                el.classList.remove('hidden');
+            });
+        };
+
+        document.body.appendChild(dialog);
+        dialog.showModal();
+    }
+
+    function setPaneRecording(projectID, sessionID, paneID, enabled) {
+        return fetch('/projects/' + projectID + '/sessions/' + sessionID + '/panes/' + paneID + '/recording', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ enabled: enabled }),
+        })
+        .then(function(r) {
+            if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+            return r.json();
+        })
+        .then(function(data) {
+            if (window.ClawIDEToast) {
+                window.ClawIDEToast.show(data.recording ? 'Recording started' : 'Recording stopped');
+            }
+            return data.recording;
+        })
+        .catch(function(err) {
+            console.error('Failed to toggle recording:', err);
+            return !enabled;
+        });
+    }
+
     function persistResize(projectID, sessionID, paneID, ratio) {
         fetch('/projects/' + projectID + '/sessions/' + sessionID + '/panes/' + paneID + '/resize', {
             method: 'PATCH',
@@ -0,0 +1,49 @@
+// ClawIDE Ports
+// Lists the ports a project or feature listens on (dev servers started in its
+// panes and its compose file's published ports) as clickable links.
+(function() {
+    'use strict';
+
+    var POLL_MS = 5000;
+
+    // panel returns the Alpine state for a ports dropdown backed by url.
+    // The list is fetched on load, and refreshed while the dropdown is open.
+    function panel(url) {
+        return {
+            open: false,
+            ports: [],
+            error: '',
+            timer: null,
+            init: function() {
+                this.refresh();
+                this.$watch('open', function(open) {
+                    if (open) {
+                        this.refresh();
+                        this.timer = setInterval(this.refresh.bind(this), POLL_MS);
+                    } else {
+                        clearInterval(this.timer);
+                    }
+                }.bind(this));
+            },
+            refresh: function() {
+                var self = this;
+                fetch(url)
+                .then(function(r) {
+                    if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                    return r.json();
+                })
+                .then(function(data) {
+                    self.ports = data.ports || [];
+                    self.error = '';
+                })
+                .catch(function(err) { self.error = err.message || 'Failed to detect ports'; });
+            },
+            label: function(p) {
+                if (p.source === 'compose') return p.service + ' (compose)';
+                return p.process + (p.pane_name ? ' in ' + p.pane_name : '');
+            },
+        };
+    }
+
+    window.ClawIDEPorts = { panel: panel };
+})();
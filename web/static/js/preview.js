@@ -0,0 +1,73 @@
+// ClawIDE Feature Preview
+// Point a feature at its dev server's port; ClawIDE proxies it at
+// /preview/{feature id}/ and links it from the bookmark bar.
+(function() {
+    'use strict';
+
+    var DIALOG_STYLES = 'bg-surface-base text-th-text-secondary rounded-xl shadow-2xl border border-th-border-strong p-0 backdrop:bg-black/60';
+    var INPUT_STYLES = 'w-full bg-surface-raised border border-th-border-strong rounded-lg px-3 py-2 text-sm text-th-text-primary placeholder-th-text-faint focus:outline-none focus:border-accent-border';
+    var BTN_PRIMARY = 'px-4 py-2 text-sm bg-accent hover:bg-accent-hover text-th-text-primary rounded-lg transition-colors font-medium';
+    var BTN_CANCEL = 'px-4 py-2 text-sm text-th-text-muted hover:text-th-text-primary hover:bg-surface-raised rounded-lg transition-colors';
+    var BTN_DANGER = 'px-4 py-2 text-sm text-red-400 hover:text-red-300 hover:bg-red-900/20 rounded-lg transition-colors mr-auto';
+
+    // open shows the preview settings. preview is the feature's current
+    // settings ({port, keep_prefix, base_href}) or null.
+    function open(projectID, featureID, preview) {
+        var p = preview || {};
+        var dialog = document.createElement('dialog');
+        dialog.className = DIALOG_STYLES;
+        dialog.style.minWidth = '380px';
+
+        dialog.innerHTML =
+            '<div class="px-6 pt-5 pb-4 space-y-3">' +
+            '  <h3 class="text-base font-semibold text-th-text-primary">Feature Preview</h3>' +
+            '  <label class="block text-xs text-th-text-muted">Dev server port' +
+            '    <input type="number" min="1" max="65535" class="pv-port mt-1 ' + INPUT_STYLES + '" value="' + (p.port || '') + '" placeholder="5173">' +
+            '  </label>' +
+            '  <label class="flex items-center gap-2 text-xs text-th-text-muted"><input type="checkbox" class="pv-keep"' + (p.keep_prefix ? ' checked' : '') + '> Keep the /preview/&hellip; prefix (server is configured with that base path)</label>' +
+            '  <label class="flex items-center gap-2 text-xs text-th-text-muted"><input type="checkbox" class="pv-base"' + (p.base_href ? ' checked' : '') + '> Inject &lt;base href&gt; into HTML pages</label>' +
+            '  <p class="text-xs text-th-text-faint">Served at <span class="font-mono">/preview/' + featureID + '/</span> on this ClawIDE, WebSockets (HMR) included, and linked from the bookmark bar. Pages run with your ClawIDE session, so only preview code you trust.</p>' +
+            '  <p class="pv-error text-xs text-red-400 hidden"></p>' +
+            '</div>' +
+            '<div class="flex justify-end gap-2 px-6 py-3 border-t border-th-border-strong">' +
+            (p.port ? '  <button type="button" class="pv-remove ' + BTN_DANGER + '">Remove</button>' : '') +
+            '  <button type="button" class="pv-cancel ' + BTN_CANCEL + '">Cancel</button>' +
+            '  <button type="button" class="pv-save ' + BTN_PRIMARY + '">Save</button>' +
+            '</div>';
+
+        function save(body) {
+            fetch('/projects/' + projectID + '/features/' + featureID + '/preview', {
+                method: 'PUT',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body),
+            })
+            .then(function(r) {
+                if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                // The header buttons and bookmark bar are rendered server-side.
+                window.location.reload();
+            })
+            .catch(function(err) {
+                var el = dialog.querySelector('.pv-error');
+                el.textContent = err.message || 'Failed to save';
+                el.classList.remove('hidden');
+            });
+        }
+
+        dialog.querySelector('.pv-cancel').onclick = function() { dialog.close(); };
+        dialog.addEventListener('close', function() { dialog.remove(); });
+        dialog.querySelector('.pv-save').onclick = function() {
+            save({
+                port: parseInt(dialog.querySelector('.pv-port').value, 10) || 0,
+                keep_prefix: dialog.querySelector('.pv-keep').checked,
+                base_href: dialog.querySelector('.pv-base').checked,
+            });
+        };
+        var remove = dialog.querySelector('.pv-remove');
+        if (remove) remove.onclick = function() { save({ port: 0 }); };
+
+        document.body.appendChild(dialog);
+        dialog.showModal();
+    }
+
+    window.ClawIDEPreview = { open: open };
+})();
@@ -0,0 +1,105 @@
+// ClawIDE Resource Limits
+// Cap a pane's (or a whole project's) memory, CPU and command run time, and
+// choose what happens when a limit is hit.
+(function() {
+    'use strict';
+
+    var DIALOG_STYLES = 'bg-surface-base text-th-text-secondary rounded-xl shadow-2xl border border-th-border-strong p-0 backdrop:bg-black/60';
+    var INPUT_STYLES = 'w-full bg-surface-raised border border-th-border-strong rounded-lg px-3 py-2 text-sm text-th-text-primary placeholder-th-text-faint focus:outline-none focus:border-accent-border';
+    var BTN_PRIMARY = 'px-4 py-2 text-sm bg-accent hover:bg-accent-hover text-th-text-primary rounded-lg transition-colors font-medium';
+    var BTN_CANCEL = 'px-4 py-2 text-sm text-th-text-muted hover:text-th-text-primary hover:bg-surface-raised rounded-lg transition-colors';
+
+    function actionSelect(cls, value) {
+        var actions = [['notify', 'Notify'], ['interrupt', 'Send Ctrl-C'], ['kill', 'Kill process tree']];
+        return '<select class="' + cls + ' ' + INPUT_STYLES + '">' + actions.map(function(a) {
+            return '<option value="' + a[0] + '"' + (a[0] === value ? ' selected' : '') + '>' + a[1] + '</option>';
+        }).join('') + '</select>';
+    }
+
+    function row(label, input, action) {
+        return '<div class="grid grid-cols-2 gap-2 items-end">' +
+            '  <label class="block text-xs text-th-text-muted">' + label + input + '</label>' +
+            '  <label class="block text-xs text-th-text-muted">Then' + action + '</label>' +
+            '</div>';
+    }
+
+    function numberInput(cls, value, placeholder) {
+        return '<input type="number" min="0" class="' + cls + ' mt-1 ' + INPUT_STYLES + '" value="' + (value || '') + '" placeholder="' + placeholder + '">';
+    }
+
+    // open shows the limits editor for a pane. node is the pane's layout
+    // node; its resource_limits is updated when the pane scope is saved.
+    function open(projectID, sessionID, node) {
+        var l = node.resource_limits || {};
+        var dialog = document.createElement('dialog');
+        dialog.className = DIALOG_STYLES;
+        dialog.style.minWidth = '420px';
+
+        dialog.innerHTML =
+            '<div class="px-6 pt-5 pb-4 space-y-3">' +
+            '  <h3 class="text-base font-semibold text-th-text-primary">Resource Limits</h3>' +
+            row('Memory (MB)', numberInput('rl-rss', l.max_rss_mb, 'No limit'), actionSelect('rl-rss-action', l.rss_action)) +
+            row('CPU (% of one core)', numberInput('rl-cpu', l.max_cpu_percent, 'No limit'), actionSelect('rl-cpu-action', l.cpu_action)) +
+            '  <label class="block text-xs text-th-text-muted">CPU must stay over the limit for (seconds)' + numberInput('rl-cpu-seconds', l.cpu_seconds, '60') + '</label>' +
+            row('Command run time (seconds)', numberInput('rl-wall', l.max_wall_seconds, 'No limit'), actionSelect('rl-wall-action', l.wall_action)) +
+            '  <select class="rl-scope ' + INPUT_STYLES + '">' +
+            '    <option value="pane">This pane</option>' +
+            '    <option value="project">All panes in this project</option>' +
+            '  </select>' +
+            '  <p class="text-xs text-th-text-faint">Limits cover the pane\'s whole process tree. Run time applies to shell panes only. Each limit a pane sets replaces the project\'s. Leave everything empty to remove the limits.</p>' +
+            '  <p class="rl-error text-xs text-red-400 hidden"></p>' +
+            '</div>' +
+            '<div class="flex justify-end gap-2 px-6 py-3 border-t border-th-border-strong">' +
+            '  <button type="button" class="rl-cancel ' + BTN_CANCEL + '">Cancel</button>' +
+            '  <button type="button" class="rl-save ' + BTN_PRIMARY + '">Save</button>' +
+            '</div>';
+
+        function num(cls) {
+            var v = parseFloat(dialog.querySelector('.' + cls).value);
+            return isNaN(v) ? 0 : v;
+        }
+        function val(cls) { return dialog.querySelector('.' + cls).value; }
+
+        dialog.querySelector('.rl-cancel').onclick = function() { dialog.close(); };
+        dialog.addEventListener('close', function() { dialog.remove(); });
+        dialog.querySelector('.rl-save').onclick = function() {
+            var scope = val('rl-scope');
+            var body = {
+                max_rss_mb: Math.round(num('rl-rss')),
+                rss_action: val('rl-rss-action'),
+                max_cpu_percent: num('rl-cpu'),
+                cpu_seconds: Math.round(num('rl-cpu-seconds')),
+                cpu_action: val('rl-cpu-action'),
+                max_wall_seconds: Math.round(num('rl-wall')),
+                wall_action: val('rl-wall-action'),
+            };
+            var url = '/projects/' + projectID + '/resource-limits';
+            if (scope === 'pane') {
+                url = '/projects/' + projectID + '/sessions/' + sessionID + '/panes/' + node.pane_id + '/resource-limits';
+            }
+            fetch(url, {
+                method: 'PUT',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body),
+            })
+            .then(function(r) {
+                if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+                return r.json();
+            })
+            .then(function(data) {
+                if (scope === 'pane') node.resource_limits = data.resource_limits || undefined;
+                dialog.close();
+            })
+            .catch(function(err) {
+                var el = dialog.querySelector('.rl-error');
+                el.textContent = err.message || 'Failed to save';
+                el.classList.remove('hidden');
+            });
+        };
+
+        document.body.appendChild(dialog);
+        dialog.showModal();
+    }
+
+    window.ClawIDEResourceLimits = { open: open };
+})();
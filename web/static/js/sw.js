@@ -0,0 +1,34 @@
+// ClawIDE service worker: shows Web Push notifications while no ClawIDE tab
+// is open (or in the background) and opens the linked page when clicked.
+self.addEventListener('push', function(event) {
+    var data = {};
+    try {
+        data = event.data ? event.data.json() : {};
+    } catch (err) {
+        data = { title: 'ClawIDE', body: event.data ? event.data.text() : '' };
+    }
+    event.waitUntil(self.registration.showNotification(data.title || 'ClawIDE', {
+        body: data.body || '',
+        tag: data.tag,
+        icon: '/static/favicon.svg',
+        badge: '/static/favicon.svg',
+        requireInteraction: data.level === 'error',
+        data: { url: data.url || '/' }
+    }));
+});
+
+self.addEventListener('notificationclick', function(event) {
+    event.notification.close();
+    var url = new URL(event.notification.data.url || '/', self.location.origin).href;
+    event.waitUntil(self.clients.matchAll({ type: 'window', includeUncontrolled: true }).then(function(windows) {
+        for (var i = 0; i < windows.length; i++) {
+            if (windows[i].url === url && 'focus' in windows[i]) {
+                return windows[i].focus();
+            }
+        }
+        if (windows.length && 'navigate' in windows[0]) {
+            return windows[0].focus().then(function(w) { return w.navigate(url); });
+        }
+        return self.clients.openWindow(url);
+    }));
+});